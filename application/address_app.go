@@ -0,0 +1,90 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/address_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/addresses"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/customers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/warehouses"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type AddressApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewAddressApplication(p *base.Persistence, c *gin.Context) address_repository.AddressHandlerRepository {
+	return &AddressApp{p, c}
+}
+
+func (a *AddressApp) SaveAddress(address *address_entity.Address) (*address_entity.Address, map[string]string) {
+	validationErr := address.Validate()
+	if len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	customer, _ := customers.NewCustomerRepository(a.p, a.c).GetCustomer(address.CustomerID)
+	if customer == nil || customer.ID == 0 {
+		return nil, map[string]string{"customer_not_found": fmt.Sprintf("customer %v not found", address.CustomerID)}
+	}
+
+	if err := a.ValidateServiceArea(address.Latitude, address.Longitude); err != nil {
+		return nil, map[string]string{"out_of_service_area": err.Error()}
+	}
+
+	repoAddress := addresses.NewAddressRepository(a.p, a.c)
+	return repoAddress.SaveAddress(address)
+}
+
+func (a *AddressApp) GetAddress(addressId int64) (*address_entity.Address, error) {
+	repoAddress := addresses.NewAddressRepository(a.p, a.c)
+	return repoAddress.GetAddress(addressId)
+}
+
+func (a *AddressApp) GetAddressesByCustomer(customerId int64) ([]address_entity.Address, error) {
+	repoAddress := addresses.NewAddressRepository(a.p, a.c)
+	return repoAddress.GetAddressesByCustomer(customerId)
+}
+
+func (a *AddressApp) UpdateAddress(address *address_entity.Address) (*address_entity.Address, error) {
+	validationErr := address.Validate()
+	for _, message := range validationErr {
+		return nil, errors.New(message)
+	}
+
+	if err := a.ValidateServiceArea(address.Latitude, address.Longitude); err != nil {
+		return nil, err
+	}
+
+	repoAddress := addresses.NewAddressRepository(a.p, a.c)
+	return repoAddress.UpdateAddress(address)
+}
+
+func (a *AddressApp) SetDefaultAddress(customerId int64, addressId int64) (*address_entity.Address, error) {
+	repoAddress := addresses.NewAddressRepository(a.p, a.c)
+	return repoAddress.SetDefaultAddress(customerId, addressId)
+}
+
+func (a *AddressApp) DeleteAddress(addressId int64) error {
+	repoAddress := addresses.NewAddressRepository(a.p, a.c)
+	return repoAddress.DeleteAddress(addressId)
+}
+
+// ValidateServiceArea rejects coordinates that no warehouse delivers to
+func (a *AddressApp) ValidateServiceArea(latitude float64, longitude float64) error {
+	serving, err := warehouses.NewWareHouseRepository(a.p, a.c).GetWarehousesServing(latitude, longitude)
+	if err != nil {
+		return err
+	}
+
+	if len(serving) == 0 {
+		return fmt.Errorf("no warehouse delivers to %v, %v", latitude, longitude)
+	}
+
+	return nil
+}
@@ -2,14 +2,24 @@ package application
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/auth_repository"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/auth"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/customers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/mailer"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/utils/security"
+	"gorm.io/gorm"
 )
 
 type AuthApp struct {
@@ -21,36 +31,545 @@ func NewAuthApplication(p *base.Persistence, c *gin.Context) auth_repository.Aut
 	return &AuthApp{p, c}
 }
 
-func (a *AuthApp) AuthenticateUser(username string, password string) (string, *customer_entity.Customer, error) {
+var errInvalidCredentials = errors.New("invalid username or password")
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// dummyPasswordHash is compared against when the username does not exist, so unknown usernames
+// take as long to reject as wrong passwords
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = security.Hash("not-a-real-password")
+	})
+	return string(dummyHash)
+}
+
+// progressiveDelay doubles the wait between attempts with every failure, up to 30 seconds
+func progressiveDelay(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+
+	delay := time.Second << uint(failures-2)
+	if delay > 30*time.Second || delay <= 0 {
+		return 30 * time.Second
+	}
+	return delay
+}
+
+func (a *AuthApp) clientIP() string {
+	if a.c == nil {
+		return ""
+	}
+	return a.c.ClientIP()
+}
+
+// AuthenticateUser checks the credentials and starts a session. Unknown usernames and wrong passwords
+// fail the same way and both count towards the lockout of the username and the IP address
+func (a *AuthApp) AuthenticateUser(username string, password string) (*auth_entity.TokenPair, *customer_entity.Customer, error) {
 	authRepo := auth.NewAuthRepository(a.p, a.c)
+	ip := a.clientIP()
+
+	if err := a.checkLoginAllowed(username, ip); err != nil {
+		return nil, nil, err
+	}
+
 	customer, err := authRepo.GetCustomerWithUsername(username)
 
 	if err != nil {
-		return "", nil, err
+		_ = security.VerifyPassword(dummyPasswordHash(), password)
+		a.recordLoginFailure(username, ip)
+		return nil, nil, errInvalidCredentials
 	}
 
 	verifiedPassword := security.VerifyPassword(customer.Password, password)
+	verifiedUsername := customer_entity.NormalizeUsername(customer.Username) == customer_entity.NormalizeUsername(username)
+
+	if verifiedPassword != nil || !verifiedUsername {
+		a.recordLoginFailure(username, ip)
+		return nil, nil, errInvalidCredentials
+	}
+
+	_ = authRepo.ClearLoginAttempts(auth.UsernameAttemptsKey(username))
+
+	// Every login starts a new refresh token family
+	familyId, err := auth.NewTokenID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, _, err := a.issueTokens(nil, customer, familyId)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if verifiedPassword != nil {
-		return "", nil, verifiedPassword
+	return tokens, customer, nil
+}
+
+// checkLoginAllowed rejects the attempt while the username or the IP address is locked or still waiting out its delay
+func (a *AuthApp) checkLoginAllowed(username string, ip string) error {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	now := time.Now()
+
+	var throttled *auth_entity.LoginThrottledError
+	for _, key := range []string{auth.UsernameAttemptsKey(username), auth.IPAttemptsKey(ip)} {
+		attempts := authRepo.GetLoginAttempts(key)
+
+		waitUntil, locked := attempts.NextAttemptAt, false
+		if attempts.LockedUntil.After(waitUntil) {
+			waitUntil, locked = attempts.LockedUntil, true
+		}
+
+		wait := waitUntil.Sub(now)
+		if wait > 0 && (throttled == nil || wait > throttled.RetryAfter) {
+			throttled = &auth_entity.LoginThrottledError{RetryAfter: wait, Locked: locked}
+		}
 	}
 
-	verifiedUsername := customer.Username == username
+	if throttled != nil {
+		return throttled
+	}
+	return nil
+}
+
+func (a *AuthApp) recordLoginFailure(username string, ip string) {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	now := time.Now()
 
-	if !verifiedUsername {
-		return "", nil, errors.New("Invalid username")
+	limits := []struct {
+		key         string
+		maxAttempts int
+		username    string
+		details     string
+	}{
+		{auth.UsernameAttemptsKey(username), auth.LoginMaxAttempts(), strings.ToLower(strings.TrimSpace(username)), "username locked"},
+		{auth.IPAttemptsKey(ip), auth.LoginMaxAttemptsPerIP(), "", "ip address locked"},
 	}
 
-	token, err := authRepo.GenerateToken([]byte(os.Getenv("JWT_SECRET")), int64(customer.ID), customer.Username)
+	for _, limit := range limits {
+		attempts := authRepo.GetLoginAttempts(limit.key)
+		attempts.Failures++
+		attempts.NextAttemptAt = now.Add(progressiveDelay(attempts.Failures))
 
+		if attempts.Failures >= limit.maxAttempts {
+			attempts.Failures = 0
+			attempts.LockedUntil = now.Add(auth.LoginLockout())
+
+			event := auth_entity.AuditEvent{
+				Event:    auth_entity.AuditLoginLockout,
+				Username: limit.username,
+				IP:       ip,
+				Details:  fmt.Sprintf("%v after %v failed attempts", limit.details, limit.maxAttempts),
+			}
+			if customer, err := authRepo.GetCustomerWithUsername(username); err == nil && limit.username != "" {
+				event.CustomerID = customer.ID
+			}
+			a.saveAuditEvent(&event)
+		}
+
+		if err := authRepo.SaveLoginAttempts(limit.key, attempts); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// UnlockLogin lifts the lockout of a username and/or an IP address
+func (a *AuthApp) UnlockLogin(username string, ip string) error {
+	if username == "" && ip == "" {
+		return errors.New("username or ip is required")
+	}
+
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	actor := ""
+	if a.c != nil {
+		actor = a.c.GetString("userID")
+	}
+
+	if username != "" {
+		if err := authRepo.ClearLoginAttempts(auth.UsernameAttemptsKey(username)); err != nil {
+			return err
+		}
+	}
+	if ip != "" {
+		if err := authRepo.ClearLoginAttempts(auth.IPAttemptsKey(ip)); err != nil {
+			return err
+		}
+	}
+
+	a.saveAuditEvent(&auth_entity.AuditEvent{
+		Event:    auth_entity.AuditLoginUnlock,
+		Username: strings.ToLower(strings.TrimSpace(username)),
+		IP:       ip,
+		Actor:    actor,
+	})
+
+	return nil
+}
+
+func (a *AuthApp) GetAuditEvents(event string, username string) ([]auth_entity.AuditEvent, error) {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	return authRepo.GetAuditEvents(event, username)
+}
+
+// saveAuditEvent writes the event to the audit table and the application log
+func (a *AuthApp) saveAuditEvent(event *auth_entity.AuditEvent) {
+	a.p.Logger.Warn("audit/"+event.Event, map[string]interface{}{
+		"username": event.Username,
+		"ip":       event.IP,
+		"actor":    event.Actor,
+		"details":  event.Details,
+	})
+
+	if err := auth.NewAuthRepository(a.p, a.c).SaveAuditEvent(event); err != nil {
+		log.Println(err)
+	}
+}
+
+// RefreshTokens exchanges a refresh token for a new access and refresh token. A refresh token that
+// was already rotated is a sign of theft, so the whole family it belongs to is revoked
+func (a *AuthApp) RefreshTokens(refreshToken string) (*auth_entity.TokenPair, error) {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+
+	storedToken, err := authRepo.GetRefreshToken(refreshToken)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	return token, customer, nil
+	if reused, checkErr := storedToken.CheckRefresh(time.Now()); checkErr != nil {
+		if reused {
+			a.revokeFamily(storedToken.FamilyID)
+		}
+		return nil, checkErr
+	}
+
+	customer, _ := customers.NewCustomerRepository(a.p, a.c).GetCustomer(storedToken.CustomerID)
+	if customer == nil || customer.ID == 0 {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	var tokens *auth_entity.TokenPair
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		var newToken *auth_entity.RefreshToken
+		var issueErr error
+		tokens, newToken, issueErr = a.issueTokens(tx, customer, storedToken.FamilyID)
+		if issueErr != nil {
+			return issueErr
+		}
+
+		return authRepo.ConsumeRefreshToken(tx, storedToken.ID, newToken.ID)
+	})
+	if txErr != nil {
+		a.revokeFamily(storedToken.FamilyID)
+		return nil, txErr
+	}
+
+	return tokens, nil
+}
+
+// Logout revokes the access token and the refresh tokens of the same login
+func (a *AuthApp) Logout(customerId int64, jti string) error {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	if err := authRepo.RevokeJTI(jti); err != nil {
+		return err
+	}
+
+	refreshToken, err := authRepo.GetRefreshTokenByAccessJTI(jti)
+	if err == nil && refreshToken.CustomerID == customerId {
+		a.revokeFamily(refreshToken.FamilyID)
+	}
+
+	return nil
 }
 
-func (a *AuthApp) BlacklistToken(token string) error {
+// LogoutAllSessions revokes every refresh token of the customer and every access token issued so far
+func (a *AuthApp) LogoutAllSessions(customerId int64) error {
 	authRepo := auth.NewAuthRepository(a.p, a.c)
-	return authRepo.BlacklistToken(token)
+	if err := authRepo.RevokeCustomerRefreshTokens(customerId); err != nil {
+		return err
+	}
+
+	return authRepo.RevokeCustomerAccessTokens(customerId)
+}
+
+func (a *AuthApp) RevokeToken(jti string) error {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	if err := authRepo.RevokeJTI(jti); err != nil {
+		return err
+	}
+
+	refreshToken, err := authRepo.GetRefreshTokenByAccessJTI(jti)
+	if err == nil {
+		a.revokeFamily(refreshToken.FamilyID)
+	}
+
+	return nil
+}
+
+func (a *AuthApp) GetJWKS() (*auth_entity.JWKS, error) {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	return authRepo.GetJWKS()
+}
+
+func (a *AuthApp) GetSigningKeys() ([]auth_entity.SigningKey, error) {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	return authRepo.GetSigningKeys()
+}
+
+func (a *AuthApp) RotateSigningKey(algorithm string) (*auth_entity.SigningKey, error) {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	return authRepo.RotateSigningKey(algorithm)
+}
+
+func (a *AuthApp) issueTokens(tx *gorm.DB, customer *customer_entity.Customer, familyId string) (*auth_entity.TokenPair, *auth_entity.RefreshToken, error) {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+
+	role := customer.Role
+	if !auth_entity.IsValidRole(role) {
+		role = auth_entity.RoleCustomer
+	}
+
+	accessToken, jti, err := authRepo.GenerateToken(int64(customer.ID), customer.Username, role)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawRefreshToken, refreshToken, err := authRepo.SaveRefreshToken(tx, customer.ID, familyId, jti)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens := &auth_entity.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: rawRefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(auth.AccessTokenTTL().Seconds()),
+	}
+
+	return tokens, refreshToken, nil
+}
+
+// revokeFamily revokes every refresh token of a login and the access tokens issued with them
+func (a *AuthApp) revokeFamily(familyId string) {
+	revokeTokenFamily(auth.NewAuthRepository(a.p, a.c), familyId)
+}
+
+// tokenFamilyRepository is the part of the auth repository that revokes a refresh token family
+type tokenFamilyRepository interface {
+	GetRefreshTokenFamily(familyId string) ([]auth_entity.RefreshToken, error)
+	RevokeRefreshTokenFamily(familyId string) error
+	RevokeJTI(jti string) error
+}
+
+func revokeTokenFamily(authRepo tokenFamilyRepository, familyId string) {
+	family, err := authRepo.GetRefreshTokenFamily(familyId)
+	if err != nil {
+		return
+	}
+
+	_ = authRepo.RevokeRefreshTokenFamily(familyId)
+	for _, refreshToken := range family {
+		_ = authRepo.RevokeJTI(refreshToken.AccessJTI)
+	}
+}
+
+// appLink builds a link into the frontend from APP_BASE_URL
+func appLink(path string, token string) string {
+	baseUrl := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+	if baseUrl == "" {
+		baseUrl = "http://localhost:8080"
+	}
+	return fmt.Sprintf("%v%v?token=%v", baseUrl, path, url.QueryEscape(token))
+}
+
+// RequestEmailVerification mails the customer a link to confirm their email address
+func (a *AuthApp) RequestEmailVerification(customerId int64) error {
+	customer, _ := customers.NewCustomerRepository(a.p, a.c).GetCustomer(customerId)
+	if customer == nil || customer.ID == 0 {
+		return fmt.Errorf("customer %v not found", customerId)
+	}
+	if customer.Email == "" {
+		return errors.New("customer has no email address")
+	}
+	if customer.EmailVerifiedAt != nil {
+		return errors.New("email address is already verified")
+	}
+
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	token, err := authRepo.GenerateActionToken(customerId, auth_entity.PurposeEmailVerification, customer.Email, auth.EmailVerificationTTL())
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %v,\n\nPlease confirm your email address by opening the link below:\n\n%v\n\nThe link expires in %v.\n",
+		customer.Name, appLink("/verify-email", token), auth.EmailVerificationTTL())
+	return mailer.NewMailerRepository(mailer.ConfiguredMailer()).SendMail(customer.Email, "Confirm your email address", body)
+}
+
+func (a *AuthApp) VerifyEmail(token string) (*customer_entity.Customer, error) {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	customerId, email, err := authRepo.ConsumeActionToken(token, auth_entity.PurposeEmailVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	return customers.NewCustomerRepository(a.p, a.c).MarkEmailVerified(customerId, email)
+}
+
+// RequestPasswordReset mails a reset link when the address belongs to a customer. It never reports
+// whether the address is known, failures are only logged
+func (a *AuthApp) RequestPasswordReset(email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return errors.New("email is required")
+	}
+
+	customer, err := customers.NewCustomerRepository(a.p, a.c).GetCustomerByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	token, err := authRepo.GenerateActionToken(int64(customer.ID), auth_entity.PurposePasswordReset, customer.Email, auth.PasswordResetTTL())
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	body := fmt.Sprintf("Hi %v,\n\nA password reset was requested for your account. Open the link below to choose a new password:\n\n%v\n\nThe link expires in %v. If you did not ask for this you can ignore this email.\n",
+		customer.Name, appLink("/reset-password", token), auth.PasswordResetTTL())
+	if err := mailer.NewMailerRepository(mailer.ConfiguredMailer()).SendMail(customer.Email, "Reset your password", body); err != nil {
+		log.Println(err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password from a reset link and signs the customer out everywhere
+func (a *AuthApp) ResetPassword(token string, newPassword string) error {
+	// Check what can be checked before the token is used up
+	if message := customer_entity.ValidatePassword(newPassword, ""); message != "" {
+		return errors.New(message)
+	}
+
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	customerId, _, err := authRepo.ConsumeActionToken(token, auth_entity.PurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	customerRepo := customers.NewCustomerRepository(a.p, a.c)
+	customer, _ := customerRepo.GetCustomer(customerId)
+	if customer == nil || customer.ID == 0 {
+		return fmt.Errorf("customer %v not found", customerId)
+	}
+	if message := customer_entity.ValidatePassword(newPassword, customer_entity.NormalizeUsername(customer.Username)); message != "" {
+		return errors.New(message)
+	}
+
+	hashedPassword, err := security.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := customerRepo.UpdatePassword(customerId, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	if err := a.LogoutAllSessions(customerId); err != nil {
+		log.Println(err)
+	}
+
+	_ = authRepo.ClearLoginAttempts(auth.UsernameAttemptsKey(customer.Username))
+
+	return nil
+}
+
+// CreateAPIKey issues a key limited to the requested scopes, each scope is a route permission
+func (a *AuthApp) CreateAPIKey(request auth_entity.APIKeyRequest, createdBy int64) (*auth_entity.CreatedAPIKey, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(request.Scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, scope := range request.Scopes {
+		if !auth_entity.IsValidPermission(scope) {
+			return nil, fmt.Errorf("unknown scope %v", scope)
+		}
+		// Keys cannot be used to hand out more keys or roles
+		if scope == auth_entity.PermAPIKeysManage || scope == auth_entity.PermRolesManage {
+			return nil, fmt.Errorf("scope %v cannot be granted to an api key", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if request.ExpiresInDays < 0 {
+		return nil, errors.New("expires_in_days cannot be negative")
+	}
+
+	apiKey := auth_entity.APIKey{
+		Name:      name,
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+	}
+	if request.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, request.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	rawKey, err := authRepo.SaveAPIKey(&apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	a.saveAuditEvent(&auth_entity.AuditEvent{
+		Event:      auth_entity.AuditAPIKeyCreated,
+		CustomerID: createdBy,
+		IP:         a.clientIP(),
+		Actor:      fmt.Sprint(createdBy),
+		Details:    fmt.Sprintf("%v (%v) scopes %v", apiKey.Name, apiKey.Prefix, strings.Join(scopes, ",")),
+	})
+
+	return &auth_entity.CreatedAPIKey{Key: rawKey, APIKey: &apiKey}, nil
+}
+
+func (a *AuthApp) GetAPIKeys() ([]auth_entity.APIKey, error) {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	return authRepo.GetAPIKeys()
+}
+
+func (a *AuthApp) RevokeAPIKey(id uint64) error {
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	apiKey, err := authRepo.GetAPIKey(id)
+	if err != nil {
+		return err
+	}
+
+	if err := authRepo.RevokeAPIKey(id); err != nil {
+		return err
+	}
+
+	actor := ""
+	if a.c != nil {
+		actor = a.c.GetString("userID")
+	}
+	a.saveAuditEvent(&auth_entity.AuditEvent{
+		Event:   auth_entity.AuditAPIKeyRevoked,
+		IP:      a.clientIP(),
+		Actor:   actor,
+		Details: fmt.Sprintf("%v (%v)", apiKey.Name, apiKey.Prefix),
+	})
+
+	return nil
 }
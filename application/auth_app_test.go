@@ -0,0 +1,77 @@
+package application
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+)
+
+type fakeTokenFamilies struct {
+	tokens        []auth_entity.RefreshToken
+	getErr        error
+	revokedFamily []string
+	revokedJTIs   []string
+}
+
+func (f *fakeTokenFamilies) GetRefreshTokenFamily(familyId string) ([]auth_entity.RefreshToken, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	var family []auth_entity.RefreshToken
+	for _, token := range f.tokens {
+		if token.FamilyID == familyId {
+			family = append(family, token)
+		}
+	}
+	return family, nil
+}
+
+func (f *fakeTokenFamilies) RevokeRefreshTokenFamily(familyId string) error {
+	f.revokedFamily = append(f.revokedFamily, familyId)
+	return nil
+}
+
+func (f *fakeTokenFamilies) RevokeJTI(jti string) error {
+	f.revokedJTIs = append(f.revokedJTIs, jti)
+	return nil
+}
+
+func TestRevokeTokenFamily(t *testing.T) {
+	// A login rotated twice, and another login of the same customer
+	tokens := []auth_entity.RefreshToken{
+		{ID: 1, CustomerID: 7, FamilyID: "login-a", AccessJTI: "a1"},
+		{ID: 2, CustomerID: 7, FamilyID: "login-a", AccessJTI: "a2"},
+		{ID: 3, CustomerID: 7, FamilyID: "login-a", AccessJTI: "a3"},
+		{ID: 4, CustomerID: 7, FamilyID: "login-b", AccessJTI: "b1"},
+	}
+
+	tests := []struct {
+		name       string
+		getErr     error
+		familyId   string
+		wantFamily []string
+		wantJTIs   []string
+	}{
+		{"every token and access token of the login", nil, "login-a", []string{"login-a"}, []string{"a1", "a2", "a3"}},
+		{"other logins are left alone", nil, "login-b", []string{"login-b"}, []string{"b1"}},
+		{"nothing revoked when the family cannot be read", errors.New("db down"), "login-a", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTokenFamilies{tokens: tokens, getErr: tt.getErr}
+			revokeTokenFamily(repo, tt.familyId)
+
+			sort.Strings(repo.revokedJTIs)
+			if !reflect.DeepEqual(repo.revokedFamily, tt.wantFamily) {
+				t.Errorf("revoked families = %v, want %v", repo.revokedFamily, tt.wantFamily)
+			}
+			if !reflect.DeepEqual(repo.revokedJTIs, tt.wantJTIs) {
+				t.Errorf("revoked jtis = %v, want %v", repo.revokedJTIs, tt.wantJTIs)
+			}
+		})
+	}
+}
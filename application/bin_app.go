@@ -0,0 +1,534 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/bin_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/bin_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/bins"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/inventories"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/warehouses"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+type BinApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewBinApplication(p *base.Persistence, c *gin.Context) bin_repository.BinHandlerRepository {
+	return &BinApp{p, c}
+}
+
+func (a *BinApp) GetLayout(warehouseId int64) ([]bin_entity.Zone, error) {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	return repoBin.GetLayout(warehouseId)
+}
+
+func (a *BinApp) SaveZone(warehouseId int64, request bin_entity.ZoneRequest) (*bin_entity.Zone, map[string]string) {
+	if warehouseErr := a.checkWarehouse(warehouseId); warehouseErr != nil {
+		return nil, warehouseErr
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	zone := bin_entity.Zone{WarehouseID: uint64(warehouseId)}
+	request.Apply(&zone)
+
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	zones, err := repoBin.GetZones(warehouseId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+	for _, other := range zones {
+		if other.Code == zone.Code {
+			return nil, map[string]string{"code": fmt.Sprintf("warehouse %v already has a zone %v", warehouseId, zone.Code)}
+		}
+	}
+
+	return repoBin.SaveZone(&zone)
+}
+
+func (a *BinApp) UpdateZone(warehouseId int64, zoneId int64, request bin_entity.ZoneRequest) (*bin_entity.Zone, map[string]string) {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	zone, _ := repoBin.GetZone(zoneId)
+	if zone == nil || int64(zone.WarehouseID) != warehouseId {
+		return nil, map[string]string{"zone_not_found": fmt.Sprintf("zone %v not found", zoneId)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	request.Apply(zone)
+
+	zones, err := repoBin.GetZones(warehouseId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+	for _, other := range zones {
+		if other.ID != zone.ID && other.Code == zone.Code {
+			return nil, map[string]string{"code": fmt.Sprintf("warehouse %v already has a zone %v", warehouseId, zone.Code)}
+		}
+	}
+
+	updatedZone, err := repoBin.UpdateZone(zone)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return updatedZone, nil
+}
+
+// DeleteZone removes a zone without aisles
+func (a *BinApp) DeleteZone(warehouseId int64, zoneId int64) map[string]string {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	zone, _ := repoBin.GetZone(zoneId)
+	if zone == nil || int64(zone.WarehouseID) != warehouseId {
+		return map[string]string{"zone_not_found": fmt.Sprintf("zone %v not found", zoneId)}
+	}
+
+	aisles, err := repoBin.GetAisles(zoneId)
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+	if len(aisles) > 0 {
+		return map[string]string{"zone": fmt.Sprintf("zone %v still has %v aisles", zone.Code, len(aisles))}
+	}
+
+	if err := repoBin.DeleteZone(zoneId); err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+
+	return nil
+}
+
+func (a *BinApp) SaveAisle(warehouseId int64, request bin_entity.AisleRequest) (*bin_entity.Aisle, map[string]string) {
+	if warehouseErr := a.checkWarehouse(warehouseId); warehouseErr != nil {
+		return nil, warehouseErr
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	aisle := bin_entity.Aisle{WarehouseID: uint64(warehouseId)}
+	request.Apply(&aisle)
+
+	if codeErr := a.checkAisleCode(warehouseId, &aisle); codeErr != nil {
+		return nil, codeErr
+	}
+
+	return bins.NewBinRepository(a.p, a.c).SaveAisle(&aisle)
+}
+
+// UpdateAisle changes an aisle, moving it to another zone takes its bins along
+func (a *BinApp) UpdateAisle(warehouseId int64, aisleId int64, request bin_entity.AisleRequest) (*bin_entity.Aisle, map[string]string) {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	aisle, _ := repoBin.GetAisle(aisleId)
+	if aisle == nil || int64(aisle.WarehouseID) != warehouseId {
+		return nil, map[string]string{"aisle_not_found": fmt.Sprintf("aisle %v not found", aisleId)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	request.Apply(aisle)
+
+	if codeErr := a.checkAisleCode(warehouseId, aisle); codeErr != nil {
+		return nil, codeErr
+	}
+
+	updatedAisle, err := repoBin.UpdateAisle(aisle)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return updatedAisle, nil
+}
+
+// DeleteAisle removes an aisle without bins
+func (a *BinApp) DeleteAisle(warehouseId int64, aisleId int64) map[string]string {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	aisle, _ := repoBin.GetAisle(aisleId)
+	if aisle == nil || int64(aisle.WarehouseID) != warehouseId {
+		return map[string]string{"aisle_not_found": fmt.Sprintf("aisle %v not found", aisleId)}
+	}
+
+	aisleBins, err := repoBin.GetBins(aisleId)
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+	if len(aisleBins) > 0 {
+		return map[string]string{"aisle": fmt.Sprintf("aisle %v still has %v bins", aisle.Code, len(aisleBins))}
+	}
+
+	if err := repoBin.DeleteAisle(aisleId); err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+
+	return nil
+}
+
+func (a *BinApp) SaveBin(warehouseId int64, request bin_entity.BinRequest) (*bin_entity.Bin, map[string]string) {
+	if warehouseErr := a.checkWarehouse(warehouseId); warehouseErr != nil {
+		return nil, warehouseErr
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	bin := bin_entity.Bin{WarehouseID: uint64(warehouseId), Active: true}
+	request.Apply(&bin)
+
+	if codeErr := a.checkBinCode(warehouseId, &bin); codeErr != nil {
+		return nil, codeErr
+	}
+
+	return bins.NewBinRepository(a.p, a.c).SaveBin(&bin)
+}
+
+// UpdateBin changes a bin, its capacity cannot drop below what it holds
+func (a *BinApp) UpdateBin(warehouseId int64, binId int64, request bin_entity.BinRequest) (*bin_entity.Bin, map[string]string) {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	bin, _ := repoBin.GetBin(binId)
+	if bin == nil || int64(bin.WarehouseID) != warehouseId {
+		return nil, map[string]string{"bin_not_found": fmt.Sprintf("bin %v not found", binId)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	request.Apply(bin)
+
+	if codeErr := a.checkBinCode(warehouseId, bin); codeErr != nil {
+		return nil, codeErr
+	}
+
+	held, err := a.heldInBin(binId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+	if bin.Capacity > 0 && held > bin.Capacity {
+		return nil, map[string]string{"capacity": fmt.Sprintf("bin %v holds %v units, more than a capacity of %v", binId, held, bin.Capacity)}
+	}
+
+	updatedBin, err := repoBin.UpdateBin(bin)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return updatedBin, nil
+}
+
+// DeleteBin removes an empty bin
+func (a *BinApp) DeleteBin(warehouseId int64, binId int64) map[string]string {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	bin, _ := repoBin.GetBin(binId)
+	if bin == nil || int64(bin.WarehouseID) != warehouseId {
+		return map[string]string{"bin_not_found": fmt.Sprintf("bin %v not found", binId)}
+	}
+
+	held, err := a.heldInBin(binId)
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+	if held > 0 {
+		return map[string]string{"bin": fmt.Sprintf("bin %v still holds %v units, move them first", binId, held)}
+	}
+
+	if err := repoBin.DeleteBin(binId); err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+
+	return nil
+}
+
+// GetBin returns a bin with its label and the stock it holds
+func (a *BinApp) GetBin(warehouseId int64, binId int64) (*bin_entity.BinContents, error) {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	bin, _ := repoBin.GetBin(binId)
+	if bin == nil || int64(bin.WarehouseID) != warehouseId {
+		return nil, fmt.Errorf("bin %v not found", binId)
+	}
+
+	layout, err := repoBin.GetLayout(warehouseId)
+	if err != nil {
+		return nil, err
+	}
+	bin.Label = bin_entity.Bins(layout)[bin.ID].Label
+
+	stocks, err := repoBin.GetBinStocks(binId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stocks {
+		stocks[i].Label = bin.Label
+	}
+
+	return &bin_entity.BinContents{Bin: *bin, Stock: stocks}, nil
+}
+
+// GetProductBins returns the active bins holding a product in every warehouse stocking it, labelled
+func (a *BinApp) GetProductBins(productId int64) ([]bin_entity.BinStock, error) {
+	inventoryRows, err := inventories.NewInventoryRepository(a.p, a.c).GetInventoriesForProducts([]int64{productId})
+	if err != nil {
+		return nil, err
+	}
+	if len(inventoryRows) == 0 {
+		return nil, fmt.Errorf("inventory for product %v not found", productId)
+	}
+
+	var stocks []bin_entity.BinStock
+	for _, inventory := range inventoryRows {
+		warehouseStocks, err := a.productBins(int64(inventory.WarehouseID), productId)
+		if err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, warehouseStocks...)
+	}
+
+	return stocks, nil
+}
+
+// PutAway shelves units of a product in a bin. Units that were not received with the put-away are
+// limited to the stock of the product that is not yet in a bin
+func (a *BinApp) PutAway(warehouseId int64, request bin_entity.PutAwayRequest) ([]bin_entity.BinStock, map[string]string) {
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	inventory, inventoryErr := a.checkInventory(warehouseId, request.ProductID)
+	if inventoryErr != nil {
+		return nil, inventoryErr
+	}
+
+	bin, binErr := a.checkTargetBin(warehouseId, request.BinID, request.Quantity, "bin_id")
+	if binErr != nil {
+		return nil, binErr
+	}
+
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	if !request.Received {
+		binned, err := repoBin.GetBinnedQuantity(warehouseId, int64(request.ProductID))
+		if err != nil {
+			return nil, map[string]string{"db_error": err.Error()}
+		}
+		if request.Quantity > inventory.Stock-binned {
+			return nil, map[string]string{"quantity": fmt.Sprintf("only %v units of product %v are not in a bin", max(inventory.Stock-binned, 0), request.ProductID)}
+		}
+	}
+
+	inventoryRepo := inventories.NewInventoryRepository(a.p, a.c)
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		if request.Received {
+			if err := inventoryRepo.ReceiveInventory(tx, int64(request.ProductID), int64(request.Quantity), bin.WarehouseID, bin.ID); err != nil {
+				return err
+			}
+		} else {
+			logInventory := &inventory_entity.InventoryLog{
+				ProductID:   request.ProductID,
+				WarehouseID: bin.WarehouseID,
+				Reason:      "Put away",
+				Quantity:    request.Quantity,
+				ToBinID:     bin.ID,
+			}
+			if err := inventoryRepo.LogInventory(tx, logInventory); err != nil {
+				return err
+			}
+		}
+
+		return repoBin.AddBinStock(tx, bin.ID, bin.WarehouseID, request.ProductID, request.Quantity)
+	})
+	if txErr != nil {
+		return nil, map[string]string{"db_error": txErr.Error()}
+	}
+
+	productBins, err := a.productBins(warehouseId, int64(request.ProductID))
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return productBins, nil
+}
+
+// MoveStock moves units of a product from one bin to another of the same warehouse
+func (a *BinApp) MoveStock(warehouseId int64, request bin_entity.MoveRequest) ([]bin_entity.BinStock, map[string]string) {
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	if _, inventoryErr := a.checkInventory(warehouseId, request.ProductID); inventoryErr != nil {
+		return nil, inventoryErr
+	}
+
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	fromBin, _ := repoBin.GetBin(int64(request.FromBinID))
+	if fromBin == nil || int64(fromBin.WarehouseID) != warehouseId {
+		return nil, map[string]string{"from_bin_id": fmt.Sprintf("bin %v is not a bin of warehouse %v", request.FromBinID, warehouseId)}
+	}
+
+	toBin, binErr := a.checkTargetBin(warehouseId, request.ToBinID, request.Quantity, "to_bin_id")
+	if binErr != nil {
+		return nil, binErr
+	}
+
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repoBin.TakeBinStock(tx, fromBin.ID, request.ProductID, request.Quantity); err != nil {
+			return err
+		}
+		if err := repoBin.AddBinStock(tx, toBin.ID, toBin.WarehouseID, request.ProductID, request.Quantity); err != nil {
+			return err
+		}
+
+		logInventory := &inventory_entity.InventoryLog{
+			ProductID:   request.ProductID,
+			WarehouseID: toBin.WarehouseID,
+			Reason:      "Moved between bins",
+			Quantity:    request.Quantity,
+			FromBinID:   fromBin.ID,
+			ToBinID:     toBin.ID,
+		}
+		return inventories.NewInventoryRepository(a.p, a.c).LogInventory(tx, logInventory)
+	})
+	if txErr != nil {
+		return nil, map[string]string{"quantity": txErr.Error()}
+	}
+
+	productBins, err := a.productBins(warehouseId, int64(request.ProductID))
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return productBins, nil
+}
+
+func (a *BinApp) checkWarehouse(warehouseId int64) map[string]string {
+	warehouse, _ := warehouses.NewWareHouseRepository(a.p, a.c).GetWarehouse(warehouseId)
+	if warehouse == nil || warehouse.ID == 0 {
+		return map[string]string{"warehouse_not_found": fmt.Sprintf("warehouse %v not found", warehouseId)}
+	}
+	return nil
+}
+
+// checkAisleCode checks the zone of the aisle is in the warehouse and no other aisle of it has the code
+func (a *BinApp) checkAisleCode(warehouseId int64, aisle *bin_entity.Aisle) map[string]string {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	zone, _ := repoBin.GetZone(int64(aisle.ZoneID))
+	if zone == nil || int64(zone.WarehouseID) != warehouseId {
+		return map[string]string{"zone_id": fmt.Sprintf("zone %v is not a zone of warehouse %v", aisle.ZoneID, warehouseId)}
+	}
+
+	aisles, err := repoBin.GetAisles(int64(zone.ID))
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+	for _, other := range aisles {
+		if other.ID != aisle.ID && other.Code == aisle.Code {
+			return map[string]string{"code": fmt.Sprintf("zone %v already has an aisle %v", zone.Code, aisle.Code)}
+		}
+	}
+
+	return nil
+}
+
+// checkBinCode checks the aisle of the bin is in the warehouse and no other bin of it has the code,
+// the bin takes the zone of its aisle
+func (a *BinApp) checkBinCode(warehouseId int64, bin *bin_entity.Bin) map[string]string {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	aisle, _ := repoBin.GetAisle(int64(bin.AisleID))
+	if aisle == nil || int64(aisle.WarehouseID) != warehouseId {
+		return map[string]string{"aisle_id": fmt.Sprintf("aisle %v is not an aisle of warehouse %v", bin.AisleID, warehouseId)}
+	}
+	bin.ZoneID = aisle.ZoneID
+
+	aisleBins, err := repoBin.GetBins(int64(aisle.ID))
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+	for _, other := range aisleBins {
+		if other.ID != bin.ID && other.Code == bin.Code {
+			return map[string]string{"code": fmt.Sprintf("aisle %v already has a bin %v", aisle.Code, bin.Code)}
+		}
+	}
+
+	return nil
+}
+
+// checkInventory returns the inventory of a product kept in the warehouse
+func (a *BinApp) checkInventory(warehouseId int64, productId uint64) (*inventory_entity.Inventory, map[string]string) {
+	inventory, _ := inventories.NewInventoryRepository(a.p, a.c).GetInventory(int64(productId), uint64(warehouseId))
+	if inventory == nil || inventory.ProductID == 0 {
+		return nil, map[string]string{"product_id": fmt.Sprintf("product %v is not stocked in warehouse %v", productId, warehouseId)}
+	}
+	return inventory, nil
+}
+
+// checkTargetBin returns the bin units are going into, it has to be active and have room for them
+func (a *BinApp) checkTargetBin(warehouseId int64, binId uint64, quantity int, field string) (*bin_entity.Bin, map[string]string) {
+	bin, _ := bins.NewBinRepository(a.p, a.c).GetBin(int64(binId))
+	if bin == nil || int64(bin.WarehouseID) != warehouseId {
+		return nil, map[string]string{field: fmt.Sprintf("bin %v is not a bin of warehouse %v", binId, warehouseId)}
+	}
+	if !bin.Active {
+		return nil, map[string]string{field: fmt.Sprintf("bin %v is not active", binId)}
+	}
+
+	if bin.Capacity > 0 {
+		held, err := a.heldInBin(int64(binId))
+		if err != nil {
+			return nil, map[string]string{"db_error": err.Error()}
+		}
+		if held+quantity > bin.Capacity {
+			return nil, map[string]string{"quantity": fmt.Sprintf("bin %v has room for %v more units", binId, max(bin.Capacity-held, 0))}
+		}
+	}
+
+	return bin, nil
+}
+
+func (a *BinApp) heldInBin(binId int64) (int, error) {
+	stocks, err := bins.NewBinRepository(a.p, a.c).GetBinStocks(binId)
+	if err != nil {
+		return 0, err
+	}
+
+	held := 0
+	for _, stock := range stocks {
+		held += stock.Quantity
+	}
+	return held, nil
+}
+
+// productBins returns the bins of the warehouse holding a product, labelled
+func (a *BinApp) productBins(warehouseId int64, productId int64) ([]bin_entity.BinStock, error) {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	stocks, err := repoBin.GetProductBinStocks([]int64{productId})
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := repoBin.GetLayout(warehouseId)
+	if err != nil {
+		return nil, err
+	}
+	labels := bin_entity.Bins(layout)
+	warehouseStocks := make([]bin_entity.BinStock, 0, len(stocks))
+	for _, stock := range stocks {
+		if int64(stock.WarehouseID) != warehouseId {
+			continue
+		}
+		stock.Label = labels[stock.BinID].Label
+		warehouseStocks = append(warehouseStocks, stock)
+	}
+
+	return warehouseStocks, nil
+}
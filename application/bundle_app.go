@@ -0,0 +1,56 @@
+package application
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/bundle_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/bundles"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/products"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+type BundleApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewBundleApplication(p *base.Persistence, c *gin.Context) bundle_repository.BundleHandlerRepository {
+	return &BundleApp{p, c}
+}
+
+func (a *BundleApp) GetBundleItems(bundleId int64) ([]product_entity.BundleItem, error) {
+	repoBundle := bundles.NewBundleRepository(a.p, a.c)
+	return repoBundle.GetBundleItems(bundleId)
+}
+
+func (a *BundleApp) UpdateBundleItems(bundleId int64, items []product_entity.BundleItem) ([]product_entity.BundleItem, error) {
+	product, err := products.NewProductRepository(a.p, a.c).GetProduct(bundleId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !product.IsBundle() {
+		return nil, errors.New("product is not a bundle")
+	}
+
+	var savedItems []product_entity.BundleItem
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		var saveErr error
+		savedItems, saveErr = bundles.NewBundleRepository(a.p, a.c).SaveBundleItems(tx, product.ID, items)
+		return saveErr
+	})
+
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return savedItems, nil
+}
+
+func (a *BundleApp) GetBundleAvailability(bundleId int64) (*product_entity.BundleAvailability, error) {
+	repoBundle := bundles.NewBundleRepository(a.p, a.c)
+	return repoBundle.GetBundleAvailability(bundleId)
+}
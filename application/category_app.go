@@ -1,8 +1,11 @@
 package application
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/category_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/category_repository"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/categories"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
@@ -53,3 +56,48 @@ func (c *CategoryApp) DeleteCategory(categoryId int64) (error) {
 
 	return repoCategory.DeleteCategory(categoryId)
 }
+
+func (c *CategoryApp) DeleteCategoryWithPolicy(categoryId int64, policy string, dryRun bool) (*category_entity.CategoryDeletionReport, error) {
+	repoCategory := categories.NewCategoryRepository(c.p, c.c)
+	return repoCategory.DeleteCategoryWithPolicy(categoryId, policy, dryRun)
+}
+
+func (c *CategoryApp) GetCategoryTree(rootId int64) ([]*category_entity.CategoryNode, error) {
+	repoCategory := categories.NewCategoryRepository(c.p, c.c)
+	return repoCategory.GetCategoryTree(rootId)
+}
+
+func (c *CategoryApp) GetBreadcrumbs(categoryId int64) ([]category_entity.Category, error) {
+	repoCategory := categories.NewCategoryRepository(c.p, c.c)
+	return repoCategory.GetBreadcrumbs(categoryId)
+}
+
+func (c *CategoryApp) MoveCategory(categoryId int64, newParentId int64) (*category_entity.Category, error) {
+	repoCategory := categories.NewCategoryRepository(c.p, c.c)
+	return repoCategory.MoveCategory(categoryId, newParentId)
+}
+
+func (c *CategoryApp) ReorderCategories(parentId int64, orderedIds []int64) ([]category_entity.Category, error) {
+	repoCategory := categories.NewCategoryRepository(c.p, c.c)
+	return repoCategory.ReorderCategories(parentId, orderedIds)
+}
+
+func (c *CategoryApp) GetProductsInCategoryTree(categoryId int64) ([]product_entity.Product, error) {
+	repoCategory := categories.NewCategoryRepository(c.p, c.c)
+	return repoCategory.GetProductsInCategoryTree(categoryId)
+}
+
+func (c *CategoryApp) RebuildCategoryPaths() error {
+	repoCategory := categories.NewCategoryRepository(c.p, c.c)
+	return repoCategory.RebuildCategoryPaths()
+}
+
+// SetPointsMultiplier changes how fast the category's products earn loyalty points, 0 stops them earning any
+func (c *CategoryApp) SetPointsMultiplier(categoryId int64, multiplier float64) (*category_entity.Category, error) {
+	if multiplier < 0 || multiplier > category_entity.MaxPointsMultiplier {
+		return nil, fmt.Errorf("points_multiplier must be between 0 and %v", category_entity.MaxPointsMultiplier)
+	}
+
+	repoCategory := categories.NewCategoryRepository(c.p, c.c)
+	return repoCategory.SetPointsMultiplier(categoryId, multiplier)
+}
@@ -1,11 +1,18 @@
 package application
 
 import (
+	"fmt"
+	"log"
+
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/customer_repository"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/customers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/orders"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/utils/security"
 )
 
 type customerApp struct {
@@ -13,13 +20,35 @@ type customerApp struct {
 	c *gin.Context
 }
 
-func NewCustomerApplication(p *base.Persistence, c *gin.Context) customer_repository.CustomerRepository {
+func NewCustomerApplication(p *base.Persistence, c *gin.Context) customer_repository.CustomerHandlerRepository {
 	return &customerApp{p, c}
 }
 
-func (a *customerApp) SaveCustomer(customer *customer_entity.Customer) (*customer_entity.Customer, map[string]string) {
+func (a *customerApp) SaveCustomer(request customer_entity.CustomerRequest) (*customer_entity.Customer, map[string]string) {
+	validationErr := request.Validate()
+	customer := request.ToCustomer()
+	a.validateUnique(customer, validationErr)
+	if len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	// Signing up always creates a customer, other roles are granted by an admin
+	customer.Role = auth_entity.RoleCustomer
+
 	repocustomer := customers.NewCustomerRepository(a.p, a.c)
-	return repocustomer.SaveCustomer(customer)
+	savedCustomer, saveErr := repocustomer.SaveCustomer(customer)
+	if saveErr != nil {
+		return nil, saveErr
+	}
+
+	// The verification mail is best effort, the customer can ask for another one
+	if savedCustomer.Email != "" {
+		if err := NewAuthApplication(a.p, a.c).RequestEmailVerification(int64(savedCustomer.ID)); err != nil {
+			log.Println(err)
+		}
+	}
+
+	return savedCustomer, nil
 }
 
 func (a *customerApp) GetCustomer(customerId int64) (*customer_entity.Customer, error) {
@@ -31,10 +60,27 @@ func (a *customerApp) GetAllCustomers() ([]customer_entity.Customer, error) {
 	repocustomer := customers.NewCustomerRepository(a.p, a.c)
 	return repocustomer.GetAllCustomers()
 }
-	
-func (a *customerApp) UpdateCustomer(customer *customer_entity.Customer) (*customer_entity.Customer, error) {
+
+func (a *customerApp) UpdateCustomer(customerId int64, request customer_entity.UpdateCustomerRequest) (*customer_entity.Customer, map[string]string) {
 	repocustomer := customers.NewCustomerRepository(a.p, a.c)
-	return repocustomer.UpdateCustomer(customer)
+	customer, _ := repocustomer.GetCustomer(customerId)
+	if customer == nil || customer.ID == 0 {
+		return nil, map[string]string{"customer_not_found": fmt.Sprintf("customer %v not found", customerId)}
+	}
+
+	validationErr := request.Validate()
+	request.Apply(customer)
+	a.validateUnique(customer, validationErr)
+	if len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	updatedCustomer, err := repocustomer.UpdateCustomer(customer)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return updatedCustomer, nil
 }
 
 func (a *customerApp) DeleteCustomer(customerId int64) error {
@@ -42,4 +88,110 @@ func (a *customerApp) DeleteCustomer(customerId int64) error {
 	return repocustomer.DeleteCustomer(customerId)
 }
 
+func (a *customerApp) UpdateCustomerRole(customerId int64, role string) (*customer_entity.Customer, error) {
+	if !auth_entity.IsValidRole(role) {
+		return nil, fmt.Errorf("unknown role %v", role)
+	}
+
+	repocustomer := customers.NewCustomerRepository(a.p, a.c)
+	return repocustomer.UpdateCustomerRole(customerId, role)
+}
+
+// ChangePassword replaces the password after checking the current one, then signs the customer out
+// everywhere so a stolen session does not survive the change
+func (a *customerApp) ChangePassword(customerId int64, request customer_entity.ChangePasswordRequest) map[string]string {
+	repocustomer := customers.NewCustomerRepository(a.p, a.c)
+	customer, _ := repocustomer.GetCustomer(customerId)
+	if customer == nil || customer.ID == 0 {
+		return map[string]string{"customer_not_found": fmt.Sprintf("customer %v not found", customerId)}
+	}
+
+	passwordHash, err := repocustomer.GetPasswordHash(customerId)
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+
+	validationErr := map[string]string{}
+	if request.OldPassword == "" || security.VerifyPassword(passwordHash, request.OldPassword) != nil {
+		validationErr["old_password"] = "old password is incorrect"
+	}
+	if message := customer_entity.ValidatePassword(request.NewPassword, customer_entity.NormalizeUsername(customer.Username)); message != "" {
+		validationErr["new_password"] = message
+	} else if request.NewPassword == request.OldPassword {
+		validationErr["new_password"] = "new password must be different from the old password"
+	}
+	if len(validationErr) > 0 {
+		return validationErr
+	}
+
+	hashedPassword, err := security.Hash(request.NewPassword)
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+
+	if err := repocustomer.UpdatePassword(customerId, string(hashedPassword)); err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+
+	if err := NewAuthApplication(a.p, a.c).LogoutAllSessions(customerId); err != nil {
+		log.Println(err)
+	}
+
+	return nil
+}
+
+// DeleteAccount deletes the customer after checking their password. Pending orders have to be
+// cancelled first so no stock stays reserved for a deleted account
+func (a *customerApp) DeleteAccount(customerId int64, request customer_entity.DeleteAccountRequest) map[string]string {
+	repocustomer := customers.NewCustomerRepository(a.p, a.c)
+	customer, _ := repocustomer.GetCustomer(customerId)
+	if customer == nil || customer.ID == 0 {
+		return map[string]string{"customer_not_found": fmt.Sprintf("customer %v not found", customerId)}
+	}
+
+	passwordHash, err := repocustomer.GetPasswordHash(customerId)
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+
+	if request.Password == "" || security.VerifyPassword(passwordHash, request.Password) != nil {
+		return map[string]string{"password": "password is incorrect"}
+	}
+
+	customerOrders, err := orders.NewOrderRepository(a.p, a.c).GetOrdersByCustomer(customerId)
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+	for _, order := range customerOrders {
+		if order.Status == order_entity.OrderStatusPending || order.Status == order_entity.OrderStatusAwaitingPayment {
+			return map[string]string{"pending_orders": "cancel your pending orders before deleting your account"}
+		}
+	}
 
+	if err := repocustomer.DeleteCustomer(customerId); err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+
+	if err := NewAuthApplication(a.p, a.c).LogoutAllSessions(customerId); err != nil {
+		log.Println(err)
+	}
+
+	return nil
+}
+
+// validateUnique adds an error for a username or email that belongs to another customer
+func (a *customerApp) validateUnique(customer *customer_entity.Customer, validationErr map[string]string) {
+	repocustomer := customers.NewCustomerRepository(a.p, a.c)
+
+	if _, ok := validationErr["username"]; !ok {
+		if existing, err := repocustomer.GetCustomerByUsername(customer.Username); err == nil && existing.ID != customer.ID {
+			validationErr["username"] = "username is already taken"
+		}
+	}
+
+	if _, ok := validationErr["email"]; !ok && customer.Email != "" {
+		if existing, err := repocustomer.GetCustomerByEmail(customer.Email); err == nil && existing.ID != customer.ID {
+			validationErr["email"] = "email is already in use"
+		}
+	}
+}
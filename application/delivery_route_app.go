@@ -0,0 +1,462 @@
+package application
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/delivery_route_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/deliveryroutes"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/orders"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/shipments"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/warehouses"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/utils/geo"
+	"gorm.io/gorm"
+)
+
+type DeliveryRouteApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewDeliveryRouteApplication(p *base.Persistence, c *gin.Context) delivery_route_repository.DeliveryRouteHandlerRepository {
+	return &DeliveryRouteApp{p, c}
+}
+
+// routeCandidate is a shipment waiting for a route with what the planner needs from its order
+type routeCandidate struct {
+	shipment shipment_entity.Shipment
+	order    order_entity.Order
+	point    geo.Point
+	packages int
+}
+
+// PlanRoutes batches the pending shipments of a warehouse into driver runs. Shipments are grouped by
+// delivery slot, and each run is filled from the warehouse outwards with the closest shipment that
+// still fits in the vehicle, so a run covers one neighbourhood. The stops of every run are then put
+// in visiting order, and the shipments are assigned to the run's driver
+func (a *DeliveryRouteApp) PlanRoutes(warehouseId int64, request shipment_entity.RoutePlanRequest, actorId int64) (*shipment_entity.RoutePlan, map[string]string) {
+	warehouse, _ := warehouses.NewWareHouseRepository(a.p, a.c).GetWarehouse(warehouseId)
+	if warehouse == nil || warehouse.ID == 0 {
+		return nil, map[string]string{"warehouse_not_found": fmt.Sprintf("warehouse %v not found", warehouseId)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	drivers, driverErr := a.routeDrivers(warehouse, request.DriverIDs)
+	if driverErr != nil {
+		return nil, driverErr
+	}
+
+	plan := shipment_entity.RoutePlan{
+		Routes:    []shipment_entity.DeliveryRoute{},
+		Unplanned: []shipment_entity.UnplannedShipment{},
+	}
+
+	candidates, err := a.routeCandidates(warehouse, request, &plan)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	depot := geo.Point{Latitude: warehouse.Latitude, Longitude: warehouse.Longitude}
+	routes, unplanned := planRuns(depot, candidates, drivers, actorId)
+	plan.Routes = append(plan.Routes, routes...)
+	plan.Unplanned = append(plan.Unplanned, unplanned...)
+
+	if len(plan.Routes) == 0 {
+		return &plan, nil
+	}
+
+	repoRoute := deliveryroutes.NewDeliveryRouteRepository(a.p, a.c)
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for i := range plan.Routes {
+			route := &plan.Routes[i]
+			if _, err := repoRoute.SaveDeliveryRoute(tx, route); err != nil {
+				return err
+			}
+
+			for _, stop := range route.Stops {
+				shipment := stop.Shipment
+				shipment.DriverID = route.DriverID
+				shipment.Status = shipment_entity.ShipmentStatusAssigned
+				shipment.AssignedAt = &now
+				if err := repoShipment.UpdateShipment(tx, shipment, shipment_entity.ShipmentStatusPending); err != nil {
+					return err
+				}
+
+				event := shipment_entity.ShipmentEvent{
+					ShipmentID: shipment.ID,
+					Status:     shipment_entity.ShipmentStatusAssigned,
+					Note:       fmt.Sprintf("Assigned to driver %v on route %v", route.DriverID, route.ID),
+					RecordedBy: actorId,
+					RecordedAt: now,
+				}
+				if err := repoShipment.SaveShipmentEvent(tx, &event); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if txErr != nil {
+		return nil, map[string]string{"status": txErr.Error()}
+	}
+
+	return &plan, nil
+}
+
+func (a *DeliveryRouteApp) GetDeliveryRoute(routeId int64) (*shipment_entity.DeliveryRoute, error) {
+	repoRoute := deliveryroutes.NewDeliveryRouteRepository(a.p, a.c)
+	return repoRoute.GetDeliveryRoute(routeId)
+}
+
+func (a *DeliveryRouteApp) GetDeliveryRoutes(filter shipment_entity.DeliveryRouteFilter) ([]shipment_entity.DeliveryRoute, error) {
+	repoRoute := deliveryroutes.NewDeliveryRouteRepository(a.p, a.c)
+	return repoRoute.GetDeliveryRoutes(filter)
+}
+
+// CancelDeliveryRoute drops a planned route before any of its shipments left the warehouse,
+// the shipments go back to pending so they can be planned again
+func (a *DeliveryRouteApp) CancelDeliveryRoute(routeId int64, actorId int64) (*shipment_entity.DeliveryRoute, map[string]string) {
+	repoRoute := deliveryroutes.NewDeliveryRouteRepository(a.p, a.c)
+	route, _ := repoRoute.GetDeliveryRoute(routeId)
+	if route == nil {
+		return nil, map[string]string{"route_not_found": fmt.Sprintf("route %v not found", routeId)}
+	}
+
+	if route.Status != shipment_entity.RouteStatusPlanned {
+		return nil, map[string]string{"status": fmt.Sprintf("route %v is %v, only planned routes can be cancelled", routeId, route.Status)}
+	}
+
+	for _, stop := range route.Stops {
+		if stop.Shipment != nil && stop.Shipment.Status != shipment_entity.ShipmentStatusAssigned && stop.Shipment.Status != shipment_entity.ShipmentStatusPending {
+			return nil, map[string]string{"status": fmt.Sprintf("shipment %v of route %v is already %v", stop.ShipmentID, routeId, stop.Shipment.Status)}
+		}
+	}
+
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repoRoute.UpdateDeliveryRouteStatus(tx, routeId, shipment_entity.RouteStatusPlanned, shipment_entity.RouteStatusCancelled); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, stop := range route.Stops {
+			shipment := stop.Shipment
+			// Shipments handed to another driver since the route was planned stay with them
+			if shipment == nil || shipment.Status != shipment_entity.ShipmentStatusAssigned || shipment.DriverID != route.DriverID {
+				continue
+			}
+
+			shipment.DriverID = 0
+			shipment.Status = shipment_entity.ShipmentStatusPending
+			shipment.AssignedAt = nil
+			if err := repoShipment.UpdateShipment(tx, shipment, shipment_entity.ShipmentStatusAssigned); err != nil {
+				return err
+			}
+
+			event := shipment_entity.ShipmentEvent{
+				ShipmentID: shipment.ID,
+				Status:     shipment_entity.ShipmentStatusPending,
+				Note:       fmt.Sprintf("Route %v cancelled", routeId),
+				RecordedBy: actorId,
+				RecordedAt: now,
+			}
+			if err := repoShipment.SaveShipmentEvent(tx, &event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if txErr != nil {
+		return nil, map[string]string{"status": txErr.Error()}
+	}
+
+	route, err := repoRoute.GetDeliveryRoute(routeId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return route, nil
+}
+
+// routeDrivers returns the drivers to plan with, largest vehicle first. Without a choice these are
+// the active drivers of the warehouse and the drivers working for every warehouse
+func (a *DeliveryRouteApp) routeDrivers(warehouse *warehouse_entity.Warehouse, driverIds []uint64) ([]shipment_entity.Driver, map[string]string) {
+	drivers := []shipment_entity.Driver{}
+	if len(driverIds) > 0 {
+		shipmentApp := &ShipmentApp{a.p, a.c}
+		seen := map[uint64]bool{}
+		for _, driverId := range driverIds {
+			if seen[driverId] {
+				continue
+			}
+			seen[driverId] = true
+
+			driver, driverErr := shipmentApp.checkAssignable(driverId, warehouse.ID)
+			if driverErr != nil {
+				return nil, driverErr
+			}
+			drivers = append(drivers, *driver)
+		}
+	} else {
+		all, err := shipments.NewShipmentRepository(a.p, a.c).GetDrivers(0)
+		if err != nil {
+			return nil, map[string]string{"db_error": err.Error()}
+		}
+		for _, driver := range all {
+			if driver.Active && (driver.WarehouseID == 0 || driver.WarehouseID == warehouse.ID) {
+				drivers = append(drivers, driver)
+			}
+		}
+	}
+
+	if len(drivers) == 0 {
+		return nil, map[string]string{"driver_ids": fmt.Sprintf("warehouse %v has no active drivers", warehouse.ID)}
+	}
+
+	sort.SliceStable(drivers, func(i, j int) bool {
+		return capacityRank(drivers[i].VehicleCapacityKg) > capacityRank(drivers[j].VehicleCapacityKg)
+	})
+	return drivers, nil
+}
+
+// routeCandidates returns the pending shipments of the warehouse in the requested slot or day that are
+// not on a planned route yet. Shipments whose order has no delivery coordinates are left out of the plan
+func (a *DeliveryRouteApp) routeCandidates(warehouse *warehouse_entity.Warehouse, request shipment_entity.RoutePlanRequest, plan *shipment_entity.RoutePlan) ([]routeCandidate, error) {
+	pending, err := shipments.NewShipmentRepository(a.p, a.c).GetShipments(shipment_entity.ShipmentFilter{
+		WarehouseID: int64(warehouse.ID),
+		Status:      shipment_entity.ShipmentStatusPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	routed, err := deliveryroutes.NewDeliveryRouteRepository(a.p, a.c).GetRoutedShipmentIDs(int64(warehouse.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	orderIds := []int64{}
+	for _, shipment := range pending {
+		orderIds = append(orderIds, shipment.OrderID)
+	}
+	orderList, err := orders.NewOrderRepository(a.p, a.c).GetOrdersByIDs(orderIds)
+	if err != nil {
+		return nil, err
+	}
+	ordersById := map[uint64]order_entity.Order{}
+	for _, order := range orderList {
+		ordersById[order.ID] = order
+	}
+
+	location := warehouse.Location()
+	candidates := []routeCandidate{}
+	for _, shipment := range pending {
+		order, ok := ordersById[uint64(shipment.OrderID)]
+		if !ok || routed[shipment.ID] {
+			continue
+		}
+		if request.DeliverySlotID > 0 && order.DeliverySlotID != request.DeliverySlotID {
+			continue
+		}
+		if request.Date != "" && (order.DeliveryStartsAt == nil || order.DeliveryStartsAt.In(location).Format(warehouse_entity.DateLayout) != request.Date) {
+			continue
+		}
+
+		address := order.DeliveryAddress
+		if address.Latitude == 0 && address.Longitude == 0 {
+			plan.Unplanned = append(plan.Unplanned, shipment_entity.UnplannedShipment{
+				ShipmentID: shipment.ID,
+				OrderID:    shipment.OrderID,
+				Reason:     "order has no delivery coordinates",
+			})
+			continue
+		}
+
+		shipment.Driver = nil
+		candidates = append(candidates, routeCandidate{
+			shipment: shipment,
+			order:    order,
+			point:    geo.Point{Latitude: address.Latitude, Longitude: address.Longitude},
+			packages: max(order.PackageCount, 1),
+		})
+	}
+
+	return candidates, nil
+}
+
+// planRuns batches the candidates into runs of the drivers, slot by slot, handing runs to the drivers in
+// turn. Shipments too large for every vehicle are returned as unplanned rather than tried again and again
+func planRuns(depot geo.Point, candidates []routeCandidate, drivers []shipment_entity.Driver, actorId int64) ([]shipment_entity.DeliveryRoute, []shipment_entity.UnplannedShipment) {
+	routes := []shipment_entity.DeliveryRoute{}
+	unplanned := []shipment_entity.UnplannedShipment{}
+
+	// Shipments too big for every vehicle would never leave the queue
+	fitting := []routeCandidate{}
+	for _, candidate := range candidates {
+		fits := false
+		for _, driver := range drivers {
+			if driver.Fits(candidate.order.PackageWeightKg, candidate.packages) {
+				fits = true
+				break
+			}
+		}
+		if !fits {
+			unplanned = append(unplanned, shipment_entity.UnplannedShipment{
+				ShipmentID: candidate.shipment.ID,
+				OrderID:    candidate.shipment.OrderID,
+				Reason:     "too large for every vehicle",
+			})
+			continue
+		}
+		fitting = append(fitting, candidate)
+	}
+
+	for _, group := range groupBySlot(fitting) {
+		runs := map[uint64]int{}
+		next := 0
+		for len(group) > 0 {
+			driver := drivers[next%len(drivers)]
+			next++
+
+			var batch []routeCandidate
+			batch, group = fillRun(depot, group, &driver)
+			if len(batch) == 0 {
+				continue
+			}
+
+			runs[driver.ID]++
+			routes = append(routes, buildRoute(depot, batch, &driver, runs[driver.ID], actorId))
+		}
+	}
+
+	return routes, unplanned
+}
+
+// groupBySlot splits the candidates per delivery slot, earliest slot first and orders without a slot last
+func groupBySlot(candidates []routeCandidate) [][]routeCandidate {
+	groups := map[uint64][]routeCandidate{}
+	starts := map[uint64]time.Time{}
+	slotIds := []uint64{}
+	for _, candidate := range candidates {
+		slotId := candidate.order.DeliverySlotID
+		if _, ok := groups[slotId]; !ok {
+			slotIds = append(slotIds, slotId)
+			if candidate.order.DeliveryStartsAt != nil {
+				starts[slotId] = *candidate.order.DeliveryStartsAt
+			}
+		}
+		groups[slotId] = append(groups[slotId], candidate)
+	}
+
+	sort.SliceStable(slotIds, func(i, j int) bool {
+		if slotIds[i] == 0 || slotIds[j] == 0 {
+			return slotIds[j] == 0 && slotIds[i] != 0
+		}
+		return starts[slotIds[i]].Before(starts[slotIds[j]])
+	})
+
+	result := make([][]routeCandidate, 0, len(slotIds))
+	for _, slotId := range slotIds {
+		result = append(result, groups[slotId])
+	}
+	return result
+}
+
+// fillRun takes the candidates for one run of the driver, each time the one closest to the last stop
+// that still fits in the vehicle, and returns the run and the candidates left over
+func fillRun(depot geo.Point, candidates []routeCandidate, driver *shipment_entity.Driver) ([]routeCandidate, []routeCandidate) {
+	taken := make([]bool, len(candidates))
+	batch := []routeCandidate{}
+	current := depot
+	weightKg, packages := 0.0, 0
+	for len(batch) < shipment_entity.MaxRouteStops {
+		closest := -1
+		closestKm := 0.0
+		for i, candidate := range candidates {
+			if taken[i] || !driver.Fits(weightKg+candidate.order.PackageWeightKg, packages+candidate.packages) {
+				continue
+			}
+			km := geo.Haversine(current.Latitude, current.Longitude, candidate.point.Latitude, candidate.point.Longitude)
+			if closest == -1 || km < closestKm {
+				closest, closestKm = i, km
+			}
+		}
+		if closest == -1 {
+			break
+		}
+
+		taken[closest] = true
+		candidate := candidates[closest]
+		batch = append(batch, candidate)
+		current = candidate.point
+		weightKg += candidate.order.PackageWeightKg
+		packages += candidate.packages
+	}
+
+	left := []routeCandidate{}
+	for i, candidate := range candidates {
+		if !taken[i] {
+			left = append(left, candidate)
+		}
+	}
+	return batch, left
+}
+
+// buildRoute puts the stops of a run in visiting order and measures each leg
+func buildRoute(depot geo.Point, batch []routeCandidate, driver *shipment_entity.Driver, run int, actorId int64) shipment_entity.DeliveryRoute {
+	points := make([]geo.Point, len(batch))
+	for i, candidate := range batch {
+		points[i] = candidate.point
+	}
+	order := geo.PlanTour(depot, points)
+
+	route := shipment_entity.DeliveryRoute{
+		WarehouseID:    batch[0].shipment.WarehouseID,
+		DriverID:       driver.ID,
+		DeliverySlotID: batch[0].order.DeliverySlotID,
+		Run:            run,
+		Status:         shipment_entity.RouteStatusPlanned,
+		DistanceKm:     geo.TourDistance(depot, points, order),
+		CreatedBy:      actorId,
+	}
+
+	previous := depot
+	for sequence, index := range order {
+		candidate := batch[index]
+		shipment := candidate.shipment
+		route.WeightKg += candidate.order.PackageWeightKg
+		route.Packages += candidate.packages
+		route.Stops = append(route.Stops, shipment_entity.RouteStop{
+			Sequence:   sequence + 1,
+			ShipmentID: shipment.ID,
+			OrderID:    shipment.OrderID,
+			Latitude:   candidate.point.Latitude,
+			Longitude:  candidate.point.Longitude,
+			LegKm:      geo.Haversine(previous.Latitude, previous.Longitude, candidate.point.Latitude, candidate.point.Longitude),
+			Shipment:   &shipment,
+		})
+		previous = candidate.point
+	}
+
+	return route
+}
+
+// capacityRank orders vehicle capacities, no limit being the largest
+func capacityRank(capacityKg float64) float64 {
+	if capacityKg == 0 {
+		return math.Inf(1)
+	}
+	return capacityKg
+}
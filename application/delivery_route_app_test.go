@@ -0,0 +1,169 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/utils/geo"
+)
+
+var routeDepot = geo.Point{Latitude: 51.5, Longitude: -0.12}
+
+func routeTestCandidate(id uint64, slotId uint64, startsAt *time.Time, weightKg float64, packages int) routeCandidate {
+	offset := float64(id) * 0.001
+	return routeCandidate{
+		shipment: shipment_entity.Shipment{ID: id, OrderID: int64(id), WarehouseID: 1},
+		order: order_entity.Order{
+			ID:               id,
+			DeliverySlotID:   slotId,
+			DeliveryStartsAt: startsAt,
+			PackageWeightKg:  weightKg,
+		},
+		point:    geo.Point{Latitude: routeDepot.Latitude + offset, Longitude: routeDepot.Longitude - offset},
+		packages: packages,
+	}
+}
+
+func TestFillRunRespectsVehicleCapacity(t *testing.T) {
+	tests := []struct {
+		name      string
+		driver    shipment_entity.Driver
+		weightKg  float64
+		packages  int
+		wantBatch int
+	}{
+		{"weight limit", shipment_entity.Driver{ID: 1, VehicleCapacityKg: 10}, 4, 1, 2},
+		{"package limit", shipment_entity.Driver{ID: 1, VehicleCapacityPackages: 5}, 1, 2, 2},
+		{"exactly full", shipment_entity.Driver{ID: 1, VehicleCapacityKg: 12}, 4, 1, 3},
+		{"no limits", shipment_entity.Driver{ID: 1}, 4, 1, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []routeCandidate{}
+			for id := uint64(1); id <= 6; id++ {
+				candidates = append(candidates, routeTestCandidate(id, 0, nil, tt.weightKg, tt.packages))
+			}
+
+			batch, left := fillRun(routeDepot, candidates, &tt.driver)
+			if len(batch) != tt.wantBatch {
+				t.Errorf("fillRun() took %v candidates, want %v", len(batch), tt.wantBatch)
+			}
+			if len(batch)+len(left) != len(candidates) {
+				t.Errorf("fillRun() returned %v taken and %v left of %v candidates", len(batch), len(left), len(candidates))
+			}
+
+			weightKg, packages := 0.0, 0
+			for _, candidate := range batch {
+				weightKg += candidate.order.PackageWeightKg
+				packages += candidate.packages
+			}
+			if !tt.driver.Fits(weightKg, packages) {
+				t.Errorf("fillRun() loaded %v kg in %v packages, more than the vehicle holds", weightKg, packages)
+			}
+		})
+	}
+}
+
+func TestFillRunRespectsMaxRouteStops(t *testing.T) {
+	candidates := []routeCandidate{}
+	for id := uint64(1); id <= shipment_entity.MaxRouteStops+5; id++ {
+		candidates = append(candidates, routeTestCandidate(id, 0, nil, 1, 1))
+	}
+
+	batch, left := fillRun(routeDepot, candidates, &shipment_entity.Driver{ID: 1})
+	if len(batch) != shipment_entity.MaxRouteStops {
+		t.Errorf("fillRun() took %v candidates, want %v", len(batch), shipment_entity.MaxRouteStops)
+	}
+	if len(left) != 5 {
+		t.Errorf("fillRun() left %v candidates, want 5", len(left))
+	}
+}
+
+func TestGroupBySlot(t *testing.T) {
+	morning := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	evening := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	candidates := []routeCandidate{
+		routeTestCandidate(1, 0, nil, 1, 1),
+		routeTestCandidate(2, 7, &evening, 1, 1),
+		routeTestCandidate(3, 3, &morning, 1, 1),
+		routeTestCandidate(4, 7, &evening, 1, 1),
+		routeTestCandidate(5, 0, nil, 1, 1),
+	}
+
+	groups := groupBySlot(candidates)
+
+	wantSlots := []uint64{3, 7, 0}
+	wantSizes := []int{1, 2, 2}
+	if len(groups) != len(wantSlots) {
+		t.Fatalf("groupBySlot() returned %v groups, want %v", len(groups), len(wantSlots))
+	}
+	for i, group := range groups {
+		if len(group) != wantSizes[i] {
+			t.Errorf("group %v has %v candidates, want %v", i, len(group), wantSizes[i])
+		}
+		for _, candidate := range group {
+			if candidate.order.DeliverySlotID != wantSlots[i] {
+				t.Errorf("group %v holds slot %v, want slot %v", i, candidate.order.DeliverySlotID, wantSlots[i])
+			}
+		}
+	}
+}
+
+func TestPlanRunsWhenShipmentsFitOnlyOneVehicle(t *testing.T) {
+	small := shipment_entity.Driver{ID: 1, VehicleCapacityKg: 5}
+	large := shipment_entity.Driver{ID: 2, VehicleCapacityKg: 50}
+
+	candidates := []routeCandidate{}
+	for id := uint64(1); id <= 6; id++ {
+		candidates = append(candidates, routeTestCandidate(id, 0, nil, 20, 1))
+	}
+	for id := uint64(7); id <= 40; id++ {
+		candidates = append(candidates, routeTestCandidate(id, 0, nil, 1, 1))
+	}
+	candidates = append(candidates, routeTestCandidate(41, 0, nil, 80, 1))
+
+	type result struct {
+		routes    []shipment_entity.DeliveryRoute
+		unplanned []shipment_entity.UnplannedShipment
+	}
+	done := make(chan result, 1)
+	go func() {
+		routes, unplanned := planRuns(routeDepot, candidates, []shipment_entity.Driver{small, large}, 1)
+		done <- result{routes, unplanned}
+	}()
+
+	var planned result
+	select {
+	case planned = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("planRuns() did not finish")
+	}
+
+	if len(planned.unplanned) != 1 || planned.unplanned[0].ShipmentID != 41 {
+		t.Errorf("planRuns() unplanned = %+v, want only shipment 41", planned.unplanned)
+	}
+
+	drivers := map[uint64]shipment_entity.Driver{small.ID: small, large.ID: large}
+	routed := map[uint64]bool{}
+	for _, route := range planned.routes {
+		driver := drivers[route.DriverID]
+		if !driver.Fits(route.WeightKg, route.Packages) {
+			t.Errorf("route of driver %v carries %v kg, more than the vehicle holds", route.DriverID, route.WeightKg)
+		}
+		if len(route.Stops) > shipment_entity.MaxRouteStops {
+			t.Errorf("route of driver %v has %v stops, more than %v", route.DriverID, len(route.Stops), shipment_entity.MaxRouteStops)
+		}
+		for _, stop := range route.Stops {
+			if routed[stop.ShipmentID] {
+				t.Errorf("shipment %v is on more than one route", stop.ShipmentID)
+			}
+			routed[stop.ShipmentID] = true
+		}
+	}
+	if len(routed) != 40 {
+		t.Errorf("planRuns() routed %v shipments, want 40", len(routed))
+	}
+}
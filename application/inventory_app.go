@@ -1,10 +1,14 @@
 package application
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/inventory_repository"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/inventories"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/products"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/warehouses"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
@@ -23,14 +27,46 @@ func NewInventoryApplication(p *base.Persistence, c *gin.Context) inventory_repo
 // }
 
 
-func (a *InventoryApp) GetInventory(InventoryId int64) (*inventory_entity.Inventory, error) {
+// GetInventories returns the stock every warehouse holds of a product
+func (a *InventoryApp) GetInventories(productId int64) ([]inventory_entity.Inventory, error) {
 	repoInventory := inventories.NewInventoryRepository(a.p, a.c)
-	return repoInventory.GetInventory(InventoryId)
+	return repoInventory.GetInventoriesForProducts([]int64{productId})
 }
 
-func (a *InventoryApp) UpdateInventory(Inventory *inventory_entity.Inventory) (*inventory_entity.Inventory, error) {
+// UpdateInventory sets the stock a warehouse holds of a product, a warehouse not stocking the product yet starts to
+func (a *InventoryApp) UpdateInventory(inventory *inventory_entity.Inventory) (*inventory_entity.Inventory, map[string]string) {
+	if inventory.WarehouseID == 0 {
+		return nil, map[string]string{"warehouse_id": "warehouse_id is required"}
+	}
+
 	repoInventory := inventories.NewInventoryRepository(a.p, a.c)
-	return repoInventory.UpdateInventory(Inventory)
+	existing, err := repoInventory.GetInventory(int64(inventory.ProductID), inventory.WarehouseID)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	if existing != nil {
+		updated, updateErr := repoInventory.UpdateInventory(inventory)
+		if updateErr != nil {
+			return nil, map[string]string{"db_error": updateErr.Error()}
+		}
+		return updated, nil
+	}
+
+	product, _ := products.NewProductRepository(a.p, a.c).GetProduct(int64(inventory.ProductID))
+	if product == nil || product.ID == 0 {
+		return nil, map[string]string{"product_not_found": fmt.Sprintf("product %v not found", inventory.ProductID)}
+	}
+	if product.IsBundle() {
+		return nil, map[string]string{"product_id": "a bundle is stocked through its components"}
+	}
+
+	warehouse, _ := warehouses.NewWareHouseRepository(a.p, a.c).GetWarehouse(int64(inventory.WarehouseID))
+	if warehouse == nil || warehouse.ID == 0 {
+		return nil, map[string]string{"warehouse_not_found": fmt.Sprintf("warehouse %v not found", inventory.WarehouseID)}
+	}
+
+	return repoInventory.SaveInventory(inventory)
 }
 
 func (a *InventoryApp) DeleteInventory(InventoryId int64) error {
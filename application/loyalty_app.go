@@ -0,0 +1,386 @@
+package application
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/loyalty_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/return_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/loyalty_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/loyalty"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/orders"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+// lotBatchSize is how many lots a run of the loyalty job vests or expires at most
+const lotBatchSize = 500
+
+type LoyaltyApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewLoyaltyApplication(p *base.Persistence, c *gin.Context) loyalty_repository.LoyaltyHandlerRepository {
+	return &LoyaltyApp{p, c}
+}
+
+// ConfiguredLoyaltySettings reads the points earned per currency unit from LOYALTY_POINTS_PER_UNIT,
+// the value of a point from LOYALTY_POINT_VALUE and how many days vested points last from
+// LOYALTY_POINTS_EXPIRY_DAYS, falling back to the defaults
+func ConfiguredLoyaltySettings() loyalty_entity.LoyaltySettings {
+	settings := loyalty_entity.LoyaltySettings{
+		PointsPerUnit: loyalty_entity.DefaultPointsPerUnit,
+		PointValue:    loyalty_entity.DefaultPointValue,
+		ExpiryDays:    loyalty_entity.DefaultExpiryDays,
+	}
+	if rate, err := strconv.ParseFloat(os.Getenv("LOYALTY_POINTS_PER_UNIT"), 64); err == nil && rate >= 0 {
+		settings.PointsPerUnit = rate
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("LOYALTY_POINT_VALUE"), 64); err == nil && value > 0 {
+		settings.PointValue = value
+	}
+	if days, err := strconv.Atoi(os.Getenv("LOYALTY_POINTS_EXPIRY_DAYS")); err == nil && days > 0 {
+		settings.ExpiryDays = days
+	}
+	return settings
+}
+
+func (a *LoyaltyApp) GetAccount(customerId int64) (*loyalty_entity.LoyaltySummary, map[string]string) {
+	if customerErr := (&WalletApp{a.p, a.c}).checkCustomer(customerId); customerErr != nil {
+		return nil, customerErr
+	}
+
+	account, err := loyalty.NewLoyaltyRepository(a.p, a.c).GetOrCreateAccount(nil, customerId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	summary := ConfiguredLoyaltySettings().Summary(account)
+	return &summary, nil
+}
+
+func (a *LoyaltyApp) GetStatement(customerId int64, pagination *entity.Pagination) ([]loyalty_entity.PointsEntry, map[string]string) {
+	summary, accountErr := a.GetAccount(customerId)
+	if accountErr != nil {
+		return nil, accountErr
+	}
+
+	entries, err := loyalty.NewLoyaltyRepository(a.p, a.c).GetEntries(summary.ID, pagination)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return entries, nil
+}
+
+// VestPoints makes the points of orders whose return window has closed available, and starts
+// their expiry. It returns how many points vested
+func (a *LoyaltyApp) VestPoints(now time.Time) (int64, error) {
+	repoLoyalty := loyalty.NewLoyaltyRepository(a.p, a.c)
+	lots, err := repoLoyalty.GetLotsToVest(now, lotBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	settings := ConfiguredLoyaltySettings()
+	var vested int64
+	for i := range lots {
+		lot := &lots[i]
+		points := lot.Remaining
+		expiresAt := now.AddDate(0, 0, settings.ExpiryDays)
+		lot.Vested = true
+		lot.ExpiresAt = &expiresAt
+
+		txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+			if err := repoLoyalty.UpdateLot(tx, lot, points, false); err != nil {
+				return err
+			}
+			// Points of a lot fully reversed by returns have nothing left to move
+			if points == 0 {
+				return nil
+			}
+
+			return repoLoyalty.PostEntry(tx, &loyalty_entity.PointsEntry{
+				AccountID:   lot.AccountID,
+				Type:        loyalty_entity.EntryVest,
+				Points:      points,
+				OrderID:     lot.OrderID,
+				LotID:       lot.ID,
+				ExpiresAt:   &expiresAt,
+				Description: fmt.Sprintf("Points of order %v are available", lot.OrderID),
+			}, points, -points)
+		})
+		if txErr != nil {
+			a.p.Logger.Error("application/VestPoints", map[string]interface{}{"lot_id": lot.ID, "error": txErr.Error()})
+			continue
+		}
+		vested += points
+	}
+
+	return vested, nil
+}
+
+// ExpirePoints takes away the points left of lots past their expiry. It returns how many points expired
+func (a *LoyaltyApp) ExpirePoints(now time.Time) (int64, error) {
+	repoLoyalty := loyalty.NewLoyaltyRepository(a.p, a.c)
+	lots, err := repoLoyalty.GetLotsToExpire(now, lotBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int64
+	for i := range lots {
+		lot := &lots[i]
+		if !lot.Expired(now) {
+			continue
+		}
+		points := lot.Remaining
+		lot.Remaining = 0
+
+		txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+			if err := repoLoyalty.UpdateLot(tx, lot, points, true); err != nil {
+				return err
+			}
+
+			return repoLoyalty.PostEntry(tx, &loyalty_entity.PointsEntry{
+				AccountID:   lot.AccountID,
+				Type:        loyalty_entity.EntryExpire,
+				Points:      -points,
+				OrderID:     lot.OrderID,
+				LotID:       lot.ID,
+				Description: "Points expired",
+			}, -points, 0)
+		})
+		if txErr != nil {
+			a.p.Logger.Error("application/ExpirePoints", map[string]interface{}{"lot_id": lot.ID, "error": txErr.Error()})
+			continue
+		}
+		expired += points
+	}
+
+	return expired, nil
+}
+
+// redeemPoints takes the points off the orders as a discount, one after the other, never more than an
+// order's checkout. Points left over once every order is free are not redeemed, and an order the
+// points pay in full is confirmed
+func (a *LoyaltyApp) redeemPoints(tx *gorm.DB, placed []order_entity.Order, points int64) error {
+	if len(placed) == 0 || points <= 0 {
+		return nil
+	}
+
+	repoLoyalty := loyalty.NewLoyaltyRepository(a.p, a.c)
+	account, err := repoLoyalty.GetOrCreateAccount(tx, placed[0].CustomerID)
+	if err != nil {
+		return err
+	}
+	if points > account.Available {
+		return fmt.Errorf("only %v loyalty points are available", account.Available)
+	}
+
+	settings := ConfiguredLoyaltySettings()
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	left := points
+	for i := range placed {
+		order := &placed[i]
+		redeemed := min(left, settings.PointsFor(order.TotalCheckout))
+		if redeemed <= 0 {
+			continue
+		}
+
+		if err := a.spendLots(tx, account.ID, redeemed); err != nil {
+			return err
+		}
+		err := repoLoyalty.PostEntry(tx, &loyalty_entity.PointsEntry{
+			AccountID:   account.ID,
+			Type:        loyalty_entity.EntryRedeem,
+			Points:      -redeemed,
+			OrderID:     int64(order.ID),
+			Description: fmt.Sprintf("Order %v", order.ID),
+		}, -redeemed, 0)
+		if err != nil {
+			return err
+		}
+
+		discount := settings.Discount(redeemed)
+		if err := repoOrder.SavePointsRedemption(tx, int64(order.ID), redeemed, discount); err != nil {
+			return err
+		}
+		order.PointsRedeemed = redeemed
+		order.PointsDiscount = discount
+		order.TotalCheckout = math.Round((order.TotalCheckout-discount)*100) / 100
+
+		if order.TotalCheckout <= 0 && order.Status == order_entity.OrderStatusAwaitingPayment {
+			if err := repoOrder.UpdateOrderStatus(tx, int64(order.ID), order.Status, order_entity.OrderStatusPending); err != nil {
+				return err
+			}
+			order.Status = order_entity.OrderStatusPending
+		}
+		left -= redeemed
+	}
+
+	return nil
+}
+
+// earnPoints credits the points a delivered order earns as pending, they vest once the return window closes
+func (a *LoyaltyApp) earnPoints(tx *gorm.DB, order *order_entity.Order, deliveredAt time.Time) error {
+	repoLoyalty := loyalty.NewLoyaltyRepository(a.p, a.c)
+	earned, err := repoLoyalty.GetOrderEntries(tx, int64(order.ID), loyalty_entity.EntryEarn)
+	if err != nil {
+		return err
+	}
+	if len(earned) > 0 {
+		return nil
+	}
+
+	productIds := make([]int64, 0, len(order.OrderedItems))
+	for _, item := range order.OrderedItems {
+		productIds = append(productIds, item.ProductID)
+	}
+	multipliers, err := repoLoyalty.GetPointsMultipliers(productIds)
+	if err != nil {
+		return err
+	}
+
+	points := ConfiguredLoyaltySettings().EarnedPoints(order, multipliers)
+	if points <= 0 {
+		return nil
+	}
+
+	account, err := repoLoyalty.GetOrCreateAccount(tx, order.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	vestsAt := deliveredAt.AddDate(0, 0, return_entity.ReturnWindowDays)
+	return repoLoyalty.PostEntry(tx, &loyalty_entity.PointsEntry{
+		AccountID:   account.ID,
+		Type:        loyalty_entity.EntryEarn,
+		Points:      points,
+		OrderID:     int64(order.ID),
+		Remaining:   points,
+		VestsAt:     &vestsAt,
+		Description: fmt.Sprintf("Order %v delivered", order.ID),
+	}, 0, points)
+}
+
+// restoreRedeemed gives back the points redeemed on an order that will not be delivered
+func (a *LoyaltyApp) restoreRedeemed(tx *gorm.DB, order *order_entity.Order) error {
+	return a.restorePoints(tx, order, order.PointsRedeemed, 0, fmt.Sprintf("Order %v not delivered", order.ID))
+}
+
+// reverseForReturn settles the points of a completed return in proportion to the value of the accepted
+// units. The points redeemed on them come back, like the rest of the refund, and the points they
+// earned are taken away, as far as they have not been spent yet
+func (a *LoyaltyApp) reverseForReturn(tx *gorm.DB, order *order_entity.Order, ret *return_entity.ReturnRequest) error {
+	if order.TotalCost <= 0 || ret.ItemsAmount <= 0 {
+		return nil
+	}
+	share := math.Min(ret.ItemsAmount/order.TotalCost, 1)
+
+	restored := int64(math.Round(float64(order.PointsRedeemed) * share))
+	if err := a.restorePoints(tx, order, restored, ret.ID, fmt.Sprintf("Return %v", ret.ID)); err != nil {
+		return err
+	}
+
+	repoLoyalty := loyalty.NewLoyaltyRepository(a.p, a.c)
+	lots, err := repoLoyalty.GetOrderEntries(tx, int64(order.ID), loyalty_entity.EntryEarn)
+	if err != nil {
+		return err
+	}
+	for i := range lots {
+		lot := &lots[i]
+		reversed := min(int64(math.Round(float64(lot.Points)*share)), lot.Remaining)
+		if reversed <= 0 {
+			continue
+		}
+
+		fromRemaining := lot.Remaining
+		lot.Remaining -= reversed
+		if err := repoLoyalty.UpdateLot(tx, lot, fromRemaining, lot.Vested); err != nil {
+			return err
+		}
+
+		availableChange, pendingChange := int64(0), -reversed
+		if lot.Vested {
+			availableChange, pendingChange = -reversed, 0
+		}
+		err := repoLoyalty.PostEntry(tx, &loyalty_entity.PointsEntry{
+			AccountID:   lot.AccountID,
+			Type:        loyalty_entity.EntryReverse,
+			Points:      -reversed,
+			OrderID:     int64(order.ID),
+			ReturnID:    ret.ID,
+			LotID:       lot.ID,
+			Description: fmt.Sprintf("Return %v", ret.ID),
+		}, availableChange, pendingChange)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restorePoints credits points back as a new lot, available straight away
+func (a *LoyaltyApp) restorePoints(tx *gorm.DB, order *order_entity.Order, points int64, returnId uint64, description string) error {
+	if points <= 0 {
+		return nil
+	}
+
+	repoLoyalty := loyalty.NewLoyaltyRepository(a.p, a.c)
+	account, err := repoLoyalty.GetOrCreateAccount(tx, order.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().AddDate(0, 0, ConfiguredLoyaltySettings().ExpiryDays)
+	return repoLoyalty.PostEntry(tx, &loyalty_entity.PointsEntry{
+		AccountID:   account.ID,
+		Type:        loyalty_entity.EntryRestore,
+		Points:      points,
+		OrderID:     int64(order.ID),
+		ReturnID:    returnId,
+		Remaining:   points,
+		Vested:      true,
+		ExpiresAt:   &expiresAt,
+		Description: description,
+	}, points, 0)
+}
+
+// spendLots takes the points from the account's lots, the ones expiring first first
+func (a *LoyaltyApp) spendLots(tx *gorm.DB, accountId uint64, points int64) error {
+	repoLoyalty := loyalty.NewLoyaltyRepository(a.p, a.c)
+	lots, err := repoLoyalty.GetSpendableLots(tx, accountId)
+	if err != nil {
+		return err
+	}
+
+	loyalty_entity.SortLotsForSpending(lots)
+	fromRemaining := make([]int64, len(lots))
+	for i := range lots {
+		fromRemaining[i] = lots[i].Remaining
+	}
+
+	if left := loyalty_entity.TakePoints(lots, points); left > 0 {
+		return fmt.Errorf("loyalty account %v does not have %v points to spend", accountId, points)
+	}
+
+	for i := range lots {
+		if lots[i].Remaining == fromRemaining[i] {
+			continue
+		}
+		if err := repoLoyalty.UpdateLot(tx, &lots[i], fromRemaining[i], true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
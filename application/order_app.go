@@ -1,19 +1,35 @@
 package application
 
 import (
-	"errors"
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/ordereditem_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/order_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/addresses"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/bundles"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/customers"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/inventories"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/ordereditems"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/orders"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/payments"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/products"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/schedules"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/warehouses"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/utils/geo"
+	"gorm.io/gorm"
 )
 
 type OrderApp struct {
@@ -25,48 +41,111 @@ func NewOrderApplication(p *base.Persistence, c *gin.Context) order_repository.O
 	return &OrderApp{p, c}
 }
 
-func (a *OrderApp) CalculateTotalCost(rawOrder order_entity.RawOrder) float64 {
+// CalculateTotalCost prices the products of a raw order, a product that cannot be found fails the order
+func (a *OrderApp) CalculateTotalCost(rawOrder order_entity.RawOrder) (float64, error) {
 	span := a.p.Logger.Start(a.c, "application/CalculateTotalCost")
 	defer span.End()
 	var totalCost float64
 
 	for productID, quantity := range rawOrder.Products {
 		id, _ := strconv.ParseInt(productID, 10, 64)
-		product, err := products.NewProductRepository(a.p, a.c).GetProduct(id); if err != nil {
-			log.Println(err)
+		product, err := products.NewProductRepository(a.p, a.c).GetProduct(id)
+		if err != nil {
+			a.p.Logger.Error("application/CalculateTotalCost", map[string]interface{}{"product_id": productID, "error": err.Error()})
+			return 0, fmt.Errorf("product %v not found", productID)
 		}
 
 		totalCost += (product.Price * float64(quantity))
 	}	
 
 	a.p.Logger.Info("application/CalculateTotalCost", map[string]interface{}{"total_cost": totalCost})
-	return totalCost
+	return totalCost, nil
 }
 
 
-func (a *OrderApp) SaveOrderFromRaw(rawOrder order_entity.RawOrder) (*order_entity.Order, error) {
-	// Start a new span for the SaveOrderFromRaw function
-	span := a.p.Logger.Start(a.c, "application/SaveOrderFromRaw", a.p.Logger.SetContextWithSpanFunc())
+// PlaceOrder saves a raw order, an order that does not name a warehouse ships from the nearest one
+// delivering to the address, and is split into one order per warehouse when no single warehouse has every item
+func (a *OrderApp) PlaceOrder(rawOrder order_entity.RawOrder) ([]order_entity.Order, error) {
+	// Start a new span for the PlaceOrder function
+	span := a.p.Logger.Start(a.c, "application/PlaceOrder", a.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
-	var errTx error
-	tx := a.p.DB.Begin()
-	if tx.Error != nil {
-		return nil, errors.New("failed to start transaction")
-	}
-
-	// Defer rollback in case of panic
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		} else if errTx != nil {
-			tx.Rollback()
-		} else {
-			errC := tx.Commit().Error
-			if errC != nil {
-				tx.Rollback()
+	deliveryAddress, addressErr := a.ResolveDeliveryAddress(rawOrder)
+	if addressErr != nil {
+		return nil, addressErr
+	}
+
+	// A delivery slot belongs to one warehouse, so an order booking one ships from it whole
+	var deliverySlot *warehouse_entity.DeliverySlot
+	if rawOrder.DeliverySlotID > 0 {
+		deliverySlot, _ = schedules.NewScheduleRepository(a.p, a.c).GetDeliverySlot(int64(rawOrder.DeliverySlotID))
+		if deliverySlot == nil {
+			return nil, fmt.Errorf("delivery slot %v not found", rawOrder.DeliverySlotID)
+		}
+		if rawOrder.WarehouseID == 0 {
+			rawOrder.WarehouseID = deliverySlot.WarehouseID
+		}
+		if rawOrder.WarehouseID != deliverySlot.WarehouseID {
+			return nil, fmt.Errorf("delivery slot %v is not a slot of warehouse %v", deliverySlot.ID, rawOrder.WarehouseID)
+		}
+	}
+
+	fulfilments, planErr := a.PlanFulfilment(rawOrder, deliveryAddress)
+	if planErr != nil {
+		return nil, planErr
+	}
+
+	fulfilmentGroup := ""
+	if len(fulfilments) > 1 {
+		var groupErr error
+		fulfilmentGroup, groupErr = newFulfilmentGroup()
+		if groupErr != nil {
+			return nil, groupErr
+		}
+		a.p.Logger.Info("application/PlaceOrder", map[string]interface{}{"fulfilment_group": fulfilmentGroup, "fulfilments": fulfilments})
+	}
+
+	var savedOrders []order_entity.Order
+	err := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		if deliverySlot != nil {
+			if reserveErr := schedules.NewScheduleRepository(a.p, a.c).ReserveDeliverySlot(tx, int64(deliverySlot.ID)); reserveErr != nil {
+				return reserveErr
 			}
 		}
-	}()
+
+		for _, fulfilment := range fulfilments {
+			splitOrder := rawOrder
+			splitOrder.WarehouseID = fulfilment.WarehouseID
+			splitOrder.Products = fulfilment.Products
+
+			savedOrder, saveErr := a.SaveOrderFromRaw(tx, splitOrder, deliveryAddress, fulfilmentGroup, deliverySlot)
+			if saveErr != nil {
+				return saveErr
+			}
+			savedOrders = append(savedOrders, *savedOrder)
+		}
+
+		// Points come off the checkout first, the wallet pays what is left
+		if err := (&LoyaltyApp{a.p, a.c}).redeemPoints(tx, savedOrders, rawOrder.RedeemPoints); err != nil {
+			return err
+		}
+		if rawOrder.UseWallet {
+			return (&WalletApp{a.p, a.c}).payOrders(tx, savedOrders)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return savedOrders, nil
+}
+
+// SaveOrderFromRaw saves a raw order shipping from its warehouse and reduces the stock that warehouse holds of its items,
+// the delivery slot is expected to be reserved already
+func (a *OrderApp) SaveOrderFromRaw(tx *gorm.DB, rawOrder order_entity.RawOrder, deliveryAddress *order_entity.DeliveryAddress, fulfilmentGroup string, deliverySlot *warehouse_entity.DeliverySlot) (*order_entity.Order, error) {
+	if rawOrder.Status == "" {
+		rawOrder.Status = order_entity.OrderStatusPending
+	}
 
 	// Create an order entity
 	order := order_entity.Order{
@@ -74,10 +153,21 @@ func (a *OrderApp) SaveOrderFromRaw(rawOrder order_entity.RawOrder) (*order_enti
 		WarehouseID: rawOrder.WarehouseID,
 		Status:      rawOrder.Status,
 		TotalFees:   0,
+		AddressID:   rawOrder.AddressID,
+		DeliveryAddress: *deliveryAddress,
+		FulfilmentGroup: fulfilmentGroup,
+	}
+	if deliverySlot != nil {
+		order.DeliverySlotID = deliverySlot.ID
+		order.DeliveryStartsAt = &deliverySlot.StartsAt
+		order.DeliveryEndsAt = &deliverySlot.EndsAt
 	}
 
 	// Calculates total costs of all the products
-	totalCost := a.CalculateTotalCost(rawOrder)
+	totalCost, costErr := a.CalculateTotalCost(rawOrder)
+	if costErr != nil {
+		return nil, costErr
+	}
 	// Set other fields of the order entity
 	order.TotalCost = totalCost
 	totalCheckout := totalCost + order.TotalFees
@@ -90,8 +180,7 @@ func (a *OrderApp) SaveOrderFromRaw(rawOrder order_entity.RawOrder) (*order_enti
 	savedOrder, err := repoOrder.SaveOrder(tx, &order)
 
 	if err != nil {
-		errTx = err
-		return nil, errTx
+		return nil, err
 	}
 
 	repoOrderedItem := ordereditems.NewOrderedItemsRepository(a.p, a.c)
@@ -100,7 +189,6 @@ func (a *OrderApp) SaveOrderFromRaw(rawOrder order_entity.RawOrder) (*order_enti
 
 		product, productErr := products.NewProductRepository(a.p, a.c).GetProduct(productId)
 		if productErr != nil {
-			errTx = err
 			return nil, productErr
 		}
 
@@ -113,24 +201,297 @@ func (a *OrderApp) SaveOrderFromRaw(rawOrder order_entity.RawOrder) (*order_enti
 		}
 
 		inventoryRepo := inventories.NewInventoryRepository(a.p, a.c)
-		reduceInventoryErr := inventoryRepo.ReduceInventory(tx, productId, quantity)
+		var reduceInventoryErr error
+		if product.IsBundle() {
+			var bundleItems []product_entity.BundleItem
+			bundleItems, reduceInventoryErr = a.ReduceBundleInventory(tx, product.ID, quantity, order.WarehouseID)
+			for _, bundleItem := range bundleItems {
+				orderedItem.Components = append(orderedItem.Components, ordereditem_entity.OrderedComponent{
+					ComponentID: bundleItem.ComponentID,
+					Quantity:    bundleItem.Quantity,
+				})
+			}
+		} else {
+			reduceInventoryErr = inventoryRepo.ReduceInventory(tx, productId, quantity, order.WarehouseID)
+		}
 
 		if reduceInventoryErr != nil {
-			errTx = reduceInventoryErr
-			return nil, errTx
+			return nil, reduceInventoryErr
 		}
 		// Save ordered item
 		_, err := repoOrderedItem.SaveOrderedItem(tx, &orderedItem)
 
 		if err != nil {
-			errTx = err
-			return nil, errTx
+			return nil, err
 		}
 	}
 	
 	return savedOrder, nil
 }
 
+// PlanFulfilment picks the warehouses an order ships from. A warehouse named on the order must deliver to the address
+// and have every item in stock, otherwise the nearest warehouse delivering there with every item in stock is used,
+// and failing that each item ships from the nearest delivering warehouse that stocks it. A bundle ships whole, so
+// all its components must be stocked at the one warehouse
+func (a *OrderApp) PlanFulfilment(rawOrder order_entity.RawOrder, deliveryAddress *order_entity.DeliveryAddress) ([]order_entity.Fulfilment, error) {
+	repoWarehouse := warehouses.NewWareHouseRepository(a.p, a.c)
+
+	needs, err := a.stockNeeds(rawOrder.Products)
+	if err != nil {
+		return nil, err
+	}
+
+	var stockIds []int64
+	for _, components := range needs {
+		for componentId := range components {
+			stockIds = append(stockIds, componentId)
+		}
+	}
+
+	inventoryRows, err := inventories.NewInventoryRepository(a.p, a.c).GetInventoriesForProducts(stockIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// stock[warehouse][product] is what is left to allocate
+	stock := map[uint64]map[int64]int64{}
+	for _, row := range inventoryRows {
+		if stock[row.WarehouseID] == nil {
+			stock[row.WarehouseID] = map[int64]int64{}
+		}
+		stock[row.WarehouseID][int64(row.ProductID)] += int64(row.Stock)
+	}
+
+	hasStock := func(warehouseId uint64, components map[int64]int64) bool {
+		for componentId, quantity := range components {
+			if stock[warehouseId][componentId] < quantity {
+				return false
+			}
+		}
+		return true
+	}
+
+	total := map[int64]int64{}
+	for _, components := range needs {
+		for componentId, quantity := range components {
+			total[componentId] += quantity
+		}
+	}
+
+	if rawOrder.WarehouseID > 0 {
+		warehouse, _ := repoWarehouse.GetWarehouse(int64(rawOrder.WarehouseID))
+		if warehouse == nil || warehouse.ID == 0 {
+			return nil, fmt.Errorf("warehouse %v not found", rawOrder.WarehouseID)
+		}
+
+		if !warehouse.Serves(deliveryAddress.Latitude, deliveryAddress.Longitude) {
+			return nil, fmt.Errorf("warehouse %v does not deliver to %v", warehouse.ID, deliveryAddress.Address)
+		}
+
+		productIDs := make([]string, 0, len(needs))
+		for productID := range needs {
+			productIDs = append(productIDs, productID)
+		}
+		sort.Strings(productIDs)
+		for _, productID := range productIDs {
+			if !hasStock(warehouse.ID, needs[productID]) {
+				return nil, fmt.Errorf("product %v is not in stock at warehouse %v", productID, warehouse.ID)
+			}
+		}
+		if !hasStock(warehouse.ID, total) {
+			return nil, fmt.Errorf("warehouse %v does not have enough stock for the order", warehouse.ID)
+		}
+
+		return []order_entity.Fulfilment{{
+			WarehouseID: warehouse.ID,
+			DistanceKm:  geo.Haversine(warehouse.Latitude, warehouse.Longitude, deliveryAddress.Latitude, deliveryAddress.Longitude),
+			Products:    rawOrder.Products,
+		}}, nil
+	}
+
+	nearest, err := repoWarehouse.GetNearestWarehouses(deliveryAddress.Latitude, deliveryAddress.Longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	var serving []warehouse_entity.WarehouseDistance
+	for _, warehouse := range nearest {
+		if warehouse.Serves {
+			serving = append(serving, warehouse)
+		}
+	}
+	if len(serving) == 0 {
+		return nil, fmt.Errorf("no warehouse delivers to %v", deliveryAddress.Address)
+	}
+
+	for _, warehouse := range serving {
+		if hasStock(warehouse.ID, total) {
+			return []order_entity.Fulfilment{{
+				WarehouseID: warehouse.ID,
+				DistanceKm:  warehouse.DistanceKm,
+				Products:    rawOrder.Products,
+			}}, nil
+		}
+	}
+
+	// No warehouse has everything, split the items across the nearest warehouses that stock them
+	productIDs := make([]string, 0, len(rawOrder.Products))
+	for productID := range rawOrder.Products {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Strings(productIDs)
+
+	split := map[uint64]map[string]int64{}
+	for _, productID := range productIDs {
+		allocated := false
+		for _, warehouse := range serving {
+			if !hasStock(warehouse.ID, needs[productID]) {
+				continue
+			}
+			for componentId, quantity := range needs[productID] {
+				stock[warehouse.ID][componentId] -= quantity
+			}
+			if split[warehouse.ID] == nil {
+				split[warehouse.ID] = map[string]int64{}
+			}
+			split[warehouse.ID][productID] = rawOrder.Products[productID]
+			allocated = true
+			break
+		}
+
+		if !allocated {
+			return nil, fmt.Errorf("product %v is not in stock at any warehouse delivering to %v", productID, deliveryAddress.Address)
+		}
+	}
+
+	var fulfilments []order_entity.Fulfilment
+	for _, warehouse := range serving {
+		if split[warehouse.ID] != nil {
+			fulfilments = append(fulfilments, order_entity.Fulfilment{
+				WarehouseID: warehouse.ID,
+				DistanceKm:  warehouse.DistanceKm,
+				Products:    split[warehouse.ID],
+			})
+		}
+	}
+
+	return fulfilments, nil
+}
+
+// stockNeeds maps every ordered product to the stock it uses, a bundle uses the stock of its components
+func (a *OrderApp) stockNeeds(orderedProducts map[string]int64) (map[string]map[int64]int64, error) {
+	needs := map[string]map[int64]int64{}
+	for productID, quantity := range orderedProducts {
+		productId, parseErr := strconv.ParseInt(productID, 10, 64)
+		if parseErr != nil || quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity %v for product %v", quantity, productID)
+		}
+
+		product, _ := products.NewProductRepository(a.p, a.c).GetProduct(productId)
+		if product == nil || product.ID == 0 {
+			return nil, fmt.Errorf("product %v not found", productID)
+		}
+
+		needs[productID] = map[int64]int64{}
+		if !product.IsBundle() {
+			needs[productID][productId] = quantity
+			continue
+		}
+
+		items, err := bundles.NewBundleRepository(a.p, a.c).GetBundleItems(productId)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			needs[productID][int64(item.ComponentID)] += item.Quantity * quantity
+		}
+	}
+
+	return needs, nil
+}
+
+// newFulfilmentGroup returns the key shared by the orders an order was split into
+func newFulfilmentGroup() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+
+// ResolveDeliveryAddress snapshots the address chosen for the order, falling back to the customer's
+// default address and then to the address on the customer profile
+func (a *OrderApp) ResolveDeliveryAddress(rawOrder order_entity.RawOrder) (*order_entity.DeliveryAddress, error) {
+	repoAddress := addresses.NewAddressRepository(a.p, a.c)
+
+	var address *address_entity.Address
+	if rawOrder.AddressID > 0 {
+		var err error
+		address, err = repoAddress.GetAddress(int64(rawOrder.AddressID))
+		if err != nil {
+			return nil, err
+		}
+		if address.CustomerID != rawOrder.CustomerID {
+			return nil, fmt.Errorf("address %v not found", rawOrder.AddressID)
+		}
+	} else {
+		var err error
+		address, err = repoAddress.GetDefaultAddress(rawOrder.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var deliveryAddress order_entity.DeliveryAddress
+	if address != nil {
+		deliveryAddress = order_entity.DeliveryAddress{
+			Label:        address.Label,
+			ContactName:  address.ContactName,
+			Phone:        address.Phone,
+			Address:      address.Address,
+			Instructions: address.Instructions,
+			Latitude:     address.Latitude,
+			Longitude:    address.Longitude,
+		}
+	} else {
+		customer, _ := customers.NewCustomerRepository(a.p, a.c).GetCustomer(rawOrder.CustomerID)
+		if customer == nil || customer.ID == 0 {
+			return nil, fmt.Errorf("customer %v not found", rawOrder.CustomerID)
+		}
+		deliveryAddress = order_entity.DeliveryAddress{
+			ContactName: customer.Name,
+			Address:     customer.Address,
+			Latitude:    customer.Latitude,
+			Longitude:   customer.Longitude,
+		}
+	}
+
+	return &deliveryAddress, nil
+}
+
+// ReduceBundleInventory deducts the stock the warehouse holds of every component in a bundle for the quantity of bundles ordered
+// and returns the components it deducted, so the ordered item can record them
+func (a *OrderApp) ReduceBundleInventory(tx *gorm.DB, bundleId uint64, quantity int64, warehouseId uint64) ([]product_entity.BundleItem, error) {
+	items, err := bundles.NewBundleRepository(a.p, a.c).GetBundleItems(int64(bundleId))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("bundle %v has no components", bundleId)
+	}
+
+	inventoryRepo := inventories.NewInventoryRepository(a.p, a.c)
+	for _, item := range items {
+		reduceErr := inventoryRepo.ReduceInventoryForBundle(tx, int64(item.ComponentID), item.Quantity*quantity, bundleId, warehouseId)
+		if reduceErr != nil {
+			return nil, fmt.Errorf("component %v: %v", item.ComponentID, reduceErr)
+		}
+	}
+
+	return items, nil
+}
 
 func (a *OrderApp) GetOrder(OrderId int64) (*order_entity.Order, error) {
 	span := a.p.Logger.Start(a.c, "application/GetOrder", a.p.Logger.SetContextWithSpanFunc())
@@ -144,6 +505,102 @@ func (a *OrderApp) GetAllOrders() ([]order_entity.Order, error) {
 	return repoOrder.GetAllOrders()
 }
 	
+func (a *OrderApp) GetOrdersByCustomer(customerId int64) ([]order_entity.Order, error) {
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	return repoOrder.GetOrdersByCustomer(customerId)
+}
+
+func (a *OrderApp) GetOrdersByCustomerPage(customerId int64, pagination *entity.Pagination) ([]order_entity.Order, error) {
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	return repoOrder.GetOrdersByCustomerPage(customerId, pagination)
+}
+
+// CancelOrder cancels a pending or unpaid order, puts its items back in stock, frees its delivery slot,
+// and gives back what was paid for it and the points redeemed
+func (a *OrderApp) CancelOrder(orderId int64) (*order_entity.Order, error) {
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	order, _ := repoOrder.GetOrder(orderId)
+	if order == nil || order.ID == 0 {
+		return nil, fmt.Errorf("order %v not found", orderId)
+	}
+
+	if order.Status != order_entity.OrderStatusPending && order.Status != order_entity.OrderStatusAwaitingPayment {
+		return nil, fmt.Errorf("only pending orders can be cancelled, order %v is %v", orderId, order.Status)
+	}
+
+	var settle func()
+	err := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repoOrder.UpdateOrderStatus(tx, orderId, order.Status, order_entity.OrderStatusCancelled); err != nil {
+			return err
+		}
+
+		reason := fmt.Sprintf("Order %v cancelled", orderId)
+		if err := (&ShipmentApp{a.p, a.c}).restockOrder(tx, order, reason); err != nil {
+			return err
+		}
+
+		if order.DeliverySlotID > 0 {
+			if err := schedules.NewScheduleRepository(a.p, a.c).ReleaseDeliverySlot(tx, int64(order.DeliverySlotID)); err != nil {
+				return err
+			}
+		}
+
+		var refundErr error
+		settle, refundErr = a.refundOrder(tx, orderId, reason)
+		return refundErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	settle()
+
+	return repoOrder.GetOrder(orderId)
+}
+
+// refundOrder gives back what the customer paid for an order beyond what it now costs: all of it once the
+// order is cancelled or returned, the difference when a short pick lowered its total. The card payments are
+// refunded first and the wallet gets the rest, a cancelled or returned order also gets its redeemed points back.
+// The wallet and points move inside tx, the gateway is only called by the returned func once tx has committed
+func (a *OrderApp) refundOrder(tx *gorm.DB, orderId int64, reason string) (func(), error) {
+	order, err := orders.NewOrderRepository(a.p, a.c).LockOrder(tx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := payments.NewPaymentRepository(a.p, a.c).GetPaymentsForOrder(orderId)
+	if err != nil {
+		return nil, err
+	}
+	var held float64
+	for i := range records {
+		held += records[i].HeldAmount()
+	}
+
+	closed := order.Status == order_entity.OrderStatusCancelled || order.Status == order_entity.OrderStatusReturned
+	due := math.Max(order.TotalCheckout, 0)
+	if closed {
+		due = 0
+	}
+
+	overpaid := math.Round((held+order.WalletAmount-due)*100) / 100
+	fromCard := math.Max(math.Min(overpaid, held), 0)
+	if err := (&WalletApp{a.p, a.c}).refundOrder(tx, order, overpaid-fromCard, reason); err != nil {
+		return nil, err
+	}
+	if closed {
+		if err := (&LoyaltyApp{a.p, a.c}).restoreRedeemed(tx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	return func() {
+		if fromCard > 0 {
+			(&PaymentApp{a.p, a.c}).releaseOrderPayments(orderId, fromCard, reason)
+		}
+	}, nil
+}
+
 func (a *OrderApp) UpdateOrder(Order *order_entity.Order) (*order_entity.Order, error) {
 	span := a.p.Logger.Start(a.c, "application/UpdateOrder", a.p.Logger.SetContextWithSpanFunc())
 	defer span.End()
@@ -151,8 +608,23 @@ func (a *OrderApp) UpdateOrder(Order *order_entity.Order) (*order_entity.Order,
 	return repoOrder.UpdateOrder(Order)
 }
 
+// DeleteOrder removes an order once nothing more happens to it. A pending order is cancelled first so its
+// stock, slot and payments are given back, an order on its way to the customer cannot be deleted
 func (a *OrderApp) DeleteOrder(OrderId int64) error {
 	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	order, _ := repoOrder.GetOrder(OrderId)
+	if order == nil || order.ID == 0 {
+		return fmt.Errorf("order %v not found", OrderId)
+	}
+
+	if order.Status == order_entity.OrderStatusPending || order.Status == order_entity.OrderStatusAwaitingPayment {
+		if _, err := a.CancelOrder(OrderId); err != nil {
+			return err
+		}
+	} else if !slices.Contains(order_entity.ClosedStatuses, order.Status) {
+		return fmt.Errorf("order %v is %v, only pending, delivered, cancelled or returned orders can be deleted", OrderId, order.Status)
+	}
+
 	return repoOrder.DeleteOrder(OrderId)
 }
 
@@ -5,7 +5,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/ordereditem_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/ordereditem_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/bundles"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/inventories"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/ordereditems"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
@@ -20,16 +22,33 @@ func NewOrderedItemApplication(p *base.Persistence, c *gin.Context) ordereditem_
 	return &OrderedItemApp{p, c}
 }
 
-func (a *OrderedItemApp) ReverseOrder(orderedItems []ordereditem_entity.OrderedItem) map[string]string {
+// ReverseOrder puts the ordered items back in stock at the warehouse the order ships from
+func (a *OrderedItemApp) ReverseOrder(warehouseId uint64, orderedItems []ordereditem_entity.OrderedItem) map[string]string {
 	errorMap := make(map[string]string)
 
 	for _, orderedItem := range orderedItems {
 		// Calculate the quantity to add to inventory by reversing the ordered quantity
 		quantityToAdd := orderedItem.Quantity
 
-		// Increase inventory for the product
+		// Increase inventory for the product, or for each component if it is a bundle
 		inventoryRepo := inventories.NewInventoryRepository(a.p, a.c)
-		err := inventoryRepo.IncreaseInventory(orderedItem.ProductID, quantityToAdd)
+		bundleItems, bundleErr := orderedBundleItems(a.p, a.c, orderedItem.ID, orderedItem.ProductID)
+		if bundleErr != nil {
+			errorMap[fmt.Sprintf("product_%d", orderedItem.ProductID)] = bundleErr.Error()
+			continue
+		}
+
+		if len(bundleItems) > 0 {
+			for _, bundleItem := range bundleItems {
+				err := inventoryRepo.IncreaseInventoryForBundle(int64(bundleItem.ComponentID), warehouseId, bundleItem.Quantity*quantityToAdd, uint64(orderedItem.ProductID))
+				if err != nil {
+					errorMap[fmt.Sprintf("product_%d", bundleItem.ComponentID)] = err.Error()
+				}
+			}
+			continue
+		}
+
+		err := inventoryRepo.IncreaseInventory(orderedItem.ProductID, warehouseId, quantityToAdd)
 		if err != nil {
 			errorMap[fmt.Sprintf("product_%d", orderedItem.ProductID)] = err.Error()
 		}
@@ -39,6 +58,30 @@ func (a *OrderedItemApp) ReverseOrder(orderedItems []ordereditem_entity.OrderedI
 	return errorMap
 }
 
+// orderedBundleItems returns the components of a bundle as they were when the item was ordered.
+// Items ordered before components were recorded fall back to the bundle's current components
+func orderedBundleItems(p *base.Persistence, c *gin.Context, orderedItemId uint64, productId int64) ([]product_entity.BundleItem, error) {
+	components, err := ordereditems.NewOrderedItemsRepository(p, c).GetOrderedComponents(orderedItemId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(components) == 0 {
+		return bundles.NewBundleRepository(p, c).GetBundleItems(productId)
+	}
+
+	bundleItems := make([]product_entity.BundleItem, 0, len(components))
+	for _, component := range components {
+		bundleItems = append(bundleItems, product_entity.BundleItem{
+			BundleID:    uint64(productId),
+			ComponentID: component.ComponentID,
+			Quantity:    component.Quantity,
+		})
+	}
+
+	return bundleItems, nil
+}
+
 func (a *OrderedItemApp) GetAllOrderedItems() ([]ordereditem_entity.OrderedItem, error) {
 	repoOrderedItem := ordereditems.NewOrderedItemsRepository(a.p, a.c)
 	return repoOrderedItem.GetAllOrderedItems()
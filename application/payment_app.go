@@ -0,0 +1,354 @@
+package application
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/payment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/payment_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/orders"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/payment"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/payments"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+type PaymentApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewPaymentApplication(p *base.Persistence, c *gin.Context) payment_repository.PaymentHandlerRepository {
+	return &PaymentApp{p, c}
+}
+
+func (a *PaymentApp) gateway() payment_repository.PaymentRepository {
+	return payment.NewPaymentRepository(payment.ConfiguredGateway(), a.p, a.c)
+}
+
+// AuthorizePayment authorizes what is left to pay of an order awaiting payment, its checkout total
+// less what the customer's wallet paid. A successful
+// authorization confirms the order, which then goes on to be picked. A declined one is recorded
+// and the customer can try again with another payment method
+func (a *PaymentApp) AuthorizePayment(orderId int64, request payment_entity.PaymentRequest, actorId int64) (*payment_entity.Payment, map[string]string) {
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	order, _ := repoOrder.GetOrder(orderId)
+	if order == nil || order.ID == 0 {
+		return nil, map[string]string{"order_not_found": fmt.Sprintf("order %v not found", orderId)}
+	}
+
+	if order.Status != order_entity.OrderStatusAwaitingPayment {
+		return nil, map[string]string{"status": fmt.Sprintf("order %v is %v, only orders awaiting payment can be paid", orderId, order.Status)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	due := math.Round((order.TotalCheckout-order.WalletAmount)*100) / 100
+	gateway := a.gateway()
+	result, err := gateway.Authorize(request.Token, due, fmt.Sprintf("Order %v", orderId))
+	if err != nil {
+		return nil, map[string]string{"gateway_error": err.Error()}
+	}
+
+	now := time.Now()
+	record := payment_entity.Payment{
+		OrderID:    orderId,
+		CustomerID: order.CustomerID,
+		Gateway:    gateway.Name(),
+		Reference:  result.Reference,
+		Status:     payment_entity.PaymentStatusDeclined,
+		Amount:     due,
+		Events: []payment_entity.PaymentEvent{{
+			Type:       payment_entity.EventAuthorize,
+			Amount:     due,
+			Success:    result.Success,
+			Message:    result.Message,
+			RecordedBy: actorId,
+			RecordedAt: now,
+		}},
+	}
+
+	repoPayment := payments.NewPaymentRepository(a.p, a.c)
+	if !result.Success {
+		record.DeclineReason = result.Message
+		if _, err := repoPayment.SavePayment(nil, &record); err != nil {
+			return nil, map[string]string{"db_error": err.Error()}
+		}
+		return nil, map[string]string{"token": fmt.Sprintf("payment declined: %v", result.Message)}
+	}
+
+	record.Status = payment_entity.PaymentStatusAuthorized
+	record.AuthorizedAt = &now
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := repoPayment.SavePayment(tx, &record); err != nil {
+			return err
+		}
+		return repoOrder.UpdateOrderStatus(tx, orderId, order_entity.OrderStatusAwaitingPayment, order_entity.OrderStatusPending)
+	})
+	if txErr != nil {
+		// The order was not confirmed, so the money must not stay held
+		if _, voidErr := gateway.Void(result.Reference); voidErr != nil {
+			a.p.Logger.Error("application/AuthorizePayment", map[string]interface{}{"order_id": orderId, "reference": result.Reference, "error": voidErr.Error()})
+		}
+		return nil, map[string]string{"status": txErr.Error()}
+	}
+
+	return a.reload(int64(record.ID))
+}
+
+func (a *PaymentApp) GetPayment(paymentId int64) (*payment_entity.Payment, error) {
+	repoPayment := payments.NewPaymentRepository(a.p, a.c)
+	return repoPayment.GetPayment(paymentId)
+}
+
+func (a *PaymentApp) GetPaymentsForOrder(orderId int64) ([]payment_entity.Payment, error) {
+	repoPayment := payments.NewPaymentRepository(a.p, a.c)
+	return repoPayment.GetPaymentsForOrder(orderId)
+}
+
+// CapturePayment takes the money of an authorized payment, all of it unless an amount is given
+func (a *PaymentApp) CapturePayment(paymentId int64, request payment_entity.AmountRequest, actorId int64) (*payment_entity.Payment, map[string]string) {
+	record, recordErr := a.paymentIn(paymentId, payment_entity.PaymentStatusAuthorized)
+	if recordErr != nil {
+		return nil, recordErr
+	}
+
+	if validationErr := request.Validate(record.Amount); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+	amount := request.Amount
+	if amount == 0 {
+		amount = record.Amount
+	}
+
+	result, err := a.gateway().Capture(record.Reference, amount)
+	if err != nil {
+		return nil, map[string]string{"gateway_error": err.Error()}
+	}
+
+	now := time.Now()
+	event := payment_entity.PaymentEvent{Type: payment_entity.EventCapture, Amount: amount, Success: result.Success, Message: result.Message, RecordedBy: actorId, RecordedAt: now}
+	if !result.Success {
+		return a.declined(record, event)
+	}
+
+	record.Status = payment_entity.PaymentStatusCaptured
+	record.CapturedAmount = amount
+	record.CapturedAt = &now
+	return a.saveStep(record, payment_entity.PaymentStatusAuthorized, event)
+}
+
+// VoidPayment releases an authorized payment that will not be captured
+func (a *PaymentApp) VoidPayment(paymentId int64, actorId int64) (*payment_entity.Payment, map[string]string) {
+	record, recordErr := a.paymentIn(paymentId, payment_entity.PaymentStatusAuthorized)
+	if recordErr != nil {
+		return nil, recordErr
+	}
+
+	result, err := a.gateway().Void(record.Reference)
+	if err != nil {
+		return nil, map[string]string{"gateway_error": err.Error()}
+	}
+
+	now := time.Now()
+	event := payment_entity.PaymentEvent{Type: payment_entity.EventVoid, Amount: record.Amount, Success: result.Success, Message: result.Message, RecordedBy: actorId, RecordedAt: now}
+	if !result.Success {
+		return a.declined(record, event)
+	}
+
+	record.Status = payment_entity.PaymentStatusVoided
+	record.VoidedAt = &now
+	return a.saveStep(record, payment_entity.PaymentStatusAuthorized, event)
+}
+
+// RefundPayment pays back captured money, all that is left unless an amount is given
+func (a *PaymentApp) RefundPayment(paymentId int64, request payment_entity.AmountRequest, actorId int64) (*payment_entity.Payment, map[string]string) {
+	record, recordErr := a.paymentIn(paymentId, payment_entity.PaymentStatusCaptured, payment_entity.PaymentStatusPartiallyRefunded)
+	if recordErr != nil {
+		return nil, recordErr
+	}
+
+	if validationErr := request.Validate(record.RefundableAmount()); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+	amount := request.Amount
+	if amount == 0 {
+		amount = record.RefundableAmount()
+	}
+
+	result, err := a.gateway().Refund(record.Reference, amount)
+	if err != nil {
+		return nil, map[string]string{"gateway_error": err.Error()}
+	}
+
+	event := payment_entity.PaymentEvent{Type: payment_entity.EventRefund, Amount: amount, Success: result.Success, Message: request.Reason, RecordedBy: actorId, RecordedAt: time.Now()}
+	if !result.Success {
+		event.Message = result.Message
+		return a.declined(record, event)
+	}
+
+	fromStatus := record.Status
+	record.ApplyRefund(amount)
+	return a.saveStep(record, fromStatus, event)
+}
+
+// HandleWebhook applies a notification from the gateway to the payment it is about. Webhooks
+// already seen are ignored, and so are those that do not fit the payment's status, they are
+// only recorded
+func (a *PaymentApp) HandleWebhook(payload []byte, signature string) map[string]string {
+	event, err := a.gateway().VerifyWebhook(payload, signature)
+	if err != nil {
+		return map[string]string{"signature": err.Error()}
+	}
+
+	repoPayment := payments.NewPaymentRepository(a.p, a.c)
+	seen, err := repoPayment.HasGatewayEvent(event.ID)
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+	if seen {
+		return nil
+	}
+
+	record, _ := repoPayment.GetPaymentByReference(event.Reference)
+	if record == nil {
+		return map[string]string{"payment_not_found": fmt.Sprintf("payment %v not found", event.Reference)}
+	}
+
+	now := time.Now()
+	fromStatus := record.Status
+	applied := true
+	switch {
+	case event.Type == payment_entity.WebhookCaptured && record.Status == payment_entity.PaymentStatusAuthorized:
+		record.Status = payment_entity.PaymentStatusCaptured
+		record.CapturedAmount = record.Amount
+		if event.Amount > 0 && event.Amount < record.Amount {
+			record.CapturedAmount = event.Amount
+		}
+		record.CapturedAt = &now
+	case event.Type == payment_entity.WebhookVoided && record.Status == payment_entity.PaymentStatusAuthorized:
+		record.Status = payment_entity.PaymentStatusVoided
+		record.VoidedAt = &now
+	case event.Type == payment_entity.WebhookRefunded && (record.Status == payment_entity.PaymentStatusCaptured || record.Status == payment_entity.PaymentStatusPartiallyRefunded):
+		record.ApplyRefund(min(event.Amount, record.RefundableAmount()))
+	default:
+		applied = false
+	}
+
+	paymentEvent := payment_entity.PaymentEvent{
+		PaymentID:      record.ID,
+		Type:           payment_entity.EventWebhook,
+		Amount:         event.Amount,
+		Success:        applied,
+		Message:        event.Type,
+		GatewayEventID: event.ID,
+		RecordedAt:     now,
+	}
+	if !applied {
+		paymentEvent.Message = fmt.Sprintf("%v ignored, payment is %v", event.Type, record.Status)
+		if err := repoPayment.SavePaymentEvent(nil, &paymentEvent); err != nil {
+			return map[string]string{"db_error": err.Error()}
+		}
+		return nil
+	}
+
+	if _, stepErr := a.saveStep(record, fromStatus, paymentEvent); stepErr != nil {
+		return stepErr
+	}
+	return nil
+}
+
+// releaseOrderPayments gives back up to amount of what the payments of an order hold. Authorizations
+// are voided, or lowered so the capture takes less, and captured money is refunded. Gateway failures are
+// logged, staff can still settle the payment by hand
+func (a *PaymentApp) releaseOrderPayments(orderId int64, amount float64, reason string) {
+	records, err := payments.NewPaymentRepository(a.p, a.c).GetPaymentsForOrder(orderId)
+	if err != nil {
+		a.p.Logger.Error("application/releaseOrderPayments", map[string]interface{}{"order_id": orderId, "error": err.Error()})
+		return
+	}
+
+	left := math.Round(amount*100) / 100
+	for i := range records {
+		record := &records[i]
+		release := math.Round(min(left, record.HeldAmount())*100) / 100
+		if release <= 0 {
+			continue
+		}
+
+		var releaseErr map[string]string
+		switch {
+		case record.Status == payment_entity.PaymentStatusAuthorized && release >= record.Amount:
+			_, releaseErr = a.VoidPayment(int64(record.ID), 0)
+		case record.Status == payment_entity.PaymentStatusAuthorized:
+			event := payment_entity.PaymentEvent{Type: payment_entity.EventReduce, Amount: release, Success: true, Message: reason, RecordedAt: time.Now()}
+			record.Amount = math.Round((record.Amount-release)*100) / 100
+			_, releaseErr = a.saveStep(record, payment_entity.PaymentStatusAuthorized, event)
+		default:
+			_, releaseErr = a.RefundPayment(int64(record.ID), payment_entity.AmountRequest{Amount: release, Reason: reason}, 0)
+		}
+		if releaseErr != nil {
+			a.p.Logger.Error("application/releaseOrderPayments", map[string]interface{}{"order_id": orderId, "payment_id": record.ID, "errors": releaseErr})
+			continue
+		}
+
+		left -= release
+	}
+}
+
+// paymentIn returns the payment if it is in one of the statuses
+func (a *PaymentApp) paymentIn(paymentId int64, statuses ...string) (*payment_entity.Payment, map[string]string) {
+	record, _ := payments.NewPaymentRepository(a.p, a.c).GetPayment(paymentId)
+	if record == nil {
+		return nil, map[string]string{"payment_not_found": fmt.Sprintf("payment %v not found", paymentId)}
+	}
+
+	for _, status := range statuses {
+		if record.Status == status {
+			return record, nil
+		}
+	}
+	return nil, map[string]string{"status": fmt.Sprintf("payment %v is %v", paymentId, record.Status)}
+}
+
+// declined records a call the gateway turned down, the payment itself stays as it was
+func (a *PaymentApp) declined(record *payment_entity.Payment, event payment_entity.PaymentEvent) (*payment_entity.Payment, map[string]string) {
+	event.PaymentID = record.ID
+	if err := payments.NewPaymentRepository(a.p, a.c).SavePaymentEvent(nil, &event); err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return nil, map[string]string{"payment": fmt.Sprintf("gateway declined the %v: %v", event.Type, event.Message)}
+}
+
+// saveStep saves the payment if it is still in fromStatus and records the event, all or nothing
+func (a *PaymentApp) saveStep(record *payment_entity.Payment, fromStatus string, event payment_entity.PaymentEvent) (*payment_entity.Payment, map[string]string) {
+	repoPayment := payments.NewPaymentRepository(a.p, a.c)
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repoPayment.UpdatePayment(tx, record, fromStatus); err != nil {
+			return err
+		}
+
+		event.PaymentID = record.ID
+		return repoPayment.SavePaymentEvent(tx, &event)
+	})
+	if txErr != nil {
+		return nil, map[string]string{"status": txErr.Error()}
+	}
+
+	return a.reload(int64(record.ID))
+}
+
+func (a *PaymentApp) reload(paymentId int64) (*payment_entity.Payment, map[string]string) {
+	record, err := payments.NewPaymentRepository(a.p, a.c).GetPayment(paymentId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return record, nil
+}
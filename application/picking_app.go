@@ -0,0 +1,618 @@
+package application
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/bin_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/picking_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/picking_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/bins"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/inventories"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/ordereditems"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/orders"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/picking"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/schedules"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/warehouses"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+type PickingApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewPickingApplication(p *base.Persistence, c *gin.Context) picking_repository.PickingHandlerRepository {
+	return &PickingApp{p, c}
+}
+
+// CreatePickList batches pending orders of a warehouse into a pick list and moves them to picking
+func (a *PickingApp) CreatePickList(warehouseId int64, request picking_entity.PickListRequest, staffId int64) (*picking_entity.PickList, map[string]string) {
+	warehouse, _ := warehouses.NewWareHouseRepository(a.p, a.c).GetWarehouse(warehouseId)
+	if warehouse == nil || warehouse.ID == 0 {
+		return nil, map[string]string{"warehouse_not_found": fmt.Sprintf("warehouse %v not found", warehouseId)}
+	}
+
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	var ordersToPick []order_entity.Order
+	if len(request.OrderIDs) > 0 {
+		if len(request.OrderIDs) > picking_entity.MaxPickListOrders {
+			return nil, map[string]string{"order_ids": fmt.Sprintf("a pick list takes at most %v orders", picking_entity.MaxPickListOrders)}
+		}
+		for _, orderId := range request.OrderIDs {
+			order, _ := repoOrder.GetOrder(orderId)
+			if order == nil || order.ID == 0 || int64(order.WarehouseID) != warehouseId {
+				return nil, map[string]string{"order_ids": fmt.Sprintf("order %v is not an order of warehouse %v", orderId, warehouseId)}
+			}
+			if order.Status != order_entity.OrderStatusPending {
+				return nil, map[string]string{"order_ids": fmt.Sprintf("order %v is %v, only pending orders can be picked", orderId, order.Status)}
+			}
+			ordersToPick = append(ordersToPick, *order)
+		}
+	} else {
+		maxOrders := request.MaxOrders
+		if maxOrders <= 0 {
+			maxOrders = picking_entity.DefaultPickListOrders
+		}
+		if maxOrders > picking_entity.MaxPickListOrders {
+			maxOrders = picking_entity.MaxPickListOrders
+		}
+
+		var err error
+		ordersToPick, err = repoOrder.GetOrdersToPick(warehouseId, maxOrders)
+		if err != nil {
+			return nil, map[string]string{"db_error": err.Error()}
+		}
+	}
+
+	if len(ordersToPick) == 0 {
+		return nil, map[string]string{"order_ids": "there are no pending orders to pick"}
+	}
+
+	items, err := a.pickItems(warehouseId, ordersToPick)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	pickList := picking_entity.PickList{
+		WarehouseID: warehouse.ID,
+		Status:      picking_entity.PickListStatusOpen,
+		CreatedBy:   staffId,
+		Items:       items,
+	}
+
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		// The conditional status change keeps an order from landing on two pick lists
+		for _, order := range ordersToPick {
+			if err := repoOrder.UpdateOrderStatus(tx, int64(order.ID), order_entity.OrderStatusPending, order_entity.OrderStatusPicking); err != nil {
+				return err
+			}
+		}
+
+		_, saveErr := picking.NewPickingRepository(a.p, a.c).SavePickList(tx, &pickList)
+		return saveErr
+	})
+	if txErr != nil {
+		return nil, map[string]string{"order_ids": txErr.Error()}
+	}
+
+	savedPickList, err := picking.NewPickingRepository(a.p, a.c).GetPickList(int64(pickList.ID))
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return savedPickList, nil
+}
+
+func (a *PickingApp) GetPickList(pickListId int64) (*picking_entity.PickList, error) {
+	repoPicking := picking.NewPickingRepository(a.p, a.c)
+	return repoPicking.GetPickList(pickListId)
+}
+
+func (a *PickingApp) GetPickLists(warehouseId int64, status string) ([]picking_entity.PickList, error) {
+	repoPicking := picking.NewPickingRepository(a.p, a.c)
+	return repoPicking.GetPickLists(warehouseId, status)
+}
+
+// ConfirmPick records how many units of an item were found. Units that could not be found are taken
+// off the order and their stock is returned, once every item of an order is confirmed the order is picked
+func (a *PickingApp) ConfirmPick(pickListId int64, pickItemId int64, confirmation picking_entity.PickConfirmation, staffId int64) (*picking_entity.PickList, map[string]string) {
+	repoPicking := picking.NewPickingRepository(a.p, a.c)
+	pickList, err := repoPicking.GetPickList(pickListId)
+	if err != nil {
+		return nil, map[string]string{"pick_list_not_found": err.Error()}
+	}
+	if pickList.Status != picking_entity.PickListStatusOpen {
+		return nil, map[string]string{"status": fmt.Sprintf("pick list %v is %v", pickListId, pickList.Status)}
+	}
+
+	var item *picking_entity.PickItem
+	for i := range pickList.Items {
+		if int64(pickList.Items[i].ID) == pickItemId {
+			item = &pickList.Items[i]
+		}
+	}
+	if item == nil {
+		return nil, map[string]string{"pick_item_not_found": fmt.Sprintf("pick item %v not found", pickItemId)}
+	}
+	if item.Status != picking_entity.PickItemStatusPending {
+		return nil, map[string]string{"status": fmt.Sprintf("pick item %v has already been confirmed", pickItemId)}
+	}
+
+	picked := item.ToPick()
+	if confirmation.PickedQuantity != nil {
+		picked = *confirmation.PickedQuantity
+	}
+	if picked < 0 || picked > item.ToPick() {
+		return nil, map[string]string{"picked_quantity": fmt.Sprintf("picked_quantity must be between 0 and %v", item.ToPick())}
+	}
+
+	now := time.Now()
+	settle := func() {}
+	var claimErr error
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		// Decide on the items as they are now, a confirmation of another item of the list may
+		// have committed since the list was read
+		lockedItems, err := repoPicking.LockPickItems(tx, pickListId)
+		if err != nil {
+			return err
+		}
+		pickList.Items = lockedItems
+		item = nil
+		for i := range pickList.Items {
+			if int64(pickList.Items[i].ID) == pickItemId {
+				item = &pickList.Items[i]
+			}
+		}
+		if item == nil || item.Status != picking_entity.PickItemStatusPending {
+			claimErr = fmt.Errorf("pick item %v has already been confirmed", pickItemId)
+			return claimErr
+		}
+		// A short pick of another component of the same bundles may have lowered what is left to pick
+		if confirmation.PickedQuantity == nil || picked > item.ToPick() {
+			picked = item.ToPick()
+		}
+
+		short := item.Confirm(picked, staffId, now)
+
+		// Claim the item before touching stock, a concurrent confirmation of it fails here
+		if claimErr = repoPicking.UpdatePickItem(tx, item, picking_entity.PickItemStatusPending); claimErr != nil {
+			return claimErr
+		}
+
+		if short > 0 {
+			if err := a.shortPick(tx, pickList, item, short); err != nil {
+				return err
+			}
+			if err := repoPicking.UpdatePickItem(tx, item, item.Status); err != nil {
+				return err
+			}
+		}
+
+		if picked > 0 && item.BinID > 0 {
+			if err := a.takeFromBin(tx, pickList, item, picked); err != nil {
+				return err
+			}
+		}
+
+		if err := a.advanceOrder(tx, pickList, item.OrderID); err != nil {
+			return err
+		}
+
+		// A short pick lowered the order's total or cancelled it, the customer gets the difference back
+		if short > 0 {
+			var refundErr error
+			settle, refundErr = (&OrderApp{a.p, a.c}).refundOrder(tx, item.OrderID, fmt.Sprintf("Short pick on order %v", item.OrderID))
+			if refundErr != nil {
+				return refundErr
+			}
+		}
+
+		if !pickList.Picked() {
+			return nil
+		}
+		return repoPicking.UpdatePickListStatus(tx, pickListId, picking_entity.PickListStatusOpen, picking_entity.PickListStatusPicked)
+	})
+	if claimErr != nil {
+		return nil, map[string]string{"status": fmt.Sprintf("pick item %v has already been confirmed", pickItemId)}
+	}
+	if txErr != nil {
+		return nil, map[string]string{"db_error": txErr.Error()}
+	}
+
+	settle()
+	a.completePickList(pickListId)
+
+	updatedPickList, err := repoPicking.GetPickList(pickListId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return updatedPickList, nil
+}
+
+// PackOrder records the packages of a picked order, the pick list is completed once all its orders are packed
+func (a *PickingApp) PackOrder(orderId int64, request picking_entity.PackRequest) (*order_entity.Order, map[string]string) {
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	order, _ := repoOrder.GetOrder(orderId)
+	if order == nil || order.ID == 0 {
+		return nil, map[string]string{"order_not_found": fmt.Sprintf("order %v not found", orderId)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	if order.Status != order_entity.OrderStatusPicked {
+		return nil, map[string]string{"status": fmt.Sprintf("order %v is %v, only picked orders can be packed", orderId, order.Status)}
+	}
+
+	if err := repoOrder.SavePackingDetails(orderId, request.PackageCount, request.WeightKg); err != nil {
+		return nil, map[string]string{"status": err.Error()}
+	}
+
+	items, _ := picking.NewPickingRepository(a.p, a.c).GetPickItemsForOrder(nil, orderId)
+	completed := map[uint64]bool{}
+	for _, item := range items {
+		if !completed[item.PickListID] {
+			completed[item.PickListID] = true
+			a.completePickList(int64(item.PickListID))
+		}
+	}
+
+	packedOrder, err := repoOrder.GetOrder(orderId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return packedOrder, nil
+}
+
+// pickItems turns the lines of the orders into items to pick, a bundle becomes its components,
+// and sends each item to the bins holding the product in the order of the walk
+func (a *PickingApp) pickItems(warehouseId int64, ordersToPick []order_entity.Order) ([]picking_entity.PickItem, error) {
+	var items []picking_entity.PickItem
+	var productIds []int64
+	for _, order := range ordersToPick {
+		for _, orderedItem := range order.OrderedItems {
+			if orderedItem.Quantity <= 0 {
+				continue
+			}
+
+			bundleItems, err := orderedBundleItems(a.p, a.c, orderedItem.ID, orderedItem.ProductID)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(bundleItems) == 0 {
+				items = append(items, picking_entity.PickItem{
+					OrderID:       int64(order.ID),
+					OrderedItemID: orderedItem.ID,
+					ProductID:     orderedItem.ProductID,
+					Quantity:      orderedItem.Quantity,
+					Status:        picking_entity.PickItemStatusPending,
+				})
+				productIds = append(productIds, orderedItem.ProductID)
+				continue
+			}
+
+			for _, bundleItem := range bundleItems {
+				items = append(items, picking_entity.PickItem{
+					OrderID:        int64(order.ID),
+					OrderedItemID:  orderedItem.ID,
+					ProductID:      int64(bundleItem.ComponentID),
+					BundleID:       uint64(orderedItem.ProductID),
+					UnitsPerBundle: bundleItem.Quantity,
+					Quantity:       bundleItem.Quantity * orderedItem.Quantity,
+					Status:         picking_entity.PickItemStatusPending,
+				})
+				productIds = append(productIds, int64(bundleItem.ComponentID))
+			}
+		}
+	}
+
+	items, err := a.assignBins(warehouseId, items, productIds)
+	if err != nil {
+		return nil, err
+	}
+
+	inventoryRows, err := inventories.NewInventoryRepository(a.p, a.c).GetInventoriesForProducts(productIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// Products that are not in a bin yet fall back to the location kept on their inventory
+	locations := map[int64]string{}
+	for _, row := range inventoryRows {
+		if int64(row.WarehouseID) == warehouseId {
+			locations[int64(row.ProductID)] = row.Location
+		}
+	}
+	for i := range items {
+		if items[i].BinID == 0 {
+			items[i].Location = locations[items[i].ProductID]
+		}
+	}
+
+	return items, nil
+}
+
+// assignBins takes each item from the first bin along the walk that holds all of it, otherwise a
+// product is split over bins along the walk. Bundle components are not split, as short picks take
+// off whole bundles, and go to the bin that holds the most. The items are then put in walking order
+func (a *PickingApp) assignBins(warehouseId int64, items []picking_entity.PickItem, productIds []int64) ([]picking_entity.PickItem, error) {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	stocks, err := repoBin.GetProductBinStocks(productIds)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := repoBin.GetLayout(warehouseId)
+	if err != nil {
+		return nil, err
+	}
+	layoutBins := bin_entity.Bins(layout)
+
+	stocked := map[uint64]bool{}
+	for _, stock := range stocks {
+		stocked[stock.BinID] = true
+	}
+	preference := bin_entity.RouteOrder(layout, stocked)
+
+	productStocks := map[int64][]*bin_entity.BinStock{}
+	for i := range stocks {
+		if _, ok := layoutBins[stocks[i].BinID]; !ok || int64(stocks[i].WarehouseID) != warehouseId {
+			continue
+		}
+		productStocks[int64(stocks[i].ProductID)] = append(productStocks[int64(stocks[i].ProductID)], &stocks[i])
+	}
+	for _, candidates := range productStocks {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return preference[candidates[i].BinID] < preference[candidates[j].BinID]
+		})
+	}
+
+	var assigned []picking_entity.PickItem
+	for _, item := range items {
+		candidates := productStocks[item.ProductID]
+		need := int(item.Quantity)
+
+		var whole *bin_entity.BinStock
+		for _, candidate := range candidates {
+			if candidate.Quantity >= need {
+				whole = candidate
+				break
+			}
+		}
+
+		if whole == nil && item.BundleID > 0 {
+			for _, candidate := range candidates {
+				if candidate.Quantity > 0 && (whole == nil || candidate.Quantity > whole.Quantity) {
+					whole = candidate
+				}
+			}
+		}
+
+		if whole != nil {
+			whole.Quantity -= min(need, whole.Quantity)
+			item.BinID = whole.BinID
+			item.Location = layoutBins[whole.BinID].Label
+			assigned = append(assigned, item)
+			continue
+		}
+
+		for _, candidate := range candidates {
+			if need == 0 {
+				break
+			}
+			if candidate.Quantity == 0 {
+				continue
+			}
+
+			take := min(need, candidate.Quantity)
+			candidate.Quantity -= take
+			need -= take
+
+			split := item
+			split.Quantity = int64(take)
+			split.BinID = candidate.BinID
+			split.Location = layoutBins[candidate.BinID].Label
+			assigned = append(assigned, split)
+		}
+		if need > 0 {
+			item.Quantity = int64(need)
+			assigned = append(assigned, item)
+		}
+	}
+
+	visited := map[uint64]bool{}
+	for _, item := range assigned {
+		if item.BinID > 0 {
+			visited[item.BinID] = true
+		}
+	}
+	walk := bin_entity.RouteOrder(layout, visited)
+
+	sort.SliceStable(assigned, func(i, j int) bool {
+		ri, rj := walk[assigned[i].BinID], walk[assigned[j].BinID]
+		if (ri == 0) != (rj == 0) {
+			return ri != 0
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		if assigned[i].ProductID != assigned[j].ProductID {
+			return assigned[i].ProductID < assigned[j].ProductID
+		}
+		return assigned[i].OrderID < assigned[j].OrderID
+	})
+	for i := range assigned {
+		assigned[i].Sequence = i + 1
+	}
+
+	return assigned, nil
+}
+
+// shortPick takes what could not be found off the order and returns the stock it held. A missing
+// component removes whole bundles, so the other components of those bundles are returned as well
+func (a *PickingApp) shortPick(tx *gorm.DB, pickList *picking_entity.PickList, item *picking_entity.PickItem, short int64) error {
+	repoOrderedItem := ordereditems.NewOrderedItemsRepository(a.p, a.c)
+	orderedItems, err := repoOrderedItem.GetAllOrderedItemsForOrder(item.OrderID)
+	if err != nil {
+		return err
+	}
+
+	var orderedQuantity int64
+	for _, orderedItem := range orderedItems {
+		if orderedItem.ID == item.OrderedItemID {
+			orderedQuantity = orderedItem.Quantity
+		}
+	}
+
+	inventoryRepo := inventories.NewInventoryRepository(a.p, a.c)
+	reason := fmt.Sprintf("Short pick on order %v - Return reserved inventory", item.OrderID)
+
+	if item.BundleID == 0 {
+		item.RemovedQuantity += short
+		if err := repoOrderedItem.UpdateOrderedItemQuantity(tx, item.OrderedItemID, orderedQuantity-short); err != nil {
+			return err
+		}
+		if err := inventoryRepo.ReturnInventory(tx, item.ProductID, short, 0, pickList.WarehouseID, reason); err != nil {
+			return err
+		}
+		return orders.NewOrderRepository(a.p, a.c).RecalculateOrderTotals(tx, item.OrderID)
+	}
+
+	bundlesRemoved := (short + item.UnitsPerBundle - 1) / item.UnitsPerBundle
+	if err := repoOrderedItem.UpdateOrderedItemQuantity(tx, item.OrderedItemID, orderedQuantity-bundlesRemoved); err != nil {
+		return err
+	}
+
+	repoPicking := picking.NewPickingRepository(a.p, a.c)
+	for i := range pickList.Items {
+		component := &pickList.Items[i]
+		if component.OrderedItemID != item.OrderedItemID {
+			continue
+		}
+
+		fromStatus := component.Status
+		removed := component.UnitsPerBundle * bundlesRemoved
+		component.RemovedQuantity += removed
+		if component.PickedQuantity > component.ToPick() {
+			component.PickedQuantity = component.ToPick()
+		}
+		if component.Status == picking_entity.PickItemStatusPending && component.ToPick() == 0 {
+			component.Status = picking_entity.PickItemStatusShort
+		}
+
+		if err := inventoryRepo.ReturnInventory(tx, component.ProductID, removed, component.BundleID, pickList.WarehouseID, reason); err != nil {
+			return err
+		}
+		if component.ID != item.ID {
+			if err := repoPicking.UpdatePickItem(tx, component, fromStatus); err != nil {
+				return err
+			}
+		}
+	}
+
+	return orders.NewOrderRepository(a.p, a.c).RecalculateOrderTotals(tx, item.OrderID)
+}
+
+// takeFromBin takes the picked units out of the bin. The stock was already taken off the inventory
+// when the order was placed, so only the bin changes. A bin holding fewer units than were picked
+// had a wrong count and is emptied
+func (a *PickingApp) takeFromBin(tx *gorm.DB, pickList *picking_entity.PickList, item *picking_entity.PickItem, picked int64) error {
+	repoBin := bins.NewBinRepository(a.p, a.c)
+	stocks, err := repoBin.GetBinStocks(int64(item.BinID))
+	if err != nil {
+		return err
+	}
+
+	held := 0
+	for _, stock := range stocks {
+		if int64(stock.ProductID) == item.ProductID {
+			held = stock.Quantity
+		}
+	}
+
+	take := min(int(picked), held)
+	if take < int(picked) {
+		a.p.Logger.Warn("application/takeFromBin", map[string]interface{}{"bin_id": item.BinID, "product_id": item.ProductID, "held": held, "picked": picked})
+	}
+	if take == 0 {
+		return nil
+	}
+
+	if err := repoBin.TakeBinStock(tx, item.BinID, uint64(item.ProductID), take); err != nil {
+		return err
+	}
+
+	logInventory := &inventory_entity.InventoryLog{
+		ProductID:   uint64(item.ProductID),
+		WarehouseID: pickList.WarehouseID,
+		Reason:      fmt.Sprintf("Picked for order %v", item.OrderID),
+		BundleID:    item.BundleID,
+		Quantity:    take,
+		FromBinID:   item.BinID,
+	}
+	return inventories.NewInventoryRepository(a.p, a.c).LogInventory(tx, logInventory)
+}
+
+// advanceOrder moves an order to picked once none of its items are left to confirm,
+// an order that lost every unit to short picks is cancelled and its delivery slot freed,
+// ConfirmPick then refunds it
+func (a *PickingApp) advanceOrder(tx *gorm.DB, pickList *picking_entity.PickList, orderId int64) error {
+	done, remaining := pickList.OrderPicked(orderId)
+	if !done {
+		return nil
+	}
+
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	if remaining > 0 {
+		return repoOrder.UpdateOrderStatus(tx, orderId, order_entity.OrderStatusPicking, order_entity.OrderStatusPicked)
+	}
+
+	// Read before the status changes, the status update clears the cached order
+	order, _ := repoOrder.GetOrder(orderId)
+	if err := repoOrder.UpdateOrderStatus(tx, orderId, order_entity.OrderStatusPicking, order_entity.OrderStatusCancelled); err != nil {
+		return err
+	}
+
+	if order != nil && order.DeliverySlotID > 0 {
+		return schedules.NewScheduleRepository(a.p, a.c).ReleaseDeliverySlot(tx, int64(order.DeliverySlotID))
+	}
+
+	return nil
+}
+
+// completePickList closes a picked list once each of its orders is packed or cancelled
+func (a *PickingApp) completePickList(pickListId int64) {
+	pickList, err := picking.NewPickingRepository(a.p, a.c).GetPickList(pickListId)
+	if err != nil || pickList.Status != picking_entity.PickListStatusPicked {
+		return
+	}
+
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	checked := map[int64]bool{}
+	for _, item := range pickList.Items {
+		if checked[item.OrderID] {
+			continue
+		}
+		checked[item.OrderID] = true
+
+		order, _ := repoOrder.GetOrder(item.OrderID)
+		if order == nil || (order.Status != order_entity.OrderStatusPacked && order.Status != order_entity.OrderStatusCancelled) {
+			return
+		}
+	}
+
+	err = picking.NewPickingRepository(a.p, a.c).UpdatePickListStatus(nil, pickListId, picking_entity.PickListStatusPicked, picking_entity.PickListStatusCompleted)
+	if err != nil {
+		a.p.Logger.Error("application/completePickList", map[string]interface{}{"pick_list_id": pickListId, "error": err.Error()})
+	}
+}
@@ -0,0 +1,147 @@
+package application
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/privacy_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/privacy_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/auth"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/privacy"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/search"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/storage"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// customerSearchCollections maps every search collection that can hold customer documents to the field holding the customer id
+var customerSearchCollections = map[string]string{
+	"customers": "id",
+	"orders":    "customer_id",
+}
+
+type PrivacyApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewPrivacyApplication(p *base.Persistence, c *gin.Context) privacy_repository.PrivacyHandlerRepository {
+	return &PrivacyApp{p, c}
+}
+
+// ExportCustomerData zips everything stored about the customer, one JSON file per kind of record
+func (a *PrivacyApp) ExportCustomerData(customerId int64) ([]byte, error) {
+	export, err := privacy.NewPrivacyRepository(a.p, a.c).GetCustomerData(customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"customer.json", export.Customer},
+		{"addresses.json", export.Addresses},
+		{"orders.json", export.Orders},
+		{"tokens.json", map[string]interface{}{"refresh_tokens": export.RefreshTokens, "action_tokens": export.ActionTokens}},
+		{"audit_events.json", export.AuditEvents},
+		{"wallet.json", map[string]interface{}{"wallet": export.Wallet, "transactions": export.WalletTransactions}},
+		{"loyalty.json", map[string]interface{}{"account": export.LoyaltyAccount, "entries": export.PointsEntries}},
+		{"returns.json", export.Returns},
+		{"payments.json", export.Payments},
+		{"shipments.json", export.Shipments},
+		{"export.json", map[string]interface{}{"customer_id": customerId, "exported_at": export.ExportedAt}},
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	a.p.Logger.Info("application/ExportCustomerData", map[string]interface{}{"customer_id": customerId})
+	return buffer.Bytes(), nil
+}
+
+// EraseCustomer anonymises the customer in the database, then removes what is left of them in
+// the cache and the search index and ends their sessions
+func (a *PrivacyApp) EraseCustomer(customerId int64) (*privacy_entity.ErasureReport, error) {
+	report, err := privacy.NewPrivacyRepository(a.p, a.c).EraseCustomer(customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", a.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_CUSTOMER", customerId))
+	for _, addressId := range report.AddressIDs {
+		cacheRepo.DelKey(fmt.Sprintf("%v_ADDRESS", addressId))
+	}
+	for _, orderId := range report.OrderIDs {
+		cacheRepo.DelKey(fmt.Sprintf("%v_ORDER", orderId))
+	}
+
+	authRepo := auth.NewAuthRepository(a.p, a.c)
+	_ = authRepo.ClearLoginAttempts(auth.UsernameAttemptsKey(report.Username))
+	if err := authRepo.RevokeCustomerAccessTokens(customerId); err != nil {
+		log.Println(err)
+	}
+
+	// Shipments without a proof file fail to delete it, which is fine
+	storageRepo := storage.NewStorageRepository("Supabase", a.p)
+	for _, shipmentId := range report.ShipmentIDs {
+		for _, file := range []string{shipment_entity.SignatureFile(shipmentId), shipment_entity.PhotoFile(shipmentId)} {
+			if err := storageRepo.DeleteFile(shipment_entity.ProofBucket, file); err == nil {
+				report.ProofFilesDeleted++
+			}
+		}
+	}
+
+	searchRepo := search.NewSearchRepository(os.Getenv("SEARCH_PROVIDER"), a.p, a.c)
+	report.SearchDocsPurged = []string{}
+	for collection, field := range customerSearchCollections {
+		if err := searchRepo.DeleteMultipleDoc(field, collection, customerId); err != nil {
+			log.Println(err)
+			continue
+		}
+		report.SearchDocsPurged = append(report.SearchDocsPurged, collection)
+	}
+
+	actor := ""
+	if a.c != nil {
+		actor = a.c.GetString("userID")
+	}
+	event := auth_entity.AuditEvent{
+		Event:      privacy_entity.AuditCustomerErased,
+		CustomerID: customerId,
+		Actor:      actor,
+		Details:    fmt.Sprintf("%v addresses deleted, %v orders, %v returns and %v shipments anonymised", len(report.AddressIDs), len(report.OrderIDs), len(report.ReturnIDs), len(report.ShipmentIDs)),
+	}
+	a.p.Logger.Warn("audit/"+event.Event, map[string]interface{}{"customer_id": customerId, "actor": actor})
+	if err := authRepo.SaveAuditEvent(&event); err != nil {
+		log.Println(err)
+	}
+
+	return report, nil
+}
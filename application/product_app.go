@@ -11,6 +11,7 @@ import (
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/product_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/bundles"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/inventories"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/products"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/search"
@@ -35,6 +36,7 @@ func ConvertProductandInventory(productForInventory product_entity.ProductForInv
 	product.Description = productForInventory.Description
 	product.Price = productForInventory.Price
 	product.CategoryID = productForInventory.CategoryID
+	product.Type = productForInventory.Type
 
 	inventory.ProductID = product.ID
 	inventory.WarehouseID = productForInventory.WarehouseID
@@ -46,12 +48,19 @@ func ConvertProductandInventory(productForInventory product_entity.ProductForInv
 
 func (a *productApp) SaveProductAndInventory(productForInventory product_entity.ProductForInventory) (*product_entity.Product, *inventory_entity.Inventory, map[string]string) {
     product, inventory := ConvertProductandInventory(productForInventory)
+    if typeErr := product.ValidateType(); typeErr != nil {
+        return nil, nil, map[string]string{"type": typeErr.Error()}
+    }
     repoProduct := products.NewProductRepository(a.p, a.c)
     repoInventory := inventories.NewInventoryRepository(a.p, a.c)
+    if product.IsBundle() {
+        return a.saveBundle(&product, productForInventory.BundleItems)
+    }
     savedProduct, saveErr := repoProduct.SaveProduct(&product)
     if saveErr != nil {
         return nil, nil, saveErr
     }
+
     inventory.ProductID = savedProduct.ID // Set ProductID to the ID of the newly created product
     _, saveInventoryErr := repoInventory.SaveInventory(&inventory)
     if saveInventoryErr != nil {
@@ -61,6 +70,47 @@ func (a *productApp) SaveProductAndInventory(productForInventory product_entity.
 }
 
 
+// Bundles do not hold stock of their own, so the components are saved instead of an inventory
+// and the returned inventory reflects how many bundles the component stock can fulfil.
+// The components are created together with the bundle, so a failure leaves neither behind
+func (a *productApp) saveBundle(bundle *product_entity.Product, items []product_entity.BundleItem) (*product_entity.Product, *inventory_entity.Inventory, map[string]string) {
+	repoProduct := products.NewProductRepository(a.p, a.c)
+	repoBundle := bundles.NewBundleRepository(a.p, a.c)
+	dbErr := map[string]string{}
+
+	validateErr := repoBundle.ValidateBundleItems(nil, bundle.ID, items)
+	if validateErr != nil {
+		dbErr["bundle_error"] = validateErr.Error()
+		return nil, nil, dbErr
+	}
+
+	bundle.BundleItems = make([]product_entity.BundleItem, 0, len(items))
+	for _, item := range items {
+		bundle.BundleItems = append(bundle.BundleItems, product_entity.BundleItem{
+			ComponentID: item.ComponentID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	savedBundle, saveErr := repoProduct.SaveProduct(bundle)
+	if saveErr != nil {
+		return nil, nil, saveErr
+	}
+
+	availability, availabilityErr := repoBundle.GetBundleAvailability(int64(savedBundle.ID))
+	if availabilityErr != nil {
+		dbErr["bundle_error"] = availabilityErr.Error()
+		return nil, nil, dbErr
+	}
+
+	inventory := inventory_entity.Inventory{
+		ProductID: savedBundle.ID,
+		Stock:     int(availability.Available),
+	}
+
+	return savedBundle, &inventory, nil
+}
+
 func (a *productApp) GetProduct(productId int64) (*product_entity.Product, error) {
 	repoProduct := products.NewProductRepository(a.p, a.c)
 	return repoProduct.GetProduct(productId)
@@ -74,6 +124,9 @@ func (a *productApp) GetAllProducts() ([]product_entity.Product, error) {
 }
 	
 func (a *productApp) UpdateProduct(product *product_entity.Product) (*product_entity.Product, error) {
+	if typeErr := product.ValidateType(); typeErr != nil {
+		return nil, typeErr
+	}
 	repoProduct := products.NewProductRepository(a.p, a.c)
 	return repoProduct.UpdateProduct(product)
 }
@@ -0,0 +1,330 @@
+package application
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/return_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/wallet_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/return_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/inventories"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/orders"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/returns"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/shipments"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+type ReturnApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewReturnApplication(p *base.Persistence, c *gin.Context) return_repository.ReturnHandlerRepository {
+	return &ReturnApp{p, c}
+}
+
+// RequestReturn opens a return for items of a delivered order. An item can only be returned up to
+// the quantity ordered, less what other returns of the order hold or took back
+func (a *ReturnApp) RequestReturn(orderId int64, input return_entity.ReturnRequestInput) (*return_entity.ReturnRequest, map[string]string) {
+	order, _ := orders.NewOrderRepository(a.p, a.c).GetOrder(orderId)
+	if order == nil || order.ID == 0 {
+		return nil, map[string]string{"order_not_found": fmt.Sprintf("order %v not found", orderId)}
+	}
+
+	if order.Status != order_entity.OrderStatusDelivered {
+		return nil, map[string]string{"status": fmt.Sprintf("order %v is %v, only delivered orders can be returned", orderId, order.Status)}
+	}
+
+	shipment, _ := shipments.NewShipmentRepository(a.p, a.c).GetShipmentForOrder(orderId)
+	if shipment != nil && shipment.DeliveredAt != nil && time.Since(*shipment.DeliveredAt) > return_entity.ReturnWindowDays*24*time.Hour {
+		return nil, map[string]string{"order_id": fmt.Sprintf("order %v was delivered more than %v days ago", orderId, return_entity.ReturnWindowDays)}
+	}
+
+	if validationErr := input.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	returned, alreadyRefunded, err := a.returnedSoFar(orderId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	ret := return_entity.ReturnRequest{
+		OrderID:     orderId,
+		CustomerID:  order.CustomerID,
+		WarehouseID: order.WarehouseID,
+		Status:      return_entity.ReturnStatusRequested,
+		Note:        input.Note,
+	}
+	for i, requested := range input.Items {
+		field := fmt.Sprintf("items[%v]", i)
+		found := false
+		for _, orderedItem := range order.OrderedItems {
+			if orderedItem.ID != requested.OrderedItemID {
+				continue
+			}
+			found = true
+
+			returnable := orderedItem.Quantity - returned[orderedItem.ID]
+			if requested.Quantity > returnable {
+				return nil, map[string]string{field: fmt.Sprintf("only %v of ordered item %v can be returned", max(returnable, 0), orderedItem.ID)}
+			}
+
+			unitPrice := orderedItem.UnitPrice
+			if unitPrice == 0 && orderedItem.Quantity > 0 {
+				unitPrice = orderedItem.TotalPrice / float64(orderedItem.Quantity)
+			}
+			ret.Items = append(ret.Items, return_entity.ReturnItem{
+				OrderedItemID: orderedItem.ID,
+				ProductID:     orderedItem.ProductID,
+				Quantity:      requested.Quantity,
+				UnitPrice:     unitPrice,
+				Reason:        requested.Reason,
+				Comment:       requested.Comment,
+			})
+		}
+		if !found {
+			return nil, map[string]string{field: fmt.Sprintf("ordered item %v is not part of order %v", requested.OrderedItemID, orderId)}
+		}
+	}
+
+	return_entity.ComputeRefund(order, ret.Items, false, alreadyRefunded).Apply(&ret)
+
+	return returns.NewReturnRepository(a.p, a.c).SaveReturn(&ret)
+}
+
+func (a *ReturnApp) GetReturn(returnId int64) (*return_entity.ReturnRequest, error) {
+	repoReturn := returns.NewReturnRepository(a.p, a.c)
+	return repoReturn.GetReturn(returnId)
+}
+
+func (a *ReturnApp) GetReturns(filter return_entity.ReturnFilter) ([]return_entity.ReturnRequest, error) {
+	repoReturn := returns.NewReturnRepository(a.p, a.c)
+	return repoReturn.GetReturns(filter)
+}
+
+// CancelReturn withdraws a return that has not been reviewed yet
+func (a *ReturnApp) CancelReturn(returnId int64) (*return_entity.ReturnRequest, map[string]string) {
+	return a.review(returnId, return_entity.ReturnStatusCancelled, return_entity.ReviewRequest{}, 0)
+}
+
+// ApproveReturn lets the customer send the items back
+func (a *ReturnApp) ApproveReturn(returnId int64, request return_entity.ReviewRequest, actorId int64) (*return_entity.ReturnRequest, map[string]string) {
+	return a.review(returnId, return_entity.ReturnStatusApproved, request, actorId)
+}
+
+// RejectReturn turns down a return, its items can be asked for again
+func (a *ReturnApp) RejectReturn(returnId int64, request return_entity.ReviewRequest, actorId int64) (*return_entity.ReturnRequest, map[string]string) {
+	return a.review(returnId, return_entity.ReturnStatusRejected, request, actorId)
+}
+
+// InspectReturn records what came back of an approved return and completes it. Accepted units are
+// restocked, written off or set aside for the supplier, the refund is settled on them and credited
+// to the customer's wallet, and the loyalty points they earned or were paid with are settled too
+func (a *ReturnApp) InspectReturn(returnId int64, request return_entity.InspectionRequest, actorId int64) (*return_entity.ReturnRequest, map[string]string) {
+	repoReturn := returns.NewReturnRepository(a.p, a.c)
+	ret, _ := repoReturn.GetReturn(returnId)
+	if ret == nil {
+		return nil, map[string]string{"return_not_found": fmt.Sprintf("return %v not found", returnId)}
+	}
+
+	if ret.Status != return_entity.ReturnStatusApproved {
+		return nil, map[string]string{"status": fmt.Sprintf("return %v is %v, only approved returns can be inspected", returnId, ret.Status)}
+	}
+
+	if validationErr := request.Validate(ret.Items); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	order, _ := orders.NewOrderRepository(a.p, a.c).GetOrder(ret.OrderID)
+	if order == nil || order.ID == 0 {
+		return nil, map[string]string{"order_not_found": fmt.Sprintf("order %v not found", ret.OrderID)}
+	}
+
+	_, alreadyRefunded, err := a.returnedSoFar(ret.OrderID)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	inspected := map[uint64]return_entity.InspectionItem{}
+	for _, item := range request.Items {
+		inspected[item.ReturnItemID] = item
+	}
+	for i := range ret.Items {
+		item := &ret.Items[i]
+		item.AcceptedQuantity = inspected[item.ID].AcceptedQuantity
+		item.Disposition = ""
+		if item.AcceptedQuantity > 0 {
+			item.Disposition = inspected[item.ID].Disposition
+		}
+	}
+
+	now := time.Now()
+	return_entity.ComputeRefund(order, ret.Items, true, alreadyRefunded).Apply(ret)
+	ret.Status = return_entity.ReturnStatusCompleted
+	ret.InspectedBy = actorId
+	ret.InspectedAt = &now
+	if request.Note != "" {
+		ret.ReviewNote = request.Note
+	}
+
+	txErr := a.p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repoReturn.UpdateReturn(tx, ret, return_entity.ReturnStatusApproved); err != nil {
+			return err
+		}
+
+		for i := range ret.Items {
+			item := &ret.Items[i]
+			if err := repoReturn.UpdateReturnItem(tx, item); err != nil {
+				return err
+			}
+			if item.AcceptedQuantity > 0 {
+				if err := a.dispose(tx, ret, item); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := (&LoyaltyApp{a.p, a.c}).reverseForReturn(tx, order, ret); err != nil {
+			return err
+		}
+
+		// Refunds are paid as store credit
+		if ret.RefundAmount <= 0 {
+			return nil
+		}
+		_, err := (&WalletApp{a.p, a.c}).post(tx, ret.CustomerID, wallet_entity.TransactionRefund, ret.RefundAmount, wallet_entity.WalletTransaction{
+			OrderID:     ret.OrderID,
+			ReturnID:    ret.ID,
+			Description: fmt.Sprintf("Return %v", ret.ID),
+			CreatedBy:   actorId,
+		})
+		return err
+	})
+	if txErr != nil {
+		return nil, map[string]string{"status": txErr.Error()}
+	}
+
+	return a.reload(returnId)
+}
+
+// review moves a requested return to approved, rejected or cancelled
+func (a *ReturnApp) review(returnId int64, toStatus string, request return_entity.ReviewRequest, actorId int64) (*return_entity.ReturnRequest, map[string]string) {
+	repoReturn := returns.NewReturnRepository(a.p, a.c)
+	ret, _ := repoReturn.GetReturn(returnId)
+	if ret == nil {
+		return nil, map[string]string{"return_not_found": fmt.Sprintf("return %v not found", returnId)}
+	}
+
+	if ret.Status != return_entity.ReturnStatusRequested {
+		return nil, map[string]string{"status": fmt.Sprintf("return %v is %v, only requested returns can be %v", returnId, ret.Status, toStatus)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	ret.Status = toStatus
+	if actorId > 0 {
+		now := time.Now()
+		ret.ReviewedBy = actorId
+		ret.ReviewedAt = &now
+		ret.ReviewNote = request.Note
+	}
+
+	if err := repoReturn.UpdateReturn(nil, ret, return_entity.ReturnStatusRequested); err != nil {
+		return nil, map[string]string{"status": err.Error()}
+	}
+
+	return a.reload(returnId)
+}
+
+// returnedSoFar returns how many units of each ordered item other returns of the order hold or took
+// back, and how much was refunded already
+func (a *ReturnApp) returnedSoFar(orderId int64) (map[uint64]int64, float64, error) {
+	rets, err := returns.NewReturnRepository(a.p, a.c).GetReturns(return_entity.ReturnFilter{OrderID: orderId})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	returned := map[uint64]int64{}
+	refunded := 0.0
+	for _, ret := range rets {
+		switch {
+		case ret.Open():
+			for _, item := range ret.Items {
+				returned[item.OrderedItemID] += item.Quantity
+			}
+		case ret.Status == return_entity.ReturnStatusCompleted:
+			for _, item := range ret.Items {
+				returned[item.OrderedItemID] += item.AcceptedQuantity
+			}
+			refunded += ret.RefundAmount
+		}
+	}
+
+	return returned, refunded, nil
+}
+
+// dispose applies the inspection outcome of an item to the inventory, a bundle as its components.
+// Restocked units are sellable again, written off units and units for the supplier were taken out
+// of stock when the order was placed and are only logged
+func (a *ReturnApp) dispose(tx *gorm.DB, ret *return_entity.ReturnRequest, item *return_entity.ReturnItem) error {
+	inventoryRepo := inventories.NewInventoryRepository(a.p, a.c)
+	bundleItems, err := orderedBundleItems(a.p, a.c, item.OrderedItemID, item.ProductID)
+	if err != nil {
+		return err
+	}
+
+	type unit struct {
+		productId int64
+		quantity  int64
+		bundleId  uint64
+	}
+	units := []unit{{item.ProductID, item.AcceptedQuantity, 0}}
+	if len(bundleItems) > 0 {
+		units = units[:0]
+		for _, bundleItem := range bundleItems {
+			units = append(units, unit{int64(bundleItem.ComponentID), bundleItem.Quantity * item.AcceptedQuantity, uint64(item.ProductID)})
+		}
+	}
+
+	for _, u := range units {
+		if item.Disposition == return_entity.DispositionRestock {
+			if err := inventoryRepo.ReturnInventory(tx, u.productId, u.quantity, u.bundleId, ret.WarehouseID, fmt.Sprintf("Restocked from return %v", ret.ID)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		reason := fmt.Sprintf("Written off from return %v", ret.ID)
+		if item.Disposition == return_entity.DispositionReturnToSupplier {
+			reason = fmt.Sprintf("Returned to supplier from return %v", ret.ID)
+		}
+		logInventory := inventory_entity.InventoryLog{
+			ProductID:   uint64(u.productId),
+			WarehouseID: ret.WarehouseID,
+			Quantity:    int(u.quantity),
+			Reason:      reason,
+			BundleID:    u.bundleId,
+		}
+		if err := inventoryRepo.LogInventory(tx, &logInventory); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *ReturnApp) reload(returnId int64) (*return_entity.ReturnRequest, map[string]string) {
+	ret, err := returns.NewReturnRepository(a.p, a.c).GetReturn(returnId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return ret, nil
+}
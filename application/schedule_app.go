@@ -0,0 +1,280 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/schedule_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/schedules"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/warehouses"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type ScheduleApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewScheduleApplication(p *base.Persistence, c *gin.Context) schedule_repository.ScheduleHandlerRepository {
+	return &ScheduleApp{p, c}
+}
+
+func (a *ScheduleApp) GetOperatingHours(warehouseId int64) ([]warehouse_entity.OperatingHours, error) {
+	repoSchedule := schedules.NewScheduleRepository(a.p, a.c)
+	return repoSchedule.GetOperatingHours(warehouseId)
+}
+
+// UpdateOperatingHours replaces the weekly hours of a warehouse, days left out are closed
+func (a *ScheduleApp) UpdateOperatingHours(warehouseId int64, request warehouse_entity.OperatingHoursRequest) ([]warehouse_entity.OperatingHours, map[string]string) {
+	warehouse, errs := a.getWarehouse(warehouseId)
+	if errs != nil {
+		return nil, errs
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	savedHours, err := schedules.NewScheduleRepository(a.p, a.c).SaveOperatingHours(warehouse.ID, request.Hours)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return savedHours, nil
+}
+
+func (a *ScheduleApp) GetHolidays(warehouseId int64) ([]warehouse_entity.Holiday, error) {
+	repoSchedule := schedules.NewScheduleRepository(a.p, a.c)
+	return repoSchedule.GetHolidays(warehouseId)
+}
+
+// SaveHoliday closes a warehouse on a date and removes that day's delivery slots, a date with
+// booked slots is refused until those orders are moved or cancelled
+func (a *ScheduleApp) SaveHoliday(warehouseId int64, request warehouse_entity.HolidayRequest) (*warehouse_entity.Holiday, map[string]string) {
+	warehouse, errs := a.getWarehouse(warehouseId)
+	if errs != nil {
+		return nil, errs
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	repoSchedule := schedules.NewScheduleRepository(a.p, a.c)
+	holidays, err := repoSchedule.GetHolidays(warehouseId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+	for _, holiday := range holidays {
+		if holiday.Date == request.Date {
+			return nil, map[string]string{"date": fmt.Sprintf("warehouse %v is already closed on %v", warehouseId, request.Date)}
+		}
+	}
+
+	location := warehouse.Location()
+	day, _ := time.ParseInLocation(warehouse_entity.DateLayout, request.Date, location)
+	slots, err := repoSchedule.GetDeliverySlots(warehouseId, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+	for _, slot := range slots {
+		if slot.Reserved > 0 {
+			return nil, map[string]string{"date": fmt.Sprintf("delivery slot %v on %v has reservations", slot.ID, request.Date)}
+		}
+	}
+	for _, slot := range slots {
+		if deleteErr := repoSchedule.DeleteDeliverySlot(int64(slot.ID)); deleteErr != nil {
+			return nil, map[string]string{"date": deleteErr.Error()}
+		}
+	}
+
+	holiday := warehouse_entity.Holiday{
+		WarehouseID: warehouse.ID,
+		Date:        request.Date,
+		Reason:      request.Reason,
+	}
+
+	return repoSchedule.SaveHoliday(&holiday)
+}
+
+func (a *ScheduleApp) DeleteHoliday(warehouseId int64, holidayId int64) error {
+	repoSchedule := schedules.NewScheduleRepository(a.p, a.c)
+	holiday, _ := repoSchedule.GetHoliday(holidayId)
+	if holiday == nil || int64(holiday.WarehouseID) != warehouseId {
+		return fmt.Errorf("holiday %v not found", holidayId)
+	}
+
+	return repoSchedule.DeleteHoliday(holidayId)
+}
+
+// SaveDeliverySlot adds a single slot, which must fall inside the opening hours of a day the warehouse is open
+func (a *ScheduleApp) SaveDeliverySlot(warehouseId int64, request warehouse_entity.DeliverySlotRequest) (*warehouse_entity.DeliverySlot, map[string]string) {
+	warehouse, errs := a.getWarehouse(warehouseId)
+	if errs != nil {
+		return nil, errs
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	repoSchedule := schedules.NewScheduleRepository(a.p, a.c)
+	hours, holidays, err := a.getSchedule(warehouseId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+	if !warehouse_entity.OpenDuring(hours, holidays, warehouse.Location(), request.StartsAt, request.EndsAt) {
+		return nil, map[string]string{"starts_at": "the slot must fall inside the opening hours of a day the warehouse is open"}
+	}
+
+	savedSlots, err := repoSchedule.SaveDeliverySlots([]warehouse_entity.DeliverySlot{{
+		WarehouseID: warehouse.ID,
+		StartsAt:    request.StartsAt,
+		EndsAt:      request.EndsAt,
+		Capacity:    request.Capacity,
+	}})
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+	if len(savedSlots) == 0 {
+		return nil, map[string]string{"starts_at": "a delivery slot already starts at this time"}
+	}
+
+	return &savedSlots[0], nil
+}
+
+// GenerateDeliverySlots fills the opening hours of each day in the range with back to back slots,
+// skipping holidays, times already past and slots that already exist
+func (a *ScheduleApp) GenerateDeliverySlots(warehouseId int64, request warehouse_entity.GenerateSlotsRequest) ([]warehouse_entity.DeliverySlot, map[string]string) {
+	warehouse, errs := a.getWarehouse(warehouseId)
+	if errs != nil {
+		return nil, errs
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	hours, holidays, err := a.getSchedule(warehouseId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	location := warehouse.Location()
+	from, _ := time.ParseInLocation(warehouse_entity.DateLayout, request.From, location)
+	slotLength := time.Duration(request.SlotMinutes) * time.Minute
+	now := time.Now()
+
+	var slots []warehouse_entity.DeliverySlot
+	for day := 0; day < request.Days; day++ {
+		date := from.AddDate(0, 0, day)
+		for _, openDay := range hours {
+			if openDay.Weekday != int(date.Weekday()) {
+				continue
+			}
+			opens, closes, ok := openDay.Window(date, location)
+			if !ok {
+				continue
+			}
+			for start := opens; !start.Add(slotLength).After(closes); start = start.Add(slotLength) {
+				if start.After(now) && warehouse_entity.OpenDuring(hours, holidays, location, start, start.Add(slotLength)) {
+					slots = append(slots, warehouse_entity.DeliverySlot{
+						WarehouseID: warehouse.ID,
+						StartsAt:    start,
+						EndsAt:      start.Add(slotLength),
+						Capacity:    request.Capacity,
+					})
+				}
+			}
+		}
+	}
+
+	savedSlots, err := schedules.NewScheduleRepository(a.p, a.c).SaveDeliverySlots(slots)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return savedSlots, nil
+}
+
+// GetDeliverySlots returns the slots of a number of days from a date in the warehouse's timezone,
+// from today when no date is given. With onlyAvailable the slots already started or full are left out
+func (a *ScheduleApp) GetDeliverySlots(warehouseId int64, from string, days int, onlyAvailable bool) ([]warehouse_entity.DeliverySlot, error) {
+	warehouse, _ := warehouses.NewWareHouseRepository(a.p, a.c).GetWarehouse(warehouseId)
+	if warehouse == nil || warehouse.ID == 0 {
+		return nil, fmt.Errorf("warehouse %v not found", warehouseId)
+	}
+
+	location := warehouse.Location()
+	start := time.Now().In(location)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+	if from != "" {
+		var err error
+		start, err = time.ParseInLocation(warehouse_entity.DateLayout, from, location)
+		if err != nil {
+			return nil, errors.New("from must be YYYY-MM-DD")
+		}
+	}
+	if days <= 0 {
+		days = warehouse_entity.DefaultSlotDays
+	}
+	if days > warehouse_entity.MaxSlotDays {
+		days = warehouse_entity.MaxSlotDays
+	}
+
+	slots, err := schedules.NewScheduleRepository(a.p, a.c).GetDeliverySlots(warehouseId, start, start.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+
+	if !onlyAvailable {
+		return slots, nil
+	}
+
+	now := time.Now()
+	available := []warehouse_entity.DeliverySlot{}
+	for _, slot := range slots {
+		if slot.Available > 0 && slot.StartsAt.After(now) {
+			available = append(available, slot)
+		}
+	}
+
+	return available, nil
+}
+
+func (a *ScheduleApp) DeleteDeliverySlot(warehouseId int64, slotId int64) error {
+	repoSchedule := schedules.NewScheduleRepository(a.p, a.c)
+	slot, _ := repoSchedule.GetDeliverySlot(slotId)
+	if slot == nil || int64(slot.WarehouseID) != warehouseId {
+		return fmt.Errorf("delivery slot %v not found", slotId)
+	}
+
+	return repoSchedule.DeleteDeliverySlot(slotId)
+}
+
+func (a *ScheduleApp) getWarehouse(warehouseId int64) (*warehouse_entity.Warehouse, map[string]string) {
+	warehouse, _ := warehouses.NewWareHouseRepository(a.p, a.c).GetWarehouse(warehouseId)
+	if warehouse == nil || warehouse.ID == 0 {
+		return nil, map[string]string{"warehouse_not_found": fmt.Sprintf("warehouse %v not found", warehouseId)}
+	}
+
+	return warehouse, nil
+}
+
+func (a *ScheduleApp) getSchedule(warehouseId int64) ([]warehouse_entity.OperatingHours, []warehouse_entity.Holiday, error) {
+	repoSchedule := schedules.NewScheduleRepository(a.p, a.c)
+	hours, err := repoSchedule.GetOperatingHours(warehouseId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	holidays, err := repoSchedule.GetHolidays(warehouseId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hours, holidays, nil
+}
@@ -0,0 +1,158 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/service_area_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/serviceareas"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/warehouses"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type ServiceAreaApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewServiceAreaApplication(p *base.Persistence, c *gin.Context) service_area_repository.ServiceAreaHandlerRepository {
+	return &ServiceAreaApp{p, c}
+}
+
+func (a *ServiceAreaApp) SaveServiceArea(warehouseId int64, request warehouse_entity.ServiceAreaRequest) (*warehouse_entity.ServiceArea, map[string]string) {
+	warehouse, _ := warehouses.NewWareHouseRepository(a.p, a.c).GetWarehouse(warehouseId)
+	if warehouse == nil || warehouse.ID == 0 {
+		return nil, map[string]string{"warehouse_not_found": fmt.Sprintf("warehouse %v not found", warehouseId)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	area := warehouse_entity.ServiceArea{WarehouseID: warehouse.ID, Active: true}
+	request.Apply(&area)
+
+	savedArea, saveErr := serviceareas.NewServiceAreaRepository(a.p, a.c).SaveServiceArea(&area)
+	if saveErr != nil {
+		return nil, saveErr
+	}
+
+	a.warnOverlaps(savedArea)
+
+	return savedArea, nil
+}
+
+func (a *ServiceAreaApp) GetServiceAreas(warehouseId int64) ([]warehouse_entity.ServiceArea, error) {
+	repoServiceArea := serviceareas.NewServiceAreaRepository(a.p, a.c)
+	return repoServiceArea.GetServiceAreas(warehouseId)
+}
+
+func (a *ServiceAreaApp) UpdateServiceArea(warehouseId int64, areaId int64, request warehouse_entity.ServiceAreaRequest) (*warehouse_entity.ServiceArea, map[string]string) {
+	repoServiceArea := serviceareas.NewServiceAreaRepository(a.p, a.c)
+	area, _ := repoServiceArea.GetServiceArea(areaId)
+	if area == nil || int64(area.WarehouseID) != warehouseId {
+		return nil, map[string]string{"service_area_not_found": fmt.Sprintf("service area %v not found", areaId)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	request.Apply(area)
+
+	updatedArea, err := repoServiceArea.UpdateServiceArea(area)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	a.warnOverlaps(updatedArea)
+
+	return updatedArea, nil
+}
+
+func (a *ServiceAreaApp) DeleteServiceArea(warehouseId int64, areaId int64) error {
+	repoServiceArea := serviceareas.NewServiceAreaRepository(a.p, a.c)
+	area, _ := repoServiceArea.GetServiceArea(areaId)
+	if area == nil || int64(area.WarehouseID) != warehouseId {
+		return fmt.Errorf("service area %v not found", areaId)
+	}
+
+	return repoServiceArea.DeleteServiceArea(areaId)
+}
+
+// GetServiceAreaOverlaps returns every pair of active service areas of different warehouses that overlap
+func (a *ServiceAreaApp) GetServiceAreaOverlaps() ([]warehouse_entity.ServiceAreaOverlap, error) {
+	areas, err := serviceareas.NewServiceAreaRepository(a.p, a.c).GetActiveServiceAreas()
+	if err != nil {
+		return nil, err
+	}
+
+	overlaps := []warehouse_entity.ServiceAreaOverlap{}
+	for i := range areas {
+		for j := i + 1; j < len(areas); j++ {
+			if areas[i].WarehouseID == areas[j].WarehouseID || !areas[i].Area.Overlaps(areas[j].Area) {
+				continue
+			}
+			overlaps = append(overlaps, warehouse_entity.ServiceAreaOverlap{
+				WarehouseID:        areas[i].WarehouseID,
+				ServiceAreaID:      areas[i].ID,
+				OtherWarehouseID:   areas[j].WarehouseID,
+				OtherServiceAreaID: areas[j].ID,
+			})
+		}
+	}
+
+	return overlaps, nil
+}
+
+// CheckServiceability lists the warehouses delivering to a coordinate, nearest first
+func (a *ServiceAreaApp) CheckServiceability(latitude float64, longitude float64) (*warehouse_entity.Serviceability, error) {
+	nearest, err := warehouses.NewWareHouseRepository(a.p, a.c).GetNearestWarehouses(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceability := warehouse_entity.Serviceability{
+		Latitude:   latitude,
+		Longitude:  longitude,
+		Warehouses: []warehouse_entity.ServingWarehouse{},
+	}
+	for _, warehouse := range nearest {
+		if !warehouse.Serves {
+			continue
+		}
+		serviceability.Warehouses = append(serviceability.Warehouses, warehouse_entity.ServingWarehouse{
+			ID:         warehouse.ID,
+			Name:       warehouse.Name,
+			DistanceKm: warehouse.DistanceKm,
+		})
+	}
+	serviceability.Serviceable = len(serviceability.Warehouses) > 0
+
+	return &serviceability, nil
+}
+
+// warnOverlaps logs the other warehouses an active service area overlaps, overlaps are allowed
+// but usually mean two warehouses compete for the same deliveries
+func (a *ServiceAreaApp) warnOverlaps(area *warehouse_entity.ServiceArea) {
+	if !area.Active {
+		return
+	}
+
+	areas, err := serviceareas.NewServiceAreaRepository(a.p, a.c).GetActiveServiceAreas()
+	if err != nil {
+		return
+	}
+
+	for _, other := range areas {
+		if other.WarehouseID != area.WarehouseID && area.Area.Overlaps(other.Area) {
+			a.p.Logger.Warn("application/ServiceAreaOverlap", map[string]interface{}{
+				"warehouse_id":          area.WarehouseID,
+				"service_area_id":       area.ID,
+				"other_warehouse_id":    other.WarehouseID,
+				"other_service_area_id": other.ID,
+			})
+		}
+	}
+}
@@ -0,0 +1,546 @@
+package application
+
+import (
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/shipment_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/customers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/inventories"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/orders"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/shipments"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/storage"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/warehouses"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+type ShipmentApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewShipmentApplication(p *base.Persistence, c *gin.Context) shipment_repository.ShipmentHandlerRepository {
+	return &ShipmentApp{p, c}
+}
+
+func (a *ShipmentApp) SaveDriver(request shipment_entity.DriverRequest) (*shipment_entity.Driver, map[string]string) {
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	driver := shipment_entity.Driver{Active: true}
+	request.Apply(&driver)
+
+	if driverErr := a.checkDriver(&driver); driverErr != nil {
+		return nil, driverErr
+	}
+
+	return shipments.NewShipmentRepository(a.p, a.c).SaveDriver(&driver)
+}
+
+func (a *ShipmentApp) GetDriver(driverId int64) (*shipment_entity.Driver, error) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	return repoShipment.GetDriver(driverId)
+}
+
+func (a *ShipmentApp) GetDriverByCustomer(customerId int64) (*shipment_entity.Driver, error) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	return repoShipment.GetDriverByCustomer(customerId)
+}
+
+func (a *ShipmentApp) GetDrivers(warehouseId int64) ([]shipment_entity.Driver, error) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	return repoShipment.GetDrivers(warehouseId)
+}
+
+func (a *ShipmentApp) UpdateDriver(driverId int64, request shipment_entity.DriverRequest) (*shipment_entity.Driver, map[string]string) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	driver, _ := repoShipment.GetDriver(driverId)
+	if driver == nil {
+		return nil, map[string]string{"driver_not_found": fmt.Sprintf("driver %v not found", driverId)}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	request.Apply(driver)
+
+	if driverErr := a.checkDriver(driver); driverErr != nil {
+		return nil, driverErr
+	}
+
+	updatedDriver, err := repoShipment.UpdateDriver(driver)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return updatedDriver, nil
+}
+
+// DeleteDriver removes a driver who has no shipments left to deliver
+func (a *ShipmentApp) DeleteDriver(driverId int64) map[string]string {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	driver, _ := repoShipment.GetDriver(driverId)
+	if driver == nil {
+		return map[string]string{"driver_not_found": fmt.Sprintf("driver %v not found", driverId)}
+	}
+
+	driverShipments, err := repoShipment.GetShipments(shipment_entity.ShipmentFilter{DriverID: driverId})
+	if err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+	for _, shipment := range driverShipments {
+		if shipment.Status == shipment_entity.ShipmentStatusAssigned || shipment.Status == shipment_entity.ShipmentStatusOutForDelivery {
+			return map[string]string{"driver": fmt.Sprintf("driver %v still has shipment %v to deliver", driverId, shipment.ID)}
+		}
+	}
+
+	if err := repoShipment.DeleteDriver(driverId); err != nil {
+		return map[string]string{"db_error": err.Error()}
+	}
+
+	return nil
+}
+
+// CreateShipment opens the shipment of a packed order, assigning a driver straight away when one is given
+func (a *ShipmentApp) CreateShipment(orderId int64, request shipment_entity.AssignRequest, actorId int64) (*shipment_entity.Shipment, map[string]string) {
+	order, _ := orders.NewOrderRepository(a.p, a.c).GetOrder(orderId)
+	if order == nil || order.ID == 0 {
+		return nil, map[string]string{"order_not_found": fmt.Sprintf("order %v not found", orderId)}
+	}
+
+	if order.Status != order_entity.OrderStatusPacked {
+		return nil, map[string]string{"status": fmt.Sprintf("order %v is %v, only packed orders can be shipped", orderId, order.Status)}
+	}
+
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	if existing, _ := repoShipment.GetShipmentForOrder(orderId); existing != nil {
+		return nil, map[string]string{"order_id": fmt.Sprintf("order %v already has shipment %v", orderId, existing.ID)}
+	}
+
+	now := time.Now()
+	shipment := shipment_entity.Shipment{
+		OrderID:     orderId,
+		WarehouseID: order.WarehouseID,
+		Status:      shipment_entity.ShipmentStatusPending,
+		Events: []shipment_entity.ShipmentEvent{{
+			Status:     shipment_entity.ShipmentStatusPending,
+			RecordedBy: actorId,
+			RecordedAt: now,
+		}},
+	}
+
+	if request.DriverID > 0 {
+		driver, driverErr := a.checkAssignable(request.DriverID, order.WarehouseID)
+		if driverErr != nil {
+			return nil, driverErr
+		}
+
+		shipment.DriverID = driver.ID
+		shipment.Status = shipment_entity.ShipmentStatusAssigned
+		shipment.AssignedAt = &now
+		shipment.Events = append(shipment.Events, shipment_entity.ShipmentEvent{
+			Status:     shipment_entity.ShipmentStatusAssigned,
+			Note:       fmt.Sprintf("Assigned to driver %v", driver.ID),
+			RecordedBy: actorId,
+			RecordedAt: now,
+		})
+	}
+
+	if _, err := repoShipment.SaveShipment(nil, &shipment); err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return a.reload(int64(shipment.ID))
+}
+
+func (a *ShipmentApp) GetShipment(shipmentId int64) (*shipment_entity.Shipment, error) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	return repoShipment.GetShipment(shipmentId)
+}
+
+func (a *ShipmentApp) GetShipmentForOrder(orderId int64) (*shipment_entity.Shipment, error) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	return repoShipment.GetShipmentForOrder(orderId)
+}
+
+func (a *ShipmentApp) GetShipments(filter shipment_entity.ShipmentFilter) ([]shipment_entity.Shipment, error) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	return repoShipment.GetShipments(filter)
+}
+
+// AssignShipment gives a shipment that is not yet on the road to a driver
+func (a *ShipmentApp) AssignShipment(shipmentId int64, request shipment_entity.AssignRequest, actorId int64) (*shipment_entity.Shipment, map[string]string) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	shipment, _ := repoShipment.GetShipment(shipmentId)
+	if shipment == nil {
+		return nil, map[string]string{"shipment_not_found": fmt.Sprintf("shipment %v not found", shipmentId)}
+	}
+
+	if shipment.Status != shipment_entity.ShipmentStatusPending && shipment.Status != shipment_entity.ShipmentStatusAssigned {
+		return nil, map[string]string{"status": fmt.Sprintf("shipment %v is %v, only pending or assigned shipments can be assigned", shipmentId, shipment.Status)}
+	}
+
+	driver, driverErr := a.checkAssignable(request.DriverID, shipment.WarehouseID)
+	if driverErr != nil {
+		return nil, driverErr
+	}
+
+	now := time.Now()
+	fromStatus := shipment.Status
+	shipment.DriverID = driver.ID
+	shipment.Status = shipment_entity.ShipmentStatusAssigned
+	shipment.AssignedAt = &now
+
+	event := shipment_entity.ShipmentEvent{
+		Status:     shipment_entity.ShipmentStatusAssigned,
+		Note:       fmt.Sprintf("Assigned to driver %v", driver.ID),
+		RecordedBy: actorId,
+		RecordedAt: now,
+	}
+
+	if txErr := a.saveStep(shipment, fromStatus, nil, event); txErr != nil {
+		return nil, map[string]string{"status": txErr.Error()}
+	}
+
+	return a.reload(shipmentId)
+}
+
+// UpdateShipmentStatus records the driver leaving with the shipment, a failed attempt, or the
+// shipment coming back to the warehouse. A failure that may succeed another time goes back to the
+// driver until MaxDeliveryAttempts is reached, any other failure returns the shipment to the
+// warehouse, where its stock is put back once it arrives
+func (a *ShipmentApp) UpdateShipmentStatus(shipmentId int64, update shipment_entity.StatusUpdate, actorId int64) (*shipment_entity.Shipment, map[string]string) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	shipment, _ := repoShipment.GetShipment(shipmentId)
+	if shipment == nil {
+		return nil, map[string]string{"shipment_not_found": fmt.Sprintf("shipment %v not found", shipmentId)}
+	}
+
+	if validationErr := update.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	now := time.Now()
+	fromStatus := shipment.Status
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	events := []shipment_entity.ShipmentEvent{{
+		Status:     update.Status,
+		Latitude:   update.Latitude,
+		Longitude:  update.Longitude,
+		Reason:     update.Reason,
+		Note:       update.Note,
+		RecordedBy: actorId,
+		RecordedAt: now,
+	}}
+	if update.Latitude != nil {
+		shipment.LastLatitude = update.Latitude
+		shipment.LastLongitude = update.Longitude
+	}
+
+	var orderStep func(tx *gorm.DB) error
+	settle := func() {}
+	switch update.Status {
+	case shipment_entity.ShipmentStatusOutForDelivery:
+		if fromStatus != shipment_entity.ShipmentStatusAssigned {
+			return nil, map[string]string{"status": fmt.Sprintf("shipment %v is %v, only assigned shipments can go out for delivery", shipmentId, fromStatus)}
+		}
+
+		shipment.Status = update.Status
+		shipment.Attempts++
+		shipment.DispatchedAt = &now
+		shipment.FailureReason = ""
+		if shipment.Attempts == 1 {
+			orderStep = func(tx *gorm.DB) error {
+				return repoOrder.UpdateOrderStatus(tx, shipment.OrderID, order_entity.OrderStatusPacked, order_entity.OrderStatusShipped)
+			}
+		}
+
+	case shipment_entity.ShipmentStatusFailed:
+		if fromStatus != shipment_entity.ShipmentStatusOutForDelivery {
+			return nil, map[string]string{"status": fmt.Sprintf("shipment %v is %v, only shipments out for delivery can fail", shipmentId, fromStatus)}
+		}
+
+		shipment.FailureReason = update.Reason
+		next := shipment_entity.ShipmentStatusAssigned
+		note := fmt.Sprintf("Attempt %v of %v failed, delivery will be attempted again", shipment.Attempts, shipment_entity.MaxDeliveryAttempts)
+		if update.ReturnToWarehouse || !shipment_entity.FailureReasons[update.Reason] || shipment.Attempts >= shipment_entity.MaxDeliveryAttempts {
+			next = shipment_entity.ShipmentStatusReturning
+			note = "Returning to warehouse"
+		}
+		shipment.Status = next
+		events = append(events, shipment_entity.ShipmentEvent{
+			Status:     next,
+			Note:       note,
+			RecordedBy: actorId,
+			RecordedAt: now,
+		})
+
+	case shipment_entity.ShipmentStatusReturned:
+		if fromStatus != shipment_entity.ShipmentStatusReturning {
+			return nil, map[string]string{"status": fmt.Sprintf("shipment %v is %v, only returning shipments can be returned", shipmentId, fromStatus)}
+		}
+
+		shipment.Status = update.Status
+		shipment.ReturnedAt = &now
+		orderStep = func(tx *gorm.DB) error {
+			order, _ := repoOrder.GetOrder(shipment.OrderID)
+			if order == nil {
+				return fmt.Errorf("order %v not found", shipment.OrderID)
+			}
+			if err := repoOrder.UpdateOrderStatus(tx, shipment.OrderID, order_entity.OrderStatusShipped, order_entity.OrderStatusReturned); err != nil {
+				return err
+			}
+
+			var refundErr error
+			settle, refundErr = (&OrderApp{a.p, a.c}).refundOrder(tx, shipment.OrderID, fmt.Sprintf("Shipment %v returned to warehouse", shipment.ID))
+			if refundErr != nil {
+				return refundErr
+			}
+			return a.restockOrder(tx, order, fmt.Sprintf("Shipment %v returned to warehouse - Return inventory", shipment.ID))
+		}
+	}
+
+	if txErr := a.saveStep(shipment, fromStatus, orderStep, events...); txErr != nil {
+		return nil, map[string]string{"status": txErr.Error()}
+	}
+	settle()
+
+	return a.reload(shipmentId)
+}
+
+// UpdateShipmentLocation records where the driver is with a shipment out for delivery
+func (a *ShipmentApp) UpdateShipmentLocation(shipmentId int64, update shipment_entity.LocationUpdate, actorId int64) (*shipment_entity.Shipment, map[string]string) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	shipment, _ := repoShipment.GetShipment(shipmentId)
+	if shipment == nil {
+		return nil, map[string]string{"shipment_not_found": fmt.Sprintf("shipment %v not found", shipmentId)}
+	}
+
+	if validationErr := update.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	if shipment.Status != shipment_entity.ShipmentStatusOutForDelivery && shipment.Status != shipment_entity.ShipmentStatusReturning {
+		return nil, map[string]string{"status": fmt.Sprintf("shipment %v is %v, only shipments on the road report their location", shipmentId, shipment.Status)}
+	}
+
+	shipment.LastLatitude = update.Latitude
+	shipment.LastLongitude = update.Longitude
+	event := shipment_entity.ShipmentEvent{
+		Status:     shipment_entity.EventLocation,
+		Latitude:   update.Latitude,
+		Longitude:  update.Longitude,
+		RecordedBy: actorId,
+		RecordedAt: time.Now(),
+	}
+
+	if txErr := a.saveStep(shipment, shipment.Status, nil, event); txErr != nil {
+		return nil, map[string]string{"status": txErr.Error()}
+	}
+
+	return a.reload(shipmentId)
+}
+
+// DeliverShipment confirms a delivery. At least a signature or a photo is needed, both are uploaded
+// to storage before the shipment and its order are marked delivered
+func (a *ShipmentApp) DeliverShipment(shipmentId int64, proof shipment_entity.DeliveryProof, signature multipart.File, photo multipart.File, actorId int64) (*shipment_entity.Shipment, map[string]string) {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	shipment, _ := repoShipment.GetShipment(shipmentId)
+	if shipment == nil {
+		return nil, map[string]string{"shipment_not_found": fmt.Sprintf("shipment %v not found", shipmentId)}
+	}
+
+	if validationErr := proof.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+	if signature == nil && photo == nil {
+		return nil, map[string]string{"proof": "a signature or a photo is required as proof of delivery"}
+	}
+
+	if shipment.Status != shipment_entity.ShipmentStatusOutForDelivery {
+		return nil, map[string]string{"status": fmt.Sprintf("shipment %v is %v, only shipments out for delivery can be delivered", shipmentId, shipment.Status)}
+	}
+
+	now := time.Now()
+	shipment.Status = shipment_entity.ShipmentStatusDelivered
+	shipment.DeliveredAt = &now
+	shipment.FailureReason = ""
+	shipment.RecipientName = proof.RecipientName
+	if proof.Latitude != nil {
+		shipment.LastLatitude = proof.Latitude
+		shipment.LastLongitude = proof.Longitude
+	}
+
+	event := shipment_entity.ShipmentEvent{
+		Status:     shipment_entity.ShipmentStatusDelivered,
+		Latitude:   proof.Latitude,
+		Longitude:  proof.Longitude,
+		Note:       proof.Note,
+		RecordedBy: actorId,
+		RecordedAt: now,
+	}
+
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	storageRepo := storage.NewStorageRepository("Supabase", a.p)
+	uploaded := []string{}
+	var storageErr error
+	orderStep := func(tx *gorm.DB) error {
+		order, _ := repoOrder.GetOrder(shipment.OrderID)
+		if order == nil {
+			return fmt.Errorf("order %v not found", shipment.OrderID)
+		}
+		if err := repoOrder.UpdateOrderStatus(tx, shipment.OrderID, order_entity.OrderStatusShipped, order_entity.OrderStatusDelivered); err != nil {
+			return err
+		}
+		if err := (&LoyaltyApp{a.p, a.c}).earnPoints(tx, order, now); err != nil {
+			return err
+		}
+
+		// The proof is uploaded last, once this request holds the shipment, so a rejected delivery
+		// uploads nothing and cannot overwrite the proof of the delivery that went through
+		files := []struct {
+			file multipart.File
+			name string
+			url  *string
+		}{
+			{signature, shipment_entity.SignatureFile(shipment.ID), &shipment.SignatureURL},
+			{photo, shipment_entity.PhotoFile(shipment.ID), &shipment.PhotoURL},
+		}
+		for _, proofFile := range files {
+			if proofFile.file == nil {
+				continue
+			}
+			url, err := storageRepo.SaveFile(proofFile.file, proofFile.name, shipment_entity.ProofBucket)
+			if err != nil {
+				storageErr = err
+				return err
+			}
+			uploaded = append(uploaded, proofFile.name)
+			*proofFile.url = url
+		}
+
+		return shipments.NewShipmentRepository(a.p, a.c).UpdateShipment(tx, shipment, shipment_entity.ShipmentStatusDelivered)
+	}
+
+	if txErr := a.saveStep(shipment, shipment_entity.ShipmentStatusOutForDelivery, orderStep, event); txErr != nil {
+		for _, file := range uploaded {
+			if err := storageRepo.DeleteFile(shipment_entity.ProofBucket, file); err != nil {
+				a.p.Logger.Error("application/DeliverShipment", map[string]interface{}{"shipment_id": shipmentId, "file": file, "error": err.Error()})
+			}
+		}
+		if storageErr != nil {
+			return nil, map[string]string{"storage_error": storageErr.Error()}
+		}
+		return nil, map[string]string{"status": txErr.Error()}
+	}
+
+	return a.reload(shipmentId)
+}
+
+// saveStep saves the shipment if it is still in fromStatus, records the events and moves the order along, all or nothing
+func (a *ShipmentApp) saveStep(shipment *shipment_entity.Shipment, fromStatus string, orderStep func(tx *gorm.DB) error, events ...shipment_entity.ShipmentEvent) error {
+	repoShipment := shipments.NewShipmentRepository(a.p, a.c)
+	return a.p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repoShipment.UpdateShipment(tx, shipment, fromStatus); err != nil {
+			return err
+		}
+
+		for i := range events {
+			events[i].ShipmentID = shipment.ID
+			if err := repoShipment.SaveShipmentEvent(tx, &events[i]); err != nil {
+				return err
+			}
+		}
+
+		if orderStep != nil {
+			return orderStep(tx)
+		}
+		return nil
+	})
+}
+
+// restockOrder puts the items of an order that came back to the warehouse back in stock, a bundle as its components
+func (a *ShipmentApp) restockOrder(tx *gorm.DB, order *order_entity.Order, reason string) error {
+	inventoryRepo := inventories.NewInventoryRepository(a.p, a.c)
+	for _, orderedItem := range order.OrderedItems {
+		if orderedItem.Quantity <= 0 {
+			continue
+		}
+
+		bundleItems, err := orderedBundleItems(a.p, a.c, orderedItem.ID, orderedItem.ProductID)
+		if err != nil {
+			return err
+		}
+
+		if len(bundleItems) == 0 {
+			if err := inventoryRepo.ReturnInventory(tx, orderedItem.ProductID, orderedItem.Quantity, 0, order.WarehouseID, reason); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, bundleItem := range bundleItems {
+			if err := inventoryRepo.ReturnInventory(tx, int64(bundleItem.ComponentID), bundleItem.Quantity*orderedItem.Quantity, uint64(orderedItem.ProductID), order.WarehouseID, reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkDriver checks the warehouse and customer account a driver is linked to
+func (a *ShipmentApp) checkDriver(driver *shipment_entity.Driver) map[string]string {
+	if driver.WarehouseID > 0 {
+		warehouse, _ := warehouses.NewWareHouseRepository(a.p, a.c).GetWarehouse(int64(driver.WarehouseID))
+		if warehouse == nil || warehouse.ID == 0 {
+			return map[string]string{"warehouse_id": fmt.Sprintf("warehouse %v not found", driver.WarehouseID)}
+		}
+	}
+
+	if driver.CustomerID > 0 {
+		customer, _ := customers.NewCustomerRepository(a.p, a.c).GetCustomer(driver.CustomerID)
+		if customer == nil || customer.ID == 0 {
+			return map[string]string{"customer_id": fmt.Sprintf("customer %v not found", driver.CustomerID)}
+		}
+
+		other, _ := shipments.NewShipmentRepository(a.p, a.c).GetDriverByCustomer(driver.CustomerID)
+		if other != nil && other.ID != driver.ID {
+			return map[string]string{"customer_id": fmt.Sprintf("customer %v is already driver %v", driver.CustomerID, other.ID)}
+		}
+	}
+
+	return nil
+}
+
+// checkAssignable returns the driver if they are active and work for the warehouse, a driver without a warehouse works for all
+func (a *ShipmentApp) checkAssignable(driverId uint64, warehouseId uint64) (*shipment_entity.Driver, map[string]string) {
+	driver, _ := shipments.NewShipmentRepository(a.p, a.c).GetDriver(int64(driverId))
+	if driver == nil {
+		return nil, map[string]string{"driver_id": fmt.Sprintf("driver %v not found", driverId)}
+	}
+	if !driver.Active {
+		return nil, map[string]string{"driver_id": fmt.Sprintf("driver %v is not active", driverId)}
+	}
+	if driver.WarehouseID > 0 && driver.WarehouseID != warehouseId {
+		return nil, map[string]string{"driver_id": fmt.Sprintf("driver %v does not work for warehouse %v", driverId, warehouseId)}
+	}
+
+	return driver, nil
+}
+
+func (a *ShipmentApp) reload(shipmentId int64) (*shipment_entity.Shipment, map[string]string) {
+	shipment, err := shipments.NewShipmentRepository(a.p, a.c).GetShipment(shipmentId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return shipment, nil
+}
@@ -0,0 +1,57 @@
+package application
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/trash_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/trash"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type TrashApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewTrashApplication(p *base.Persistence, c *gin.Context) trash_repository.TrashHandlerRepository {
+	return &TrashApp{p, c}
+}
+
+func (a *TrashApp) GetDeleted(resource string) (interface{}, error) {
+	repoTrash := trash.NewTrashRepository(a.p, a.c)
+	return repoTrash.GetDeleted(resource)
+}
+
+func (a *TrashApp) RestoreDeleted(resource string, id int64) (interface{}, error) {
+	repoTrash := trash.NewTrashRepository(a.p, a.c)
+	return repoTrash.RestoreDeleted(resource, id)
+}
+
+func (a *TrashApp) PurgeDeleted(resource string, id int64) error {
+	repoTrash := trash.NewTrashRepository(a.p, a.c)
+	return repoTrash.PurgeDeleted(resource, id)
+}
+
+// PurgeExpired permanently removes everything that has been in the recycle bin for longer than the retention period,
+// a resource that fails is logged and the others are still purged
+func (a *TrashApp) PurgeExpired(retentionDays int) (map[string]int64, error) {
+	if retentionDays < 0 {
+		return nil, errors.New("retention days cannot be negative")
+	}
+
+	repoTrash := trash.NewTrashRepository(a.p, a.c)
+	before := time.Now().AddDate(0, 0, -retentionDays)
+	purged := make(map[string]int64)
+
+	for _, resource := range trash.Resources() {
+		count, err := repoTrash.PurgeDeletedBefore(resource, before)
+		purged[resource] = count
+		if err != nil {
+			a.p.Logger.Error("application/PurgeExpired", map[string]interface{}{"resource": resource, "error": err.Error()})
+		}
+	}
+
+	return purged, nil
+}
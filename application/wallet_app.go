@@ -0,0 +1,226 @@
+package application
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/wallet_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/wallet_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/customers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/orders"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/payment"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/wallets"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+type WalletApp struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewWalletApplication(p *base.Persistence, c *gin.Context) wallet_repository.WalletHandlerRepository {
+	return &WalletApp{p, c}
+}
+
+// GetWallet returns the wallet of the customer, a customer who never had store credit has an empty one
+func (a *WalletApp) GetWallet(customerId int64) (*wallet_entity.Wallet, map[string]string) {
+	if customerErr := a.checkCustomer(customerId); customerErr != nil {
+		return nil, customerErr
+	}
+
+	wallet, err := wallets.NewWalletRepository(a.p, a.c).GetOrCreateWallet(nil, customerId)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return wallet, nil
+}
+
+func (a *WalletApp) GetStatement(customerId int64, pagination *entity.Pagination) ([]wallet_entity.WalletTransaction, map[string]string) {
+	wallet, walletErr := a.GetWallet(customerId)
+	if walletErr != nil {
+		return nil, walletErr
+	}
+
+	transactions, err := wallets.NewWalletRepository(a.p, a.c).GetTransactions(wallet.ID, pagination)
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return transactions, nil
+}
+
+// TopUpWallet charges the payment method through the gateway and credits the wallet with the amount.
+// The money is captured straight away, there is nothing to ship before taking it. Top-ups are off with the
+// fake gateway, which approves any token and would let customers mint store credit
+func (a *WalletApp) TopUpWallet(customerId int64, request wallet_entity.TopUpRequest, actorId int64) (*wallet_entity.WalletTransaction, map[string]string) {
+	if !payment.IsRealGateway(payment.ConfiguredGateway()) {
+		return nil, map[string]string{"gateway": "wallet top-ups need a real payment gateway"}
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	gateway := payment.NewPaymentRepository(payment.ConfiguredGateway(), a.p, a.c)
+	authorized, err := gateway.Authorize(request.Token, request.Amount, fmt.Sprintf("Wallet top-up of customer %v", customerId))
+	if err != nil {
+		return nil, map[string]string{"gateway_error": err.Error()}
+	}
+	if !authorized.Success {
+		return nil, map[string]string{"token": fmt.Sprintf("payment declined: %v", authorized.Message)}
+	}
+
+	captured, err := gateway.Capture(authorized.Reference, request.Amount)
+	if err != nil || !captured.Success {
+		if _, voidErr := gateway.Void(authorized.Reference); voidErr != nil {
+			a.p.Logger.Error("application/TopUpWallet", map[string]interface{}{"reference": authorized.Reference, "error": voidErr.Error()})
+		}
+		if err != nil {
+			return nil, map[string]string{"gateway_error": err.Error()}
+		}
+		return nil, map[string]string{"token": fmt.Sprintf("payment declined: %v", captured.Message)}
+	}
+
+	transaction, err := a.post(nil, customerId, wallet_entity.TransactionTopUp, request.Amount, wallet_entity.WalletTransaction{
+		Reference:   authorized.Reference,
+		Description: "Top-up",
+		CreatedBy:   actorId,
+	})
+	if err != nil {
+		// The customer paid for credit they did not get, so the money goes back
+		if _, refundErr := gateway.Refund(authorized.Reference, request.Amount); refundErr != nil {
+			a.p.Logger.Error("application/TopUpWallet", map[string]interface{}{"reference": authorized.Reference, "error": refundErr.Error()})
+		}
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return transaction, nil
+}
+
+// CreditWallet gives store credit to a customer by hand, optionally about one of their orders
+func (a *WalletApp) CreditWallet(customerId int64, request wallet_entity.CreditRequest, actorId int64) (*wallet_entity.WalletTransaction, map[string]string) {
+	if customerErr := a.checkCustomer(customerId); customerErr != nil {
+		return nil, customerErr
+	}
+
+	if validationErr := request.Validate(); len(validationErr) > 0 {
+		return nil, validationErr
+	}
+
+	if request.OrderID > 0 {
+		order, _ := orders.NewOrderRepository(a.p, a.c).GetOrder(request.OrderID)
+		if order == nil || order.ID == 0 || order.CustomerID != customerId {
+			return nil, map[string]string{"order_id": fmt.Sprintf("order %v is not an order of customer %v", request.OrderID, customerId)}
+		}
+	}
+
+	transaction, err := a.post(nil, customerId, request.Type, request.Amount, wallet_entity.WalletTransaction{
+		OrderID:     request.OrderID,
+		Description: request.Description,
+		CreatedBy:   actorId,
+	})
+	if err != nil {
+		return nil, map[string]string{"db_error": err.Error()}
+	}
+
+	return transaction, nil
+}
+
+// payOrders pays the orders from their customer's wallet as far as its balance goes, one after the
+// other. An order the wallet pays in full is confirmed, the rest of one paid in part is left to the gateway
+func (a *WalletApp) payOrders(tx *gorm.DB, placed []order_entity.Order) error {
+	if len(placed) == 0 {
+		return nil
+	}
+
+	wallet, err := wallets.NewWalletRepository(a.p, a.c).GetOrCreateWallet(tx, placed[0].CustomerID)
+	if err != nil {
+		return err
+	}
+
+	repoOrder := orders.NewOrderRepository(a.p, a.c)
+	balance := wallet.Balance
+	for i := range placed {
+		order := &placed[i]
+		amount := math.Round(min(balance, order.TotalCheckout)*100) / 100
+		if amount <= 0 {
+			continue
+		}
+
+		transaction, err := a.post(tx, order.CustomerID, wallet_entity.TransactionPayment, amount, wallet_entity.WalletTransaction{
+			OrderID:     int64(order.ID),
+			Description: fmt.Sprintf("Order %v", order.ID),
+		})
+		if err != nil {
+			return err
+		}
+
+		status := order.Status
+		if transaction.Amount >= order.TotalCheckout && status == order_entity.OrderStatusAwaitingPayment {
+			status = order_entity.OrderStatusPending
+		}
+		if err := repoOrder.SaveWalletAmount(tx, int64(order.ID), transaction.Amount, status); err != nil {
+			return err
+		}
+
+		order.WalletAmount = transaction.Amount
+		order.Status = status
+		balance -= transaction.Amount
+	}
+
+	return nil
+}
+
+// refundOrder gives back to the wallet up to amount of what it paid for an order, the order keeps
+// the rest as its wallet amount
+func (a *WalletApp) refundOrder(tx *gorm.DB, order *order_entity.Order, amount float64, description string) error {
+	amount = math.Round(min(amount, order.WalletAmount)*100) / 100
+	if amount <= 0 {
+		return nil
+	}
+
+	if err := orders.NewOrderRepository(a.p, a.c).RefundWalletAmount(tx, int64(order.ID), amount); err != nil {
+		return err
+	}
+
+	_, err := a.post(tx, order.CustomerID, wallet_entity.TransactionReversal, amount, wallet_entity.WalletTransaction{
+		OrderID:     int64(order.ID),
+		Description: description,
+	})
+	return err
+}
+
+// post appends a transaction of the type to the customer's wallet, details carries its references
+func (a *WalletApp) post(tx *gorm.DB, customerId int64, transactionType string, amount float64, details wallet_entity.WalletTransaction) (*wallet_entity.WalletTransaction, error) {
+	repoWallet := wallets.NewWalletRepository(a.p, a.c)
+	wallet, err := repoWallet.GetOrCreateWallet(tx, customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := wallet_entity.NewTransaction(wallet, transactionType, amount)
+	transaction.OrderID = details.OrderID
+	transaction.ReturnID = details.ReturnID
+	transaction.Reference = details.Reference
+	transaction.Description = details.Description
+	transaction.CreatedBy = details.CreatedBy
+	if err := repoWallet.PostTransaction(tx, &transaction); err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+func (a *WalletApp) checkCustomer(customerId int64) map[string]string {
+	customer, _ := customers.NewCustomerRepository(a.p, a.c).GetCustomer(customerId)
+	if customer == nil || customer.ID == 0 {
+		return map[string]string{"customer_not_found": fmt.Sprintf("customer %v not found", customerId)}
+	}
+
+	return nil
+}
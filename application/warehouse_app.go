@@ -50,6 +50,11 @@ func (a *warehouseApp) DeleteWarehouse(warehouseId int64) error {
 	return repowarehouse.DeleteWarehouse(warehouseId)
 }
 
+func (a *warehouseApp) GetNearestWarehouses(latitude float64, longitude float64) ([]warehouse_entity.WarehouseDistance, error) {
+	repowarehouse := warehouses.NewWareHouseRepository(a.p, a.c)
+	return repowarehouse.GetNearestWarehouses(latitude, longitude)
+}
+
 func (a *warehouseApp) SearchWarehouse(name string) ([]warehouse_entity.Warehouse, error) {
 	searchProvider := os.Getenv("SEARCH_PROVIDER")
 
@@ -0,0 +1,43 @@
+package address_entity
+
+import "github.com/harisquqo/quqo-challenge-1/domain/entity"
+
+type Address struct {
+	entity.BaseModelWDelete
+	ID           uint64  `gorm:"primary_key;not null;" json:"id"`
+	CustomerID   int64   `gorm:"not null;index;" json:"customer_id"`
+	Label        string  `gorm:"size:100;not null;" json:"label"`
+	ContactName  string  `gorm:"size:255;not null;" json:"contact_name"`
+	Phone        string  `gorm:"size:50;not null;" json:"phone"`
+	Address      string  `gorm:"size:255;not null;" json:"address"`
+	Instructions string  `gorm:"size:500;" json:"instructions"`
+	Latitude     float64 `gorm:"type:numeric;not null;" json:"latitude"`
+	Longitude    float64 `gorm:"type:numeric;not null;" json:"longitude"`
+	IsDefault    bool    `gorm:"not null;default:false;" json:"is_default"`
+}
+
+// Validate checks the fields every delivery address needs
+func (a *Address) Validate() map[string]string {
+	errorMessages := map[string]string{}
+
+	if a.Label == "" {
+		errorMessages["label_required"] = "label is required"
+	}
+	if a.ContactName == "" {
+		errorMessages["contact_name_required"] = "contact name is required"
+	}
+	if a.Phone == "" {
+		errorMessages["phone_required"] = "phone is required"
+	}
+	if a.Address == "" {
+		errorMessages["address_required"] = "address is required"
+	}
+	if a.Latitude < -90 || a.Latitude > 90 || a.Longitude < -180 || a.Longitude > 180 {
+		errorMessages["coordinates_invalid"] = "coordinates are out of range"
+	}
+	if a.Latitude == 0 && a.Longitude == 0 {
+		errorMessages["coordinates_required"] = "coordinates are required"
+	}
+
+	return errorMessages
+}
@@ -0,0 +1,49 @@
+package auth_entity
+
+import (
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+)
+
+const (
+	AuditAPIKeyCreated = "api_key_created"
+	AuditAPIKeyRevoked = "api_key_revoked"
+)
+
+// APIKey lets scanners and integrations call the API without a customer login. Only a hash of the
+// key is stored, the prefix identifies the key in listings and is used to look it up
+type APIKey struct {
+	entity.BaseModelWDelete
+	ID         uint64     `gorm:"primary_key;not null;" json:"id"`
+	Name       string     `gorm:"size:255;not null;" json:"name"`
+	Prefix     string     `gorm:"size:32;not null;uniqueIndex;" json:"prefix"`
+	KeyHash    string     `gorm:"size:64;not null;" json:"-"`
+	Scopes     []string   `gorm:"type:text;serializer:json;" json:"scopes"`
+	CreatedBy  int64      `gorm:"default:0;" json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// HasScope reports whether the key was granted the permission
+func (k *APIKey) HasScope(permission string) bool {
+	for _, scope := range k.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// CreatedAPIKey is returned once when the key is created, the raw key cannot be shown again
+type CreatedAPIKey struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"api_key"`
+}
@@ -0,0 +1,48 @@
+package auth_entity
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	AuditLoginLockout = "login_lockout"
+	AuditLoginUnlock  = "login_unlock"
+)
+
+// LoginAttempts counts failed logins for a username or an IP address inside the attempt window
+type LoginAttempts struct {
+	Failures      int       `json:"failures"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+}
+
+// LoginThrottledError is returned while a username or IP address has to wait before trying again
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts, try again in %v", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("please wait %v before trying again", e.RetryAfter.Round(time.Second))
+}
+
+// AuditEvent records security relevant actions
+type AuditEvent struct {
+	ID         uint64    `gorm:"primary_key;not null;" json:"id"`
+	Event      string    `gorm:"size:50;not null;index;" json:"event"`
+	CustomerID int64     `gorm:"default:0;index;" json:"customer_id"`
+	Username   string    `gorm:"size:255;index;" json:"username"`
+	IP         string    `gorm:"size:64;" json:"ip"`
+	Actor      string    `gorm:"size:255;" json:"actor"`
+	Details    string    `gorm:"size:500;" json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type UnlockLoginRequest struct {
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+}
@@ -0,0 +1,95 @@
+package auth_entity
+
+const (
+	RoleAdmin             = "admin"
+	RoleWarehouseOperator = "warehouse_operator"
+	RoleSupport           = "support"
+	RoleCustomer          = "customer"
+	RoleDriver            = "driver"
+)
+
+const (
+	PermProductsRead    = "products:read"
+	PermProductsWrite   = "products:write"
+	PermInventoryRead   = "inventory:read"
+	PermInventoryWrite  = "inventory:write"
+	PermWarehousesRead  = "warehouses:read"
+	PermWarehousesWrite = "warehouses:write"
+	PermCategoriesRead  = "categories:read"
+	PermCategoriesWrite = "categories:write"
+	PermCustomersRead   = "customers:read"
+	PermCustomersWrite  = "customers:write"
+	PermRolesManage     = "roles:manage"
+	PermOrdersCreate    = "orders:create"
+	PermOrdersRead      = "orders:read"
+	PermOrdersWrite     = "orders:write"
+	PermTrashManage     = "trash:manage"
+	PermSessionsManage  = "sessions:manage"
+	PermKeysManage      = "keys:manage"
+	PermAPIKeysManage   = "apikeys:manage"
+	PermPrivacyManage   = "privacy:manage"
+	PermPickingManage   = "picking:manage"
+	PermShipmentsManage = "shipments:manage"
+	PermDeliveriesWrite = "deliveries:write"
+	PermReturnsManage   = "returns:manage"
+	PermPaymentsManage  = "payments:manage"
+	PermWalletsManage   = "wallets:manage"
+	PermLoyaltyManage   = "loyalty:manage"
+)
+
+// RolePermissions lists what each role may do. Permissions on customers and orders apply to
+// every record, customers can still reach their own profile and orders through ownership checks
+var RolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermProductsRead, PermProductsWrite, PermInventoryRead, PermInventoryWrite,
+		PermWarehousesRead, PermWarehousesWrite, PermCategoriesRead, PermCategoriesWrite,
+		PermCustomersRead, PermCustomersWrite, PermRolesManage,
+		PermOrdersCreate, PermOrdersRead, PermOrdersWrite, PermTrashManage, PermSessionsManage,
+		PermKeysManage, PermAPIKeysManage, PermPrivacyManage, PermPickingManage,
+		PermShipmentsManage, PermDeliveriesWrite, PermReturnsManage, PermPaymentsManage,
+		PermWalletsManage, PermLoyaltyManage,
+	},
+	RoleWarehouseOperator: {
+		PermProductsRead, PermInventoryRead, PermInventoryWrite,
+		PermWarehousesRead, PermCategoriesRead, PermOrdersRead, PermOrdersWrite, PermPickingManage,
+		PermShipmentsManage, PermDeliveriesWrite, PermReturnsManage,
+	},
+	RoleSupport: {
+		PermProductsRead, PermInventoryRead, PermWarehousesRead, PermCategoriesRead,
+		PermCustomersRead, PermCustomersWrite, PermOrdersCreate, PermOrdersRead, PermOrdersWrite,
+		PermReturnsManage, PermPaymentsManage, PermWalletsManage, PermLoyaltyManage,
+	},
+	RoleCustomer: {
+		PermProductsRead, PermWarehousesRead, PermCategoriesRead, PermOrdersCreate,
+	},
+	// Drivers only reach the shipments assigned to them
+	RoleDriver: {
+		PermDeliveriesWrite,
+	},
+}
+
+func IsValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// IsStaffRole reports whether accounts with the role are more than a shopper's. Their details can
+// only be changed by themselves or by those who manage roles, so an account cannot be taken over
+// by pointing its email at another inbox
+func IsStaffRole(role string) bool {
+	return role != RoleCustomer
+}
+
+// IsValidPermission reports whether the permission exists, admins are granted every permission
+func IsValidPermission(permission string) bool {
+	return HasPermission(RoleAdmin, permission)
+}
+
+func HasPermission(role string, permission string) bool {
+	for _, granted := range RolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
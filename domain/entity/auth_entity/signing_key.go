@@ -0,0 +1,45 @@
+package auth_entity
+
+import (
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey is a token signing key pair identified by the kid header of the tokens it signs.
+// Only the active key signs, retired keys keep verifying until the tokens they signed expire
+type SigningKey struct {
+	entity.BaseModelWDelete
+	ID         uint64     `gorm:"primary_key;not null;" json:"id"`
+	KID        string     `gorm:"size:64;not null;uniqueIndex;" json:"kid"`
+	Algorithm  string     `gorm:"size:20;not null;" json:"algorithm"`
+	PrivateKey string     `gorm:"type:text;not null;" json:"-"`
+	PublicKey  string     `gorm:"type:text;not null;" json:"public_key"`
+	Active     bool       `gorm:"not null;default:false;" json:"active"`
+	RetiredAt  *time.Time `json:"retired_at"`
+}
+
+// JWK is the public part of a signing key as published in the JWKS document
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type RotateKeyRequest struct {
+	Algorithm string `json:"algorithm"`
+}
@@ -0,0 +1,79 @@
+package auth_entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+)
+
+// RefreshToken is stored as a hash only. Every rotation revokes the presented token and issues a
+// new one in the same family, so presenting a revoked token again means it was stolen
+type RefreshToken struct {
+	entity.BaseModelWDelete
+	ID           uint64     `gorm:"primary_key;not null;" json:"id"`
+	CustomerID   int64      `gorm:"not null;index;" json:"customer_id"`
+	TokenHash    string     `gorm:"size:64;not null;uniqueIndex;" json:"-"`
+	FamilyID     string     `gorm:"size:64;not null;index;" json:"family_id"`
+	AccessJTI    string     `gorm:"size:64;not null;index;" json:"access_jti"`
+	ExpiresAt    time.Time  `gorm:"not null;" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID uint64     `gorm:"default:0;" json:"replaced_by_id"`
+}
+
+// CheckRefresh reports why the refresh token cannot be exchanged for new tokens. reused is set when the
+// token was already rotated, which means it was stolen and its whole family has to be revoked
+func (t *RefreshToken) CheckRefresh(now time.Time) (reused bool, err error) {
+	if t.RevokedAt != nil {
+		return true, errors.New("refresh token has already been used, the session has been revoked")
+	}
+	if now.After(t.ExpiresAt) {
+		return false, errors.New("refresh token has expired")
+	}
+	return false, nil
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RevokeRequest struct {
+	JTI string `json:"jti"`
+}
+
+const (
+	PurposeEmailVerification = "email_verification"
+	PurposePasswordReset     = "password_reset"
+)
+
+// ActionToken tracks a signed one-time token sent by email, the token itself is a JWT
+// and only its jti is stored
+type ActionToken struct {
+	ID         uint64     `gorm:"primary_key;not null;" json:"id"`
+	JTI        string     `gorm:"size:64;not null;uniqueIndex;" json:"jti"`
+	Purpose    string     `gorm:"size:50;not null;index;" json:"purpose"`
+	CustomerID int64      `gorm:"not null;index;" json:"customer_id"`
+	ExpiresAt  time.Time  `gorm:"not null;" json:"expires_at"`
+	UsedAt     *time.Time `json:"used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+type ActionTokenRequest struct {
+	Token string `json:"token"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
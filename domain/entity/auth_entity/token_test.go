@@ -0,0 +1,35 @@
+package auth_entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckRefresh(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rotatedAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		token      RefreshToken
+		wantReused bool
+		wantErr    bool
+	}{
+		{"unused and live", RefreshToken{ExpiresAt: now.Add(time.Hour)}, false, false},
+		{"expired", RefreshToken{ExpiresAt: now.Add(-time.Second)}, false, true},
+		{"already rotated", RefreshToken{ExpiresAt: now.Add(time.Hour), RevokedAt: &rotatedAt, ReplacedByID: 2}, true, true},
+		{"rotated and expired is still reuse", RefreshToken{ExpiresAt: now.Add(-time.Second), RevokedAt: &rotatedAt}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reused, err := tt.token.CheckRefresh(now)
+			if reused != tt.wantReused {
+				t.Errorf("reused = %v, want %v", reused, tt.wantReused)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package bin_entity
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+)
+
+// Zone is an area of a warehouse, such as chilled or bulk, walked in order of Sequence
+type Zone struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WarehouseID uint64 `gorm:"not null;index;" json:"warehouse_id"`
+	Code string `gorm:"size:20;not null;" json:"code"`
+	Name string `gorm:"size:255;" json:"name"`
+	Sequence int `gorm:"default:0;" json:"sequence"`
+	Aisles []Aisle `gorm:"foreignKey:ZoneID;references:ID" json:"aisles,omitempty"`
+}
+
+// Aisle is a row of bins inside a zone, walked in order of Sequence
+type Aisle struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WarehouseID uint64 `gorm:"not null;index;" json:"warehouse_id"`
+	ZoneID uint64 `gorm:"not null;index;" json:"zone_id"`
+	Code string `gorm:"size:20;not null;" json:"code"`
+	Sequence int `gorm:"default:0;" json:"sequence"`
+	Bins []Bin `gorm:"foreignKey:AisleID;references:ID" json:"bins,omitempty"`
+}
+
+// Bin is a shelf position inside an aisle. Sequence is its position along the aisle and
+// Capacity the most units it holds, zero meaning no limit. Inactive bins take no stock and
+// are left out of pick lists
+type Bin struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WarehouseID uint64 `gorm:"not null;index;" json:"warehouse_id"`
+	ZoneID uint64 `gorm:"not null;index;" json:"zone_id"`
+	AisleID uint64 `gorm:"not null;index;" json:"aisle_id"`
+	Code string `gorm:"size:20;not null;" json:"code"`
+	Sequence int `gorm:"default:0;" json:"sequence"`
+	Capacity int `gorm:"default:0;" json:"capacity"`
+	Active bool `gorm:"not null;" json:"active"`
+	Label string `gorm:"-" json:"label,omitempty"`
+}
+
+// BinStock is how many units of a product sit in a bin. Inventory stays the count of sellable
+// units of the warehouse, bins say where the physical units are
+type BinStock struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	BinID uint64 `gorm:"not null;index;" json:"bin_id"`
+	WarehouseID uint64 `gorm:"not null;index;" json:"warehouse_id"`
+	ProductID uint64 `gorm:"not null;index;" json:"product_id"`
+	Quantity int `gorm:"not null;default:0;" json:"quantity"`
+	Label string `gorm:"-" json:"label,omitempty"`
+}
+
+// BinContents is a bin with the stock it holds
+type BinContents struct {
+	Bin
+	Stock []BinStock `json:"stock"`
+}
+
+type ZoneRequest struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Sequence int `json:"sequence"`
+}
+
+type AisleRequest struct {
+	ZoneID uint64 `json:"zone_id"`
+	Code string `json:"code"`
+	Sequence int `json:"sequence"`
+}
+
+type BinRequest struct {
+	AisleID uint64 `json:"aisle_id"`
+	Code string `json:"code"`
+	Sequence int `json:"sequence"`
+	Capacity int `json:"capacity"`
+	Active *bool `json:"active"`
+}
+
+// PutAwayRequest shelves units of a product in a bin. Received units are new stock arriving at
+// the warehouse and are added to its inventory, otherwise the units are already counted in the
+// inventory and only lacked a bin
+type PutAwayRequest struct {
+	ProductID uint64 `json:"product_id"`
+	BinID uint64 `json:"bin_id"`
+	Quantity int `json:"quantity"`
+	Received bool `json:"received"`
+}
+
+type MoveRequest struct {
+	ProductID uint64 `json:"product_id"`
+	FromBinID uint64 `json:"from_bin_id"`
+	ToBinID uint64 `json:"to_bin_id"`
+	Quantity int `json:"quantity"`
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *ZoneRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if err := validateCode(r.Code); err != "" {
+		errorMessages["code"] = err
+	}
+
+	return errorMessages
+}
+
+func (r *ZoneRequest) Apply(zone *Zone) {
+	zone.Code = strings.ToUpper(strings.TrimSpace(r.Code))
+	zone.Name = strings.TrimSpace(r.Name)
+	zone.Sequence = r.Sequence
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *AisleRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if r.ZoneID == 0 {
+		errorMessages["zone_id"] = "zone_id is required"
+	}
+	if err := validateCode(r.Code); err != "" {
+		errorMessages["code"] = err
+	}
+
+	return errorMessages
+}
+
+func (r *AisleRequest) Apply(aisle *Aisle) {
+	aisle.ZoneID = r.ZoneID
+	aisle.Code = strings.ToUpper(strings.TrimSpace(r.Code))
+	aisle.Sequence = r.Sequence
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *BinRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if r.AisleID == 0 {
+		errorMessages["aisle_id"] = "aisle_id is required"
+	}
+	if err := validateCode(r.Code); err != "" {
+		errorMessages["code"] = err
+	}
+	if r.Capacity < 0 {
+		errorMessages["capacity"] = "capacity cannot be negative"
+	}
+
+	return errorMessages
+}
+
+// Apply copies the request onto the bin, leaving out active keeps the bin as it is
+func (r *BinRequest) Apply(bin *Bin) {
+	bin.AisleID = r.AisleID
+	bin.Code = strings.ToUpper(strings.TrimSpace(r.Code))
+	bin.Sequence = r.Sequence
+	bin.Capacity = r.Capacity
+	if r.Active != nil {
+		bin.Active = *r.Active
+	}
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *PutAwayRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if r.ProductID == 0 {
+		errorMessages["product_id"] = "product_id is required"
+	}
+	if r.BinID == 0 {
+		errorMessages["bin_id"] = "bin_id is required"
+	}
+	if r.Quantity <= 0 {
+		errorMessages["quantity"] = "quantity must be greater than 0"
+	}
+
+	return errorMessages
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *MoveRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if r.ProductID == 0 {
+		errorMessages["product_id"] = "product_id is required"
+	}
+	if r.FromBinID == 0 {
+		errorMessages["from_bin_id"] = "from_bin_id is required"
+	}
+	if r.ToBinID == 0 {
+		errorMessages["to_bin_id"] = "to_bin_id is required"
+	}
+	if r.FromBinID != 0 && r.FromBinID == r.ToBinID {
+		errorMessages["to_bin_id"] = "to_bin_id must differ from from_bin_id"
+	}
+	if r.Quantity <= 0 {
+		errorMessages["quantity"] = "quantity must be greater than 0"
+	}
+
+	return errorMessages
+}
+
+func validateCode(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return "code is required"
+	}
+	if len(code) > 20 || strings.ContainsAny(code, " -") {
+		return "code must be at most 20 characters without spaces or dashes"
+	}
+	return ""
+}
+
+// SetLabels names every bin of the layout zone-aisle-bin, such as A-03-12
+func SetLabels(zones []Zone) {
+	for z := range zones {
+		for a := range zones[z].Aisles {
+			aisle := &zones[z].Aisles[a]
+			for b := range aisle.Bins {
+				aisle.Bins[b].Label = zones[z].Code + "-" + aisle.Code + "-" + aisle.Bins[b].Code
+			}
+		}
+	}
+}
+
+// Bins returns every bin of the layout by id, labelled
+func Bins(zones []Zone) map[uint64]Bin {
+	SetLabels(zones)
+	bins := map[uint64]Bin{}
+	for _, zone := range zones {
+		for _, aisle := range zone.Aisles {
+			for _, bin := range aisle.Bins {
+				bins[bin.ID] = bin
+			}
+		}
+	}
+	return bins
+}
+
+// RouteOrder ranks the visited bins along an S-shaped walk of the warehouse: zones and aisles
+// in sequence, skipping aisles with nothing to pick, and every other visited aisle walked back
+// from its far end so the picker never walks an aisle twice
+func RouteOrder(zones []Zone, visited map[uint64]bool) map[uint64]int {
+	zones = append([]Zone(nil), zones...)
+	sort.SliceStable(zones, func(i, j int) bool {
+		return zones[i].Sequence < zones[j].Sequence
+	})
+
+	rank := map[uint64]int{}
+	forward := true
+	for _, zone := range zones {
+		aisles := append([]Aisle(nil), zone.Aisles...)
+		sort.SliceStable(aisles, func(i, j int) bool {
+			return aisles[i].Sequence < aisles[j].Sequence
+		})
+
+		for _, aisle := range aisles {
+			var bins []Bin
+			for _, bin := range aisle.Bins {
+				if visited[bin.ID] {
+					bins = append(bins, bin)
+				}
+			}
+			if len(bins) == 0 {
+				continue
+			}
+
+			sort.SliceStable(bins, func(i, j int) bool {
+				if forward {
+					return bins[i].Sequence < bins[j].Sequence
+				}
+				return bins[i].Sequence > bins[j].Sequence
+			})
+			for _, bin := range bins {
+				rank[bin.ID] = len(rank) + 1
+			}
+			forward = !forward
+		}
+	}
+
+	return rank
+}
@@ -7,5 +7,48 @@ type Category struct {
 	ID uint64 `json:"id"`
 	ParentID int64 `gorm:"size:100;not null;" json:"parent_id"`
 	Name string `gorm:"size:100;not null;" json:"name"`
+	Path string `gorm:"size:255;index;" json:"path"`
+	Depth int `gorm:"not null;default:0;" json:"depth"`
+	Position int `gorm:"not null;default:0;" json:"position"`
+	// PointsMultiplier is how many times the usual rate the category's products earn loyalty points
+	PointsMultiplier float64 `gorm:"type:numeric;not null;default:1;" json:"points_multiplier"`
 	ParentCategories []Category `gorm:"foreignKey:ID;references:ParentID" json:"ParentCategories"`
 }
+
+// CategoryNode is a category with its children nested underneath it
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
+}
+
+type MoveCategory struct {
+	ParentID int64 `json:"parent_id"`
+}
+
+type ReorderCategories struct {
+	CategoryIDs []int64 `json:"category_ids"`
+}
+
+// MaxPointsMultiplier caps how much faster than usual a category's products earn loyalty points
+const MaxPointsMultiplier = 10.0
+
+type PointsMultiplier struct {
+	PointsMultiplier float64 `json:"points_multiplier"`
+}
+
+const (
+	DeletePolicyBlock    = "block"
+	DeletePolicyReassign = "reassign"
+	DeletePolicyCascade  = "cascade"
+)
+
+// CategoryDeletionReport describes what a category deletion changes, or would change on a dry run
+type CategoryDeletionReport struct {
+	CategoryID         uint64   `json:"category_id"`
+	Policy             string   `json:"policy"`
+	DryRun             bool     `json:"dry_run"`
+	ChildCategoryIDs   []uint64 `json:"child_category_ids"`
+	DeletedCategoryIDs []uint64 `json:"deleted_category_ids"`
+	AffectedProductIDs []uint64 `json:"affected_product_ids"`
+	ReassignedToID     int64    `json:"reassigned_to_id"`
+}
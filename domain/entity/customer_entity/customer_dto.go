@@ -0,0 +1,199 @@
+package customer_entity
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	MinPasswordLength = 8
+	// bcrypt ignores everything after 72 bytes
+	MaxPasswordLength = 72
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,31}$`)
+
+// CustomerRequest is the body of a sign up
+type CustomerRequest struct {
+	Name      string  `json:"name"`
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Username  string  `json:"username"`
+	Email     string  `json:"email"`
+	Password  string  `json:"password"`
+}
+
+// UpdateCustomerRequest is the body of a profile update, passwords are changed through ChangePasswordRequest
+type UpdateCustomerRequest struct {
+	Name      string  `json:"name"`
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Username  string  `json:"username"`
+	Email     string  `json:"email"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+// DeleteAccountRequest confirms the deletion of the logged in customer's account with their password
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// CustomerResponse is what the API returns for a customer, it never includes the password hash
+type CustomerResponse struct {
+	ID              int64      `json:"id"`
+	Name            string     `json:"name"`
+	Address         string     `json:"address"`
+	Latitude        float64    `json:"latitude"`
+	Longitude       float64    `json:"longitude"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Role            string     `json:"role"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// NormalizeUsername trims and lowercases usernames so logins are case insensitive
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (c *Customer) ToResponse() CustomerResponse {
+	return CustomerResponse{
+		ID:              c.ID,
+		Name:            c.Name,
+		Address:         c.Address,
+		Latitude:        c.Latitude,
+		Longitude:       c.Longitude,
+		Username:        c.Username,
+		Email:           c.Email,
+		EmailVerifiedAt: c.EmailVerifiedAt,
+		Role:            c.Role,
+		CreatedAt:       c.CreatedAt,
+		UpdatedAt:       c.UpdatedAt,
+	}
+}
+
+func ToResponses(customers []Customer) []CustomerResponse {
+	responses := make([]CustomerResponse, 0, len(customers))
+	for i := range customers {
+		responses = append(responses, customers[i].ToResponse())
+	}
+	return responses
+}
+
+// ToCustomer normalises the request into a new customer
+func (r *CustomerRequest) ToCustomer() *Customer {
+	return &Customer{
+		Name:      strings.TrimSpace(r.Name),
+		Address:   strings.TrimSpace(r.Address),
+		Latitude:  r.Latitude,
+		Longitude: r.Longitude,
+		Username:  NormalizeUsername(r.Username),
+		Email:     NormalizeEmail(r.Email),
+		Password:  r.Password,
+	}
+}
+
+// NewUpdateCustomerRequest starts an update from the current profile, so fields left out of the body are kept
+func NewUpdateCustomerRequest(c *Customer) UpdateCustomerRequest {
+	return UpdateCustomerRequest{
+		Name:      c.Name,
+		Address:   c.Address,
+		Latitude:  c.Latitude,
+		Longitude: c.Longitude,
+		Username:  c.Username,
+		Email:     c.Email,
+	}
+}
+
+// Apply copies the normalised profile fields onto the customer
+func (r *UpdateCustomerRequest) Apply(c *Customer) {
+	c.Name = strings.TrimSpace(r.Name)
+	c.Address = strings.TrimSpace(r.Address)
+	c.Latitude = r.Latitude
+	c.Longitude = r.Longitude
+	c.Username = NormalizeUsername(r.Username)
+	c.Email = NormalizeEmail(r.Email)
+}
+
+// Validate returns the problems with the sign up keyed by field
+func (r *CustomerRequest) Validate() map[string]string {
+	errorMessages := validateProfile(r.Name, r.Username, r.Email, r.Latitude, r.Longitude)
+	if message := ValidatePassword(r.Password, NormalizeUsername(r.Username)); message != "" {
+		errorMessages["password"] = message
+	}
+
+	return errorMessages
+}
+
+// Validate returns the problems with the profile update keyed by field
+func (r *UpdateCustomerRequest) Validate() map[string]string {
+	return validateProfile(r.Name, r.Username, r.Email, r.Latitude, r.Longitude)
+}
+
+func validateProfile(name string, username string, email string, latitude float64, longitude float64) map[string]string {
+	errorMessages := map[string]string{}
+
+	if strings.TrimSpace(name) == "" {
+		errorMessages["name"] = "name is required"
+	}
+	if !usernamePattern.MatchString(NormalizeUsername(username)) {
+		errorMessages["username"] = "username must be 3 to 32 characters of letters, digits, dots, dashes or underscores"
+	}
+	if email = NormalizeEmail(email); email != "" {
+		if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+			errorMessages["email"] = "email is not a valid address"
+		}
+	}
+	if latitude < -90 || latitude > 90 {
+		errorMessages["latitude"] = "latitude must be between -90 and 90"
+	}
+	if longitude < -180 || longitude > 180 {
+		errorMessages["longitude"] = "longitude must be between -180 and 180"
+	}
+
+	return errorMessages
+}
+
+// ValidatePassword applies the password strength rules and returns what is wrong, or an empty string
+func ValidatePassword(password string, username string) string {
+	if len(password) < MinPasswordLength {
+		return "password must be at least 8 characters"
+	}
+	if len(password) > MaxPasswordLength {
+		return "password must be at most 72 bytes"
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return "password must contain at least one letter and one digit"
+	}
+
+	if username != "" && strings.Contains(strings.ToLower(password), username) {
+		return "password must not contain the username"
+	}
+
+	return ""
+}
@@ -1,6 +1,8 @@
 package customer_entity
 
 import (
+	"time"
+
 	"github.com/harisquqo/quqo-challenge-1/domain/entity"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/utils/security"
 	"gorm.io/gorm"
@@ -15,13 +17,21 @@ type Customer struct {
 	Latitude float64 `gorm:"type:numeric;not null;" json:"latitude"`
 	Longitude float64 `gorm:"type:numeric;not null;" json:"longitude"`
 	Username string `gorm:"size:255;not null;unique" json:"username"`
-	Password string `gorm:"size:255;not null;" json:"password"`
+	Email string `gorm:"size:255;index:idx_customers_email,unique,where:email <> ''" json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Password string `gorm:"size:255;not null;" json:"-"`
+	Role string `gorm:"size:50;not null;default:customer;" json:"role"`
+}
+
+type UpdateRole struct {
+	Role string `json:"role"`
 }
 
 
 
-//BeforeSave is a gorm hook
-func (c *Customer) BeforeSave(tx *gorm.DB) error {
+//BeforeCreate is a gorm hook. Only new customers carry a plain password, updates store hashes
+//through UpdatePassword, so hashing on every save would hash the hash again
+func (c *Customer) BeforeCreate(tx *gorm.DB) error {
 	hashPassword, err := security.Hash(c.Password)
 	if err != nil {
 		return err
@@ -2,11 +2,14 @@ package inventory_entity
 
 import "github.com/harisquqo/quqo-challenge-1/domain/entity"
 
+// Inventory is the stock a warehouse holds of a product, a product has a row for every warehouse stocking it
 type Inventory struct {
 	entity.BaseModelWDelete
 	ProductID uint64 `gorm:"primary_key;not null;auto_increment:false;" json:"product_id"`
-	WarehouseID uint64 `gorm:"size:100;not null;" json:"warehouse_id"`
+	WarehouseID uint64 `gorm:"primary_key;not null;auto_increment:false;" json:"warehouse_id"`
 	Stock int `gorm:"size:255;not null;" json:"stock"`
+	// Free text location for products not yet put away in bins
+	Location string `gorm:"size:50;not null;default:'';" json:"location"`
 }
 
 
@@ -17,6 +20,11 @@ type InventoryLog struct {
 	WarehouseID uint64 `gorm:"size:100;not null;" json:"warehouse_id"`
 	StockChange int `gorm:"size:255;not null;" json:"stock_change"`
 	Reason string `gorm:"size:255;not null;" json:"reason"`
+	BundleID uint64 `gorm:"default:0;" json:"bundle_id,omitempty"`
+	// Bin movements and returned units not put back on sale, StockChange stays 0 when the sellable stock does not change
+	Quantity int `gorm:"default:0;" json:"quantity,omitempty"`
+	FromBinID uint64 `gorm:"default:0;" json:"from_bin_id,omitempty"`
+	ToBinID uint64 `gorm:"default:0;" json:"to_bin_id,omitempty"`
 }
 
 // Create Product, Update Inventory, Update
\ No newline at end of file
@@ -0,0 +1,158 @@
+package loyalty_entity
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+)
+
+const (
+	EntryEarn    = "earn"
+	EntryVest    = "vest"
+	EntryRedeem  = "redeem"
+	EntryRestore = "restore"
+	EntryReverse = "reverse"
+	EntryExpire  = "expire"
+
+	DefaultPointsPerUnit = 1.0
+	DefaultPointValue    = 0.01
+	DefaultExpiryDays    = 365
+)
+
+// LoyaltyAccount holds the points of a customer. Pending points were earned on orders still inside
+// their return window, only available points can be redeemed. Both always equal what the account's
+// entries add up to, they are kept on the account so redemptions can check them atomically
+type LoyaltyAccount struct {
+	entity.BaseModelWOutID
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	CustomerID int64 `gorm:"not null;uniqueIndex;" json:"customer_id"`
+	Available int64 `gorm:"not null;default:0;" json:"available"`
+	Pending int64 `gorm:"not null;default:0;" json:"pending"`
+}
+
+// PointsEntry is a line of the points ledger, it is never deleted. Points moves the available balance,
+// except for earn entries, which add to the pending balance until their vest entry moves them over.
+// Earn and restore entries are lots: Remaining is how much of them is left to spend, reverse or
+// expire, spending always takes from the lots expiring first
+type PointsEntry struct {
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	AccountID uint64 `gorm:"not null;index;" json:"account_id"`
+	Type string `gorm:"size:20;not null;index;" json:"type"`
+	Points int64 `gorm:"not null;" json:"points"`
+	AvailableAfter int64 `gorm:"not null;" json:"available_after"`
+	PendingAfter int64 `gorm:"not null;" json:"pending_after"`
+	OrderID int64 `gorm:"default:0;index;" json:"order_id,omitempty"`
+	ReturnID uint64 `gorm:"default:0;" json:"return_id,omitempty"`
+	LotID uint64 `gorm:"default:0;" json:"lot_id,omitempty"`
+	Remaining int64 `gorm:"default:0;" json:"remaining,omitempty"`
+	Vested bool `gorm:"default:false;" json:"vested,omitempty"`
+	VestsAt *time.Time `json:"vests_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Description string `gorm:"size:255;" json:"description,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoyaltySettings are how points are earned, what they are worth and how long they last
+type LoyaltySettings struct {
+	PointsPerUnit float64 `json:"points_per_unit"`
+	PointValue float64 `json:"point_value"`
+	ExpiryDays int `json:"expiry_days"`
+}
+
+// LoyaltySummary is the account of a customer with what its points are worth
+type LoyaltySummary struct {
+	LoyaltyAccount
+	AvailableValue float64 `json:"available_value"`
+	Settings LoyaltySettings `json:"settings"`
+}
+
+// EarnedPoints works out the points an order earns, rounded down. Items earn on what was paid for
+// them, so the share of the order paid with points earns nothing. multipliers are keyed by product,
+// a product missing from them earns at the usual rate
+func (s LoyaltySettings) EarnedPoints(order *order_entity.Order, multipliers map[int64]float64) int64 {
+	if order.TotalCost <= 0 {
+		return 0
+	}
+
+	paidShare := math.Max(1-order.PointsDiscount/order.TotalCost, 0)
+	points := 0.0
+	for _, item := range order.OrderedItems {
+		multiplier, ok := multipliers[item.ProductID]
+		if !ok {
+			multiplier = 1
+		}
+		points += item.TotalPrice * paidShare * s.PointsPerUnit * multiplier
+	}
+
+	// Keep float noise from costing a point
+	return int64(math.Floor(points + 1e-9))
+}
+
+// Discount is what the points take off an order
+func (s LoyaltySettings) Discount(points int64) float64 {
+	return math.Round(float64(points)*s.PointValue*100) / 100
+}
+
+// PointsFor is the most points that can go towards an amount without exceeding it
+func (s LoyaltySettings) PointsFor(amount float64) int64 {
+	if s.PointValue <= 0 {
+		return 0
+	}
+	return int64(math.Floor(amount/s.PointValue + 1e-9))
+}
+
+// Summary adds what the available points are worth to the account
+func (s LoyaltySettings) Summary(account *LoyaltyAccount) LoyaltySummary {
+	return LoyaltySummary{
+		LoyaltyAccount: *account,
+		AvailableValue: s.Discount(account.Available),
+		Settings:       s,
+	}
+}
+
+// IsLot reports whether the entry holds points that can later be spent, reversed or expire
+func (e *PointsEntry) IsLot() bool {
+	return e.Type == EntryEarn || e.Type == EntryRestore
+}
+
+// Expired reports whether the lot has points left that can no longer be spent
+func (e *PointsEntry) Expired(now time.Time) bool {
+	return e.IsLot() && e.Vested && e.Remaining > 0 && e.ExpiresAt != nil && !e.ExpiresAt.After(now)
+}
+
+// SortLotsForSpending puts lots in the order points are spent from them, the ones expiring first
+// first and lots that never expire last
+func SortLotsForSpending(lots []PointsEntry) {
+	sort.Slice(lots, func(i, j int) bool {
+		a, b := lots[i].ExpiresAt, lots[j].ExpiresAt
+		switch {
+		case a == nil && b == nil:
+			return lots[i].ID < lots[j].ID
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		case !a.Equal(*b):
+			return a.Before(*b)
+		}
+		return lots[i].ID < lots[j].ID
+	})
+}
+
+// TakePoints spends points from the lots in their order, lowering what each has left,
+// and returns the points the lots could not cover
+func TakePoints(lots []PointsEntry, points int64) int64 {
+	left := points
+	for i := range lots {
+		if left == 0 {
+			break
+		}
+		taken := min(left, lots[i].Remaining)
+		lots[i].Remaining -= taken
+		left -= taken
+	}
+	return left
+}
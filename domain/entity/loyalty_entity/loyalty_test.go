@@ -0,0 +1,114 @@
+package loyalty_entity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var lotsNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func expiresIn(d time.Duration) *time.Time {
+	at := lotsNow.Add(d)
+	return &at
+}
+
+func TestSortLotsForSpending(t *testing.T) {
+	lots := []PointsEntry{
+		{ID: 1, Type: EntryEarn, Remaining: 10, ExpiresAt: expiresIn(30 * 24 * time.Hour)},
+		{ID: 2, Type: EntryRestore, Remaining: 10},
+		{ID: 3, Type: EntryEarn, Remaining: 10, ExpiresAt: expiresIn(24 * time.Hour)},
+		{ID: 4, Type: EntryEarn, Remaining: 10, ExpiresAt: expiresIn(24 * time.Hour)},
+		{ID: 5, Type: EntryEarn, Remaining: 10},
+	}
+
+	SortLotsForSpending(lots)
+
+	var order []uint64
+	for _, lot := range lots {
+		order = append(order, lot.ID)
+	}
+	if want := []uint64{3, 4, 1, 2, 5}; !reflect.DeepEqual(order, want) {
+		t.Errorf("spending order = %v, want %v", order, want)
+	}
+}
+
+func TestTakePoints(t *testing.T) {
+	tests := []struct {
+		name          string
+		remaining     []int64
+		points        int64
+		wantRemaining []int64
+		wantLeft      int64
+	}{
+		{"first lot covers it", []int64{50, 50}, 30, []int64{20, 50}, 0},
+		{"spills over to the next lot", []int64{50, 50}, 70, []int64{0, 30}, 0},
+		{"empties every lot", []int64{50, 50}, 100, []int64{0, 0}, 0},
+		{"not enough points", []int64{50, 50}, 120, []int64{0, 0}, 20},
+		{"empty lots are skipped", []int64{0, 40}, 10, []int64{0, 30}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lots := make([]PointsEntry, len(tt.remaining))
+			for i, remaining := range tt.remaining {
+				lots[i] = PointsEntry{ID: uint64(i + 1), Type: EntryEarn, Remaining: remaining}
+			}
+
+			left := TakePoints(lots, tt.points)
+			if left != tt.wantLeft {
+				t.Errorf("left = %v, want %v", left, tt.wantLeft)
+			}
+			for i, lot := range lots {
+				if lot.Remaining != tt.wantRemaining[i] {
+					t.Errorf("lot %v remaining = %v, want %v", lot.ID, lot.Remaining, tt.wantRemaining[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSpendTakesExpiringLotsFirst(t *testing.T) {
+	lots := []PointsEntry{
+		{ID: 1, Type: EntryEarn, Vested: true, Remaining: 100, ExpiresAt: expiresIn(60 * 24 * time.Hour)},
+		{ID: 2, Type: EntryRestore, Vested: true, Remaining: 40, ExpiresAt: expiresIn(2 * 24 * time.Hour)},
+	}
+
+	SortLotsForSpending(lots)
+	if left := TakePoints(lots, 60); left != 0 {
+		t.Fatalf("left = %v, want 0", left)
+	}
+
+	// The lot about to expire is used up, the rest comes from the later one
+	remaining := map[uint64]int64{}
+	for _, lot := range lots {
+		remaining[lot.ID] = lot.Remaining
+	}
+	if remaining[2] != 0 || remaining[1] != 80 {
+		t.Errorf("remaining = %v, want lot 2 spent and 80 left on lot 1", remaining)
+	}
+}
+
+func TestPointsEntryExpired(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry PointsEntry
+		want  bool
+	}{
+		{"past its expiry", PointsEntry{Type: EntryEarn, Vested: true, Remaining: 10, ExpiresAt: expiresIn(-time.Hour)}, true},
+		{"expires right now", PointsEntry{Type: EntryRestore, Vested: true, Remaining: 10, ExpiresAt: expiresIn(0)}, true},
+		{"not yet", PointsEntry{Type: EntryEarn, Vested: true, Remaining: 10, ExpiresAt: expiresIn(time.Hour)}, false},
+		{"spent already", PointsEntry{Type: EntryEarn, Vested: true, Remaining: 0, ExpiresAt: expiresIn(-time.Hour)}, false},
+		{"still pending", PointsEntry{Type: EntryEarn, Vested: false, Remaining: 10, ExpiresAt: expiresIn(-time.Hour)}, false},
+		{"never expires", PointsEntry{Type: EntryEarn, Vested: true, Remaining: 10}, false},
+		{"not a lot", PointsEntry{Type: EntryRedeem, Vested: true, Remaining: 10, ExpiresAt: expiresIn(-time.Hour)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.Expired(lotsNow); got != tt.want {
+				t.Errorf("Expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,28 @@
 package order_entity
 
 import (
+	"time"
+
 	"github.com/harisquqo/quqo-challenge-1/domain/entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/ordereditem_entity"
 )
 
+const (
+	// OrderStatusAwaitingPayment orders are confirmed, and become pending, once their payment is authorized
+	OrderStatusAwaitingPayment = "awaiting_payment"
+	OrderStatusPending         = "pending"
+	OrderStatusCancelled       = "cancelled"
+	OrderStatusPicking         = "picking"
+	OrderStatusPicked          = "picked"
+	OrderStatusPacked          = "packed"
+	OrderStatusShipped         = "shipped"
+	OrderStatusDelivered       = "delivered"
+	OrderStatusReturned        = "returned"
+)
+
+// ClosedStatuses are the statuses an order no longer moves on from
+var ClosedStatuses = []string{OrderStatusDelivered, OrderStatusCancelled, OrderStatusReturned}
+
 type Order struct {
 	entity.BaseModelWDelete
 	ID uint64 `json:"id"`
@@ -14,6 +32,18 @@ type Order struct {
 	CustomerID int64 `gorm:"not null;" json:"customer_id"`
 	WarehouseID uint64 `gorm:"size:100;not null;" json:"warehouse_id"`
 	Status string `gorm:"size:255;not null;" json:"status"`
+	AddressID uint64 `gorm:"default:0;" json:"address_id"`
+	DeliveryAddress DeliveryAddress `gorm:"embedded;embeddedPrefix:delivery_" json:"delivery_address"`
+	FulfilmentGroup string `gorm:"size:64;index;" json:"fulfilment_group,omitempty"`
+	DeliverySlotID uint64 `gorm:"default:0;index;" json:"delivery_slot_id,omitempty"`
+	DeliveryStartsAt *time.Time `json:"delivery_starts_at,omitempty"`
+	DeliveryEndsAt *time.Time `json:"delivery_ends_at,omitempty"`
+	PackageCount int `gorm:"default:0;" json:"package_count,omitempty"`
+	PackageWeightKg float64 `gorm:"type:numeric;default:0;" json:"package_weight_kg,omitempty"`
+	PackedAt *time.Time `json:"packed_at,omitempty"`
+	WalletAmount float64 `gorm:"type:numeric;default:0;" json:"wallet_amount,omitempty"`
+	PointsRedeemed int64 `gorm:"default:0;" json:"points_redeemed,omitempty"`
+	PointsDiscount float64 `gorm:"type:numeric;default:0;" json:"points_discount,omitempty"`
 	OrderedItems []ordereditem_entity.OrderedItem `gorm:"foreignKey:OrderID;references:ID" json:"ordered_items"`
 }
 
@@ -23,5 +53,64 @@ type RawOrder struct {
 	CustomerID int64 `gorm:"not null;" json:"customer_id"`
 	WarehouseID uint64 `gorm:"size:100;not null;" json:"warehouse_id"`
 	Status string `gorm:"size:255;not null;" json:"status"`
+	AddressID uint64 `json:"address_id"`
+	DeliverySlotID uint64 `json:"delivery_slot_id"`
+	UseWallet bool `json:"use_wallet"`
+	RedeemPoints int64 `json:"redeem_points"`
 	Products  map[string]int64 `json:"products"`
-}
\ No newline at end of file
+}
+
+// Fulfilment is the part of an order shipped from a single warehouse, an order whose items no
+// single warehouse has in stock is split into one order per fulfilment
+type Fulfilment struct {
+	WarehouseID uint64 `json:"warehouse_id"`
+	DistanceKm float64 `json:"distance_km"`
+	Products map[string]int64 `json:"products"`
+}
+
+// DeliveryAddress is a copy of the delivery address taken when the order is placed,
+// later changes to the customer's address book do not affect it
+type DeliveryAddress struct {
+	Label string `gorm:"size:100;" json:"label"`
+	ContactName string `gorm:"size:255;" json:"contact_name"`
+	Phone string `gorm:"size:50;" json:"phone"`
+	Address string `gorm:"size:255;" json:"address"`
+	Instructions string `gorm:"size:500;" json:"instructions"`
+	Latitude float64 `gorm:"type:numeric;" json:"latitude"`
+	Longitude float64 `gorm:"type:numeric;" json:"longitude"`
+}
+
+// DeliveryAddressColumns are never changed once the order is saved
+var DeliveryAddressColumns = []string{"address_id", "delivery_label", "delivery_contact_name", "delivery_phone",
+	"delivery_address", "delivery_instructions", "delivery_latitude", "delivery_longitude"}
+
+// DeliverySlotColumns only change through slot reservation, so the slot's count of places stays right
+var DeliverySlotColumns = []string{"delivery_slot_id", "delivery_starts_at", "delivery_ends_at"}
+
+// WalletColumns only change when the order is paid from the customer's wallet, so the ledger stays right
+var WalletColumns = []string{"wallet_amount"}
+
+// PointsColumns only change when loyalty points are redeemed on the order, so the points ledger stays right
+var PointsColumns = []string{"points_redeemed", "points_discount"}
+
+// StatusColumns only change through the order's lifecycle (payment, picking, shipping or cancelling),
+// so stock, slots, the wallet, points and payments follow every change
+var StatusColumns = []string{"status"}
+
+// OwnershipColumns tie the order to its customer and the warehouse it ships from, refunds and
+// stock follow them so they never change once the order is saved
+var OwnershipColumns = []string{"customer_id", "warehouse_id", "fulfilment_group"}
+
+// TotalColumns are worked out from the ordered items when the order is placed, refunds are capped by them
+var TotalColumns = []string{"total_cost", "total_fees", "total_checkout"}
+
+// FixedColumns are the columns a general order update leaves alone
+func FixedColumns() []string {
+	columns := append([]string{}, StatusColumns...)
+	columns = append(columns, OwnershipColumns...)
+	columns = append(columns, TotalColumns...)
+	columns = append(columns, DeliveryAddressColumns...)
+	columns = append(columns, DeliverySlotColumns...)
+	columns = append(columns, WalletColumns...)
+	return append(columns, PointsColumns...)
+}
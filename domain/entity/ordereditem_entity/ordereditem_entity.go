@@ -10,4 +10,15 @@ type OrderedItem struct {
 	Quantity int64 `gorm:"size:255;not null;" json:"quantity"` 
 	UnitPrice float64 `gorm:"type:numeric;not null;" json:"unit_price"`
 	TotalPrice float64 `gorm:"size:100;not null;" json:"total_price"`
+	Components []OrderedComponent `gorm:"foreignKey:OrderedItemID;references:ID" json:"components,omitempty"`
+}
+
+// OrderedComponent records what a bundle was made of when it was ordered, so stock goes back
+// to the components the customer received even if the bundle has been changed since
+type OrderedComponent struct {
+	entity.BaseModelWDelete
+	ID uint64 `json:"id"`
+	OrderedItemID uint64 `gorm:"index;not null;" json:"ordered_item_id"`
+	ComponentID uint64 `gorm:"not null;" json:"component_id"`
+	Quantity int64 `gorm:"not null;" json:"quantity"`
 }
\ No newline at end of file
@@ -0,0 +1,44 @@
+package entity
+
+import "strconv"
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Pagination describes one page of a list and the size of the whole list
+type Pagination struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// NewPagination parses page and page_size query values, falling back to the first page of the default size
+func NewPagination(page string, pageSize string) Pagination {
+	p, err := strconv.Atoi(page)
+	if err != nil || p < 1 {
+		p = 1
+	}
+
+	size, err := strconv.Atoi(pageSize)
+	if err != nil || size < 1 {
+		size = DefaultPageSize
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+
+	return Pagination{Page: p, PageSize: size}
+}
+
+func (p *Pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// SetTotal records the size of the whole list and the number of pages it makes
+func (p *Pagination) SetTotal(total int64) {
+	p.Total = total
+	p.TotalPages = int((total + int64(p.PageSize) - 1) / int64(p.PageSize))
+}
@@ -0,0 +1,144 @@
+package payment_entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+)
+
+const (
+	PaymentStatusAuthorized        = "authorized"
+	PaymentStatusDeclined          = "declined"
+	PaymentStatusCaptured          = "captured"
+	PaymentStatusVoided            = "voided"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+	PaymentStatusRefunded          = "refunded"
+
+	EventAuthorize = "authorize"
+	EventCapture   = "capture"
+	EventVoid      = "void"
+	EventRefund    = "refund"
+	EventReduce    = "reduce"
+	EventWebhook   = "webhook"
+
+	// Webhook event types sent by gateways
+	WebhookCaptured = "payment.captured"
+	WebhookVoided   = "payment.voided"
+	WebhookRefunded = "payment.refunded"
+)
+
+// Payment is the payment of an order through a gateway. Reference is the gateway's id for it, the
+// captured amount can be less than the authorized one and refunds never exceed what was captured
+type Payment struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	OrderID int64 `gorm:"not null;index;" json:"order_id"`
+	CustomerID int64 `gorm:"not null;index;" json:"customer_id"`
+	Gateway string `gorm:"size:50;not null;" json:"gateway"`
+	Reference string `gorm:"size:100;index;" json:"reference,omitempty"`
+	Status string `gorm:"size:30;not null;index;" json:"status"`
+	Amount float64 `gorm:"type:numeric;not null;" json:"amount"`
+	CapturedAmount float64 `gorm:"type:numeric;default:0;" json:"captured_amount"`
+	RefundedAmount float64 `gorm:"type:numeric;default:0;" json:"refunded_amount"`
+	DeclineReason string `gorm:"size:255;" json:"decline_reason,omitempty"`
+	AuthorizedAt *time.Time `json:"authorized_at,omitempty"`
+	CapturedAt *time.Time `json:"captured_at,omitempty"`
+	VoidedAt *time.Time `json:"voided_at,omitempty"`
+	Events []PaymentEvent `gorm:"foreignKey:PaymentID;references:ID" json:"events,omitempty"`
+}
+
+// PaymentEvent is a call made to the gateway or a webhook received from it. GatewayEventID is set for
+// webhooks, so a webhook delivered twice is only applied once
+type PaymentEvent struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	PaymentID uint64 `gorm:"not null;index;" json:"payment_id"`
+	Type string `gorm:"size:30;not null;" json:"type"`
+	Amount float64 `gorm:"type:numeric;default:0;" json:"amount"`
+	Success bool `gorm:"not null;" json:"success"`
+	Message string `gorm:"size:255;" json:"message,omitempty"`
+	GatewayEventID string `gorm:"size:100;index;" json:"gateway_event_id,omitempty"`
+	RecordedBy int64 `gorm:"default:0;" json:"recorded_by,omitempty"`
+	RecordedAt time.Time `gorm:"not null;" json:"recorded_at"`
+}
+
+// GatewayResult is the answer of a gateway to a call. A declined call is not an error, Success is
+// false and Message says why
+type GatewayResult struct {
+	Reference string
+	Success bool
+	Message string
+	Amount float64
+}
+
+// WebhookEvent is a verified notification from a gateway about one of its payments
+type WebhookEvent struct {
+	ID string `json:"id"`
+	Type string `json:"type"`
+	Reference string `json:"reference"`
+	Amount float64 `json:"amount"`
+}
+
+// PaymentRequest authorizes the payment of an order with a payment method tokenized by the gateway
+type PaymentRequest struct {
+	Token string `json:"token"`
+}
+
+// AmountRequest captures or refunds part of a payment, leaving out the amount takes all that is left
+type AmountRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *PaymentRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if strings.TrimSpace(r.Token) == "" {
+		errorMessages["token"] = "token is required"
+	}
+
+	return errorMessages
+}
+
+// Validate checks the amount against what is left to take, keyed by field
+func (r *AmountRequest) Validate(left float64) map[string]string {
+	errorMessages := map[string]string{}
+	if r.Amount < 0 {
+		errorMessages["amount"] = "amount cannot be negative"
+	}
+	if r.Amount > left {
+		errorMessages["amount"] = fmt.Sprintf("amount cannot be more than %.2f", left)
+	}
+	if len(r.Reason) > 255 {
+		errorMessages["reason"] = "reason must be at most 255 characters"
+	}
+
+	return errorMessages
+}
+
+// RefundableAmount is how much of the captured amount has not been refunded
+func (p *Payment) RefundableAmount() float64 {
+	return p.CapturedAmount - p.RefundedAmount
+}
+
+// HeldAmount is the money of the customer the payment still holds, what is authorized or captured and not refunded
+func (p *Payment) HeldAmount() float64 {
+	switch p.Status {
+	case PaymentStatusAuthorized:
+		return p.Amount
+	case PaymentStatusCaptured, PaymentStatusPartiallyRefunded:
+		return p.RefundableAmount()
+	}
+	return 0
+}
+
+// ApplyRefund adds a refund to the payment, a payment refunded in full is refunded
+func (p *Payment) ApplyRefund(amount float64) {
+	p.RefundedAmount += amount
+	p.Status = PaymentStatusPartiallyRefunded
+	if p.RefundedAmount >= p.CapturedAmount {
+		p.Status = PaymentStatusRefunded
+	}
+}
@@ -0,0 +1,129 @@
+package picking_entity
+
+import (
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+)
+
+const (
+	PickListStatusOpen      = "open"
+	PickListStatusPicked    = "picked"
+	PickListStatusCompleted = "completed"
+
+	PickItemStatusPending = "pending"
+	PickItemStatusPicked  = "picked"
+	PickItemStatusShort   = "short"
+
+	DefaultPickListOrders = 20
+	MaxPickListOrders     = 100
+)
+
+// PickList batches the items of several orders of one warehouse into a single walk of the shelves
+type PickList struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WarehouseID uint64 `gorm:"not null;index;" json:"warehouse_id"`
+	Status string `gorm:"size:20;not null;index;" json:"status"`
+	CreatedBy int64 `gorm:"default:0;" json:"created_by"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Items []PickItem `gorm:"foreignKey:PickListID;references:ID" json:"items"`
+}
+
+// PickItem is a product to take off the shelf for one order. A bundle is picked as its components,
+// each item remembering how many units go into one bundle. RemovedQuantity is taken off the order
+// after a short pick, so Quantity - RemovedQuantity is what is still to be picked. A product spread
+// over several bins is split into one item per bin, Sequence is the item's place on the walk
+type PickItem struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	PickListID uint64 `gorm:"not null;index;" json:"pick_list_id"`
+	OrderID int64 `gorm:"not null;index;" json:"order_id"`
+	OrderedItemID uint64 `gorm:"not null;index;" json:"ordered_item_id"`
+	ProductID int64 `gorm:"not null;" json:"product_id"`
+	BundleID uint64 `gorm:"default:0;" json:"bundle_id,omitempty"`
+	UnitsPerBundle int64 `gorm:"default:0;" json:"units_per_bundle,omitempty"`
+	BinID uint64 `gorm:"default:0;" json:"bin_id,omitempty"`
+	Location string `gorm:"size:70;" json:"location"`
+	Sequence int `gorm:"default:0;" json:"sequence"`
+	Quantity int64 `gorm:"not null;" json:"quantity"`
+	PickedQuantity int64 `gorm:"default:0;" json:"picked_quantity"`
+	RemovedQuantity int64 `gorm:"default:0;" json:"removed_quantity"`
+	Status string `gorm:"size:20;not null;" json:"status"`
+	PickedBy int64 `gorm:"default:0;" json:"picked_by,omitempty"`
+	PickedAt *time.Time `json:"picked_at,omitempty"`
+}
+
+// PickListRequest names the orders to pick, or leaves them out to take the oldest pending orders
+type PickListRequest struct {
+	OrderIDs []int64 `json:"order_ids"`
+	MaxOrders int `json:"max_orders"`
+}
+
+// PickConfirmation is how many units were found, leaving it out means all of them
+type PickConfirmation struct {
+	PickedQuantity *int64 `json:"picked_quantity"`
+}
+
+type PackRequest struct {
+	PackageCount int `json:"package_count"`
+	WeightKg float64 `json:"weight_kg"`
+}
+
+// ToPick returns how many units are still wanted for the order
+func (i *PickItem) ToPick() int64 {
+	return i.Quantity - i.RemovedQuantity
+}
+
+// Confirm records the units found, the item is short when fewer than wanted were found.
+// It returns how many units were short
+func (i *PickItem) Confirm(picked int64, staffId int64, at time.Time) int64 {
+	short := i.ToPick() - picked
+	i.PickedQuantity = picked
+	i.PickedBy = staffId
+	i.PickedAt = &at
+	i.Status = PickItemStatusPicked
+	if short > 0 {
+		i.Status = PickItemStatusShort
+	}
+	return short
+}
+
+// OrderPicked reports whether every item of the order on the list is confirmed, and how many units
+// the order still has. An order left with none lost all of them to short picks
+func (l *PickList) OrderPicked(orderId int64) (bool, int64) {
+	var remaining int64
+	for _, item := range l.Items {
+		if item.OrderID != orderId {
+			continue
+		}
+		if item.Status == PickItemStatusPending {
+			return false, 0
+		}
+		remaining += item.ToPick()
+	}
+	return true, remaining
+}
+
+// Picked reports whether every item on the list is confirmed
+func (l *PickList) Picked() bool {
+	for _, item := range l.Items {
+		if item.Status == PickItemStatusPending {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate returns the problems with the packing details keyed by field
+func (r *PackRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if r.PackageCount <= 0 {
+		errorMessages["package_count"] = "package_count must be greater than 0"
+	}
+	if r.WeightKg <= 0 {
+		errorMessages["weight_kg"] = "weight_kg must be greater than 0"
+	}
+
+	return errorMessages
+}
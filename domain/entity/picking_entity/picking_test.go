@@ -0,0 +1,99 @@
+package picking_entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPickItemConfirm(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		item       PickItem
+		picked     int64
+		wantShort  int64
+		wantStatus string
+	}{
+		{"all found", PickItem{Quantity: 3}, 3, 0, PickItemStatusPicked},
+		{"some missing", PickItem{Quantity: 3}, 1, 2, PickItemStatusShort},
+		{"none found", PickItem{Quantity: 3}, 0, 3, PickItemStatusShort},
+		{"units already taken off by a short component", PickItem{Quantity: 4, RemovedQuantity: 2}, 2, 0, PickItemStatusPicked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := tt.item
+			item.Status = PickItemStatusPending
+			short := item.Confirm(tt.picked, 5, at)
+			if short != tt.wantShort {
+				t.Errorf("short = %v, want %v", short, tt.wantShort)
+			}
+			if item.Status != tt.wantStatus {
+				t.Errorf("status = %v, want %v", item.Status, tt.wantStatus)
+			}
+			if item.PickedQuantity != tt.picked || item.PickedBy != 5 || item.PickedAt == nil || !item.PickedAt.Equal(at) {
+				t.Errorf("confirmation not recorded: %+v", item)
+			}
+		})
+	}
+}
+
+func TestPickCompletion(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	// Order 1 has two items, order 2 one
+	list := PickList{Items: []PickItem{
+		{ID: 1, OrderID: 1, Quantity: 2, Status: PickItemStatusPending},
+		{ID: 2, OrderID: 1, Quantity: 1, Status: PickItemStatusPending},
+		{ID: 3, OrderID: 2, Quantity: 4, Status: PickItemStatusPending},
+	}}
+
+	steps := []struct {
+		name          string
+		index         int
+		picked        int64
+		removed       int64
+		order         int64
+		wantDone      bool
+		wantRemaining int64
+		wantListDone  bool
+	}{
+		{"order waits for its other item", 0, 2, 0, 1, false, 0, false},
+		{"order picked once every item is confirmed", 1, 1, 0, 1, true, 3, false},
+		{"an order losing every unit is left with none", 2, 0, 4, 2, true, 0, true},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			item := &list.Items[step.index]
+			item.Confirm(step.picked, 5, at)
+			// A short pick takes the missing units off the order
+			item.RemovedQuantity += step.removed
+
+			done, remaining := list.OrderPicked(step.order)
+			if done != step.wantDone || remaining != step.wantRemaining {
+				t.Errorf("OrderPicked(%v) = %v, %v, want %v, %v", step.order, done, remaining, step.wantDone, step.wantRemaining)
+			}
+			if got := list.Picked(); got != step.wantListDone {
+				t.Errorf("Picked() = %v, want %v", got, step.wantListDone)
+			}
+		})
+	}
+}
+
+func TestOrderPickedIgnoresOtherOrders(t *testing.T) {
+	list := PickList{Items: []PickItem{
+		{OrderID: 1, Quantity: 2, Status: PickItemStatusPicked},
+		{OrderID: 2, Quantity: 1, Status: PickItemStatusPending},
+	}}
+
+	if done, remaining := list.OrderPicked(1); !done || remaining != 2 {
+		t.Errorf("OrderPicked(1) = %v, %v, want true, 2", done, remaining)
+	}
+	if done, _ := list.OrderPicked(2); done {
+		t.Error("OrderPicked(2) = true with an item still pending")
+	}
+	if list.Picked() {
+		t.Error("Picked() = true with an item still pending")
+	}
+}
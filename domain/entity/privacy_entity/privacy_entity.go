@@ -0,0 +1,65 @@
+package privacy_entity
+
+import (
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/loyalty_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/payment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/return_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/wallet_entity"
+)
+
+const AuditCustomerErased = "customer_erased"
+
+// ErasedName replaces the name of an erased customer on the profile and on the orders
+const ErasedName = "Erased customer"
+
+// RetainedRecords are the records kept when a customer is erased and why, they hold amounts and references but no personal details
+var RetainedRecords = map[string]string{
+	"orders":              "kept for accounting, the delivery details are anonymised",
+	"wallet_transactions": "kept for accounting, the store credit ledger must balance",
+	"points_entries":      "kept for accounting, the points ledger must balance",
+	"payments":            "kept for accounting and chargebacks, they hold the gateway reference and amounts, never card details",
+	"return_requests":     "kept for accounting, the customer's notes and comments are removed",
+	"shipments":           "kept for delivery records, the recipient, proof of delivery and locations are removed",
+}
+
+// CustomerDataExport is everything stored about a customer, as handed out for a data subject access request
+type CustomerDataExport struct {
+	ExportedAt         time.Time                         `json:"exported_at"`
+	Customer           customer_entity.CustomerResponse  `json:"customer"`
+	Addresses          []address_entity.Address          `json:"addresses"`
+	Orders             []order_entity.Order              `json:"orders"`
+	RefreshTokens      []auth_entity.RefreshToken        `json:"refresh_tokens"`
+	ActionTokens       []auth_entity.ActionToken         `json:"action_tokens"`
+	AuditEvents        []auth_entity.AuditEvent          `json:"audit_events"`
+	Wallet             *wallet_entity.Wallet             `json:"wallet"`
+	WalletTransactions []wallet_entity.WalletTransaction `json:"wallet_transactions"`
+	LoyaltyAccount     *loyalty_entity.LoyaltyAccount    `json:"loyalty_account"`
+	PointsEntries      []loyalty_entity.PointsEntry      `json:"points_entries"`
+	Returns            []return_entity.ReturnRequest     `json:"returns"`
+	Payments           []payment_entity.Payment          `json:"payments"`
+	Shipments          []shipment_entity.Shipment        `json:"shipments"`
+}
+
+// ErasureReport lists what was removed or anonymised when a customer was erased
+type ErasureReport struct {
+	CustomerID            int64             `json:"customer_id"`
+	Username              string            `json:"-"`
+	AddressIDs            []uint64          `json:"address_ids"`
+	OrderIDs              []uint64          `json:"order_ids"`
+	RefreshTokensDeleted  int64             `json:"refresh_tokens_deleted"`
+	ActionTokensDeleted   int64             `json:"action_tokens_deleted"`
+	AuditEventsAnonymised int64             `json:"audit_events_anonymised"`
+	ReturnIDs             []uint64          `json:"return_ids"`
+	ShipmentIDs           []uint64          `json:"shipment_ids"`
+	ProofFilesDeleted     int               `json:"proof_files_deleted"`
+	SearchDocsPurged      []string          `json:"search_docs_purged"`
+	Retained              map[string]string `json:"retained"`
+	ErasedAt              time.Time         `json:"erased_at"`
+}
@@ -0,0 +1,82 @@
+package product_entity
+
+import (
+	"math"
+	"sort"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
+)
+
+// BundleItem is a single component product inside a bundle product
+type BundleItem struct {
+	entity.BaseModelWDelete
+	ID          uint64 `json:"id"`
+	BundleID    uint64 `gorm:"not null;index" json:"bundle_id"`
+	ComponentID uint64 `gorm:"not null;" json:"component_id"`
+	Quantity    int64  `gorm:"not null;" json:"quantity"`
+}
+
+// BundleAvailability is the number of complete bundles that can be built from component stock.
+// A bundle ships whole from one warehouse, so it is worked out per warehouse and the total is their sum
+type BundleAvailability struct {
+	BundleID   uint64                  `json:"bundle_id"`
+	Available  int64                   `json:"available"`
+	Warehouses []WarehouseAvailability `json:"warehouses"`
+}
+
+// WarehouseAvailability is the number of bundles the component stock of one warehouse can build
+type WarehouseAvailability struct {
+	WarehouseID uint64                  `json:"warehouse_id"`
+	Available   int64                   `json:"available"`
+	Components  []ComponentAvailability `json:"components"`
+}
+
+type ComponentAvailability struct {
+	ComponentID uint64 `json:"component_id"`
+	Quantity    int64  `json:"quantity"`
+	Stock       int64  `json:"stock"`
+	Available   int64  `json:"available"`
+}
+
+// NewBundleAvailability works out the availability of a bundle in every warehouse stocking any of its components
+func NewBundleAvailability(bundleID uint64, items []BundleItem, inventories []inventory_entity.Inventory) BundleAvailability {
+	stock := map[uint64]map[uint64]int64{}
+	var warehouseIDs []uint64
+	for _, inventory := range inventories {
+		if stock[inventory.WarehouseID] == nil {
+			stock[inventory.WarehouseID] = map[uint64]int64{}
+			warehouseIDs = append(warehouseIDs, inventory.WarehouseID)
+		}
+		stock[inventory.WarehouseID][inventory.ProductID] += int64(inventory.Stock)
+	}
+	sort.Slice(warehouseIDs, func(i, j int) bool { return warehouseIDs[i] < warehouseIDs[j] })
+
+	availability := BundleAvailability{BundleID: bundleID, Warehouses: []WarehouseAvailability{}}
+	for _, warehouseID := range warehouseIDs {
+		warehouse := WarehouseAvailability{WarehouseID: warehouseID, Available: math.MaxInt64}
+		for _, item := range items {
+			componentStock := stock[warehouseID][item.ComponentID]
+			componentAvailable := int64(0)
+			if item.Quantity > 0 {
+				componentAvailable = componentStock / item.Quantity
+			}
+
+			warehouse.Components = append(warehouse.Components, ComponentAvailability{
+				ComponentID: item.ComponentID,
+				Quantity:    item.Quantity,
+				Stock:       componentStock,
+				Available:   componentAvailable,
+			})
+			warehouse.Available = min(warehouse.Available, componentAvailable)
+		}
+		if len(items) == 0 {
+			warehouse.Available = 0
+		}
+
+		availability.Warehouses = append(availability.Warehouses, warehouse)
+		availability.Available += warehouse.Available
+	}
+
+	return availability
+}
@@ -0,0 +1,94 @@
+package product_entity
+
+import (
+	"testing"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
+)
+
+func TestNewBundleAvailability(t *testing.T) {
+	// Two of product 1 and one of product 2 make a bundle
+	items := []BundleItem{{ComponentID: 1, Quantity: 2}, {ComponentID: 2, Quantity: 1}}
+
+	tests := []struct {
+		name        string
+		inventories []inventory_entity.Inventory
+		want        int64
+		byWarehouse map[uint64]int64
+	}{
+		{
+			name: "limited by the scarcest component",
+			inventories: []inventory_entity.Inventory{
+				{ProductID: 1, WarehouseID: 1, Stock: 10},
+				{ProductID: 2, WarehouseID: 1, Stock: 3},
+			},
+			want:        3,
+			byWarehouse: map[uint64]int64{1: 3},
+		},
+		{
+			name: "components split over warehouses build nothing",
+			inventories: []inventory_entity.Inventory{
+				{ProductID: 1, WarehouseID: 1, Stock: 10},
+				{ProductID: 2, WarehouseID: 2, Stock: 10},
+			},
+			want:        0,
+			byWarehouse: map[uint64]int64{1: 0, 2: 0},
+		},
+		{
+			name: "total is the sum over warehouses",
+			inventories: []inventory_entity.Inventory{
+				{ProductID: 1, WarehouseID: 1, Stock: 4},
+				{ProductID: 2, WarehouseID: 1, Stock: 5},
+				{ProductID: 1, WarehouseID: 2, Stock: 7},
+				{ProductID: 2, WarehouseID: 2, Stock: 1},
+			},
+			want:        3,
+			byWarehouse: map[uint64]int64{1: 2, 2: 1},
+		},
+		{
+			name:        "no stock anywhere",
+			want:        0,
+			byWarehouse: map[uint64]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewBundleAvailability(9, items, tt.inventories)
+			if got.Available != tt.want {
+				t.Errorf("Available = %v, want %v", got.Available, tt.want)
+			}
+			if len(got.Warehouses) != len(tt.byWarehouse) {
+				t.Fatalf("got %v warehouses, want %v", len(got.Warehouses), len(tt.byWarehouse))
+			}
+			for _, warehouse := range got.Warehouses {
+				if warehouse.Available != tt.byWarehouse[warehouse.WarehouseID] {
+					t.Errorf("warehouse %v Available = %v, want %v", warehouse.WarehouseID, warehouse.Available, tt.byWarehouse[warehouse.WarehouseID])
+				}
+				if len(warehouse.Components) != len(items) {
+					t.Errorf("warehouse %v has %v components, want %v", warehouse.WarehouseID, len(warehouse.Components), len(items))
+				}
+			}
+		})
+	}
+}
+
+func TestValidateType(t *testing.T) {
+	tests := []struct {
+		productType string
+		wantErr     bool
+	}{
+		{"", false},
+		{ProductTypeSingle, false},
+		{ProductTypeBundle, false},
+		{"kit", true},
+		{"Bundle", true},
+	}
+
+	for _, tt := range tests {
+		product := Product{Type: tt.productType}
+		if err := product.ValidateType(); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateType(%q) error = %v, wantErr %v", tt.productType, err, tt.wantErr)
+		}
+	}
+}
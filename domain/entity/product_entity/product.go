@@ -1,12 +1,19 @@
 package product_entity
 
 import (
+	"fmt"
+
 	"github.com/harisquqo/quqo-challenge-1/domain/entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/category_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/image_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
 )
 
+const (
+	ProductTypeSingle = "single"
+	ProductTypeBundle = "bundle"
+)
+
 type Product struct {
     entity.BaseModelWDelete
     ID          uint64 `json:"id"`
@@ -16,7 +23,23 @@ type Product struct {
     CategoryID  uint64 `gorm:"size:100;not null;" json:"category_id"`
     Category    category_entity.Category `gorm:"foreignKey:ID;references:CategoryID" json:"category"`
     Images      []image_entity.Image `gorm:"foreignKey:ProductID;references:ID" json:"images"`
-	Inventory	inventory_entity.Inventory `gorm:"foreignKey:ProductID;references:ID" json:"inventory"`
+	Inventories []inventory_entity.Inventory `gorm:"foreignKey:ProductID;references:ID" json:"inventories"`
+    Type        string `gorm:"size:20;not null;default:single" json:"type"`
+    BundleItems []BundleItem `gorm:"foreignKey:BundleID;references:ID" json:"bundle_items,omitempty"`
+}
+
+// IsBundle reports whether the product is made up of other products
+func (p *Product) IsBundle() bool {
+	return p.Type == ProductTypeBundle
+}
+
+// ValidateType checks the product is a single product or a bundle, a product saved without a type is single
+func (p *Product) ValidateType() error {
+	switch p.Type {
+	case "", ProductTypeSingle, ProductTypeBundle:
+		return nil
+	}
+	return fmt.Errorf("type must be %v or %v", ProductTypeSingle, ProductTypeBundle)
 }
 
 type ProductForInventory struct {
@@ -28,6 +51,8 @@ type ProductForInventory struct {
 	CategoryID uint64 `gorm:"size:100;not null;" json:"category_id"`
 	WarehouseID uint64 `gorm:"size:100;not null;" json:"warehouse_id"`
 	Stock int `gorm:"size:255;not null;" json:"stock"`
+	Type string `gorm:"size:20;not null;default:single" json:"type"`
+	BundleItems []BundleItem `json:"bundle_items"`
 }
 
 
@@ -0,0 +1,272 @@
+package return_entity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+)
+
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusCancelled = "cancelled"
+	ReturnStatusCompleted = "completed"
+
+	DispositionRestock          = "restock"
+	DispositionWriteOff         = "write_off"
+	DispositionReturnToSupplier = "return_to_supplier"
+
+	// ReturnWindowDays is how long after delivery items can be returned
+	ReturnWindowDays = 30
+)
+
+// ReturnReasons are the reasons items can be returned for, true when the fault is ours and the
+// delivery fees of the returned items are refunded as well
+var ReturnReasons = map[string]bool{
+	"damaged":          true,
+	"defective":        true,
+	"wrong_item":       true,
+	"not_as_described": true,
+	"changed_mind":     false,
+	"no_longer_needed": false,
+}
+
+// Dispositions are what can happen to returned units after inspection
+var Dispositions = []string{DispositionRestock, DispositionWriteOff, DispositionReturnToSupplier}
+
+// ReturnRequest asks to send items of a delivered order back. The refund is estimated when the
+// return is requested and settled on the units accepted at inspection
+type ReturnRequest struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	OrderID int64 `gorm:"not null;index;" json:"order_id"`
+	CustomerID int64 `gorm:"not null;index;" json:"customer_id"`
+	WarehouseID uint64 `gorm:"not null;index;" json:"warehouse_id"`
+	Status string `gorm:"size:20;not null;index;" json:"status"`
+	Note string `gorm:"size:500;" json:"note,omitempty"`
+	ReviewNote string `gorm:"size:500;" json:"review_note,omitempty"`
+	ItemsAmount float64 `gorm:"type:numeric;default:0;" json:"items_amount"`
+	DiscountAmount float64 `gorm:"type:numeric;default:0;" json:"discount_amount"`
+	FeesAmount float64 `gorm:"type:numeric;default:0;" json:"fees_amount"`
+	RefundAmount float64 `gorm:"type:numeric;default:0;" json:"refund_amount"`
+	ReviewedBy int64 `gorm:"default:0;" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	InspectedBy int64 `gorm:"default:0;" json:"inspected_by,omitempty"`
+	InspectedAt *time.Time `json:"inspected_at,omitempty"`
+	Items []ReturnItem `gorm:"foreignKey:ReturnID;references:ID" json:"items"`
+}
+
+// ReturnItem is a line of the order being returned. AcceptedQuantity and Disposition are set at inspection
+type ReturnItem struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	ReturnID uint64 `gorm:"not null;index;" json:"return_id"`
+	OrderedItemID uint64 `gorm:"not null;index;" json:"ordered_item_id"`
+	ProductID int64 `gorm:"not null;" json:"product_id"`
+	Quantity int64 `gorm:"not null;" json:"quantity"`
+	UnitPrice float64 `gorm:"type:numeric;not null;" json:"unit_price"`
+	Reason string `gorm:"size:30;not null;" json:"reason"`
+	Comment string `gorm:"size:500;" json:"comment,omitempty"`
+	AcceptedQuantity int64 `gorm:"default:0;" json:"accepted_quantity"`
+	Disposition string `gorm:"size:30;" json:"disposition,omitempty"`
+}
+
+type ReturnFilter struct {
+	CustomerID int64
+	OrderID int64
+	WarehouseID int64
+	Status string
+}
+
+type ReturnRequestInput struct {
+	Items []ReturnItemInput `json:"items"`
+	Note string `json:"note"`
+}
+
+type ReturnItemInput struct {
+	OrderedItemID uint64 `json:"ordered_item_id"`
+	Quantity int64 `json:"quantity"`
+	Reason string `json:"reason"`
+	Comment string `json:"comment"`
+}
+
+type ReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// InspectionRequest records what came back for every item of the return, units not accepted are
+// not refunded and do not touch the inventory
+type InspectionRequest struct {
+	Items []InspectionItem `json:"items"`
+	Note string `json:"note"`
+}
+
+type InspectionItem struct {
+	ReturnItemID uint64 `json:"return_item_id"`
+	AcceptedQuantity int64 `json:"accepted_quantity"`
+	Disposition string `json:"disposition"`
+}
+
+// Refund is how much of the order is paid back for some of its units
+type Refund struct {
+	ItemsAmount float64 `json:"items_amount"`
+	DiscountAmount float64 `json:"discount_amount"`
+	FeesAmount float64 `json:"fees_amount"`
+	Total float64 `json:"total"`
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *ReturnRequestInput) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if len(r.Items) == 0 {
+		errorMessages["items"] = "at least one item is required"
+	}
+	seen := map[uint64]bool{}
+	for i, item := range r.Items {
+		field := fmt.Sprintf("items[%v]", i)
+		switch {
+		case item.OrderedItemID == 0:
+			errorMessages[field] = "ordered_item_id is required"
+		case seen[item.OrderedItemID]:
+			errorMessages[field] = fmt.Sprintf("ordered item %v is given more than once", item.OrderedItemID)
+		case item.Quantity <= 0:
+			errorMessages[field] = "quantity must be greater than 0"
+		case !isReturnReason(item.Reason):
+			errorMessages[field] = "reason must be one of " + strings.Join(ReturnReasonNames(), ", ")
+		case len(item.Comment) > 500:
+			errorMessages[field] = "comment must be at most 500 characters"
+		}
+		seen[item.OrderedItemID] = true
+	}
+	if len(r.Note) > 500 {
+		errorMessages["note"] = "note must be at most 500 characters"
+	}
+
+	return errorMessages
+}
+
+// Validate returns the problems with the review keyed by field
+func (r *ReviewRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if len(r.Note) > 500 {
+		errorMessages["note"] = "note must be at most 500 characters"
+	}
+
+	return errorMessages
+}
+
+// Validate checks the inspection covers every item of the return once, keyed by field
+func (r *InspectionRequest) Validate(items []ReturnItem) map[string]string {
+	errorMessages := map[string]string{}
+	quantities := map[uint64]int64{}
+	for _, item := range items {
+		quantities[item.ID] = item.Quantity
+	}
+
+	seen := map[uint64]bool{}
+	for i, inspected := range r.Items {
+		field := fmt.Sprintf("items[%v]", i)
+		quantity, ok := quantities[inspected.ReturnItemID]
+		switch {
+		case !ok:
+			errorMessages[field] = fmt.Sprintf("return item %v is not part of the return", inspected.ReturnItemID)
+		case seen[inspected.ReturnItemID]:
+			errorMessages[field] = fmt.Sprintf("return item %v is given more than once", inspected.ReturnItemID)
+		case inspected.AcceptedQuantity < 0 || inspected.AcceptedQuantity > quantity:
+			errorMessages[field] = fmt.Sprintf("accepted_quantity must be between 0 and %v", quantity)
+		case inspected.AcceptedQuantity > 0 && !isDisposition(inspected.Disposition):
+			errorMessages[field] = "disposition must be one of " + strings.Join(Dispositions, ", ")
+		}
+		seen[inspected.ReturnItemID] = true
+	}
+	for _, item := range items {
+		if !seen[item.ID] {
+			errorMessages["items"] = fmt.Sprintf("return item %v is not inspected", item.ID)
+		}
+	}
+	if len(r.Note) > 500 {
+		errorMessages["note"] = "note must be at most 500 characters"
+	}
+
+	return errorMessages
+}
+
+// ComputeRefund works out the refund of some units of the order. Units are refunded at the price
+// paid, less their share of the order's discount, the gap between its cost plus fees and what was
+// checked out. Fees are refunded in proportion to the value returned when the fault is ours. The
+// refund never exceeds what is left of the checkout after earlier refunds
+func ComputeRefund(order *order_entity.Order, items []ReturnItem, accepted bool, alreadyRefunded float64) Refund {
+	refund := Refund{}
+	faultValue := 0.0
+	for _, item := range items {
+		quantity := item.Quantity
+		if accepted {
+			quantity = item.AcceptedQuantity
+		}
+		value := item.UnitPrice * float64(quantity)
+		refund.ItemsAmount += value
+		if ReturnReasons[item.Reason] {
+			faultValue += value
+		}
+	}
+
+	if order.TotalCost > 0 {
+		discount := math.Max(order.TotalCost+order.TotalFees-order.TotalCheckout, 0)
+		refund.DiscountAmount = discount * refund.ItemsAmount / order.TotalCost
+		refund.FeesAmount = order.TotalFees * faultValue / order.TotalCost
+	}
+
+	refund.ItemsAmount = roundCents(refund.ItemsAmount)
+	refund.DiscountAmount = roundCents(refund.DiscountAmount)
+	refund.FeesAmount = roundCents(refund.FeesAmount)
+	refund.Total = refund.ItemsAmount - refund.DiscountAmount + refund.FeesAmount
+	refund.Total = roundCents(math.Max(math.Min(refund.Total, order.TotalCheckout-alreadyRefunded), 0))
+	return refund
+}
+
+// Apply copies the refund onto the return
+func (r Refund) Apply(ret *ReturnRequest) {
+	ret.ItemsAmount = r.ItemsAmount
+	ret.DiscountAmount = r.DiscountAmount
+	ret.FeesAmount = r.FeesAmount
+	ret.RefundAmount = r.Total
+}
+
+// Open reports whether the return still holds on to its units, either waiting for review or to arrive
+func (r *ReturnRequest) Open() bool {
+	return r.Status == ReturnStatusRequested || r.Status == ReturnStatusApproved
+}
+
+// ReturnReasonNames lists the return reasons in alphabetical order
+func ReturnReasonNames() []string {
+	names := make([]string, 0, len(ReturnReasons))
+	for name := range ReturnReasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isReturnReason(reason string) bool {
+	_, ok := ReturnReasons[reason]
+	return ok
+}
+
+func isDisposition(disposition string) bool {
+	for _, known := range Dispositions {
+		if known == disposition {
+			return true
+		}
+	}
+	return false
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
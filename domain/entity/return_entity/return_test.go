@@ -0,0 +1,90 @@
+package return_entity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+)
+
+func TestComputeRefund(t *testing.T) {
+	// 10 of discount off 100 of items and 10 of fees
+	discounted := &order_entity.Order{TotalCost: 100, TotalFees: 10, TotalCheckout: 100}
+	// No discount, the checkout is the items plus the fees
+	undiscounted := &order_entity.Order{TotalCost: 50, TotalFees: 5, TotalCheckout: 55}
+
+	tests := []struct {
+		name            string
+		order           *order_entity.Order
+		items           []ReturnItem
+		accepted        bool
+		alreadyRefunded float64
+		want            Refund
+	}{
+		{
+			name:  "share of the discount is kept back",
+			order: discounted,
+			items: []ReturnItem{{Quantity: 1, UnitPrice: 40, Reason: "changed_mind"}},
+			want:  Refund{ItemsAmount: 40, DiscountAmount: 4, FeesAmount: 0, Total: 36},
+		},
+		{
+			name:  "fees refunded when the fault is ours",
+			order: discounted,
+			items: []ReturnItem{{Quantity: 1, UnitPrice: 40, Reason: "damaged"}},
+			want:  Refund{ItemsAmount: 40, DiscountAmount: 4, FeesAmount: 4, Total: 40},
+		},
+		{
+			name:     "accepted quantity after inspection",
+			order:    discounted,
+			items:    []ReturnItem{{Quantity: 2, AcceptedQuantity: 1, UnitPrice: 40, Reason: "changed_mind"}},
+			accepted: true,
+			want:     Refund{ItemsAmount: 40, DiscountAmount: 4, FeesAmount: 0, Total: 36},
+		},
+		{
+			name:  "rounded to the cent",
+			order: undiscounted,
+			items: []ReturnItem{{Quantity: 1, UnitPrice: 33.333, Reason: "changed_mind"}},
+			want:  Refund{ItemsAmount: 33.33, DiscountAmount: 0, FeesAmount: 0, Total: 33.33},
+		},
+		{
+			name:            "capped at what is left after earlier refunds",
+			order:           discounted,
+			items:           []ReturnItem{{Quantity: 1, UnitPrice: 40, Reason: "changed_mind"}},
+			alreadyRefunded: 80,
+			want:            Refund{ItemsAmount: 40, DiscountAmount: 4, FeesAmount: 0, Total: 20},
+		},
+		{
+			name:            "capped with fees included",
+			order:           undiscounted,
+			items:           []ReturnItem{{Quantity: 1, UnitPrice: 50, Reason: "defective"}},
+			alreadyRefunded: 10,
+			want:            Refund{ItemsAmount: 50, DiscountAmount: 0, FeesAmount: 5, Total: 45},
+		},
+		{
+			name:            "nothing left to refund",
+			order:           discounted,
+			items:           []ReturnItem{{Quantity: 1, UnitPrice: 40, Reason: "damaged"}},
+			alreadyRefunded: 100,
+			want:            Refund{ItemsAmount: 40, DiscountAmount: 4, FeesAmount: 4, Total: 0},
+		},
+		{
+			name:            "never negative when refunded beyond the checkout",
+			order:           discounted,
+			items:           []ReturnItem{{Quantity: 1, UnitPrice: 40, Reason: "damaged"}},
+			alreadyRefunded: 120,
+			want:            Refund{ItemsAmount: 40, DiscountAmount: 4, FeesAmount: 4, Total: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeRefund(tt.order, tt.items, tt.accepted, tt.alreadyRefunded)
+			if math.Abs(got.ItemsAmount-tt.want.ItemsAmount) > 1e-9 ||
+				math.Abs(got.DiscountAmount-tt.want.DiscountAmount) > 1e-9 ||
+				math.Abs(got.FeesAmount-tt.want.FeesAmount) > 1e-9 ||
+				math.Abs(got.Total-tt.want.Total) > 1e-9 {
+				t.Errorf("ComputeRefund() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
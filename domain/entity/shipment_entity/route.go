@@ -0,0 +1,99 @@
+package shipment_entity
+
+import (
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+)
+
+const (
+	RouteStatusPlanned   = "planned"
+	RouteStatusCancelled = "cancelled"
+
+	// MaxRouteStops keeps a run short enough to finish within a delivery slot
+	MaxRouteStops = 25
+)
+
+// DeliveryRoute is one run of a driver from the warehouse through its stops and back. Routes are
+// planned per delivery slot, a driver with more shipments than fit in the vehicle gets several runs
+type DeliveryRoute struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WarehouseID uint64 `gorm:"not null;index;" json:"warehouse_id"`
+	DriverID uint64 `gorm:"not null;index;" json:"driver_id"`
+	DeliverySlotID uint64 `gorm:"default:0;index;" json:"delivery_slot_id,omitempty"`
+	Run int `gorm:"default:1;" json:"run"`
+	Status string `gorm:"size:20;not null;index;" json:"status"`
+	DistanceKm float64 `gorm:"type:numeric;default:0;" json:"distance_km"`
+	WeightKg float64 `gorm:"type:numeric;default:0;" json:"weight_kg"`
+	Packages int `gorm:"default:0;" json:"packages"`
+	CreatedBy int64 `gorm:"default:0;" json:"created_by,omitempty"`
+	Stops []RouteStop `gorm:"foreignKey:RouteID;references:ID" json:"stops"`
+}
+
+// RouteStop is a shipment to drop off, Sequence is its place on the run
+type RouteStop struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	RouteID uint64 `gorm:"not null;index;" json:"route_id"`
+	Sequence int `gorm:"not null;" json:"sequence"`
+	ShipmentID uint64 `gorm:"not null;index;" json:"shipment_id"`
+	OrderID int64 `gorm:"not null;" json:"order_id"`
+	Latitude float64 `gorm:"type:numeric;" json:"latitude"`
+	Longitude float64 `gorm:"type:numeric;" json:"longitude"`
+	LegKm float64 `gorm:"type:numeric;default:0;" json:"leg_km"`
+	Shipment *Shipment `gorm:"foreignKey:ShipmentID;references:ID" json:"shipment,omitempty"`
+}
+
+type DeliveryRouteFilter struct {
+	WarehouseID int64
+	DriverID int64
+	Status string
+}
+
+// RoutePlanRequest narrows the shipments to plan to a delivery slot or to the slots of a day,
+// and the drivers to some of the warehouse's drivers
+type RoutePlanRequest struct {
+	DeliverySlotID uint64 `json:"delivery_slot_id"`
+	Date string `json:"date"`
+	DriverIDs []uint64 `json:"driver_ids"`
+}
+
+// RoutePlan is the outcome of planning, shipments that could not be planned say why
+type RoutePlan struct {
+	Routes []DeliveryRoute `json:"routes"`
+	Unplanned []UnplannedShipment `json:"unplanned"`
+}
+
+type UnplannedShipment struct {
+	ShipmentID uint64 `json:"shipment_id"`
+	OrderID int64 `json:"order_id"`
+	Reason string `json:"reason"`
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *RoutePlanRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if r.Date != "" {
+		if _, err := time.Parse(warehouse_entity.DateLayout, r.Date); err != nil {
+			errorMessages["date"] = "date must be YYYY-MM-DD"
+		}
+	}
+	if r.DeliverySlotID > 0 && r.Date != "" {
+		errorMessages["date"] = "give either delivery_slot_id or date"
+	}
+
+	return errorMessages
+}
+
+// Fits reports whether a load still fits in the driver's vehicle, a zero capacity has no limit
+func (d *Driver) Fits(weightKg float64, packages int) bool {
+	if d.VehicleCapacityKg > 0 && weightKg > d.VehicleCapacityKg {
+		return false
+	}
+	if d.VehicleCapacityPackages > 0 && packages > d.VehicleCapacityPackages {
+		return false
+	}
+	return true
+}
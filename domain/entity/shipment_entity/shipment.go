@@ -0,0 +1,248 @@
+package shipment_entity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+)
+
+const (
+	ShipmentStatusPending        = "pending"
+	ShipmentStatusAssigned       = "assigned"
+	ShipmentStatusOutForDelivery = "out_for_delivery"
+	ShipmentStatusDelivered      = "delivered"
+	ShipmentStatusFailed         = "failed"
+	ShipmentStatusReturning      = "returning"
+	ShipmentStatusReturned       = "returned"
+
+	// EventLocation is recorded for a position update that does not change the status
+	EventLocation = "location"
+
+	MaxDeliveryAttempts = 3
+
+	// ProofBucket is the storage bucket proof of delivery is uploaded to
+	ProofBucket = "deliveries"
+)
+
+// FailureReasons are the reasons a delivery can fail, true when another attempt may succeed.
+// Any other failure sends the shipment back to the warehouse
+var FailureReasons = map[string]bool{
+	"customer_absent":   true,
+	"address_not_found": true,
+	"access_denied":     true,
+	"vehicle_issue":     true,
+	"weather":           true,
+	"refused":           false,
+	"damaged":           false,
+	"wrong_items":       false,
+}
+
+// Driver delivers shipments. A driver with a customer account logs in with the driver role and
+// can only work on the shipments assigned to them. A vehicle capacity of 0 has no limit
+type Driver struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	Name string `gorm:"size:255;not null;" json:"name"`
+	Phone string `gorm:"size:50;" json:"phone"`
+	WarehouseID uint64 `gorm:"default:0;index;" json:"warehouse_id"`
+	CustomerID int64 `gorm:"default:0;index;" json:"customer_id,omitempty"`
+	VehicleCapacityKg float64 `gorm:"type:numeric;default:0;" json:"vehicle_capacity_kg"`
+	VehicleCapacityPackages int `gorm:"default:0;" json:"vehicle_capacity_packages"`
+	Active bool `gorm:"not null;" json:"active"`
+}
+
+// Shipment takes a packed order to the customer. A failed attempt that may succeed another time
+// goes back to its driver, up to MaxDeliveryAttempts, otherwise it returns to the warehouse
+type Shipment struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	OrderID int64 `gorm:"not null;index;" json:"order_id"`
+	WarehouseID uint64 `gorm:"not null;index;" json:"warehouse_id"`
+	DriverID uint64 `gorm:"default:0;index;" json:"driver_id"`
+	Status string `gorm:"size:30;not null;index;" json:"status"`
+	Attempts int `gorm:"default:0;" json:"attempts"`
+	FailureReason string `gorm:"size:50;" json:"failure_reason,omitempty"`
+	LastLatitude *float64 `gorm:"type:numeric;" json:"last_latitude,omitempty"`
+	LastLongitude *float64 `gorm:"type:numeric;" json:"last_longitude,omitempty"`
+	RecipientName string `gorm:"size:255;" json:"recipient_name,omitempty"`
+	SignatureURL string `gorm:"size:500;" json:"signature_url,omitempty"`
+	PhotoURL string `gorm:"size:500;" json:"photo_url,omitempty"`
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+	DispatchedAt *time.Time `json:"dispatched_at,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	ReturnedAt *time.Time `json:"returned_at,omitempty"`
+	Driver *Driver `gorm:"foreignKey:DriverID;references:ID" json:"driver,omitempty"`
+	Events []ShipmentEvent `gorm:"foreignKey:ShipmentID;references:ID" json:"events,omitempty"`
+}
+
+// ShipmentEvent is a status change or position update of a shipment
+type ShipmentEvent struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	ShipmentID uint64 `gorm:"not null;index;" json:"shipment_id"`
+	Status string `gorm:"size:30;not null;" json:"status"`
+	Latitude *float64 `gorm:"type:numeric;" json:"latitude,omitempty"`
+	Longitude *float64 `gorm:"type:numeric;" json:"longitude,omitempty"`
+	Reason string `gorm:"size:50;" json:"reason,omitempty"`
+	Note string `gorm:"size:500;" json:"note,omitempty"`
+	RecordedBy int64 `gorm:"default:0;" json:"recorded_by,omitempty"`
+	RecordedAt time.Time `gorm:"not null;" json:"recorded_at"`
+}
+
+type ShipmentFilter struct {
+	WarehouseID int64
+	DriverID int64
+	Status string
+}
+
+type DriverRequest struct {
+	Name string `json:"name"`
+	Phone string `json:"phone"`
+	WarehouseID uint64 `json:"warehouse_id"`
+	CustomerID int64 `json:"customer_id"`
+	VehicleCapacityKg float64 `json:"vehicle_capacity_kg"`
+	VehicleCapacityPackages int `json:"vehicle_capacity_packages"`
+	Active *bool `json:"active"`
+}
+
+type AssignRequest struct {
+	DriverID uint64 `json:"driver_id"`
+}
+
+// StatusUpdate moves a shipment along. A failed delivery needs one of the FailureReasons,
+// ReturnToWarehouse gives up on the order instead of trying again
+type StatusUpdate struct {
+	Status string `json:"status"`
+	Latitude *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Reason string `json:"reason"`
+	Note string `json:"note"`
+	ReturnToWarehouse bool `json:"return_to_warehouse"`
+}
+
+type LocationUpdate struct {
+	Latitude *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+// SignatureFile and PhotoFile name the proof of delivery files of a shipment in the ProofBucket
+func SignatureFile(shipmentId uint64) string {
+	return fmt.Sprintf("shipment_%v_signature", shipmentId)
+}
+
+func PhotoFile(shipmentId uint64) string {
+	return fmt.Sprintf("shipment_%v_photo", shipmentId)
+}
+
+// DeliveryProof is what the driver collects at the door, the signature and photo files are uploaded alongside
+type DeliveryProof struct {
+	RecipientName string
+	Latitude *float64
+	Longitude *float64
+	Note string
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *DriverRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if strings.TrimSpace(r.Name) == "" {
+		errorMessages["name"] = "name is required"
+	}
+	if r.CustomerID < 0 {
+		errorMessages["customer_id"] = "customer_id cannot be negative"
+	}
+	if r.VehicleCapacityKg < 0 {
+		errorMessages["vehicle_capacity_kg"] = "vehicle_capacity_kg cannot be negative"
+	}
+	if r.VehicleCapacityPackages < 0 {
+		errorMessages["vehicle_capacity_packages"] = "vehicle_capacity_packages cannot be negative"
+	}
+
+	return errorMessages
+}
+
+// Apply copies the request onto the driver, leaving out active keeps the driver as it is
+func (r *DriverRequest) Apply(driver *Driver) {
+	driver.Name = strings.TrimSpace(r.Name)
+	driver.Phone = strings.TrimSpace(r.Phone)
+	driver.WarehouseID = r.WarehouseID
+	driver.CustomerID = r.CustomerID
+	driver.VehicleCapacityKg = r.VehicleCapacityKg
+	driver.VehicleCapacityPackages = r.VehicleCapacityPackages
+	if r.Active != nil {
+		driver.Active = *r.Active
+	}
+}
+
+// Validate returns the problems with the update keyed by field
+func (r *StatusUpdate) Validate() map[string]string {
+	errorMessages := validateCoordinates(r.Latitude, r.Longitude)
+	switch r.Status {
+	case ShipmentStatusOutForDelivery, ShipmentStatusReturned:
+	case ShipmentStatusFailed:
+		if _, ok := FailureReasons[r.Reason]; !ok {
+			errorMessages["reason"] = "reason must be one of " + strings.Join(FailureReasonNames(), ", ")
+		}
+	case ShipmentStatusDelivered:
+		errorMessages["status"] = "deliveries are confirmed with proof of delivery"
+	default:
+		errorMessages["status"] = "status must be out_for_delivery, failed or returned"
+	}
+	if len(r.Note) > 500 {
+		errorMessages["note"] = "note must be at most 500 characters"
+	}
+
+	return errorMessages
+}
+
+// Validate returns the problems with the update keyed by field
+func (r *LocationUpdate) Validate() map[string]string {
+	errorMessages := validateCoordinates(r.Latitude, r.Longitude)
+	if r.Latitude == nil || r.Longitude == nil {
+		errorMessages["latitude"] = "latitude and longitude are required"
+	}
+
+	return errorMessages
+}
+
+// Validate returns the problems with the proof keyed by field
+func (r *DeliveryProof) Validate() map[string]string {
+	errorMessages := validateCoordinates(r.Latitude, r.Longitude)
+	if len(r.RecipientName) > 255 {
+		errorMessages["recipient_name"] = "recipient_name must be at most 255 characters"
+	}
+	if len(r.Note) > 500 {
+		errorMessages["note"] = "note must be at most 500 characters"
+	}
+
+	return errorMessages
+}
+
+// FailureReasonNames lists the failure reasons in alphabetical order
+func FailureReasonNames() []string {
+	names := make([]string, 0, len(FailureReasons))
+	for name := range FailureReasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validateCoordinates(latitude *float64, longitude *float64) map[string]string {
+	errorMessages := map[string]string{}
+	if (latitude == nil) != (longitude == nil) {
+		errorMessages["latitude"] = "latitude and longitude go together"
+		return errorMessages
+	}
+	if latitude != nil && (*latitude < -90 || *latitude > 90) {
+		errorMessages["latitude"] = "latitude must be between -90 and 90"
+	}
+	if longitude != nil && (*longitude < -180 || *longitude > 180) {
+		errorMessages["longitude"] = "longitude must be between -180 and 180"
+	}
+
+	return errorMessages
+}
@@ -0,0 +1,175 @@
+package wallet_entity
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+)
+
+const (
+	// Wallet credits
+	TransactionTopUp    = "top_up"
+	TransactionRefund   = "refund"
+	TransactionGoodwill = "goodwill"
+	TransactionReversal = "reversal"
+	// Wallet debits
+	TransactionPayment = "payment"
+
+	// Store accounts on the other side of wallet movements
+	AccountGatewayClearing = "gateway_clearing"
+	AccountSalesReturns    = "sales_returns"
+	AccountGoodwill        = "goodwill"
+	AccountOrderPayments   = "order_payments"
+
+	// MaxTopUp and MaxCredit cap a single top-up by the customer and a single credit by staff
+	MaxTopUp  = 500.0
+	MaxCredit = 1000.0
+)
+
+// CounterAccounts are the store accounts each type of transaction moves money to or from
+var CounterAccounts = map[string]string{
+	TransactionTopUp:    AccountGatewayClearing,
+	TransactionRefund:   AccountSalesReturns,
+	TransactionGoodwill: AccountGoodwill,
+	TransactionReversal: AccountOrderPayments,
+	TransactionPayment:  AccountOrderPayments,
+}
+
+// StaffCreditTypes are the credits staff can give by hand
+var StaffCreditTypes = []string{TransactionGoodwill, TransactionRefund}
+
+// Wallet holds the store credit of a customer. Balance always equals the sum of the wallet's
+// ledger entries, it is kept on the wallet so debits can check it atomically
+type Wallet struct {
+	entity.BaseModelWOutID
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	CustomerID int64 `gorm:"not null;uniqueIndex;" json:"customer_id"`
+	Balance float64 `gorm:"type:numeric;not null;default:0;" json:"balance"`
+}
+
+// WalletTransaction is a movement of store credit. It is never changed or deleted, a mistake is
+// corrected by another transaction
+type WalletTransaction struct {
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WalletID uint64 `gorm:"not null;index;" json:"wallet_id"`
+	Type string `gorm:"size:20;not null;" json:"type"`
+	Amount float64 `gorm:"type:numeric;not null;" json:"amount"`
+	BalanceAfter float64 `gorm:"type:numeric;not null;" json:"balance_after"`
+	OrderID int64 `gorm:"default:0;index;" json:"order_id,omitempty"`
+	ReturnID uint64 `gorm:"default:0;index;" json:"return_id,omitempty"`
+	Reference string `gorm:"size:100;" json:"reference,omitempty"`
+	Description string `gorm:"size:255;" json:"description,omitempty"`
+	CreatedBy int64 `gorm:"default:0;" json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries []LedgerEntry `gorm:"foreignKey:TransactionID;references:ID" json:"entries"`
+}
+
+// LedgerEntry is one side of a transaction. The entries of a transaction debit and credit the same
+// total, wallet accounts are credited when the store owes the customer more
+type LedgerEntry struct {
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	TransactionID uint64 `gorm:"not null;index;" json:"transaction_id"`
+	Account string `gorm:"size:50;not null;index;" json:"account"`
+	Debit float64 `gorm:"type:numeric;default:0;" json:"debit"`
+	Credit float64 `gorm:"type:numeric;default:0;" json:"credit"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TopUpRequest adds money to the wallet of the logged in customer with a payment method tokenized by the gateway
+type TopUpRequest struct {
+	Token string `json:"token"`
+	Amount float64 `json:"amount"`
+}
+
+// CreditRequest gives store credit to a customer, for a goodwill gesture or a refund settled outside a return
+type CreditRequest struct {
+	Type string `json:"type"`
+	Amount float64 `json:"amount"`
+	OrderID int64 `json:"order_id"`
+	Description string `json:"description"`
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *TopUpRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if strings.TrimSpace(r.Token) == "" {
+		errorMessages["token"] = "token is required"
+	}
+	if r.Amount <= 0 || r.Amount > MaxTopUp {
+		errorMessages["amount"] = fmt.Sprintf("amount must be greater than 0 and at most %.2f", MaxTopUp)
+	}
+
+	return errorMessages
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *CreditRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if r.Type != TransactionGoodwill && r.Type != TransactionRefund {
+		errorMessages["type"] = "type must be one of " + strings.Join(StaffCreditTypes, ", ")
+	}
+	if r.Amount <= 0 || r.Amount > MaxCredit {
+		errorMessages["amount"] = fmt.Sprintf("amount must be greater than 0 and at most %.2f", MaxCredit)
+	}
+	if strings.TrimSpace(r.Description) == "" {
+		errorMessages["description"] = "description is required"
+	}
+	if len(r.Description) > 255 {
+		errorMessages["description"] = "description must be at most 255 characters"
+	}
+
+	return errorMessages
+}
+
+// NewTransaction builds a transaction of the wallet with its two ledger entries, one on the wallet's
+// account and one on the store account of the transaction type
+func NewTransaction(wallet *Wallet, transactionType string, amount float64) WalletTransaction {
+	amount = math.Round(amount*100) / 100
+	walletEntry := LedgerEntry{Account: WalletAccount(wallet.ID)}
+	storeEntry := LedgerEntry{Account: CounterAccounts[transactionType]}
+	if Credits(transactionType) {
+		storeEntry.Debit = amount
+		walletEntry.Credit = amount
+	} else {
+		walletEntry.Debit = amount
+		storeEntry.Credit = amount
+	}
+
+	return WalletTransaction{
+		WalletID: wallet.ID,
+		Type:     transactionType,
+		Amount:   amount,
+		Entries:  []LedgerEntry{walletEntry, storeEntry},
+	}
+}
+
+// Credits reports whether the type of transaction adds to the wallet's balance
+func Credits(transactionType string) bool {
+	return transactionType != TransactionPayment
+}
+
+// Change is how much the transaction moves the wallet's balance
+func (t *WalletTransaction) Change() float64 {
+	if Credits(t.Type) {
+		return t.Amount
+	}
+	return -t.Amount
+}
+
+// Balanced reports whether the entries of the transaction debit and credit the same total
+func (t *WalletTransaction) Balanced() bool {
+	debits, credits := 0.0, 0.0
+	for _, ledgerEntry := range t.Entries {
+		debits += ledgerEntry.Debit
+		credits += ledgerEntry.Credit
+	}
+	return len(t.Entries) >= 2 && math.Abs(debits-credits) < 0.005
+}
+
+// WalletAccount is the ledger account of a wallet
+func WalletAccount(walletId uint64) string {
+	return fmt.Sprintf("wallet:%v", walletId)
+}
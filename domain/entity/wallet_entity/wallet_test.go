@@ -0,0 +1,81 @@
+package wallet_entity
+
+import "testing"
+
+func TestNewTransaction(t *testing.T) {
+	wallet := &Wallet{ID: 7}
+
+	tests := []struct {
+		name            string
+		transactionType string
+		amount          float64
+		wantAmount      float64
+		wantChange      float64
+	}{
+		{"top up", TransactionTopUp, 25, 25, 25},
+		{"refund rounds down to the cent", TransactionRefund, 7.123, 7.12, 7.12},
+		{"goodwill rounds up to the cent", TransactionGoodwill, 19.999, 20, 20},
+		{"reversal of float sum", TransactionReversal, 0.1 + 0.2, 0.3, 0.3},
+		{"payment", TransactionPayment, 12.5, 12.5, -12.5},
+		{"payment rounds to the cent", TransactionPayment, 3.336, 3.34, -3.34},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := NewTransaction(wallet, tt.transactionType, tt.amount)
+
+			if transaction.WalletID != wallet.ID || transaction.Type != tt.transactionType {
+				t.Errorf("NewTransaction() = wallet %v type %v, want wallet %v type %v", transaction.WalletID, transaction.Type, wallet.ID, tt.transactionType)
+			}
+			if transaction.Amount != tt.wantAmount {
+				t.Errorf("Amount = %v, want %v", transaction.Amount, tt.wantAmount)
+			}
+			if change := transaction.Change(); change != tt.wantChange {
+				t.Errorf("Change() = %v, want %v", change, tt.wantChange)
+			}
+			if !transaction.Balanced() {
+				t.Errorf("Balanced() = false for entries %+v", transaction.Entries)
+			}
+
+			if len(transaction.Entries) != 2 {
+				t.Fatalf("NewTransaction() has %v entries, want 2", len(transaction.Entries))
+			}
+			walletEntry, storeEntry := transaction.Entries[0], transaction.Entries[1]
+			if walletEntry.Account != WalletAccount(wallet.ID) || storeEntry.Account != CounterAccounts[tt.transactionType] {
+				t.Errorf("entries on accounts %v and %v", walletEntry.Account, storeEntry.Account)
+			}
+			if Credits(tt.transactionType) {
+				if walletEntry.Credit != tt.wantAmount || storeEntry.Debit != tt.wantAmount || walletEntry.Debit != 0 || storeEntry.Credit != 0 {
+					t.Errorf("credit entries = %+v, want the wallet credited and the store debited %v", transaction.Entries, tt.wantAmount)
+				}
+			} else if walletEntry.Debit != tt.wantAmount || storeEntry.Credit != tt.wantAmount || walletEntry.Credit != 0 || storeEntry.Debit != 0 {
+				t.Errorf("debit entries = %+v, want the wallet debited and the store credited %v", transaction.Entries, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestBalanced(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []LedgerEntry
+		want    bool
+	}{
+		{"equal debit and credit", []LedgerEntry{{Debit: 10}, {Credit: 10}}, true},
+		{"split across entries", []LedgerEntry{{Debit: 10}, {Credit: 4}, {Credit: 6}}, true},
+		{"off by less than half a cent", []LedgerEntry{{Debit: 10}, {Credit: 10.004}}, true},
+		{"off by a cent", []LedgerEntry{{Debit: 10}, {Credit: 10.01}}, false},
+		{"one sided", []LedgerEntry{{Debit: 10}, {Debit: 10}}, false},
+		{"single entry", []LedgerEntry{{Debit: 0, Credit: 0}}, false},
+		{"no entries", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := WalletTransaction{Entries: tt.entries}
+			if got := transaction.Balanced(); got != tt.want {
+				t.Errorf("Balanced() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package warehouse_entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+)
+
+const (
+	DateLayout = "2006-01-02"
+	TimeLayout = "15:04"
+
+	MaxSlotDays = 31
+	DefaultSlotDays = 7
+)
+
+// OperatingHours are the opening times of a warehouse on one day of the week in its timezone,
+// a day without hours is closed. Weekday 0 is Sunday
+type OperatingHours struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WarehouseID uint64 `gorm:"not null;uniqueIndex:idx_operating_hours_weekday;" json:"warehouse_id"`
+	Weekday int `gorm:"not null;uniqueIndex:idx_operating_hours_weekday;" json:"weekday"`
+	Opens string `gorm:"size:5;not null;" json:"opens"`
+	Closes string `gorm:"size:5;not null;" json:"closes"`
+}
+
+// Holiday is a date on which a warehouse is closed
+type Holiday struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WarehouseID uint64 `gorm:"not null;uniqueIndex:idx_holiday_date;" json:"warehouse_id"`
+	Date string `gorm:"size:10;not null;uniqueIndex:idx_holiday_date;" json:"date"`
+	Reason string `gorm:"size:255;" json:"reason"`
+}
+
+// DeliverySlot is a delivery window of a warehouse that takes at most Capacity orders
+type DeliverySlot struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WarehouseID uint64 `gorm:"not null;uniqueIndex:idx_delivery_slot_start;" json:"warehouse_id"`
+	StartsAt time.Time `gorm:"not null;uniqueIndex:idx_delivery_slot_start;" json:"starts_at"`
+	EndsAt time.Time `gorm:"not null;" json:"ends_at"`
+	Capacity int `gorm:"not null;" json:"capacity"`
+	Reserved int `gorm:"not null;default:0;" json:"reserved"`
+	Available int `gorm:"-" json:"available"`
+}
+
+type OperatingHoursRequest struct {
+	Hours []OperatingHours `json:"hours"`
+}
+
+type HolidayRequest struct {
+	Date string `json:"date"`
+	Reason string `json:"reason"`
+}
+
+type DeliverySlotRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt time.Time `json:"ends_at"`
+	Capacity int `json:"capacity"`
+}
+
+// GenerateSlotsRequest fills the opening hours of a range of days with back to back slots
+type GenerateSlotsRequest struct {
+	From string `json:"from"`
+	Days int `json:"days"`
+	SlotMinutes int `json:"slot_minutes"`
+	Capacity int `json:"capacity"`
+}
+
+// Location returns the warehouse's timezone, warehouses without a valid one run on UTC
+func (w *Warehouse) Location() *time.Location {
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil || w.Timezone == "" {
+		return time.UTC
+	}
+	return location
+}
+
+// SetAvailable fills in how many more orders the slot takes
+func (s *DeliverySlot) SetAvailable() {
+	s.Available = s.Capacity - s.Reserved
+	if s.Available < 0 {
+		s.Available = 0
+	}
+}
+
+// Validate returns the problems with the weekly hours keyed by field
+func (r *OperatingHoursRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	seen := map[int]bool{}
+	for i, hours := range r.Hours {
+		field := fmt.Sprintf("hours[%v]", i)
+		if hours.Weekday < 0 || hours.Weekday > 6 {
+			errorMessages[field] = "weekday must be between 0 (Sunday) and 6 (Saturday)"
+			continue
+		}
+		if seen[hours.Weekday] {
+			errorMessages[field] = fmt.Sprintf("weekday %v is given more than once", hours.Weekday)
+			continue
+		}
+		seen[hours.Weekday] = true
+
+		opens, opensErr := time.Parse(TimeLayout, hours.Opens)
+		closes, closesErr := time.Parse(TimeLayout, hours.Closes)
+		if opensErr != nil || closesErr != nil {
+			errorMessages[field] = "opens and closes must be HH:MM"
+		} else if !opens.Before(closes) {
+			errorMessages[field] = "opens must be before closes"
+		}
+	}
+
+	return errorMessages
+}
+
+// Validate returns the problems with the holiday keyed by field
+func (r *HolidayRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if _, err := time.Parse(DateLayout, r.Date); err != nil {
+		errorMessages["date"] = "date must be YYYY-MM-DD"
+	}
+	if len(strings.TrimSpace(r.Reason)) > 255 {
+		errorMessages["reason"] = "reason must be at most 255 characters"
+	}
+
+	return errorMessages
+}
+
+// Validate returns the problems with the slot keyed by field
+func (r *DeliverySlotRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if r.StartsAt.IsZero() || !r.StartsAt.After(time.Now()) {
+		errorMessages["starts_at"] = "starts_at must be in the future"
+	}
+	if !r.EndsAt.After(r.StartsAt) {
+		errorMessages["ends_at"] = "ends_at must be after starts_at"
+	}
+	if r.Capacity <= 0 {
+		errorMessages["capacity"] = "capacity must be greater than 0"
+	}
+
+	return errorMessages
+}
+
+// Validate returns the problems with the generation request keyed by field
+func (r *GenerateSlotsRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if _, err := time.Parse(DateLayout, r.From); err != nil {
+		errorMessages["from"] = "from must be YYYY-MM-DD"
+	}
+	if r.Days <= 0 || r.Days > MaxSlotDays {
+		errorMessages["days"] = fmt.Sprintf("days must be between 1 and %v", MaxSlotDays)
+	}
+	if r.SlotMinutes < 15 || r.SlotMinutes > 24*60 {
+		errorMessages["slot_minutes"] = "slot_minutes must be between 15 and 1440"
+	}
+	if r.Capacity <= 0 {
+		errorMessages["capacity"] = "capacity must be greater than 0"
+	}
+
+	return errorMessages
+}
+
+// OpenDuring reports whether a window falls on a single day the warehouse is open, inside that day's hours
+func OpenDuring(hours []OperatingHours, holidays []Holiday, location *time.Location, startsAt time.Time, endsAt time.Time) bool {
+	start, end := startsAt.In(location), endsAt.In(location)
+	if start.Format(DateLayout) != end.Add(-time.Nanosecond).Format(DateLayout) {
+		return false
+	}
+
+	for _, holiday := range holidays {
+		if holiday.Date == start.Format(DateLayout) {
+			return false
+		}
+	}
+
+	for _, day := range hours {
+		if day.Weekday != int(start.Weekday()) {
+			continue
+		}
+		opens, closes, ok := day.Window(start, location)
+		return ok && !start.Before(opens) && !end.After(closes)
+	}
+
+	return false
+}
+
+// Window returns when the warehouse opens and closes on the given date
+func (h *OperatingHours) Window(date time.Time, location *time.Location) (time.Time, time.Time, bool) {
+	opens, opensErr := time.Parse(TimeLayout, h.Opens)
+	closes, closesErr := time.Parse(TimeLayout, h.Closes)
+	if opensErr != nil || closesErr != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	year, month, day := date.In(location).Date()
+	return time.Date(year, month, day, opens.Hour(), opens.Minute(), 0, 0, location),
+		time.Date(year, month, day, closes.Hour(), closes.Minute(), 0, 0, location), true
+}
@@ -0,0 +1,69 @@
+package warehouse_entity
+
+import (
+	"strings"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/utils/geo"
+)
+
+// ServiceArea is a GeoJSON polygon a warehouse delivers to, a warehouse with active service areas
+// only delivers inside them, one without any falls back to its service radius
+type ServiceArea struct {
+	entity.BaseModelWDelete
+	ID uint64 `gorm:"primary_key;not null;" json:"id"`
+	WarehouseID uint64 `gorm:"not null;index;" json:"warehouse_id"`
+	Name string `gorm:"size:255;not null;" json:"name"`
+	Area geo.Polygon `gorm:"type:text;not null;serializer:json;" json:"area"`
+	Active bool `gorm:"not null;" json:"active"`
+}
+
+type ServiceAreaRequest struct {
+	Name string `json:"name"`
+	Area geo.Polygon `json:"area"`
+	Active *bool `json:"active"`
+}
+
+// ServiceAreaOverlap is a pair of active service areas of different warehouses that overlap
+type ServiceAreaOverlap struct {
+	WarehouseID uint64 `json:"warehouse_id"`
+	ServiceAreaID uint64 `json:"service_area_id"`
+	OtherWarehouseID uint64 `json:"other_warehouse_id"`
+	OtherServiceAreaID uint64 `json:"other_service_area_id"`
+}
+
+// ServingWarehouse is a warehouse that delivers to a coordinate
+type ServingWarehouse struct {
+	ID uint64 `json:"id"`
+	Name string `json:"name"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+type Serviceability struct {
+	Latitude float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Serviceable bool `json:"serviceable"`
+	Warehouses []ServingWarehouse `json:"warehouses"`
+}
+
+// Validate returns the problems with the request keyed by field
+func (r *ServiceAreaRequest) Validate() map[string]string {
+	errorMessages := map[string]string{}
+	if strings.TrimSpace(r.Name) == "" {
+		errorMessages["name"] = "name is required"
+	}
+	if err := r.Area.Validate(); err != nil {
+		errorMessages["area"] = err.Error()
+	}
+
+	return errorMessages
+}
+
+// Apply copies the request onto the service area, leaving out active keeps the area as it is
+func (r *ServiceAreaRequest) Apply(area *ServiceArea) {
+	area.Name = strings.TrimSpace(r.Name)
+	area.Area = r.Area
+	if r.Active != nil {
+		area.Active = *r.Active
+	}
+}
@@ -1,6 +1,9 @@
 package warehouse_entity
 
-import "github.com/harisquqo/quqo-challenge-1/domain/entity"
+import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/utils/geo"
+)
 
 
 type Warehouse struct {
@@ -10,4 +13,34 @@ type Warehouse struct {
 	Address string `gorm:"size:255;not null;" json:"address"`
 	Latitude float64 `gorm:"type:numeric;not null;" json:"latitude"`
 	Longitude float64 `gorm:"type:numeric;not null;" json:"longitude"`
-}
\ No newline at end of file
+	ServiceRadius float64 `gorm:"type:numeric;not null;default:15;" json:"service_radius"`
+	Timezone string `gorm:"size:64;not null;default:UTC;" json:"timezone"`
+	ServiceAreas []ServiceArea `gorm:"foreignKey:WarehouseID;references:ID" json:"service_areas,omitempty"`
+}
+
+// Serves reports whether a delivery point lies in one of the warehouse's active service areas,
+// or within its service radius in kilometres when it has none
+func (w *Warehouse) Serves(latitude float64, longitude float64) bool {
+	hasActiveArea := false
+	for _, area := range w.ServiceAreas {
+		if !area.Active {
+			continue
+		}
+		if area.Area.Contains(latitude, longitude) {
+			return true
+		}
+		hasActiveArea = true
+	}
+	if hasActiveArea {
+		return false
+	}
+
+	return geo.Haversine(w.Latitude, w.Longitude, latitude, longitude) <= w.ServiceRadius
+}
+
+// WarehouseDistance is a warehouse with its distance in kilometres from a delivery point
+type WarehouseDistance struct {
+	Warehouse
+	DistanceKm float64 `json:"distance_km"`
+	Serves bool `json:"serves"`
+}
@@ -0,0 +1,22 @@
+package address_repository
+
+import "github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
+
+type AddressRepository interface {
+	SaveAddress(*address_entity.Address) (*address_entity.Address, map[string]string)
+	GetAddress(int64) (*address_entity.Address, error)
+	GetAddressesByCustomer(int64) ([]address_entity.Address, error)
+	GetDefaultAddress(int64) (*address_entity.Address, error)
+	UpdateAddress(*address_entity.Address) (*address_entity.Address, error)
+	SetDefaultAddress(int64, int64) (*address_entity.Address, error)
+	DeleteAddress(int64) error
+}
+
+type AddressHandlerRepository interface {
+	SaveAddress(*address_entity.Address) (*address_entity.Address, map[string]string)
+	GetAddress(int64) (*address_entity.Address, error)
+	GetAddressesByCustomer(int64) ([]address_entity.Address, error)
+	UpdateAddress(*address_entity.Address) (*address_entity.Address, error)
+	SetDefaultAddress(int64, int64) (*address_entity.Address, error)
+	DeleteAddress(int64) error
+}
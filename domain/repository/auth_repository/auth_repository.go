@@ -1,18 +1,66 @@
 package auth_repository
 
 import (
+	"crypto"
+	"time"
+
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
+	"gorm.io/gorm"
 )
  
 type AuthHandlerRepository interface {
-	AuthenticateUser(username string, password string) (string, *customer_entity.Customer, error)
-	BlacklistToken(token string) (error)
+	AuthenticateUser(username string, password string) (*auth_entity.TokenPair, *customer_entity.Customer, error)
+	RefreshTokens(refreshToken string) (*auth_entity.TokenPair, error)
+	Logout(customerId int64, jti string) (error)
+	LogoutAllSessions(customerId int64) (error)
+	RevokeToken(jti string) (error)
+	GetJWKS() (*auth_entity.JWKS, error)
+	GetSigningKeys() ([]auth_entity.SigningKey, error)
+	RotateSigningKey(algorithm string) (*auth_entity.SigningKey, error)
+	UnlockLogin(username string, ip string) (error)
+	GetAuditEvents(event string, username string) ([]auth_entity.AuditEvent, error)
+	RequestEmailVerification(customerId int64) (error)
+	VerifyEmail(token string) (*customer_entity.Customer, error)
+	RequestPasswordReset(email string) (error)
+	ResetPassword(token string, newPassword string) (error)
+	CreateAPIKey(request auth_entity.APIKeyRequest, createdBy int64) (*auth_entity.CreatedAPIKey, error)
+	GetAPIKeys() ([]auth_entity.APIKey, error)
+	RevokeAPIKey(id uint64) (error)
 }
 
 type AuthRepository interface {
-	GenerateToken(key []byte, userId int64, credential string) (string, error)
-	ValidateToken(tokenString string, key string) (*jwt.Token, error)
+	GenerateToken(userId int64, credential string, role string) (string, string, error)
+	ValidateToken(tokenString string) (*jwt.Token, error)
 	GetCustomerWithUsername(username string) (*customer_entity.Customer, error)
-	BlacklistToken(token string) (error)
-}
\ No newline at end of file
+	RevokeJTI(jti string) (error)
+	IsJTIRevoked(jti string) bool
+	RevokeCustomerAccessTokens(customerId int64) (error)
+	TokensValidAfter(customerId int64) int64
+	SaveRefreshToken(tx *gorm.DB, customerId int64, familyId string, accessJti string) (string, *auth_entity.RefreshToken, error)
+	GetRefreshToken(refreshToken string) (*auth_entity.RefreshToken, error)
+	GetRefreshTokenByAccessJTI(jti string) (*auth_entity.RefreshToken, error)
+	GetRefreshTokenFamily(familyId string) ([]auth_entity.RefreshToken, error)
+	ConsumeRefreshToken(tx *gorm.DB, id uint64, replacedById uint64) (error)
+	RevokeRefreshTokenFamily(familyId string) (error)
+	RevokeCustomerRefreshTokens(customerId int64) (error)
+	GetSigningKey() (*auth_entity.SigningKey, crypto.Signer, error)
+	GetVerificationKey(kid string) (*auth_entity.SigningKey, crypto.PublicKey, error)
+	RotateSigningKey(algorithm string) (*auth_entity.SigningKey, error)
+	EnsureSigningKey() (error)
+	GetSigningKeys() ([]auth_entity.SigningKey, error)
+	GetJWKS() (*auth_entity.JWKS, error)
+	GetLoginAttempts(key string) auth_entity.LoginAttempts
+	SaveLoginAttempts(key string, attempts auth_entity.LoginAttempts) (error)
+	ClearLoginAttempts(key string) (error)
+	SaveAuditEvent(event *auth_entity.AuditEvent) (error)
+	GetAuditEvents(event string, username string) ([]auth_entity.AuditEvent, error)
+	GenerateActionToken(customerId int64, purpose string, email string, ttl time.Duration) (string, error)
+	ConsumeActionToken(tokenString string, purpose string) (int64, string, error)
+	SaveAPIKey(apiKey *auth_entity.APIKey) (string, error)
+	GetAPIKeys() ([]auth_entity.APIKey, error)
+	GetAPIKey(id uint64) (*auth_entity.APIKey, error)
+	RevokeAPIKey(id uint64) (error)
+	ValidateAPIKey(rawKey string) (*auth_entity.APIKey, error)
+}
@@ -0,0 +1,47 @@
+package bin_repository
+
+import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/bin_entity"
+	"gorm.io/gorm"
+)
+
+type BinRepository interface {
+	SaveZone(*bin_entity.Zone) (*bin_entity.Zone, map[string]string)
+	GetZone(int64) (*bin_entity.Zone, error)
+	GetZones(int64) ([]bin_entity.Zone, error)
+	UpdateZone(*bin_entity.Zone) (*bin_entity.Zone, error)
+	DeleteZone(int64) error
+	SaveAisle(*bin_entity.Aisle) (*bin_entity.Aisle, map[string]string)
+	GetAisle(int64) (*bin_entity.Aisle, error)
+	GetAisles(int64) ([]bin_entity.Aisle, error)
+	UpdateAisle(*bin_entity.Aisle) (*bin_entity.Aisle, error)
+	DeleteAisle(int64) error
+	SaveBin(*bin_entity.Bin) (*bin_entity.Bin, map[string]string)
+	GetBin(int64) (*bin_entity.Bin, error)
+	GetBins(int64) ([]bin_entity.Bin, error)
+	UpdateBin(*bin_entity.Bin) (*bin_entity.Bin, error)
+	DeleteBin(int64) error
+	GetLayout(int64) ([]bin_entity.Zone, error)
+	GetBinStocks(int64) ([]bin_entity.BinStock, error)
+	GetProductBinStocks([]int64) ([]bin_entity.BinStock, error)
+	GetBinnedQuantity(int64, int64) (int, error)
+	AddBinStock(*gorm.DB, uint64, uint64, uint64, int) error
+	TakeBinStock(*gorm.DB, uint64, uint64, int) error
+}
+
+type BinHandlerRepository interface {
+	GetLayout(int64) ([]bin_entity.Zone, error)
+	SaveZone(int64, bin_entity.ZoneRequest) (*bin_entity.Zone, map[string]string)
+	UpdateZone(int64, int64, bin_entity.ZoneRequest) (*bin_entity.Zone, map[string]string)
+	DeleteZone(int64, int64) map[string]string
+	SaveAisle(int64, bin_entity.AisleRequest) (*bin_entity.Aisle, map[string]string)
+	UpdateAisle(int64, int64, bin_entity.AisleRequest) (*bin_entity.Aisle, map[string]string)
+	DeleteAisle(int64, int64) map[string]string
+	SaveBin(int64, bin_entity.BinRequest) (*bin_entity.Bin, map[string]string)
+	UpdateBin(int64, int64, bin_entity.BinRequest) (*bin_entity.Bin, map[string]string)
+	DeleteBin(int64, int64) map[string]string
+	GetBin(int64, int64) (*bin_entity.BinContents, error)
+	GetProductBins(int64) ([]bin_entity.BinStock, error)
+	PutAway(int64, bin_entity.PutAwayRequest) ([]bin_entity.BinStock, map[string]string)
+	MoveStock(int64, bin_entity.MoveRequest) ([]bin_entity.BinStock, map[string]string)
+}
@@ -0,0 +1,19 @@
+package bundle_repository
+
+import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"gorm.io/gorm"
+)
+
+type BundleRepository interface {
+	ValidateBundleItems(*gorm.DB, uint64, []product_entity.BundleItem) error
+	SaveBundleItems(*gorm.DB, uint64, []product_entity.BundleItem) ([]product_entity.BundleItem, error)
+	GetBundleItems(int64) ([]product_entity.BundleItem, error)
+	GetBundleAvailability(int64) (*product_entity.BundleAvailability, error)
+}
+
+type BundleHandlerRepository interface {
+	GetBundleItems(int64) ([]product_entity.BundleItem, error)
+	UpdateBundleItems(int64, []product_entity.BundleItem) ([]product_entity.BundleItem, error)
+	GetBundleAvailability(int64) (*product_entity.BundleAvailability, error)
+}
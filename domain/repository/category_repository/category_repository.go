@@ -1,6 +1,9 @@
 package category_repository
 
-import "github.com/harisquqo/quqo-challenge-1/domain/entity/category_entity"
+import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/category_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+)
 
 
 type CategoryRepository interface {
@@ -10,4 +13,12 @@ type CategoryRepository interface {
 	GetParentCategories(int64) ([]category_entity.Category, error)
 	UpdateCategory(*category_entity.Category) (*category_entity.Category, error)
 	DeleteCategory(int64) error
+	DeleteCategoryWithPolicy(int64, string, bool) (*category_entity.CategoryDeletionReport, error)
+	GetCategoryTree(int64) ([]*category_entity.CategoryNode, error)
+	GetBreadcrumbs(int64) ([]category_entity.Category, error)
+	MoveCategory(int64, int64) (*category_entity.Category, error)
+	ReorderCategories(int64, []int64) ([]category_entity.Category, error)
+	GetProductsInCategoryTree(int64) ([]product_entity.Product, error)
+	RebuildCategoryPaths() error
+	SetPointsMultiplier(int64, float64) (*category_entity.Category, error)
 }
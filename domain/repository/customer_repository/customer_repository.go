@@ -10,4 +10,21 @@ type CustomerRepository interface {
 	GetAllCustomers() ([]customer_entity.Customer, error)
 	UpdateCustomer(*customer_entity.Customer) (*customer_entity.Customer, error)
 	DeleteCustomer(int64) error
+	UpdateCustomerRole(int64, string) (*customer_entity.Customer, error)
+	GetCustomerByEmail(string) (*customer_entity.Customer, error)
+	GetCustomerByUsername(string) (*customer_entity.Customer, error)
+	MarkEmailVerified(int64, string) (*customer_entity.Customer, error)
+	UpdatePassword(int64, string) (error)
+	GetPasswordHash(int64) (string, error)
+}
+
+type CustomerHandlerRepository interface {
+	SaveCustomer(customer_entity.CustomerRequest) (*customer_entity.Customer, map[string]string)
+	GetCustomer(int64) (*customer_entity.Customer, error)
+	GetAllCustomers() ([]customer_entity.Customer, error)
+	UpdateCustomer(int64, customer_entity.UpdateCustomerRequest) (*customer_entity.Customer, map[string]string)
+	DeleteCustomer(int64) error
+	UpdateCustomerRole(int64, string) (*customer_entity.Customer, error)
+	ChangePassword(int64, customer_entity.ChangePasswordRequest) map[string]string
+	DeleteAccount(int64, customer_entity.DeleteAccountRequest) map[string]string
 }
\ No newline at end of file
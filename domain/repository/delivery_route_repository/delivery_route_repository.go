@@ -0,0 +1,21 @@
+package delivery_route_repository
+
+import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"gorm.io/gorm"
+)
+
+type DeliveryRouteRepository interface {
+	SaveDeliveryRoute(*gorm.DB, *shipment_entity.DeliveryRoute) (*shipment_entity.DeliveryRoute, error)
+	GetDeliveryRoute(int64) (*shipment_entity.DeliveryRoute, error)
+	GetDeliveryRoutes(shipment_entity.DeliveryRouteFilter) ([]shipment_entity.DeliveryRoute, error)
+	GetRoutedShipmentIDs(int64) (map[uint64]bool, error)
+	UpdateDeliveryRouteStatus(*gorm.DB, int64, string, string) error
+}
+
+type DeliveryRouteHandlerRepository interface {
+	PlanRoutes(int64, shipment_entity.RoutePlanRequest, int64) (*shipment_entity.RoutePlan, map[string]string)
+	GetDeliveryRoute(int64) (*shipment_entity.DeliveryRoute, error)
+	GetDeliveryRoutes(shipment_entity.DeliveryRouteFilter) ([]shipment_entity.DeliveryRoute, error)
+	CancelDeliveryRoute(int64, int64) (*shipment_entity.DeliveryRoute, map[string]string)
+}
@@ -2,19 +2,25 @@ package inventory_repository
 
 import (
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
+	"gorm.io/gorm"
 )
 
 type InventoryHandlerRepository interface {
-	GetInventory(int64) (*inventory_entity.Inventory, error)
-	UpdateInventory(*inventory_entity.Inventory) (*inventory_entity.Inventory, error)
+	GetInventories(int64) ([]inventory_entity.Inventory, error)
+	UpdateInventory(*inventory_entity.Inventory) (*inventory_entity.Inventory, map[string]string)
 	GetAllInventoryInWarehouse(int64) ([]inventory_entity.Inventory, error)
 	DeleteInventory(int64) (error)
 }
 
 type InventoryRepository interface {
 	SaveInventory(*inventory_entity.Inventory) (*inventory_entity.Inventory, map[string]string)
-	GetInventory(int64) (*inventory_entity.Inventory, error)
+	GetInventory(int64, uint64) (*inventory_entity.Inventory, error)
 	GetAllInventoryInWarehouse(int64) ([]inventory_entity.Inventory, error)
+	GetInventoriesForProducts([]int64) ([]inventory_entity.Inventory, error)
 	UpdateInventory(*inventory_entity.Inventory) (*inventory_entity.Inventory, error)
 	DeleteInventory(int64) (error)
+	ReduceInventory(*gorm.DB, int64, int64, uint64) error
+	ReturnInventory(*gorm.DB, int64, int64, uint64, uint64, string) error
+	ReceiveInventory(*gorm.DB, int64, int64, uint64, uint64) error
+	LogInventory(*gorm.DB, *inventory_entity.InventoryLog) error
 }
\ No newline at end of file
@@ -0,0 +1,28 @@
+package loyalty_repository
+
+import (
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/loyalty_entity"
+	"gorm.io/gorm"
+)
+
+type LoyaltyRepository interface {
+	GetOrCreateAccount(*gorm.DB, int64) (*loyalty_entity.LoyaltyAccount, error)
+	PostEntry(*gorm.DB, *loyalty_entity.PointsEntry, int64, int64) error
+	GetEntries(uint64, *entity.Pagination) ([]loyalty_entity.PointsEntry, error)
+	GetOrderEntries(*gorm.DB, int64, string) ([]loyalty_entity.PointsEntry, error)
+	GetSpendableLots(*gorm.DB, uint64) ([]loyalty_entity.PointsEntry, error)
+	GetLotsToVest(time.Time, int) ([]loyalty_entity.PointsEntry, error)
+	GetLotsToExpire(time.Time, int) ([]loyalty_entity.PointsEntry, error)
+	UpdateLot(*gorm.DB, *loyalty_entity.PointsEntry, int64, bool) error
+	GetPointsMultipliers([]int64) (map[int64]float64, error)
+}
+
+type LoyaltyHandlerRepository interface {
+	GetAccount(int64) (*loyalty_entity.LoyaltySummary, map[string]string)
+	GetStatement(int64, *entity.Pagination) ([]loyalty_entity.PointsEntry, map[string]string)
+	VestPoints(time.Time) (int64, error)
+	ExpirePoints(time.Time) (int64, error)
+}
@@ -0,0 +1,5 @@
+package mailer_repository
+
+type MailerRepository interface {
+	SendMail(to string, subject string, body string) error
+}
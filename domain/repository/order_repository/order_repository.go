@@ -1,6 +1,7 @@
 package order_repository
 
 import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
 	"gorm.io/gorm"
 )
@@ -8,17 +9,32 @@ import (
 type OrderRepository interface {
 	SaveOrder(*gorm.DB, *order_entity.Order) (*order_entity.Order, error)
 	GetOrder(int64) (*order_entity.Order, error)
+	LockOrder(*gorm.DB, int64) (*order_entity.Order, error)
 	GetAllOrders() ([]order_entity.Order, error)
+	GetOrdersByCustomer(int64) ([]order_entity.Order, error)
+	GetOrdersByCustomerPage(int64, *entity.Pagination) ([]order_entity.Order, error)
 	UpdateOrder(*order_entity.Order) (*order_entity.Order, error)
+	UpdateOrderStatus(*gorm.DB, int64, string, string) (error)
+	GetOrdersToPick(int64, int) ([]order_entity.Order, error)
+	GetOrdersByIDs([]int64) ([]order_entity.Order, error)
+	RecalculateOrderTotals(*gorm.DB, int64) error
+	SavePackingDetails(int64, int, float64) error
+	SaveWalletAmount(*gorm.DB, int64, float64, string) error
+	RefundWalletAmount(*gorm.DB, int64, float64) error
+	SavePointsRedemption(*gorm.DB, int64, int64, float64) error
 	DeleteOrder(int64) error
 }
 
 
 
 type OrderHandlerRepository interface {
-	SaveOrderFromRaw(order_entity.RawOrder) (*order_entity.Order, error)
+	PlaceOrder(order_entity.RawOrder) ([]order_entity.Order, error)
+	PlanFulfilment(order_entity.RawOrder, *order_entity.DeliveryAddress) ([]order_entity.Fulfilment, error)
 	GetOrder(int64) (*order_entity.Order, error)
 	GetAllOrders() ([]order_entity.Order, error)
+	GetOrdersByCustomer(int64) ([]order_entity.Order, error)
+	GetOrdersByCustomerPage(int64, *entity.Pagination) ([]order_entity.Order, error)
 	UpdateOrder(*order_entity.Order) (*order_entity.Order, error)
+	CancelOrder(int64) (*order_entity.Order, error)
 	DeleteOrder(int64) error
 }
\ No newline at end of file
@@ -9,5 +9,5 @@ type OrderedItemRepository interface {
 	// SaveRawOrderItems(map[string]int64, int64) error
 	GetAllOrderedItems() ([]ordereditem_entity.OrderedItem, error)
 	GetAllOrderedItemsForOrder(int64) ([]ordereditem_entity.OrderedItem, error)
-	ReverseOrder([]ordereditem_entity.OrderedItem) map[string]string
+	ReverseOrder(uint64, []ordereditem_entity.OrderedItem) map[string]string
 }
\ No newline at end of file
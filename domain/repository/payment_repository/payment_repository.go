@@ -0,0 +1,36 @@
+package payment_repository
+
+import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/payment_entity"
+	"gorm.io/gorm"
+)
+
+// PaymentRepository is a payment gateway. Amounts are in the currency of the store
+type PaymentRepository interface {
+	Name() string
+	Authorize(token string, amount float64, description string) (*payment_entity.GatewayResult, error)
+	Capture(reference string, amount float64) (*payment_entity.GatewayResult, error)
+	Void(reference string) (*payment_entity.GatewayResult, error)
+	Refund(reference string, amount float64) (*payment_entity.GatewayResult, error)
+	VerifyWebhook(payload []byte, signature string) (*payment_entity.WebhookEvent, error)
+}
+
+type PaymentRecordRepository interface {
+	SavePayment(*gorm.DB, *payment_entity.Payment) (*payment_entity.Payment, error)
+	GetPayment(int64) (*payment_entity.Payment, error)
+	GetPaymentByReference(string) (*payment_entity.Payment, error)
+	GetPaymentsForOrder(int64) ([]payment_entity.Payment, error)
+	UpdatePayment(*gorm.DB, *payment_entity.Payment, string) error
+	SavePaymentEvent(*gorm.DB, *payment_entity.PaymentEvent) error
+	HasGatewayEvent(string) (bool, error)
+}
+
+type PaymentHandlerRepository interface {
+	AuthorizePayment(int64, payment_entity.PaymentRequest, int64) (*payment_entity.Payment, map[string]string)
+	GetPayment(int64) (*payment_entity.Payment, error)
+	GetPaymentsForOrder(int64) ([]payment_entity.Payment, error)
+	CapturePayment(int64, payment_entity.AmountRequest, int64) (*payment_entity.Payment, map[string]string)
+	VoidPayment(int64, int64) (*payment_entity.Payment, map[string]string)
+	RefundPayment(int64, payment_entity.AmountRequest, int64) (*payment_entity.Payment, map[string]string)
+	HandleWebhook([]byte, string) map[string]string
+}
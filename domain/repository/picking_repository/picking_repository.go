@@ -0,0 +1,25 @@
+package picking_repository
+
+import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/picking_entity"
+	"gorm.io/gorm"
+)
+
+type PickingRepository interface {
+	SavePickList(*gorm.DB, *picking_entity.PickList) (*picking_entity.PickList, error)
+	GetPickList(int64) (*picking_entity.PickList, error)
+	GetPickLists(int64, string) ([]picking_entity.PickList, error)
+	GetPickItemsForOrder(*gorm.DB, int64) ([]picking_entity.PickItem, error)
+	LockPickItems(*gorm.DB, int64) ([]picking_entity.PickItem, error)
+	UpdatePickItem(*gorm.DB, *picking_entity.PickItem, string) error
+	UpdatePickListStatus(*gorm.DB, int64, string, string) error
+}
+
+type PickingHandlerRepository interface {
+	CreatePickList(int64, picking_entity.PickListRequest, int64) (*picking_entity.PickList, map[string]string)
+	GetPickList(int64) (*picking_entity.PickList, error)
+	GetPickLists(int64, string) ([]picking_entity.PickList, error)
+	ConfirmPick(int64, int64, picking_entity.PickConfirmation, int64) (*picking_entity.PickList, map[string]string)
+	PackOrder(int64, picking_entity.PackRequest) (*order_entity.Order, map[string]string)
+}
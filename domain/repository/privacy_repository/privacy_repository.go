@@ -0,0 +1,13 @@
+package privacy_repository
+
+import "github.com/harisquqo/quqo-challenge-1/domain/entity/privacy_entity"
+
+type PrivacyRepository interface {
+	GetCustomerData(int64) (*privacy_entity.CustomerDataExport, error)
+	EraseCustomer(int64) (*privacy_entity.ErasureReport, error)
+}
+
+type PrivacyHandlerRepository interface {
+	ExportCustomerData(int64) ([]byte, error)
+	EraseCustomer(int64) (*privacy_entity.ErasureReport, error)
+}
@@ -0,0 +1,24 @@
+package return_repository
+
+import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/return_entity"
+	"gorm.io/gorm"
+)
+
+type ReturnRepository interface {
+	SaveReturn(*return_entity.ReturnRequest) (*return_entity.ReturnRequest, map[string]string)
+	GetReturn(int64) (*return_entity.ReturnRequest, error)
+	GetReturns(return_entity.ReturnFilter) ([]return_entity.ReturnRequest, error)
+	UpdateReturn(*gorm.DB, *return_entity.ReturnRequest, string) error
+	UpdateReturnItem(*gorm.DB, *return_entity.ReturnItem) error
+}
+
+type ReturnHandlerRepository interface {
+	RequestReturn(int64, return_entity.ReturnRequestInput) (*return_entity.ReturnRequest, map[string]string)
+	GetReturn(int64) (*return_entity.ReturnRequest, error)
+	GetReturns(return_entity.ReturnFilter) ([]return_entity.ReturnRequest, error)
+	CancelReturn(int64) (*return_entity.ReturnRequest, map[string]string)
+	ApproveReturn(int64, return_entity.ReviewRequest, int64) (*return_entity.ReturnRequest, map[string]string)
+	RejectReturn(int64, return_entity.ReviewRequest, int64) (*return_entity.ReturnRequest, map[string]string)
+	InspectReturn(int64, return_entity.InspectionRequest, int64) (*return_entity.ReturnRequest, map[string]string)
+}
@@ -0,0 +1,35 @@
+package schedule_repository
+
+import (
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+	"gorm.io/gorm"
+)
+
+type ScheduleRepository interface {
+	SaveOperatingHours(uint64, []warehouse_entity.OperatingHours) ([]warehouse_entity.OperatingHours, error)
+	GetOperatingHours(int64) ([]warehouse_entity.OperatingHours, error)
+	SaveHoliday(*warehouse_entity.Holiday) (*warehouse_entity.Holiday, map[string]string)
+	GetHoliday(int64) (*warehouse_entity.Holiday, error)
+	GetHolidays(int64) ([]warehouse_entity.Holiday, error)
+	DeleteHoliday(int64) error
+	SaveDeliverySlots([]warehouse_entity.DeliverySlot) ([]warehouse_entity.DeliverySlot, error)
+	GetDeliverySlot(int64) (*warehouse_entity.DeliverySlot, error)
+	GetDeliverySlots(int64, time.Time, time.Time) ([]warehouse_entity.DeliverySlot, error)
+	DeleteDeliverySlot(int64) error
+	ReserveDeliverySlot(*gorm.DB, int64) error
+	ReleaseDeliverySlot(*gorm.DB, int64) error
+}
+
+type ScheduleHandlerRepository interface {
+	GetOperatingHours(int64) ([]warehouse_entity.OperatingHours, error)
+	UpdateOperatingHours(int64, warehouse_entity.OperatingHoursRequest) ([]warehouse_entity.OperatingHours, map[string]string)
+	GetHolidays(int64) ([]warehouse_entity.Holiday, error)
+	SaveHoliday(int64, warehouse_entity.HolidayRequest) (*warehouse_entity.Holiday, map[string]string)
+	DeleteHoliday(int64, int64) error
+	SaveDeliverySlot(int64, warehouse_entity.DeliverySlotRequest) (*warehouse_entity.DeliverySlot, map[string]string)
+	GenerateDeliverySlots(int64, warehouse_entity.GenerateSlotsRequest) ([]warehouse_entity.DeliverySlot, map[string]string)
+	GetDeliverySlots(int64, string, int, bool) ([]warehouse_entity.DeliverySlot, error)
+	DeleteDeliverySlot(int64, int64) error
+}
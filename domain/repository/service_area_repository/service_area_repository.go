@@ -0,0 +1,21 @@
+package service_area_repository
+
+import "github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+
+type ServiceAreaRepository interface {
+	SaveServiceArea(*warehouse_entity.ServiceArea) (*warehouse_entity.ServiceArea, map[string]string)
+	GetServiceArea(int64) (*warehouse_entity.ServiceArea, error)
+	GetServiceAreas(int64) ([]warehouse_entity.ServiceArea, error)
+	GetActiveServiceAreas() ([]warehouse_entity.ServiceArea, error)
+	UpdateServiceArea(*warehouse_entity.ServiceArea) (*warehouse_entity.ServiceArea, error)
+	DeleteServiceArea(int64) error
+}
+
+type ServiceAreaHandlerRepository interface {
+	SaveServiceArea(int64, warehouse_entity.ServiceAreaRequest) (*warehouse_entity.ServiceArea, map[string]string)
+	GetServiceAreas(int64) ([]warehouse_entity.ServiceArea, error)
+	UpdateServiceArea(int64, int64, warehouse_entity.ServiceAreaRequest) (*warehouse_entity.ServiceArea, map[string]string)
+	DeleteServiceArea(int64, int64) error
+	GetServiceAreaOverlaps() ([]warehouse_entity.ServiceAreaOverlap, error)
+	CheckServiceability(float64, float64) (*warehouse_entity.Serviceability, error)
+}
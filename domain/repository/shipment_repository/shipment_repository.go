@@ -0,0 +1,40 @@
+package shipment_repository
+
+import (
+	"mime/multipart"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"gorm.io/gorm"
+)
+
+type ShipmentRepository interface {
+	SaveDriver(*shipment_entity.Driver) (*shipment_entity.Driver, map[string]string)
+	GetDriver(int64) (*shipment_entity.Driver, error)
+	GetDriverByCustomer(int64) (*shipment_entity.Driver, error)
+	GetDrivers(int64) ([]shipment_entity.Driver, error)
+	UpdateDriver(*shipment_entity.Driver) (*shipment_entity.Driver, error)
+	DeleteDriver(int64) error
+	SaveShipment(*gorm.DB, *shipment_entity.Shipment) (*shipment_entity.Shipment, error)
+	GetShipment(int64) (*shipment_entity.Shipment, error)
+	GetShipmentForOrder(int64) (*shipment_entity.Shipment, error)
+	GetShipments(shipment_entity.ShipmentFilter) ([]shipment_entity.Shipment, error)
+	UpdateShipment(*gorm.DB, *shipment_entity.Shipment, string) error
+	SaveShipmentEvent(*gorm.DB, *shipment_entity.ShipmentEvent) error
+}
+
+type ShipmentHandlerRepository interface {
+	SaveDriver(shipment_entity.DriverRequest) (*shipment_entity.Driver, map[string]string)
+	GetDriver(int64) (*shipment_entity.Driver, error)
+	GetDriverByCustomer(int64) (*shipment_entity.Driver, error)
+	GetDrivers(int64) ([]shipment_entity.Driver, error)
+	UpdateDriver(int64, shipment_entity.DriverRequest) (*shipment_entity.Driver, map[string]string)
+	DeleteDriver(int64) map[string]string
+	CreateShipment(int64, shipment_entity.AssignRequest, int64) (*shipment_entity.Shipment, map[string]string)
+	GetShipment(int64) (*shipment_entity.Shipment, error)
+	GetShipmentForOrder(int64) (*shipment_entity.Shipment, error)
+	GetShipments(shipment_entity.ShipmentFilter) ([]shipment_entity.Shipment, error)
+	AssignShipment(int64, shipment_entity.AssignRequest, int64) (*shipment_entity.Shipment, map[string]string)
+	UpdateShipmentStatus(int64, shipment_entity.StatusUpdate, int64) (*shipment_entity.Shipment, map[string]string)
+	UpdateShipmentLocation(int64, shipment_entity.LocationUpdate, int64) (*shipment_entity.Shipment, map[string]string)
+	DeliverShipment(int64, shipment_entity.DeliveryProof, multipart.File, multipart.File, int64) (*shipment_entity.Shipment, map[string]string)
+}
@@ -0,0 +1,17 @@
+package trash_repository
+
+import "time"
+
+type TrashRepository interface {
+	GetDeleted(resource string) (interface{}, error)
+	RestoreDeleted(resource string, id int64) (interface{}, error)
+	PurgeDeleted(resource string, id int64) error
+	PurgeDeletedBefore(resource string, before time.Time) (int64, error)
+}
+
+type TrashHandlerRepository interface {
+	GetDeleted(resource string) (interface{}, error)
+	RestoreDeleted(resource string, id int64) (interface{}, error)
+	PurgeDeleted(resource string, id int64) error
+	PurgeExpired(retentionDays int) (map[string]int64, error)
+}
@@ -0,0 +1,21 @@
+package wallet_repository
+
+import (
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/wallet_entity"
+	"gorm.io/gorm"
+)
+
+type WalletRepository interface {
+	GetWallet(int64) (*wallet_entity.Wallet, error)
+	GetOrCreateWallet(*gorm.DB, int64) (*wallet_entity.Wallet, error)
+	PostTransaction(*gorm.DB, *wallet_entity.WalletTransaction) error
+	GetTransactions(uint64, *entity.Pagination) ([]wallet_entity.WalletTransaction, error)
+}
+
+type WalletHandlerRepository interface {
+	GetWallet(int64) (*wallet_entity.Wallet, map[string]string)
+	GetStatement(int64, *entity.Pagination) ([]wallet_entity.WalletTransaction, map[string]string)
+	TopUpWallet(int64, wallet_entity.TopUpRequest, int64) (*wallet_entity.WalletTransaction, map[string]string)
+	CreditWallet(int64, wallet_entity.CreditRequest, int64) (*wallet_entity.WalletTransaction, map[string]string)
+}
@@ -8,6 +8,8 @@ type WarehouseRepository interface {
 	GetAllWarehouses() ([]warehouse_entity.Warehouse, error)
 	UpdateWarehouse(*warehouse_entity.Warehouse) (*warehouse_entity.Warehouse, error)
 	DeleteWarehouse(int64) error
+	GetWarehousesServing(float64, float64) ([]warehouse_entity.Warehouse, error)
+	GetNearestWarehouses(float64, float64) ([]warehouse_entity.WarehouseDistance, error)
 }
 
 type WarehouseHandlerRepository interface {
@@ -16,6 +18,7 @@ type WarehouseHandlerRepository interface {
 	GetAllWarehouses() ([]warehouse_entity.Warehouse, error)
 	UpdateWarehouse(*warehouse_entity.Warehouse) (*warehouse_entity.Warehouse, error)
 	DeleteWarehouse(int64) error
+	GetNearestWarehouses(float64, float64) ([]warehouse_entity.WarehouseDistance, error)
 	SearchWarehouse(string) ([]warehouse_entity.Warehouse, error)
 	UpdateWarehousesInSearchDB() (error)
 }
\ No newline at end of file
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/address_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Address struct {
+	AddressRepo address_repository.AddressHandlerRepository
+	Persistence *base.Persistence
+}
+
+func NewAddress(p *base.Persistence) *Address {
+	return &Address{
+		Persistence: p,
+	}
+}
+
+// customerAddress loads an address from the path and checks it belongs to the customer in the path
+func (ad *Address) customerAddress(c *gin.Context) (int64, *address_entity.Address, bool) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, err := strconv.ParseInt(c.Param("customer_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid customer ID", ""))
+		return 0, nil, false
+	}
+
+	addressID, err := strconv.ParseInt(c.Param("address_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid address ID", ""))
+		return 0, nil, false
+	}
+
+	ad.AddressRepo = application.NewAddressApplication(ad.Persistence, c)
+	address, err := ad.AddressRepo.GetAddress(addressID)
+	if err != nil || address.CustomerID != customerID {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Address not found", ""))
+		return 0, nil, false
+	}
+
+	return customerID, address, true
+}
+
+//	@Summary		Save Address
+//	@Description	Adds an address to a customer's address book.
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Param			address		body		address_entity.Address	true	"Address to be saved"
+//	@Success		201			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		422			{object}	entity.ResponseContext	"Unprocessable entity"
+//	@Router			/customers/{customer_id}/addresses [post]
+func (ad *Address) SaveAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, err := strconv.ParseInt(c.Param("customer_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid customer ID", ""))
+		return
+	}
+
+	address := address_entity.Address{}
+	if err := c.ShouldBindJSON(&address); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+	address.ID = 0
+	address.CustomerID = customerID
+
+	ad.AddressRepo = application.NewAddressApplication(ad.Persistence, c)
+
+	savedAddress, saveErr := ad.AddressRepo.SaveAddress(&address)
+	if saveErr != nil {
+		if _, ok := saveErr["db_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, saveErr["db_error"], ""))
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid address", saveErr))
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Address saved successfully", savedAddress))
+}
+
+//	@Summary		Get Addresses
+//	@Description	Retrieves a customer's address book, default address first.
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/customers/{customer_id}/addresses [get]
+func (ad *Address) GetAddresses(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, err := strconv.ParseInt(c.Param("customer_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid customer ID", ""))
+		return
+	}
+
+	ad.AddressRepo = application.NewAddressApplication(ad.Persistence, c)
+
+	allAddresses, err := ad.AddressRepo.GetAddressesByCustomer(customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": allAddresses,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Addresses of customer %v obtained", customerID), results))
+}
+
+//	@Summary		Get Address
+//	@Description	Retrieves a single address of a customer.
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Param			address_id	path		int						true	"Address ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Address not found"
+//	@Router			/customers/{customer_id}/addresses/{address_id} [get]
+func (ad *Address) GetAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	_, address, ok := ad.customerAddress(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Address %v obtained", address.ID), address))
+}
+
+//	@Summary		Update Address
+//	@Description	Updates an address of a customer. Orders already placed keep their own copy of the address.
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Param			address_id	path		int						true	"Address ID"
+//	@Param			address		body		address_entity.Address	true	"Updated address"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Address not found"
+//	@Failure		422			{object}	entity.ResponseContext	"Unprocessable entity"
+//	@Router			/customers/{customer_id}/addresses/{address_id} [put]
+func (ad *Address) UpdateAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, existingAddress, ok := ad.customerAddress(c)
+	if !ok {
+		return
+	}
+
+	address := address_entity.Address{}
+	if err := c.ShouldBindJSON(&address); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+	address.ID = existingAddress.ID
+	address.CustomerID = customerID
+
+	updatedAddress, updateErr := ad.AddressRepo.UpdateAddress(&address)
+	if updateErr != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, updateErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Address updated successfully", updatedAddress))
+}
+
+//	@Summary		Set Default Address
+//	@Description	Makes the address the customer's default delivery address.
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Param			address_id	path		int						true	"Address ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Address not found"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/customers/{customer_id}/addresses/{address_id}/default [put]
+func (ad *Address) SetDefaultAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, address, ok := ad.customerAddress(c)
+	if !ok {
+		return
+	}
+
+	defaultAddress, err := ad.AddressRepo.SetDefaultAddress(customerID, int64(address.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Address %v is now the default", address.ID), defaultAddress))
+}
+
+//	@Summary		Delete Address
+//	@Description	Removes an address from a customer's address book.
+//	@Tags			Address
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Param			address_id	path		int						true	"Address ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Address not found"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/customers/{customer_id}/addresses/{address_id} [delete]
+func (ad *Address) DeleteAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	_, address, ok := ad.customerAddress(c)
+	if !ok {
+		return
+	}
+
+	err := ad.AddressRepo.DeleteAddress(int64(address.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusError, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Address %v has been deleted", address.ID), ""))
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
-	"strings"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/harisquqo/quqo-challenge-1/application"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/auth_repository"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
@@ -37,6 +40,7 @@ func NewAuth(p *base.Persistence) *Auth {
 //	@Success		200			{object}	entity.ResponseContext		"Success"
 //	@Failure		400			{object}	entity.ResponseContext		"Bad request"
 //	@Failure		401			{object}	entity.ResponseContext		"Unauthorized"
+//	@Failure		429			{object}	entity.ResponseContext		"Too many failed attempts"
 //	@Router			/login [post]
 func (au *Auth) Login(c *gin.Context) {
 	loginDetails := LoginDetails{}
@@ -50,7 +54,15 @@ func (au *Auth) Login(c *gin.Context) {
 	
 	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
 
-	token, customer, tokenErr := au.AuthRepo.AuthenticateUser(loginDetails.Username, loginDetails.Password)
+	tokens, customer, tokenErr := au.AuthRepo.AuthenticateUser(loginDetails.Username, loginDetails.Password)
+
+	var throttled *auth_entity.LoginThrottledError
+	if errors.As(tokenErr, &throttled) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests,
+			responseContextData.ResponseData(entity.StatusFail, throttled.Error(), ""))
+		return
+	}
 
 	if tokenErr != nil {
 		c.JSON(http.StatusUnauthorized,
@@ -58,9 +70,12 @@ func (au *Auth) Login(c *gin.Context) {
 		return
 	}
 
-	c.Header("Authorization", "Bearer "+token) // Set Authorization header
+	c.Header("Authorization", "Bearer "+tokens.AccessToken) // Set Authorization header
 	userData := make(map[string]interface{})
-	userData["access_token"] = token
+	userData["access_token"] = tokens.AccessToken
+	userData["refresh_token"] = tokens.RefreshToken
+	userData["token_type"] = tokens.TokenType
+	userData["expires_in"] = tokens.ExpiresIn
 	userData["id"] = customer.ID
 	userData["username"] = customer.Username
 	userData["name"] = customer.Name
@@ -68,41 +83,444 @@ func (au *Auth) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Token sent.", userData))
 }
 
+//	@Summary		Refresh token
+//	@Description	Exchanges a refresh token for a new access token and refresh token. Each refresh token can be used once; reusing one revokes the whole login session.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Refresh Token	body		auth_entity.RefreshRequest	true	"Refresh token"
+//	@Success		200				{object}	entity.ResponseContext		"Success"
+//	@Failure		400				{object}	entity.ResponseContext		"Bad request"
+//	@Failure		401				{object}	entity.ResponseContext		"Unauthorized"
+//	@Router			/token/refresh [post]
+func (au *Auth) RefreshToken(c *gin.Context) {
+	refreshRequest := auth_entity.RefreshRequest{}
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	if err := c.ShouldBindJSON(&refreshRequest); err != nil || refreshRequest.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, "No refresh token found", ""))
+		return
+	}
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+
+	tokens, refreshErr := au.AuthRepo.RefreshTokens(refreshRequest.RefreshToken)
+	if refreshErr != nil {
+		c.JSON(http.StatusUnauthorized,
+			responseContextData.ResponseData(entity.StatusFail, refreshErr.Error(), ""))
+		return
+	}
+
+	c.Header("Authorization", "Bearer "+tokens.AccessToken)
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Token refreshed.", tokens))
+}
+
 //	@Summary		User logout
-//	@Description	Logs out the user by blacklisting the provided access token on Redis
+//	@Description	Logs out the current session by revoking the access token and its refresh token
 //	@Tags			Auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			Authorization	header		string					true	"Access Token"
 //	@Success		200				{object}	entity.ResponseContext	"Success"
-//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
 //	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
 //	@Router			/logout [post]
 func (au *Auth) Logout(c *gin.Context) {
 	responseContextData := entity.ResponseContext{Ctx: c}
-	authHeader := c.Request.Header.Get("Authorization")
+	userId, _ := strconv.ParseInt(c.GetString("userID"), 10, 64)
 
-	token := strings.TrimPrefix(authHeader, "Bearer ")
-	accessToken := map[string]string{
-		"access_token": token,
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	if err := au.AuthRepo.Logout(userId, c.GetString("jti")); err != nil {
+		c.JSON(http.StatusInternalServerError,
+			responseContextData.ResponseData(entity.StatusFail, "Failed to logout", nil))
+		return
 	}
 
-	if accessToken["access_token"] == "" {
+	c.JSON(http.StatusOK,
+		responseContextData.ResponseData(entity.StatusSuccess, "Logged out successfully", nil))
+}
+
+//	@Summary		Logout of all sessions
+//	@Description	Revokes every refresh token and access token of the current user on all devices
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Access Token"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/logout/all [post]
+func (au *Auth) LogoutAllSessions(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	userId, _ := strconv.ParseInt(c.GetString("userID"), 10, 64)
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	if err := au.AuthRepo.LogoutAllSessions(userId); err != nil {
+		c.JSON(http.StatusInternalServerError,
+			responseContextData.ResponseData(entity.StatusFail, "Failed to logout", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK,
+		responseContextData.ResponseData(entity.StatusSuccess, "Logged out of all sessions", nil))
+}
+
+//	@Summary		Revoke token
+//	@Description	Revokes an access token by its jti together with the refresh tokens of the same session
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Revoke	body		auth_entity.RevokeRequest	true	"Token id"
+//	@Success		200		{object}	entity.ResponseContext		"Success"
+//	@Failure		400		{object}	entity.ResponseContext		"Bad request"
+//	@Failure		500		{object}	entity.ResponseContext		"Internal server error"
+//	@Router			/token/revoke [post]
+func (au *Auth) RevokeToken(c *gin.Context) {
+	revokeRequest := auth_entity.RevokeRequest{}
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	if err := c.ShouldBindJSON(&revokeRequest); err != nil || revokeRequest.JTI == "" {
 		c.JSON(http.StatusBadRequest,
-			responseContextData.ResponseData(entity.StatusFail, "No token found", ""))
+			responseContextData.ResponseData(entity.StatusFail, "No jti found", ""))
 		return
 	}
 
-	
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	if err := au.AuthRepo.RevokeToken(revokeRequest.JTI); err != nil {
+		c.JSON(http.StatusInternalServerError,
+			responseContextData.ResponseData(entity.StatusFail, "Failed to revoke token", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK,
+		responseContextData.ResponseData(entity.StatusSuccess, "Token revoked", nil))
+}
+
+//	@Summary		JSON Web Key Set
+//	@Description	Publishes the public keys that verify access tokens, matched by the kid header of the token
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{object}	auth_entity.JWKS		"Success"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/.well-known/jwks.json [get]
+func (au *Auth) GetJWKS(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
 
-	// Add the access token to the blacklist in Redis
 	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
-	if err := au.AuthRepo.BlacklistToken(accessToken["access_token"]); err != nil {
+	jwks, err := au.AuthRepo.GetJWKS()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError,
-			responseContextData.ResponseData(entity.StatusFail, "Failed to logout", nil))
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	// Served as a plain JWKS document so standard JWT libraries can consume it
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
+
+//	@Summary		Get signing keys
+//	@Description	Lists the keys that currently sign or verify tokens
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/keys [get]
+func (au *Auth) GetSigningKeys(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	keys, err := au.AuthRepo.GetSigningKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": keys,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Signing keys obtained", results))
+}
+
+//	@Summary		Rotate signing key
+//	@Description	Creates a new signing key (RS256 or EdDSA) and retires the current one, which keeps verifying until its tokens expire
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Rotate	body		auth_entity.RotateKeyRequest	false	"Algorithm of the new key"
+//	@Success		200		{object}	entity.ResponseContext			"Success"
+//	@Failure		400		{object}	entity.ResponseContext			"Bad request"
+//	@Router			/keys/rotate [post]
+func (au *Auth) RotateSigningKey(c *gin.Context) {
+	rotateRequest := auth_entity.RotateKeyRequest{}
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	// The body is optional, without it the default algorithm is used
+	_ = c.ShouldBindJSON(&rotateRequest)
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	key, err := au.AuthRepo.RotateSigningKey(rotateRequest.Algorithm)
+	if err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Signing key rotated", key))
+}
+
+//	@Summary		Unlock login
+//	@Description	Lifts the login lockout of a username and/or an IP address
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Unlock	body		auth_entity.UnlockLoginRequest	true	"Username and/or IP address"
+//	@Success		200		{object}	entity.ResponseContext			"Success"
+//	@Failure		400		{object}	entity.ResponseContext			"Bad request"
+//	@Router			/login/unlock [post]
+func (au *Auth) UnlockLogin(c *gin.Context) {
+	unlockRequest := auth_entity.UnlockLoginRequest{}
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	if err := c.ShouldBindJSON(&unlockRequest); err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	if err := au.AuthRepo.UnlockLogin(unlockRequest.Username, unlockRequest.IP); err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Login unlocked", nil))
+}
+
+//	@Summary		Get audit events
+//	@Description	Lists the latest security audit events, optionally filtered by event and username
+//	@Tags			Auth
+//	@Produce		json
+//	@Param			event		query		string					false	"Event name, e.g. login_lockout"
+//	@Param			username	query		string					false	"Username"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/audit-events [get]
+func (au *Auth) GetAuditEvents(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	events, err := au.AuthRepo.GetAuditEvents(c.Query("event"), c.Query("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": events,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Audit events obtained", results))
+}
+
+//	@Summary		Request email verification
+//	@Description	Sends the current user a link to confirm their email address
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		400	{object}	entity.ResponseContext	"Bad request"
+//	@Router			/email/verification/request [post]
+func (au *Auth) RequestEmailVerification(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	userId, _ := strconv.ParseInt(c.GetString("userID"), 10, 64)
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	if err := au.AuthRepo.RequestEmailVerification(userId); err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
 		return
 	}
 
 	c.JSON(http.StatusOK,
-		responseContextData.ResponseData(entity.StatusSuccess, "Logged out successfully", nil))
+		responseContextData.ResponseData(entity.StatusSuccess, "Verification email sent", nil))
+}
+
+//	@Summary		Confirm email verification
+//	@Description	Marks the email address as verified with the token from the verification link
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Token	body		auth_entity.ActionTokenRequest	true	"Verification token"
+//	@Success		200		{object}	entity.ResponseContext			"Success"
+//	@Failure		400		{object}	entity.ResponseContext			"Bad request"
+//	@Router			/email/verification/confirm [post]
+func (au *Auth) VerifyEmail(c *gin.Context) {
+	tokenRequest := auth_entity.ActionTokenRequest{}
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	if err := c.ShouldBindJSON(&tokenRequest); err != nil || tokenRequest.Token == "" {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, "No token found", ""))
+		return
+	}
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	customer, err := au.AuthRepo.VerifyEmail(tokenRequest.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	data := map[string]interface{}{
+		"id":                customer.ID,
+		"email":             customer.Email,
+		"email_verified_at": customer.EmailVerifiedAt,
+	}
+	c.JSON(http.StatusOK,
+		responseContextData.ResponseData(entity.StatusSuccess, "Email verified", data))
+}
+
+//	@Summary		Request password reset
+//	@Description	Sends a password reset link when the email belongs to an account. The response is the same whether or not it does
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Email	body		auth_entity.EmailRequest	true	"Email address"
+//	@Success		200		{object}	entity.ResponseContext		"Success"
+//	@Failure		400		{object}	entity.ResponseContext		"Bad request"
+//	@Router			/password/reset/request [post]
+func (au *Auth) RequestPasswordReset(c *gin.Context) {
+	emailRequest := auth_entity.EmailRequest{}
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	if err := c.ShouldBindJSON(&emailRequest); err != nil || emailRequest.Email == "" {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, "No email found", ""))
+		return
+	}
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	if err := au.AuthRepo.RequestPasswordReset(emailRequest.Email); err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	c.JSON(http.StatusOK,
+		responseContextData.ResponseData(entity.StatusSuccess, "If the email belongs to an account, a reset link has been sent", nil))
+}
+
+//	@Summary		Reset password
+//	@Description	Sets a new password with the token from the reset link and signs out every session
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Reset	body		auth_entity.ResetPasswordRequest	true	"Token and new password"
+//	@Success		200		{object}	entity.ResponseContext				"Success"
+//	@Failure		400		{object}	entity.ResponseContext				"Bad request"
+//	@Router			/password/reset/confirm [post]
+func (au *Auth) ResetPassword(c *gin.Context) {
+	resetRequest := auth_entity.ResetPasswordRequest{}
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	if err := c.ShouldBindJSON(&resetRequest); err != nil || resetRequest.Token == "" {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, "No token found", ""))
+		return
+	}
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	if err := au.AuthRepo.ResetPassword(resetRequest.Token, resetRequest.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	c.JSON(http.StatusOK,
+		responseContextData.ResponseData(entity.StatusSuccess, "Password has been reset", nil))
+}
+
+//	@Summary		Create API key
+//	@Description	Creates an API key limited to the given scopes (route permissions). The key is only returned once; send it in the X-API-Key header
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			APIKey	body		auth_entity.APIKeyRequest	true	"Name, scopes and optional expiry in days"
+//	@Success		201		{object}	entity.ResponseContext		"Success"
+//	@Failure		400		{object}	entity.ResponseContext		"Bad request"
+//	@Router			/api-keys [post]
+func (au *Auth) CreateAPIKey(c *gin.Context) {
+	apiKeyRequest := auth_entity.APIKeyRequest{}
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	if err := c.ShouldBindJSON(&apiKeyRequest); err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+	userId, _ := strconv.ParseInt(c.GetString("userID"), 10, 64)
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	createdKey, err := au.AuthRepo.CreateAPIKey(apiKeyRequest, userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "API key created, store it now as it will not be shown again", createdKey))
+}
+
+//	@Summary		Get API keys
+//	@Description	Lists every API key with its prefix, scopes, expiry and last use
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/api-keys [get]
+func (au *Auth) GetAPIKeys(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	apiKeys, err := au.AuthRepo.GetAPIKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": apiKeys,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "API keys obtained", results))
+}
+
+//	@Summary		Revoke API key
+//	@Description	Revokes an API key, requests made with it are rejected from then on
+//	@Tags			Auth
+//	@Produce		json
+//	@Param			api_key_id	path		int						true	"API key ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Router			/api-keys/{api_key_id} [delete]
+func (au *Auth) RevokeAPIKey(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	apiKeyId, err := strconv.ParseUint(c.Param("api_key_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, "Invalid api key ID", ""))
+		return
+	}
+
+	au.AuthRepo = application.NewAuthApplication(au.Persistence, c)
+	if err := au.AuthRepo.RevokeAPIKey(apiKeyId); err != nil {
+		c.JSON(http.StatusBadRequest,
+			responseContextData.ResponseData(entity.StatusFail, err.Error(), nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "API key revoked", nil))
 }
@@ -0,0 +1,583 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/bin_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/bin_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Bin struct {
+	BinRepo     bin_repository.BinHandlerRepository
+	Persistence *base.Persistence
+}
+
+func NewBin(p *base.Persistence) *Bin {
+	return &Bin{
+		Persistence: p,
+	}
+}
+
+// GetLayout retrieves the zones, aisles and bins of a warehouse.
+//
+//	@Summary		Get Warehouse Layout
+//	@Description	Retrieves the zones of a warehouse with their aisles and bins, in walking order. Every bin carries its zone-aisle-bin label.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/layout [get]
+func (b *Bin) GetLayout(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	zones, getErr := b.BinRepo.GetLayout(warehouseID)
+	if getErr != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": zones,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Layout of warehouse %v obtained", warehouseID), results))
+}
+
+// SaveZone adds a zone to a warehouse.
+//
+//	@Summary		Save Zone
+//	@Description	Adds a zone to a warehouse. Zones are walked in order of sequence and their code starts every bin label.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			zone	body		bin_entity.ZoneRequest	true	"Zone"
+//	@Success		201				{object}	entity.ResponseContext	"Zone saved"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Invalid zone"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/zones [post]
+func (b *Bin) SaveZone(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := bin_entity.ZoneRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	savedZone, saveErr := b.BinRepo.SaveZone(warehouseID, request)
+	if saveErr != nil {
+		sendWarehouseErrors(c, saveErr, "Invalid zone")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Zone saved successfully", savedZone))
+}
+
+// UpdateZone replaces a zone of a warehouse.
+//
+//	@Summary		Update Zone
+//	@Description	Replaces the code, name and sequence of a zone.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			zone_id	path		int						true	"Zone ID"
+//	@Param			zone	body		bin_entity.ZoneRequest	true	"Zone"
+//	@Success		200				{object}	entity.ResponseContext	"Zone updated"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Zone not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Invalid zone"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/zones/{zone_id} [put]
+func (b *Bin) UpdateZone(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	zoneID, err := strconv.ParseInt(c.Param("zone_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Zone ID", ""))
+		return
+	}
+
+	request := bin_entity.ZoneRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	updatedZone, updateErr := b.BinRepo.UpdateZone(warehouseID, zoneID, request)
+	if updateErr != nil {
+		sendWarehouseErrors(c, updateErr, "Invalid zone")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Zone updated successfully", updatedZone))
+}
+
+// DeleteZone deletes a zone of a warehouse.
+//
+//	@Summary		Delete Zone
+//	@Description	Deletes a zone of a warehouse, a zone still having aisles cannot be deleted.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			zone_id	path		int						true	"Zone ID"
+//	@Success		200				{object}	entity.ResponseContext	"Zone deleted"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Zone not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Zone has aisles"
+//	@Router			/warehouses/{warehouse_id}/zones/{zone_id} [delete]
+func (b *Bin) DeleteZone(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	zoneID, err := strconv.ParseInt(c.Param("zone_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Zone ID", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	if deleteErr := b.BinRepo.DeleteZone(warehouseID, zoneID); deleteErr != nil {
+		sendWarehouseErrors(c, deleteErr, "Could not delete zone")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Zone deleted successfully", ""))
+}
+
+// SaveAisle adds a aisle to a warehouse.
+//
+//	@Summary		Save Aisle
+//	@Description	Adds an aisle to a zone of a warehouse. Aisles of a zone are walked in order of sequence.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			aisle	body		bin_entity.AisleRequest	true	"Aisle"
+//	@Success		201				{object}	entity.ResponseContext	"Aisle saved"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Invalid aisle"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/aisles [post]
+func (b *Bin) SaveAisle(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := bin_entity.AisleRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	savedAisle, saveErr := b.BinRepo.SaveAisle(warehouseID, request)
+	if saveErr != nil {
+		sendWarehouseErrors(c, saveErr, "Invalid aisle")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Aisle saved successfully", savedAisle))
+}
+
+// UpdateAisle replaces a aisle of a warehouse.
+//
+//	@Summary		Update Aisle
+//	@Description	Replaces the zone, code and sequence of an aisle, moving it to another zone takes its bins along.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			aisle_id	path		int						true	"Aisle ID"
+//	@Param			aisle	body		bin_entity.AisleRequest	true	"Aisle"
+//	@Success		200				{object}	entity.ResponseContext	"Aisle updated"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Aisle not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Invalid aisle"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/aisles/{aisle_id} [put]
+func (b *Bin) UpdateAisle(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	aisleID, err := strconv.ParseInt(c.Param("aisle_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Aisle ID", ""))
+		return
+	}
+
+	request := bin_entity.AisleRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	updatedAisle, updateErr := b.BinRepo.UpdateAisle(warehouseID, aisleID, request)
+	if updateErr != nil {
+		sendWarehouseErrors(c, updateErr, "Invalid aisle")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Aisle updated successfully", updatedAisle))
+}
+
+// DeleteAisle deletes a aisle of a warehouse.
+//
+//	@Summary		Delete Aisle
+//	@Description	Deletes an aisle of a warehouse, an aisle still having bins cannot be deleted.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			aisle_id	path		int						true	"Aisle ID"
+//	@Success		200				{object}	entity.ResponseContext	"Aisle deleted"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Aisle not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Aisle has bins"
+//	@Router			/warehouses/{warehouse_id}/aisles/{aisle_id} [delete]
+func (b *Bin) DeleteAisle(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	aisleID, err := strconv.ParseInt(c.Param("aisle_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Aisle ID", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	if deleteErr := b.BinRepo.DeleteAisle(warehouseID, aisleID); deleteErr != nil {
+		sendWarehouseErrors(c, deleteErr, "Could not delete aisle")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Aisle deleted successfully", ""))
+}
+
+// SaveBin adds a bin to a warehouse.
+//
+//	@Summary		Save Bin
+//	@Description	Adds a bin to an aisle of a warehouse. Sequence is its position along the aisle and capacity the most units it holds, 0 meaning no limit.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			bin	body		bin_entity.BinRequest	true	"Bin"
+//	@Success		201				{object}	entity.ResponseContext	"Bin saved"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Invalid bin"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/bins [post]
+func (b *Bin) SaveBin(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := bin_entity.BinRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	savedBin, saveErr := b.BinRepo.SaveBin(warehouseID, request)
+	if saveErr != nil {
+		sendWarehouseErrors(c, saveErr, "Invalid bin")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Bin saved successfully", savedBin))
+}
+
+// UpdateBin replaces a bin of a warehouse.
+//
+//	@Summary		Update Bin
+//	@Description	Replaces the aisle, code, sequence, capacity and active flag of a bin. The capacity cannot drop below what the bin holds.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			bin_id	path		int						true	"Bin ID"
+//	@Param			bin	body		bin_entity.BinRequest	true	"Bin"
+//	@Success		200				{object}	entity.ResponseContext	"Bin updated"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Bin not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Invalid bin"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/bins/{bin_id} [put]
+func (b *Bin) UpdateBin(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	binID, err := strconv.ParseInt(c.Param("bin_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Bin ID", ""))
+		return
+	}
+
+	request := bin_entity.BinRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	updatedBin, updateErr := b.BinRepo.UpdateBin(warehouseID, binID, request)
+	if updateErr != nil {
+		sendWarehouseErrors(c, updateErr, "Invalid bin")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Bin updated successfully", updatedBin))
+}
+
+// DeleteBin deletes a bin of a warehouse.
+//
+//	@Summary		Delete Bin
+//	@Description	Deletes an empty bin of a warehouse.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			bin_id	path		int						true	"Bin ID"
+//	@Success		200				{object}	entity.ResponseContext	"Bin deleted"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Bin not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Bin is not empty"
+//	@Router			/warehouses/{warehouse_id}/bins/{bin_id} [delete]
+func (b *Bin) DeleteBin(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	binID, err := strconv.ParseInt(c.Param("bin_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Bin ID", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	if deleteErr := b.BinRepo.DeleteBin(warehouseID, binID); deleteErr != nil {
+		sendWarehouseErrors(c, deleteErr, "Could not delete bin")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Bin deleted successfully", ""))
+}
+
+// GetBin retrieves a bin with the stock it holds.
+//
+//	@Summary		Get Bin
+//	@Description	Retrieves a bin of a warehouse with its label and the products it holds.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			bin_id			path		int						true	"Bin ID"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Bin not found"
+//	@Router			/warehouses/{warehouse_id}/bins/{bin_id} [get]
+func (b *Bin) GetBin(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	binID, err := strconv.ParseInt(c.Param("bin_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Bin ID", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	bin, getErr := b.BinRepo.GetBin(warehouseID, binID)
+	if getErr != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Bin obtained", bin))
+}
+
+// GetProductBins lists the bins a product is kept in.
+//
+//	@Summary		Get Product Bins
+//	@Description	Lists the active bins holding a product with the units in each.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			product_id	path		int						true	"Product ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Inventory not found"
+//	@Router			/products/{product_id}/bins [get]
+func (b *Bin) GetProductBins(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Product ID", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	stocks, getErr := b.BinRepo.GetProductBins(productID)
+	if getErr != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": stocks,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Bins of product %v obtained", productID), results))
+}
+
+// PutAway shelves units of a product in a bin.
+//
+//	@Summary		Put Away
+//	@Description	Shelves units of a product in a bin of the warehouse. With received set the units are new stock and are added to the inventory, otherwise they must already be counted in the inventory without a bin. Logged in the inventory log.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int							true	"Warehouse ID"
+//	@Param			put_away		body		bin_entity.PutAwayRequest	true	"Put-away"
+//	@Success		200				{object}	entity.ResponseContext		"Put away"
+//	@Failure		400				{object}	entity.ResponseContext		"Bad request"
+//	@Failure		422				{object}	entity.ResponseContext		"Invalid put-away"
+//	@Failure		500				{object}	entity.ResponseContext		"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/put-away [post]
+func (b *Bin) PutAway(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := bin_entity.PutAwayRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	stocks, putAwayErr := b.BinRepo.PutAway(warehouseID, request)
+	if putAwayErr != nil {
+		sendWarehouseErrors(c, putAwayErr, "Could not put away stock")
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": stocks,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Stock put away successfully", results))
+}
+
+// MoveStock moves units of a product between two bins.
+//
+//	@Summary		Move Stock
+//	@Description	Moves units of a product from one bin of the warehouse to another. Logged in the inventory log.
+//	@Tags			Bins
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			move			body		bin_entity.MoveRequest	true	"Move"
+//	@Success		200				{object}	entity.ResponseContext	"Stock moved"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		422				{object}	entity.ResponseContext	"Invalid move"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/bin-moves [post]
+func (b *Bin) MoveStock(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := bin_entity.MoveRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	b.BinRepo = application.NewBinApplication(b.Persistence, c)
+
+	stocks, moveErr := b.BinRepo.MoveStock(warehouseID, request)
+	if moveErr != nil {
+		sendWarehouseErrors(c, moveErr, "Could not move stock")
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": stocks,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Stock moved successfully", results))
+}
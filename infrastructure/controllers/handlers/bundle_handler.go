@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/bundle_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Bundle struct {
+	BundleRepo  bundle_repository.BundleHandlerRepository
+	Persistence *base.Persistence
+}
+
+type UpdateBundleRequest struct {
+	BundleItems []product_entity.BundleItem `json:"bundle_items"`
+}
+
+func NewBundle(p *base.Persistence) *Bundle {
+	return &Bundle{
+		Persistence: p,
+	}
+}
+
+// GetBundle retrieves the components of a bundle product.
+//	@Summary		Get Bundle
+//	@Description	Retrieves the components of a bundle product.
+//	@Tags			Bundle
+//	@Accept			json
+//	@Produce		json
+//	@Param			product_id	path		int						true	"Bundle product ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/products/{product_id}/bundle [get]
+func (bu *Bundle) GetBundle(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	bu.BundleRepo = application.NewBundleApplication(bu.Persistence, c)
+
+	bundleItems, getErr := bu.BundleRepo.GetBundleItems(productID)
+	if getErr != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": bundleItems,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Bundle %v obtained", productID), results))
+}
+
+// UpdateBundle replaces the components of a bundle product.
+//	@Summary		Update Bundle
+//	@Description	Replaces the components of a bundle product.
+//	@Tags			Bundle
+//	@Accept			json
+//	@Produce		json
+//	@Param			product_id	path		int						true	"Bundle product ID"
+//	@Param			bundle		body		UpdateBundleRequest		true	"Bundle components"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		422			{object}	entity.ResponseContext	"Unprocessable entity"
+//	@Router			/products/{product_id}/bundle [put]
+func (bu *Bundle) UpdateBundle(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid product ID", ""))
+		return
+	}
+
+	request := UpdateBundleRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	bu.BundleRepo = application.NewBundleApplication(bu.Persistence, c)
+
+	bundleItems, updateErr := bu.BundleRepo.UpdateBundleItems(productID, request.BundleItems)
+	if updateErr != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, updateErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Bundle updated successfully", bundleItems))
+}
+
+// GetBundleAvailability computes how many bundles can be fulfilled from component stock.
+//	@Summary		Get Bundle Availability
+//	@Description	Computes how many bundles each warehouse can fulfil from its component stock, a bundle ships whole from one warehouse.
+//	@Tags			Bundle
+//	@Accept			json
+//	@Produce		json
+//	@Param			product_id	path		int						true	"Bundle product ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/products/{product_id}/bundle/availability [get]
+func (bu *Bundle) GetBundleAvailability(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	bu.BundleRepo = application.NewBundleApplication(bu.Persistence, c)
+
+	availability, availabilityErr := bu.BundleRepo.GetBundleAvailability(productID)
+	if availabilityErr != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, availabilityErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Availability for bundle %v obtained", productID), availability))
+}
@@ -156,13 +156,16 @@ func (ca *Category) GetParentCategories(c *gin.Context) {
 }
 
 //	@Summary		Delete Category
-//	@Description	Deletes a category by its ID.
+//	@Description	Deletes a category by its ID. The policy decides what happens to child categories and products: block (default), reassign to the parent, or cascade. With dry_run nothing is deleted and the affected rows are reported.
 //	@Tags			Category
 //	@Accept			json
 //	@Produce		json
 //	@Param			category_id	path		int						true	"Category ID"
+//	@Param			policy		query		string					false	"block, reassign or cascade"
+//	@Param			dry_run		query		bool					false	"Only report what would be affected"
 //	@Success		200			{object}	entity.ResponseContext	"Success"
 //	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		409			{object}	entity.ResponseContext	"Category is not empty"
 //	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
 //	@Router			/category/{category_id} [delete]
 func (ca *Category) DeleteCategory(c *gin.Context) {
@@ -177,15 +180,33 @@ func (ca *Category) DeleteCategory(c *gin.Context) {
 		return
 	}
 
+	dryRun := false
+	if c.Query("dry_run") != "" {
+		dryRun, err = strconv.ParseBool(c.Query("dry_run"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid dry_run value", ""))
+			return
+		}
+	}
+
 	ca.CategoryRepo = application.NewCategoryApplication(ca.Persistence, c)
 
-	deleteErr := ca.CategoryRepo.DeleteCategory(categoryID)
+	report, deleteErr := ca.CategoryRepo.DeleteCategoryWithPolicy(categoryID, c.Query("policy"), dryRun)
 	if deleteErr != nil {
-		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusError, deleteErr.Error(), ""))
+		if report != nil {
+			c.JSON(http.StatusConflict, responseContextData.ResponseData(entity.StatusFail, deleteErr.Error(), report))
+			return
+		}
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, deleteErr.Error(), ""))
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Category %v would be deleted", categoryID), report))
 		return
 	}
 
-	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess,fmt.Sprintf("Category %v has been deleted", categoryID), ""))
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess,fmt.Sprintf("Category %v has been deleted", categoryID), report))
 }
 
 //	@Summary		Update Category
@@ -239,4 +260,241 @@ func (ca *Category) UpdateCategory(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Product updated succesfully", updatedCategory))
-}
\ No newline at end of file
+}
+//	@Summary		Get Category Tree
+//	@Description	Retrieves the whole category hierarchy, or the subtree under a category.
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			category_id	path		int						false	"Root category ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/categories/{category_id}/tree [get]
+func (ca *Category) GetCategoryTree(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	var rootID int64
+
+	if c.Param("category_id") != "" {
+		parsedID, err := strconv.ParseInt(c.Param("category_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+			return
+		}
+		rootID = parsedID
+	}
+
+	ca.CategoryRepo = application.NewCategoryApplication(ca.Persistence, c)
+
+	tree, err := ca.CategoryRepo.GetCategoryTree(rootID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results" : tree,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Category tree obtained", results))
+}
+
+//	@Summary		Get Category Breadcrumbs
+//	@Description	Retrieves the ancestors of a category from the root down to the category.
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			category_id	path		int						true	"Category ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/categories/{category_id}/breadcrumbs [get]
+func (ca *Category) GetBreadcrumbs(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	categoryID, err := strconv.ParseInt(c.Param("category_id"), 10, 64)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	ca.CategoryRepo = application.NewCategoryApplication(ca.Persistence, c)
+
+	breadcrumbs, err := ca.CategoryRepo.GetBreadcrumbs(categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results" : breadcrumbs,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Breadcrumbs for category %v obtained", categoryID), results))
+}
+
+//	@Summary		Move Category
+//	@Description	Moves a category and its whole subtree under a new parent. Use parent_id 0 to make it a root.
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			category_id	path		int								true	"Category ID"
+//	@Param			move		body		category_entity.MoveCategory	true	"New parent"
+//	@Success		200			{object}	entity.ResponseContext			"Success"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		422			{object}	entity.ResponseContext			"Unprocessable entity"
+//	@Router			/categories/{category_id}/move [put]
+func (ca *Category) MoveCategory(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	categoryID, err := strconv.ParseInt(c.Param("category_id"), 10, 64)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid category ID", ""))
+		return
+	}
+
+	move := category_entity.MoveCategory{}
+	if err := c.ShouldBindJSON(&move); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	ca.CategoryRepo = application.NewCategoryApplication(ca.Persistence, c)
+
+	movedCategory, moveErr := ca.CategoryRepo.MoveCategory(categoryID, move.ParentID)
+	if moveErr != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, moveErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Category %v moved", categoryID), movedCategory))
+}
+
+//	@Summary		Set Category Points Multiplier
+//	@Description	Sets how many times the usual rate the products of a category earn loyalty points, between 0 and 10. Use 0 for products that earn no points.
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			category_id	path		int								true	"Category ID"
+//	@Param			multiplier	body		category_entity.PointsMultiplier	true	"Points multiplier"
+//	@Success		200			{object}	entity.ResponseContext			"Success"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		422			{object}	entity.ResponseContext			"Unprocessable entity"
+//	@Router			/categories/{category_id}/points-multiplier [put]
+func (ca *Category) SetPointsMultiplier(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	categoryID, err := strconv.ParseInt(c.Param("category_id"), 10, 64)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid category ID", ""))
+		return
+	}
+
+	multiplier := category_entity.PointsMultiplier{}
+	if err := c.ShouldBindJSON(&multiplier); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	ca.CategoryRepo = application.NewCategoryApplication(ca.Persistence, c)
+
+	updatedCategory, updateErr := ca.CategoryRepo.SetPointsMultiplier(categoryID, multiplier.PointsMultiplier)
+	if updateErr != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, updateErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Category %v points multiplier set", categoryID), updatedCategory))
+}
+
+//	@Summary		Reorder Child Categories
+//	@Description	Sets the order of the children of a category. Use category_id 0 for the root categories.
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			category_id	path		int									true	"Parent category ID"
+//	@Param			order		body		category_entity.ReorderCategories	true	"Child category IDs in order"
+//	@Success		200			{object}	entity.ResponseContext				"Success"
+//	@Failure		400			{object}	entity.ResponseContext				"Bad request"
+//	@Failure		422			{object}	entity.ResponseContext				"Unprocessable entity"
+//	@Router			/categories/{category_id}/children/order [put]
+func (ca *Category) ReorderCategories(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	parentID, err := strconv.ParseInt(c.Param("category_id"), 10, 64)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid category ID", ""))
+		return
+	}
+
+	reorder := category_entity.ReorderCategories{}
+	if err := c.ShouldBindJSON(&reorder); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	ca.CategoryRepo = application.NewCategoryApplication(ca.Persistence, c)
+
+	reordered, reorderErr := ca.CategoryRepo.ReorderCategories(parentID, reorder.CategoryIDs)
+	if reorderErr != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, reorderErr.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results" : reordered,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Categories reordered", results))
+}
+
+//	@Summary		Get Products In Category
+//	@Description	Retrieves all products in a category, including products in its descendant categories.
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			category_id	path		int						true	"Category ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/categories/{category_id}/products [get]
+func (ca *Category) GetProductsInCategoryTree(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	categoryID, err := strconv.ParseInt(c.Param("category_id"), 10, 64)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	ca.CategoryRepo = application.NewCategoryApplication(ca.Persistence, c)
+
+	products, err := ca.CategoryRepo.GetProductsInCategoryTree(categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results" : products,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Products in category %v obtained", categoryID), results))
+}
+
+//	@Summary		Rebuild Category Tree
+//	@Description	Recomputes the stored path and depth of every category from the parent IDs.
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/categories/tree/rebuild [post]
+func (ca *Category) RebuildCategoryPaths(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	ca.CategoryRepo = application.NewCategoryApplication(ca.Persistence, c)
+
+	if err := ca.CategoryRepo.RebuildCategoryPaths(); err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Category tree rebuilt", ""))
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/harisquqo/quqo-challenge-1/application"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/customer_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
@@ -17,7 +19,7 @@ import (
 
 
 type Customer struct {
-	CustomerRepo customer_repository.CustomerRepository
+	CustomerRepo customer_repository.CustomerHandlerRepository
 	Persistence *base.Persistence
 }
 
@@ -35,31 +37,36 @@ func NewCustomer(p *base.Persistence) *Customer {
 //	@Tags			Customer
 //	@Accept			json
 //	@Produce		json
-//	@Param			customer	body		customer_entity.Customer			true	"Customer object to be saved"
+//	@Param			customer	body		customer_entity.CustomerRequest	true	"Customer object to be saved"
 //	@Success		201			{object}	entity.ResponseContext	"Success"
 //	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		422			{object}	entity.ResponseContext	"Validation errors by field"
 //	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
 //	@Router			/customers [post]
 func (cr Customer) SaveCustomer(c *gin.Context) {
 	responseContextData := entity.ResponseContext{Ctx: c}
-	customer := customer_entity.Customer{}
+	customerRequest := customer_entity.CustomerRequest{}
 	
 
-	if err := c.ShouldBindJSON(&customer); err != nil {
+	if err := c.ShouldBindJSON(&customerRequest); err != nil {
 		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
 		return
 	}
 
 	cr.CustomerRepo = application.NewCustomerApplication(cr.Persistence, c)
 
-	savedCustomer, saveErr := cr.CustomerRepo.SaveCustomer(&customer)
+	savedCustomer, saveErr := cr.CustomerRepo.SaveCustomer(customerRequest)
 
 	if saveErr != nil {
-		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, saveErr["db_error"], ""))
+		if _, ok := saveErr["db_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, saveErr["db_error"], ""))
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid customer", saveErr))
 		return
 	}
 
-	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Customer saved successfully", savedCustomer))
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Customer saved successfully", savedCustomer.ToResponse()))
 }
 
 //	@Summary		Get All Customers
@@ -83,7 +90,7 @@ func (cr Customer) GetAllCustomers(c *gin.Context) {
 	}
 
 	results := map[string]interface{}{
-		"results" : allCustomers,
+		"results" : customer_entity.ToResponses(allCustomers),
 	}
 	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "All customers obtained successfully", results))
 }
@@ -116,17 +123,23 @@ func (cr Customer) GetCustomer(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
 		return
 	}
-	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Customer %v obtained", customerID), customer))
+	if customer == nil || customer.ID == 0 {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Customer not found", ""))
+		return
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Customer %v obtained", customerID), customer.ToResponse()))
 }
 
 //	@Summary		Delete Customer
-//	@Description	Deletes a customer by its ID.
+//	@Description	Deletes a customer by its ID. Staff accounts can only be deleted by those who manage roles.
 //	@Tags			Customer
 //	@Accept			json
 //	@Produce		json
 //	@Param			customer_id	path		int						true	"Customer ID"
 //	@Success		200			{object}	entity.ResponseContext	"Success"
 //	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		403			{object}	entity.ResponseContext	"Forbidden"
+//	@Failure		404			{object}	entity.ResponseContext	"Customer not found"
 //	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
 //	@Router			/customers/{customer_id} [delete]
 func (cr Customer) DeleteCustomer(c *gin.Context) {
@@ -141,6 +154,17 @@ func (cr Customer) DeleteCustomer(c *gin.Context) {
 	}
 	cr.CustomerRepo = application.NewCustomerApplication(cr.Persistence, c)
 
+	existingCustomer, err := cr.CustomerRepo.GetCustomer(customerID)
+	if err != nil || existingCustomer == nil || existingCustomer.ID == 0 {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Customer not found", ""))
+		return
+	}
+
+	if auth_entity.IsStaffRole(existingCustomer.Role) && !middleware.HasPermission(c, auth_entity.PermRolesManage) {
+		c.JSON(http.StatusForbidden, responseContextData.ResponseData(entity.StatusFail, "Only those who manage roles can delete staff accounts", ""))
+		return
+	}
+
 	deleteErr := cr.CustomerRepo.DeleteCustomer(customerID)
 	// TODO: when deleting a customer, need to delete all the inventory in it
 
@@ -153,17 +177,19 @@ func (cr Customer) DeleteCustomer(c *gin.Context) {
 }
 
 //	@Summary		Update Customer
-//	@Description	Updates a customer.
+//	@Description	Updates a customer. Staff accounts, and the username or email of someone else, can only be changed by those who manage roles.
 //	@Tags			Customer
 //	@Accept			json
 //	@Produce		json
 //	@Param			customer_id	path		int							true	"Customer ID"
-//	@Param			customer	body		customer_entity.Customer	true	"Customer object to be updated"
+//	@Param			customer	body		customer_entity.UpdateCustomerRequest	true	"Customer fields to be updated"
 //	@Success		200			{object}	entity.ResponseContext		"Success"
 //	@Failure		400			{object}	entity.ResponseContext		"Bad request"
+//	@Failure		403			{object}	entity.ResponseContext		"Forbidden"
 //	@Failure		404			{object}	entity.ResponseContext		"Customer not found"
-//	@Failure		422			{object}	entity.ResponseContext		"Unprocessable entity"
+//	@Failure		422			{object}	entity.ResponseContext		"Validation errors by field"
 //	@Failure		500			{object}	entity.ResponseContext		"Internal server error"
+//	@Router			/customers/{customer_id} [put]
 func (cr Customer) UpdateCustomer(c *gin.Context) {
 	responseContextData := entity.ResponseContext{Ctx: c}
 	customerID, err := strconv.ParseInt(c.Param("customer_id"), 10, 64)
@@ -179,25 +205,127 @@ func (cr Customer) UpdateCustomer(c *gin.Context) {
 	cr.CustomerRepo = application.NewCustomerApplication(cr.Persistence, c)
 
 	existingCustomer, err := cr.CustomerRepo.GetCustomer(customerID)
-	if err != nil {
+	if err != nil || existingCustomer == nil || existingCustomer.ID == 0 {
 		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Customer not found", ""))
 		return
 	}
 
-	// Bind the JSON request body to the existing Customer
-	if err := c.ShouldBindJSON(&existingCustomer); err != nil {
+	// Fields left out of the body keep their current value
+	updateRequest := customer_entity.NewUpdateCustomerRequest(existingCustomer)
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
 		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
 		return
 	}
 
-	cr.CustomerRepo = application.NewCustomerApplication(cr.Persistence, c)
+	// Staff accounts and the details used to sign in or reset a password are only changed by the
+	// customer themselves or by those who manage roles
+	if !middleware.IsSelf(c, customerID) && !middleware.HasPermission(c, auth_entity.PermRolesManage) {
+		if auth_entity.IsStaffRole(existingCustomer.Role) {
+			c.JSON(http.StatusForbidden, responseContextData.ResponseData(entity.StatusFail, "Only those who manage roles can change staff accounts", ""))
+			return
+		}
+		if customer_entity.NormalizeUsername(updateRequest.Username) != existingCustomer.Username || customer_entity.NormalizeEmail(updateRequest.Email) != existingCustomer.Email {
+			c.JSON(http.StatusForbidden, responseContextData.ResponseData(entity.StatusFail, "Only those who manage roles can change another customer's username or email", ""))
+			return
+		}
+	}
 
 	// Update the Customer
-	updatedCustomer, updateErr := cr.CustomerRepo.UpdateCustomer(existingCustomer)
+	updatedCustomer, updateErr := cr.CustomerRepo.UpdateCustomer(customerID, updateRequest)
+	if updateErr != nil {
+		if _, ok := updateErr["db_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, updateErr["db_error"], ""))
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid customer", updateErr))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Customer updated successfully", updatedCustomer.ToResponse()))
+}
+
+//	@Summary		Update Customer Role
+//	@Description	Grants a role to a customer: admin, warehouse_operator, support or customer. The new role applies to tokens issued after the change.
+//	@Tags			Customer
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int							true	"Customer ID"
+//	@Param			role		body		customer_entity.UpdateRole	true	"New role"
+//	@Success		200			{object}	entity.ResponseContext		"Success"
+//	@Failure		400			{object}	entity.ResponseContext		"Bad request"
+//	@Failure		422			{object}	entity.ResponseContext		"Unprocessable entity"
+//	@Router			/customers/{customer_id}/role [put]
+func (cr Customer) UpdateCustomerRole(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, err := strconv.ParseInt(c.Param("customer_id"), 10, 64)
+
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Customer ID", ""))
+		return
+	}
+
+	updateRole := customer_entity.UpdateRole{}
+	if err := c.ShouldBindJSON(&updateRole); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	cr.CustomerRepo = application.NewCustomerApplication(cr.Persistence, c)
+
+	updatedCustomer, updateErr := cr.CustomerRepo.UpdateCustomerRole(customerID, updateRole.Role)
 	if updateErr != nil {
-		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, updateErr.Error(), ""))
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, updateErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Customer %v is now %v", customerID, updatedCustomer.Role), updatedCustomer.ToResponse()))
+}
+
+//	@Summary		Change Password
+//	@Description	Changes the password of the logged in customer after checking the old one. Every session is signed out afterwards.
+//	@Tags			Customer
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int										true	"Customer ID"
+//	@Param			password	body		customer_entity.ChangePasswordRequest	true	"Old and new password"
+//	@Success		200			{object}	entity.ResponseContext					"Success"
+//	@Failure		400			{object}	entity.ResponseContext					"Bad request"
+//	@Failure		403			{object}	entity.ResponseContext					"Forbidden"
+//	@Failure		422			{object}	entity.ResponseContext					"Validation errors by field"
+//	@Router			/customers/{customer_id}/password [put]
+func (cr Customer) ChangePassword(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, err := strconv.ParseInt(c.Param("customer_id"), 10, 64)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Customer ID", ""))
+		return
+	}
+
+	// Only the customer knows the old password, staff use the password reset flow instead
+	if !middleware.IsSelf(c, customerID) {
+		c.JSON(http.StatusForbidden, responseContextData.ResponseData(entity.StatusFail, "You can only change your own password", ""))
+		return
+	}
+
+	changeRequest := customer_entity.ChangePasswordRequest{}
+	if err := c.ShouldBindJSON(&changeRequest); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	cr.CustomerRepo = application.NewCustomerApplication(cr.Persistence, c)
+
+	changeErr := cr.CustomerRepo.ChangePassword(customerID, changeRequest)
+	if changeErr != nil {
+		if _, ok := changeErr["db_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, changeErr["db_error"], ""))
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid password", changeErr))
 		return
 	}
 
-	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Customer updated successfully", updatedCustomer))
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Password changed, please log in again", ""))
 }
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/delivery_route_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type DeliveryRoute struct {
+	DeliveryRouteRepo delivery_route_repository.DeliveryRouteHandlerRepository
+	Persistence       *base.Persistence
+}
+
+func NewDeliveryRoute(p *base.Persistence) *DeliveryRoute {
+	return &DeliveryRoute{
+		Persistence: p,
+	}
+}
+
+// PlanRoutes batches the pending shipments of a warehouse into driver routes.
+//
+//	@Summary		Plan Routes
+//	@Description	Groups the pending shipments of a warehouse by delivery slot and by proximity into runs that fit the drivers' vehicles, puts the stops of each run in visiting order and assigns the shipments. Only a delivery slot or a day can be planned, and the drivers can be chosen. Shipments that could not be planned are listed with the reason.
+//	@Tags			Routes
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int									true	"Warehouse ID"
+//	@Param			plan			body		shipment_entity.RoutePlanRequest	false	"Plan"
+//	@Success		201				{object}	entity.ResponseContext				"Routes planned"
+//	@Failure		400				{object}	entity.ResponseContext				"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext				"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext				"Routes cannot be planned"
+//	@Router			/warehouses/{warehouse_id}/routes/plan [post]
+func (dr *DeliveryRoute) PlanRoutes(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := shipment_entity.RoutePlanRequest{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+			return
+		}
+	}
+
+	dr.DeliveryRouteRepo = application.NewDeliveryRouteApplication(dr.Persistence, c)
+
+	plan, planErr := dr.DeliveryRouteRepo.PlanRoutes(warehouseID, request, staffID(c))
+	if planErr != nil {
+		sendWarehouseErrors(c, planErr, "Could not plan routes")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("%v routes planned", len(plan.Routes)), plan))
+}
+
+// GetWarehouseRoutes lists the routes of a warehouse.
+//
+//	@Summary		Get Warehouse Routes
+//	@Description	Lists the routes of a warehouse with their stops in visiting order, newest first.
+//	@Tags			Routes
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			driver_id		query		int						false	"Driver ID"
+//	@Param			status			query		string					false	"Route status"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/routes [get]
+func (dr *DeliveryRoute) GetWarehouseRoutes(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	filter := shipment_entity.DeliveryRouteFilter{WarehouseID: warehouseID, Status: c.Query("status")}
+	filter.DriverID, _ = strconv.ParseInt(c.Query("driver_id"), 10, 64)
+
+	dr.DeliveryRouteRepo = application.NewDeliveryRouteApplication(dr.Persistence, c)
+
+	routes, err := dr.DeliveryRouteRepo.GetDeliveryRoutes(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": routes,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Routes obtained", results))
+}
+
+// GetDriverRoutes lists the routes of a driver.
+//
+//	@Summary		Get Driver Routes
+//	@Description	Lists the routes of a driver with their stops in visiting order, newest first. A driver can only see their own routes.
+//	@Tags			Routes
+//	@Accept			json
+//	@Produce		json
+//	@Param			driver_id	path		int						true	"Driver ID"
+//	@Param			status		query		string					false	"Route status"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Driver not found"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/drivers/{driver_id}/routes [get]
+func (dr *DeliveryRoute) GetDriverRoutes(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	driverID, err := strconv.ParseInt(c.Param("driver_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Driver ID", ""))
+		return
+	}
+
+	if !middleware.HasPermission(c, auth_entity.PermShipmentsManage) {
+		driver, _ := application.NewShipmentApplication(dr.Persistence, c).GetDriver(driverID)
+		if driver == nil || driver.CustomerID == 0 || !middleware.IsSelf(c, driver.CustomerID) {
+			c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, fmt.Sprintf("driver %v not found", driverID), ""))
+			return
+		}
+	}
+
+	dr.DeliveryRouteRepo = application.NewDeliveryRouteApplication(dr.Persistence, c)
+
+	routes, err := dr.DeliveryRouteRepo.GetDeliveryRoutes(shipment_entity.DeliveryRouteFilter{DriverID: driverID, Status: c.Query("status")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": routes,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Routes obtained", results))
+}
+
+// GetRoute retrieves a route.
+//
+//	@Summary		Get Route
+//	@Description	Retrieves a route with its stops in visiting order and their shipments. A driver can only see their own routes.
+//	@Tags			Routes
+//	@Accept			json
+//	@Produce		json
+//	@Param			route_id	path		int						true	"Route ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Route not found"
+//	@Router			/routes/{route_id} [get]
+func (dr *DeliveryRoute) GetRoute(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	routeID, err := strconv.ParseInt(c.Param("route_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Route ID", ""))
+		return
+	}
+
+	dr.DeliveryRouteRepo = application.NewDeliveryRouteApplication(dr.Persistence, c)
+
+	route, err := dr.DeliveryRouteRepo.GetDeliveryRoute(routeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	if !middleware.HasPermission(c, auth_entity.PermShipmentsManage) {
+		driver, _ := application.NewShipmentApplication(dr.Persistence, c).GetDriver(int64(route.DriverID))
+		if driver == nil || driver.CustomerID == 0 || !middleware.IsSelf(c, driver.CustomerID) {
+			c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, fmt.Sprintf("route %v not found", routeID), ""))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Route %v obtained", routeID), route))
+}
+
+// CancelRoute cancels a planned route.
+//
+//	@Summary		Cancel Route
+//	@Description	Cancels a planned route none of whose shipments has left the warehouse. The shipments go back to pending so they can be planned again.
+//	@Tags			Routes
+//	@Accept			json
+//	@Produce		json
+//	@Param			route_id	path		int						true	"Route ID"
+//	@Success		200			{object}	entity.ResponseContext	"Route cancelled"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Route not found"
+//	@Failure		422			{object}	entity.ResponseContext	"Route cannot be cancelled"
+//	@Router			/routes/{route_id} [delete]
+func (dr *DeliveryRoute) CancelRoute(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	routeID, err := strconv.ParseInt(c.Param("route_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Route ID", ""))
+		return
+	}
+
+	dr.DeliveryRouteRepo = application.NewDeliveryRouteApplication(dr.Persistence, c)
+
+	route, cancelErr := dr.DeliveryRouteRepo.CancelDeliveryRoute(routeID, staffID(c))
+	if cancelErr != nil {
+		sendWarehouseErrors(c, cancelErr, "Could not cancel route")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Route cancelled successfully", route))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/harisquqo/quqo-challenge-1/application"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/inventory_repository"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
@@ -29,7 +30,7 @@ func NewInventory(p *base.Persistence) *Inventory {
 }
 
 //	@Summary		Get Inventory
-//	@Description	Retrieves inventory information for a specific product.
+//	@Description	Retrieves the stock every warehouse holds of a specific product.
 //	@Tags			Inventory
 //	@Accept			json
 //	@Produce		json
@@ -51,17 +52,20 @@ func (inv *Inventory) GetInventory(c *gin.Context) {
 
 	inv.inventoryHandlerRepo = application.NewInventoryApplication(inv.Persistence, c)
 
-	inventory, err := inv.inventoryHandlerRepo.GetInventory(productID)
+	inventories, err := inv.inventoryHandlerRepo.GetInventories(productID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
 		return
 	}
 
-	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Inventory for product %v obtained", productID), inventory))
+	results := map[string]interface{}{
+		"results": inventories,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Inventory for product %v obtained", productID), results))
 }
 
 //	@Summary		Update Inventory
-//	@Description	Updates inventory information for a specific product.
+//	@Description	Sets the stock the warehouse named by warehouse_id holds of a specific product. A warehouse that does not stock the product yet starts to.
 //	@Tags			Inventory
 //	@Accept			json
 //	@Produce		json
@@ -69,7 +73,7 @@ func (inv *Inventory) GetInventory(c *gin.Context) {
 //	@Param			inventory	body		inventory_entity.Inventory		true	"Inventory object to be updated"
 //	@Success		200			{object}	entity.ResponseContext	"Success"
 //	@Failure		400			{object}	entity.ResponseContext	"Bad request"
-//	@Failure		404			{object}	entity.ResponseContext	"Inventory not found"
+//	@Failure		404			{object}	entity.ResponseContext	"Product or warehouse not found"
 //	@Failure		422			{object}	entity.ResponseContext	"Unprocessable entity"
 //	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
 //	@Router			/inventory/{product_id} [put]
@@ -84,29 +88,21 @@ func (inv *Inventory) UpdateInventory(c *gin.Context) {
 		return
 	}
 
-	// Check if the inventory exists
-	inv.inventoryHandlerRepo = application.NewInventoryApplication(inv.Persistence, c)
-
-	existingInventory, err := inv.inventoryHandlerRepo.GetInventory(productIDofInventory)
-	if err != nil {
-		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Inventory not found", ""))
-		return
-	}
-
-	// Bind the JSON request body to the existing inventory
-	if err := c.ShouldBindJSON(&existingInventory); err != nil {
+	inventory := inventory_entity.Inventory{}
+	if err := c.ShouldBindJSON(&inventory); err != nil {
 		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
 		return
 	}
+	inventory.ProductID = uint64(productIDofInventory)
 
 	inv.inventoryHandlerRepo = application.NewInventoryApplication(inv.Persistence, c)
 
 	// Update the inventory
-	updatedInventory, updateErr := inv.inventoryHandlerRepo.UpdateInventory(existingInventory)
+	updatedInventory, updateErr := inv.inventoryHandlerRepo.UpdateInventory(&inventory)
 	if updateErr != nil {
-		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, updateErr.Error(), ""))
+		sendWarehouseErrors(c, updateErr, "Could not update inventory")
 		return
 	}
 
 	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Inventory updated successfully", updatedInventory))
-}
\ No newline at end of file
+}
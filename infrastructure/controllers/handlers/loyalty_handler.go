@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/loyalty_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Loyalty struct {
+	LoyaltyRepo loyalty_repository.LoyaltyHandlerRepository
+	Persistence *base.Persistence
+}
+
+func NewLoyalty(p *base.Persistence) *Loyalty {
+	return &Loyalty{
+		Persistence: p,
+	}
+}
+
+// GetMyLoyalty retrieves the loyalty account of the logged in customer.
+//
+//	@Summary		Get My Loyalty Points
+//	@Description	Retrieves the loyalty points of the logged in customer, those available to redeem with what they are worth and those pending until the return window of their orders closes.
+//	@Tags			Me
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/me/loyalty [get]
+func (l *Loyalty) GetMyLoyalty(c *gin.Context) {
+	l.sendAccount(c, staffID(c))
+}
+
+// GetMyLoyaltyStatement lists the points ledger of the logged in customer.
+//
+//	@Summary		Get My Loyalty Statement
+//	@Description	Lists a page of the points earned, vested, redeemed, restored, reversed and expired of the logged in customer, newest first.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			page		query		int						false	"Page, starting at 1"
+//	@Param			page_size	query		int						false	"Entries per page, at most 100"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/me/loyalty/statement [get]
+func (l *Loyalty) GetMyLoyaltyStatement(c *gin.Context) {
+	l.sendStatement(c, staffID(c))
+}
+
+// GetCustomerLoyalty retrieves the loyalty account of a customer.
+//
+//	@Summary		Get Customer Loyalty Points
+//	@Description	Retrieves the available and pending loyalty points of a customer.
+//	@Tags			Loyalty
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Customer not found"
+//	@Router			/customers/{customer_id}/loyalty [get]
+func (l *Loyalty) GetCustomerLoyalty(c *gin.Context) {
+	customerID, ok := customerParam(c)
+	if !ok {
+		return
+	}
+
+	l.sendAccount(c, customerID)
+}
+
+// GetCustomerLoyaltyStatement lists the points ledger of a customer.
+//
+//	@Summary		Get Customer Loyalty Statement
+//	@Description	Lists a page of the points ledger of a customer, newest first.
+//	@Tags			Loyalty
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Param			page		query		int						false	"Page, starting at 1"
+//	@Param			page_size	query		int						false	"Entries per page, at most 100"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Customer not found"
+//	@Router			/customers/{customer_id}/loyalty/statement [get]
+func (l *Loyalty) GetCustomerLoyaltyStatement(c *gin.Context) {
+	customerID, ok := customerParam(c)
+	if !ok {
+		return
+	}
+
+	l.sendStatement(c, customerID)
+}
+
+func (l *Loyalty) sendAccount(c *gin.Context, customerID int64) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	l.LoyaltyRepo = application.NewLoyaltyApplication(l.Persistence, c)
+
+	account, accountErr := l.LoyaltyRepo.GetAccount(customerID)
+	if accountErr != nil {
+		sendWarehouseErrors(c, accountErr, "Could not get loyalty points")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Loyalty points obtained", account))
+}
+
+func (l *Loyalty) sendStatement(c *gin.Context, customerID int64) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	l.LoyaltyRepo = application.NewLoyaltyApplication(l.Persistence, c)
+
+	pagination := entity.NewPagination(c.Query("page"), c.Query("page_size"))
+	entries, statementErr := l.LoyaltyRepo.GetStatement(customerID, &pagination)
+	if statementErr != nil {
+		sendWarehouseErrors(c, statementErr, "Could not get statement")
+		return
+	}
+
+	results := map[string]interface{}{
+		"results":    entries,
+		"pagination": pagination,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Statement obtained", results))
+}
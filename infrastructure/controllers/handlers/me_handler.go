@@ -0,0 +1,472 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// Me serves the account of the logged in customer. Every route is scoped by the userID
+// AuthHandler put on the request, ids of other customers are never read from the path
+type Me struct {
+	Persistence *base.Persistence
+}
+
+func NewMe(p *base.Persistence) *Me {
+	return &Me{
+		Persistence: p,
+	}
+}
+
+func (me *Me) currentCustomerID(c *gin.Context) (int64, bool) {
+	customerID, err := strconv.ParseInt(c.GetString("userID"), 10, 64)
+	if err != nil || customerID == 0 {
+		responseContextData := entity.ResponseContext{Ctx: c}
+		c.JSON(http.StatusForbidden, responseContextData.ResponseData(entity.StatusFail, "Invalid user", ""))
+		return 0, false
+	}
+	return customerID, true
+}
+
+// ownAddress loads an address from the path and checks it belongs to the logged in customer
+func (me *Me) ownAddress(c *gin.Context) (int64, *address_entity.Address, bool) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return 0, nil, false
+	}
+
+	addressID, err := strconv.ParseInt(c.Param("address_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid address ID", ""))
+		return 0, nil, false
+	}
+
+	address, err := application.NewAddressApplication(me.Persistence, c).GetAddress(addressID)
+	if err != nil || address == nil || address.CustomerID != customerID {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Address not found", ""))
+		return 0, nil, false
+	}
+
+	return customerID, address, true
+}
+
+// ownOrder loads an order from the path and checks it belongs to the logged in customer
+func (me *Me) ownOrder(c *gin.Context) (*order_entity.Order, bool) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return nil, false
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid order ID", ""))
+		return nil, false
+	}
+
+	order, err := application.NewOrderApplication(me.Persistence, c).GetOrder(orderID)
+	if err != nil || order == nil || order.ID == 0 || order.CustomerID != customerID {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Order not found", ""))
+		return nil, false
+	}
+
+	return order, true
+}
+
+//	@Summary		Get My Profile
+//	@Description	Retrieves the profile of the logged in customer.
+//	@Tags			Me
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		404	{object}	entity.ResponseContext	"Customer not found"
+//	@Router			/me [get]
+func (me *Me) GetProfile(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return
+	}
+
+	customer, _ := application.NewCustomerApplication(me.Persistence, c).GetCustomer(customerID)
+	if customer == nil || customer.ID == 0 {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Customer not found", ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Profile obtained", customer.ToResponse()))
+}
+
+//	@Summary		Update My Profile
+//	@Description	Updates the profile of the logged in customer. Fields left out of the body keep their value.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer	body		customer_entity.UpdateCustomerRequest	true	"Customer fields to be updated"
+//	@Success		200			{object}	entity.ResponseContext					"Success"
+//	@Failure		404			{object}	entity.ResponseContext					"Customer not found"
+//	@Failure		422			{object}	entity.ResponseContext					"Validation errors by field"
+//	@Router			/me [put]
+func (me *Me) UpdateProfile(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return
+	}
+
+	customerApp := application.NewCustomerApplication(me.Persistence, c)
+	existingCustomer, _ := customerApp.GetCustomer(customerID)
+	if existingCustomer == nil || existingCustomer.ID == 0 {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Customer not found", ""))
+		return
+	}
+
+	updateRequest := customer_entity.NewUpdateCustomerRequest(existingCustomer)
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	updatedCustomer, updateErr := customerApp.UpdateCustomer(customerID, updateRequest)
+	if updateErr != nil {
+		if _, ok := updateErr["db_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, updateErr["db_error"], ""))
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid customer", updateErr))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Profile updated successfully", updatedCustomer.ToResponse()))
+}
+
+//	@Summary		Change My Password
+//	@Description	Changes the password of the logged in customer after checking the old one. Every session is signed out afterwards.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Param			password	body		customer_entity.ChangePasswordRequest	true	"Old and new password"
+//	@Success		200			{object}	entity.ResponseContext					"Success"
+//	@Failure		422			{object}	entity.ResponseContext					"Validation errors by field"
+//	@Router			/me/password [put]
+func (me *Me) ChangePassword(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return
+	}
+
+	changeRequest := customer_entity.ChangePasswordRequest{}
+	if err := c.ShouldBindJSON(&changeRequest); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	changeErr := application.NewCustomerApplication(me.Persistence, c).ChangePassword(customerID, changeRequest)
+	if changeErr != nil {
+		if _, ok := changeErr["db_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, changeErr["db_error"], ""))
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid password", changeErr))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Password changed, please log in again", ""))
+}
+
+//	@Summary		Delete My Account
+//	@Description	Deletes the account of the logged in customer after checking the password and signs out every session.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Param			confirmation	body		customer_entity.DeleteAccountRequest	true	"Current password"
+//	@Success		200				{object}	entity.ResponseContext					"Success"
+//	@Failure		422				{object}	entity.ResponseContext					"Validation errors by field"
+//	@Router			/me [delete]
+func (me *Me) DeleteAccount(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return
+	}
+
+	deleteRequest := customer_entity.DeleteAccountRequest{}
+	if err := c.ShouldBindJSON(&deleteRequest); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	deleteErr := application.NewCustomerApplication(me.Persistence, c).DeleteAccount(customerID, deleteRequest)
+	if deleteErr != nil {
+		if _, ok := deleteErr["db_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, deleteErr["db_error"], ""))
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Account could not be deleted", deleteErr))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Account deleted", ""))
+}
+
+//	@Summary		Get My Addresses
+//	@Description	Retrieves the address book of the logged in customer, default address first.
+//	@Tags			Me
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/me/addresses [get]
+func (me *Me) GetAddresses(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return
+	}
+
+	allAddresses, err := application.NewAddressApplication(me.Persistence, c).GetAddressesByCustomer(customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": allAddresses,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Addresses obtained", results))
+}
+
+//	@Summary		Save My Address
+//	@Description	Adds an address to the address book of the logged in customer.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Param			address	body		address_entity.Address	true	"Address to be saved"
+//	@Success		201		{object}	entity.ResponseContext	"Success"
+//	@Failure		422		{object}	entity.ResponseContext	"Unprocessable entity"
+//	@Router			/me/addresses [post]
+func (me *Me) SaveAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return
+	}
+
+	address := address_entity.Address{}
+	if err := c.ShouldBindJSON(&address); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+	address.ID = 0
+	address.CustomerID = customerID
+
+	savedAddress, saveErr := application.NewAddressApplication(me.Persistence, c).SaveAddress(&address)
+	if saveErr != nil {
+		if _, ok := saveErr["db_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, saveErr["db_error"], ""))
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid address", saveErr))
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Address saved successfully", savedAddress))
+}
+
+//	@Summary		Get My Address
+//	@Description	Retrieves a single address of the logged in customer.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			address_id	path		int						true	"Address ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Address not found"
+//	@Router			/me/addresses/{address_id} [get]
+func (me *Me) GetAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	_, address, ok := me.ownAddress(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Address %v obtained", address.ID), address))
+}
+
+//	@Summary		Update My Address
+//	@Description	Updates an address of the logged in customer. Orders already placed keep their own copy of the address.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Param			address_id	path		int						true	"Address ID"
+//	@Param			address		body		address_entity.Address	true	"Updated address"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Address not found"
+//	@Router			/me/addresses/{address_id} [put]
+func (me *Me) UpdateAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, existingAddress, ok := me.ownAddress(c)
+	if !ok {
+		return
+	}
+
+	address := address_entity.Address{}
+	if err := c.ShouldBindJSON(&address); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+	address.ID = existingAddress.ID
+	address.CustomerID = customerID
+
+	updatedAddress, updateErr := application.NewAddressApplication(me.Persistence, c).UpdateAddress(&address)
+	if updateErr != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, updateErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Address updated successfully", updatedAddress))
+}
+
+//	@Summary		Set My Default Address
+//	@Description	Makes the address the default delivery address of the logged in customer.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			address_id	path		int						true	"Address ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Address not found"
+//	@Router			/me/addresses/{address_id}/default [put]
+func (me *Me) SetDefaultAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, address, ok := me.ownAddress(c)
+	if !ok {
+		return
+	}
+
+	defaultAddress, err := application.NewAddressApplication(me.Persistence, c).SetDefaultAddress(customerID, int64(address.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Address %v is now the default", address.ID), defaultAddress))
+}
+
+//	@Summary		Delete My Address
+//	@Description	Removes an address from the address book of the logged in customer.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			address_id	path		int						true	"Address ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Address not found"
+//	@Router			/me/addresses/{address_id} [delete]
+func (me *Me) DeleteAddress(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	_, address, ok := me.ownAddress(c)
+	if !ok {
+		return
+	}
+
+	err := application.NewAddressApplication(me.Persistence, c).DeleteAddress(int64(address.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusError, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Address %v has been deleted", address.ID), ""))
+}
+
+//	@Summary		Get My Orders
+//	@Description	Retrieves the order history of the logged in customer, newest first, one page at a time.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			page		query		int						false	"Page number, starting at 1"
+//	@Param			page_size	query		int						false	"Orders per page, at most 100"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/me/orders [get]
+func (me *Me) GetOrders(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return
+	}
+
+	pagination := entity.NewPagination(c.Query("page"), c.Query("page_size"))
+	customerOrders, err := application.NewOrderApplication(me.Persistence, c).GetOrdersByCustomerPage(customerID, &pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results":    customerOrders,
+		"pagination": pagination,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Orders obtained", results))
+}
+
+//	@Summary		Get My Order
+//	@Description	Retrieves one order of the logged in customer with its items.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			order_id	path		int						true	"Order ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Order not found"
+//	@Router			/me/orders/{order_id} [get]
+func (me *Me) GetOrder(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	order, ok := me.ownOrder(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Order %v obtained", order.ID), order))
+}
+
+//	@Summary		Cancel My Order
+//	@Description	Cancels a pending order of the logged in customer and puts its items back in stock.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			order_id	path		int						true	"Order ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Order not found"
+//	@Failure		409			{object}	entity.ResponseContext	"Order is no longer pending"
+//	@Router			/me/orders/{order_id}/cancel [post]
+func (me *Me) CancelOrder(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	order, ok := me.ownOrder(c)
+	if !ok {
+		return
+	}
+
+	cancelledOrder, err := application.NewOrderApplication(me.Persistence, c).CancelOrder(int64(order.ID))
+	if err != nil {
+		c.JSON(http.StatusConflict, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Order %v cancelled", order.ID), cancelledOrder))
+}
+
+//	@Summary		Export My Data
+//	@Description	Downloads a zip archive of everything stored about the logged in customer.
+//	@Tags			Me
+//	@Produce		application/zip
+//	@Success		200	{file}		file					"Zip archive"
+//	@Failure		404	{object}	entity.ResponseContext	"Customer not found"
+//	@Router			/me/export [get]
+func (me *Me) ExportData(c *gin.Context) {
+	customerID, ok := me.currentCustomerID(c)
+	if !ok {
+		return
+	}
+
+	sendCustomerExport(c, me.Persistence, customerID)
+}
@@ -9,8 +9,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/harisquqo/quqo-challenge-1/application"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/order_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
@@ -39,13 +41,13 @@ func (or Order) SaveOrder(c *gin.Context) {
 	}
 
 	or.Persistence.Logger.Info("handlers/SaveOrder", metadata)
-	if userIdErr != nil {
+	// API keys act for no customer, they can only place orders for one named in the body
+	if userIdErr != nil && !middleware.IsAPIKey(c) {
 		// Log error within the span
 		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid user", ""))
 		return
 	}
 
-	rawOrder.CustomerID = userId
 	if err := c.ShouldBindJSON(&rawOrder); err != nil {
 		// Log error within the span
 		log.Println(err)
@@ -53,9 +55,22 @@ func (or Order) SaveOrder(c *gin.Context) {
 		return
 	}
 
+	// Only staff can place orders on behalf of another customer
+	if rawOrder.CustomerID == 0 || !middleware.HasPermission(c, auth_entity.PermOrdersWrite) {
+		rawOrder.CustomerID = userId
+	}
+	// Customers' orders wait for their payment to be authorized before they are confirmed
+	if !middleware.HasPermission(c, auth_entity.PermOrdersWrite) {
+		rawOrder.Status = order_entity.OrderStatusAwaitingPayment
+	}
+	if rawOrder.CustomerID == 0 {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid user", ""))
+		return
+	}
+
 
 	or.OrderRepo = application.NewOrderApplication(or.Persistence, c)
-	savedOrder, saveErr := or.OrderRepo.SaveOrderFromRaw(rawOrder)
+	savedOrders, saveErr := or.OrderRepo.PlaceOrder(rawOrder)
 	if saveErr != nil {
 		// Log error within the span
 		or.Persistence.Logger.Error("Error from saving", map[string]interface{}{"error": saveErr})
@@ -63,7 +78,16 @@ func (or Order) SaveOrder(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Order saved successfully", &savedOrder))
+	// An order no single warehouse could fulfil comes back as the orders it was split into
+	if len(savedOrders) > 1 {
+		results := map[string]interface{}{
+			"results": savedOrders,
+		}
+		c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Order split across %v warehouses", len(savedOrders)), results))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Order saved successfully", &savedOrders[0]))
 }
 
 //	@Summary		Get All Orders
@@ -80,7 +104,15 @@ func (or Order) GetAllOrders(c *gin.Context) {
 
 	or.OrderRepo = application.NewOrderApplication(or.Persistence, c)
 
-	allOrders, err := or.OrderRepo.GetAllOrders()
+	var allOrders []order_entity.Order
+	var err error
+	if middleware.HasPermission(c, auth_entity.PermOrdersRead) {
+		allOrders, err = or.OrderRepo.GetAllOrders()
+	} else {
+		// Customers only see their own orders
+		userId, _ := strconv.ParseInt(c.GetString("userID"), 10, 64)
+		allOrders, err = or.OrderRepo.GetOrdersByCustomer(userId)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
 		return
@@ -121,19 +153,24 @@ func (or Order) GetOrder(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
 		return
 	}
+
+	if order == nil || (!middleware.HasPermission(c, auth_entity.PermOrdersRead) && !middleware.IsSelf(c, order.CustomerID)) {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Order not found", ""))
+		return
+	}
 	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Order %v obtained", orderID), order))
 }
 
 // DeleteOrder deletes a specific order by its ID.
 //	@Summary		Delete Order
-//	@Description	Deletes a specific order by its ID.
+//	@Description	Deletes a specific order by its ID. A pending order is cancelled first, giving back its stock, delivery slot and payments. Orders being picked, packed or shipped cannot be deleted.
 //	@Tags			Order
 //	@Accept			json
 //	@Produce		json
 //	@Param			order_id	path		int						true	"Order ID"
 //	@Success		200			{object}	entity.ResponseContext	"Success"
 //	@Failure		400			{object}	entity.ResponseContext	"Bad request"
-//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Failure		409			{object}	entity.ResponseContext	"Order cannot be deleted"
 //	@Router			/orders/{order_id} [delete]
 func (or Order) DeleteOrder(c *gin.Context) {
 	responseContextData := entity.ResponseContext{Ctx: c}
@@ -147,26 +184,9 @@ func (or Order) DeleteOrder(c *gin.Context) {
 	}
 	or.OrderRepo = application.NewOrderApplication(or.Persistence, c)
 
-	if err != nil {
-		fmt.Println(err)
-		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
-		return
-	}
-
 	deleteErr := or.OrderRepo.DeleteOrder(orderID)
-	// TODO: when deleting a order, need to delete all the inventory in it
-	orderedItems, orderedItemsErr := application.NewOrderedItemApplication(or.Persistence, c).GetAllOrderedItemsForOrder(orderID)
-	if orderedItemsErr != nil {	
-		fmt.Println(err)
-		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
-		return
-	}
-
-	application.NewOrderedItemApplication(or.Persistence, c).ReverseOrder(orderedItems)
-
-
 	if deleteErr != nil {
-		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, deleteErr.Error(), ""))
+		c.JSON(http.StatusConflict, responseContextData.ResponseData(entity.StatusFail, deleteErr.Error(), ""))
 		return
 	}
 
@@ -175,7 +195,7 @@ func (or Order) DeleteOrder(c *gin.Context) {
 
 // UpdateOrder updates a specific order by its ID.
 //	@Summary		Update Order
-//	@Description	Updates a specific order by its ID.
+//	@Description	Updates a specific order by its ID. The status, customer, warehouse, totals, delivery address and slot, wallet and points amounts and ordered items cannot be changed here.
 //	@Tags			Order
 //	@Accept			json
 //	@Produce		json
@@ -228,3 +248,32 @@ func (or Order) UpdateOrder(c *gin.Context) {
 
 	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Order updated successfully", updatedOrder))
 }
+
+// CancelOrder cancels a pending or unpaid order for the customer.
+//
+//	@Summary		Cancel Order
+//	@Description	Cancels a pending or unpaid order, restocking its items, freeing its delivery slot, refunding the wallet and points and voiding its payment.
+//	@Tags			Order
+//	@Produce		json
+//	@Param			order_id	path		int						true	"Order ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Invalid Order ID"
+//	@Failure		409			{object}	entity.ResponseContext	"Order can no longer be cancelled"
+//	@Router			/admin/orders/{order_id}/cancel [post]
+func (or Order) CancelOrder(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Order ID", ""))
+		return
+	}
+
+	or.OrderRepo = application.NewOrderApplication(or.Persistence, c)
+	cancelledOrder, cancelErr := or.OrderRepo.CancelOrder(orderID)
+	if cancelErr != nil {
+		c.JSON(http.StatusConflict, responseContextData.ResponseData(entity.StatusFail, cancelErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Order %v cancelled", orderID), cancelledOrder))
+}
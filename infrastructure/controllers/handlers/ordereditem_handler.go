@@ -8,7 +8,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/harisquqo/quqo-challenge-1/application"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/ordereditem_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
@@ -79,6 +81,14 @@ func (or OrderedItem) GetAllOrderedItemsForOrder(c *gin.Context) {
 		return
 	}
 
+	if !middleware.HasPermission(c, auth_entity.PermOrdersRead) {
+		order, _ := application.NewOrderApplication(or.Persistence, c).GetOrder(orderId)
+		if order == nil || !middleware.IsSelf(c, order.CustomerID) {
+			c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Order not found", ""))
+			return
+		}
+	}
+
 	or.OrderedItemRepo = application.NewOrderedItemApplication(or.Persistence, c)
 
 	orderedItems, err := or.OrderedItemRepo.GetAllOrderedItemsForOrder(orderId)
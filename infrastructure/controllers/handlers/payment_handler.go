@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/payment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/payment_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// PaymentSignatureHeader carries the gateway's signature of a webhook payload
+const PaymentSignatureHeader = "X-Payment-Signature"
+
+type Payment struct {
+	PaymentRepo payment_repository.PaymentHandlerRepository
+	Persistence *base.Persistence
+}
+
+func NewPayment(p *base.Persistence) *Payment {
+	return &Payment{
+		Persistence: p,
+	}
+}
+
+// PayMyOrder pays an order of the logged in customer.
+//
+//	@Summary		Pay My Order
+//	@Description	Authorizes the checkout total of an order of the logged in customer awaiting payment with a payment method tokenized by the gateway. The order is confirmed once the payment is authorized.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Param			order_id	path		int								true	"Order ID"
+//	@Param			payment		body		payment_entity.PaymentRequest	true	"Payment method"
+//	@Success		201			{object}	entity.ResponseContext			"Payment authorized"
+//	@Failure		404			{object}	entity.ResponseContext			"Order not found"
+//	@Failure		422			{object}	entity.ResponseContext			"Payment declined"
+//	@Failure		502			{object}	entity.ResponseContext			"Gateway unavailable"
+//	@Router			/me/orders/{order_id}/pay [post]
+func (pm *Payment) PayMyOrder(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	orderID, ok := pm.ownOrder(c)
+	if !ok {
+		return
+	}
+
+	request := payment_entity.PaymentRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	pm.PaymentRepo = application.NewPaymentApplication(pm.Persistence, c)
+
+	payment, payErr := pm.PaymentRepo.AuthorizePayment(orderID, request, staffID(c))
+	if payErr != nil {
+		sendPaymentErrors(c, payErr, "Payment failed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Payment authorized successfully", payment))
+}
+
+// GetMyOrderPayments lists the payments of an order of the logged in customer.
+//
+//	@Summary		Get My Order Payments
+//	@Description	Lists the payment attempts of an order of the logged in customer with their events.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			order_id	path		int						true	"Order ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Order not found"
+//	@Router			/me/orders/{order_id}/payments [get]
+func (pm *Payment) GetMyOrderPayments(c *gin.Context) {
+	orderID, ok := pm.ownOrder(c)
+	if !ok {
+		return
+	}
+
+	pm.sendOrderPayments(c, orderID)
+}
+
+// GetOrderPayments lists the payments of an order.
+//
+//	@Summary		Get Order Payments
+//	@Description	Lists the payment attempts of an order with their events.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			order_id	path		int						true	"Order ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/orders/{order_id}/payments [get]
+func (pm *Payment) GetOrderPayments(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Order ID", ""))
+		return
+	}
+
+	pm.sendOrderPayments(c, orderID)
+}
+
+// GetPayment retrieves a payment.
+//
+//	@Summary		Get Payment
+//	@Description	Retrieves a payment with its events.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			payment_id	path		int						true	"Payment ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Payment not found"
+//	@Router			/payments/{payment_id} [get]
+func (pm *Payment) GetPayment(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	paymentID, err := strconv.ParseInt(c.Param("payment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Payment ID", ""))
+		return
+	}
+
+	pm.PaymentRepo = application.NewPaymentApplication(pm.Persistence, c)
+
+	payment, err := pm.PaymentRepo.GetPayment(paymentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Payment %v obtained", paymentID), payment))
+}
+
+// CapturePayment captures an authorized payment.
+//
+//	@Summary		Capture Payment
+//	@Description	Takes the money of an authorized payment, all of it unless an amount is given.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			payment_id	path		int								true	"Payment ID"
+//	@Param			capture		body		payment_entity.AmountRequest	false	"Amount to capture"
+//	@Success		200			{object}	entity.ResponseContext			"Payment captured"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext			"Payment not found"
+//	@Failure		422			{object}	entity.ResponseContext			"Payment cannot be captured"
+//	@Failure		502			{object}	entity.ResponseContext			"Gateway unavailable"
+//	@Router			/payments/{payment_id}/capture [post]
+func (pm *Payment) CapturePayment(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	paymentID, request, ok := pm.amountRequest(c)
+	if !ok {
+		return
+	}
+
+	payment, captureErr := pm.PaymentRepo.CapturePayment(paymentID, request, staffID(c))
+	if captureErr != nil {
+		sendPaymentErrors(c, captureErr, "Could not capture payment")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Payment captured successfully", payment))
+}
+
+// VoidPayment voids an authorized payment.
+//
+//	@Summary		Void Payment
+//	@Description	Releases an authorized payment that will not be captured.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			payment_id	path		int						true	"Payment ID"
+//	@Success		200			{object}	entity.ResponseContext	"Payment voided"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Payment not found"
+//	@Failure		422			{object}	entity.ResponseContext	"Payment cannot be voided"
+//	@Failure		502			{object}	entity.ResponseContext	"Gateway unavailable"
+//	@Router			/payments/{payment_id}/void [post]
+func (pm *Payment) VoidPayment(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	paymentID, err := strconv.ParseInt(c.Param("payment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Payment ID", ""))
+		return
+	}
+
+	pm.PaymentRepo = application.NewPaymentApplication(pm.Persistence, c)
+
+	payment, voidErr := pm.PaymentRepo.VoidPayment(paymentID, staffID(c))
+	if voidErr != nil {
+		sendPaymentErrors(c, voidErr, "Could not void payment")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Payment voided successfully", payment))
+}
+
+// RefundPayment refunds a captured payment.
+//
+//	@Summary		Refund Payment
+//	@Description	Pays back captured money, all that is left unless an amount is given.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			payment_id	path		int								true	"Payment ID"
+//	@Param			refund		body		payment_entity.AmountRequest	false	"Amount to refund"
+//	@Success		200			{object}	entity.ResponseContext			"Payment refunded"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext			"Payment not found"
+//	@Failure		422			{object}	entity.ResponseContext			"Payment cannot be refunded"
+//	@Failure		502			{object}	entity.ResponseContext			"Gateway unavailable"
+//	@Router			/payments/{payment_id}/refund [post]
+func (pm *Payment) RefundPayment(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	paymentID, request, ok := pm.amountRequest(c)
+	if !ok {
+		return
+	}
+
+	payment, refundErr := pm.PaymentRepo.RefundPayment(paymentID, request, staffID(c))
+	if refundErr != nil {
+		sendPaymentErrors(c, refundErr, "Could not refund payment")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Payment refunded successfully", payment))
+}
+
+// PaymentWebhook receives notifications from the payment gateway.
+//
+//	@Summary		Payment Webhook
+//	@Description	Receives a signed notification from the payment gateway about one of its payments. Notifications already received are acknowledged without being applied again.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Payment-Signature	header		string					true	"Signature of the payload"
+//	@Success		200					{object}	entity.ResponseContext	"Webhook received"
+//	@Failure		401					{object}	entity.ResponseContext	"Invalid signature"
+//	@Failure		404					{object}	entity.ResponseContext	"Payment not found"
+//	@Router			/payments/webhook [post]
+func (pm *Payment) PaymentWebhook(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid payload", ""))
+		return
+	}
+
+	pm.PaymentRepo = application.NewPaymentApplication(pm.Persistence, c)
+
+	if webhookErr := pm.PaymentRepo.HandleWebhook(payload, c.GetHeader(PaymentSignatureHeader)); webhookErr != nil {
+		if signatureErr, ok := webhookErr["signature"]; ok {
+			c.JSON(http.StatusUnauthorized, responseContextData.ResponseData(entity.StatusFail, signatureErr, ""))
+			return
+		}
+		sendWarehouseErrors(c, webhookErr, "Invalid webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Webhook received", ""))
+}
+
+func (pm *Payment) sendOrderPayments(c *gin.Context, orderID int64) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	pm.PaymentRepo = application.NewPaymentApplication(pm.Persistence, c)
+
+	payments, err := pm.PaymentRepo.GetPaymentsForOrder(orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": payments,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Payments obtained", results))
+}
+
+// amountRequest reads the payment from the path and the optional amount from the body
+func (pm *Payment) amountRequest(c *gin.Context) (int64, payment_entity.AmountRequest, bool) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	request := payment_entity.AmountRequest{}
+	paymentID, err := strconv.ParseInt(c.Param("payment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Payment ID", ""))
+		return 0, request, false
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+			return 0, request, false
+		}
+	}
+
+	pm.PaymentRepo = application.NewPaymentApplication(pm.Persistence, c)
+	return paymentID, request, true
+}
+
+// ownOrder reads the order from the path and checks it belongs to the logged in customer
+func (pm *Payment) ownOrder(c *gin.Context) (int64, bool) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid order ID", ""))
+		return 0, false
+	}
+
+	order, _ := application.NewOrderApplication(pm.Persistence, c).GetOrder(orderID)
+	if order == nil || order.ID == 0 || order.CustomerID != staffID(c) {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Order not found", ""))
+		return 0, false
+	}
+
+	return orderID, true
+}
+
+// sendPaymentErrors answers 502 when the gateway could not be reached, otherwise like sendWarehouseErrors
+func sendPaymentErrors(c *gin.Context, errs map[string]string, message string) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	if gatewayError, ok := errs["gateway_error"]; ok {
+		c.JSON(http.StatusBadGateway, responseContextData.ResponseData(entity.StatusFail, gatewayError, ""))
+		return
+	}
+
+	sendWarehouseErrors(c, errs, message)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/picking_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/picking_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Picking struct {
+	PickingRepo picking_repository.PickingHandlerRepository
+	Persistence *base.Persistence
+}
+
+func NewPicking(p *base.Persistence) *Picking {
+	return &Picking{
+		Persistence: p,
+	}
+}
+
+// CreatePickList batches pending orders of a warehouse into a pick list.
+//	@Summary		Create Pick List
+//	@Description	Batches pending orders of a warehouse into a pick list sorted by shelf location and moves the orders to picking. Without order_ids the oldest pending orders are taken, earliest delivery slot first.
+//	@Tags			Picking
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int								true	"Warehouse ID"
+//	@Param			request			body		picking_entity.PickListRequest	false	"Orders to pick"
+//	@Success		201				{object}	entity.ResponseContext			"Pick list created"
+//	@Failure		400				{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext			"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext			"No orders to pick"
+//	@Router			/warehouses/{warehouse_id}/pick-lists [post]
+func (pi *Picking) CreatePickList(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := picking_entity.PickListRequest{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+			return
+		}
+	}
+
+	pi.PickingRepo = application.NewPickingApplication(pi.Persistence, c)
+
+	pickList, createErr := pi.PickingRepo.CreatePickList(warehouseID, request, staffID(c))
+	if createErr != nil {
+		sendWarehouseErrors(c, createErr, "Could not create pick list")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Pick list created successfully", pickList))
+}
+
+// GetPickLists lists the pick lists of a warehouse.
+//	@Summary		Get Pick Lists
+//	@Description	Lists the pick lists of a warehouse, newest first.
+//	@Tags			Picking
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			status			query		string					false	"open, picked or completed"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/pick-lists [get]
+func (pi *Picking) GetPickLists(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	pi.PickingRepo = application.NewPickingApplication(pi.Persistence, c)
+
+	pickLists, getErr := pi.PickingRepo.GetPickLists(warehouseID, c.Query("status"))
+	if getErr != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": pickLists,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Pick lists of warehouse %v obtained", warehouseID), results))
+}
+
+// GetPickList retrieves a pick list with its items in walking order.
+//	@Summary		Get Pick List
+//	@Description	Retrieves a pick list with its items sorted by shelf location.
+//	@Tags			Picking
+//	@Accept			json
+//	@Produce		json
+//	@Param			pick_list_id	path		int						true	"Pick list ID"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Pick list not found"
+//	@Router			/pick-lists/{pick_list_id} [get]
+func (pi *Picking) GetPickList(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	pickListID, err := strconv.ParseInt(c.Param("pick_list_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Pick List ID", ""))
+		return
+	}
+
+	pi.PickingRepo = application.NewPickingApplication(pi.Persistence, c)
+
+	pickList, getErr := pi.PickingRepo.GetPickList(pickListID)
+	if getErr != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Pick list obtained", pickList))
+}
+
+// ConfirmPick records how many units of a pick list item were found.
+//	@Summary		Confirm Pick
+//	@Description	Records how many units of an item were found, all of them when picked_quantity is left out. Units not found are taken off the order and their stock is returned. Orders move to picked once all their items are confirmed.
+//	@Tags			Picking
+//	@Accept			json
+//	@Produce		json
+//	@Param			pick_list_id	path		int								true	"Pick list ID"
+//	@Param			pick_item_id	path		int								true	"Pick item ID"
+//	@Param			confirmation	body		picking_entity.PickConfirmation	false	"Units found"
+//	@Success		200				{object}	entity.ResponseContext			"Pick confirmed"
+//	@Failure		400				{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext			"Pick list or item not found"
+//	@Failure		422				{object}	entity.ResponseContext			"Invalid confirmation"
+//	@Router			/pick-lists/{pick_list_id}/items/{pick_item_id}/confirm [post]
+func (pi *Picking) ConfirmPick(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	pickListID, err := strconv.ParseInt(c.Param("pick_list_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Pick List ID", ""))
+		return
+	}
+	pickItemID, err := strconv.ParseInt(c.Param("pick_item_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Pick Item ID", ""))
+		return
+	}
+
+	confirmation := picking_entity.PickConfirmation{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&confirmation); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+			return
+		}
+	}
+
+	pi.PickingRepo = application.NewPickingApplication(pi.Persistence, c)
+
+	pickList, confirmErr := pi.PickingRepo.ConfirmPick(pickListID, pickItemID, confirmation, staffID(c))
+	if confirmErr != nil {
+		sendWarehouseErrors(c, confirmErr, "Could not confirm pick")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Pick confirmed", pickList))
+}
+
+// PackOrder records the packages of a picked order.
+//	@Summary		Pack Order
+//	@Description	Records the package count and total weight of a picked order and moves it to packed.
+//	@Tags			Picking
+//	@Accept			json
+//	@Produce		json
+//	@Param			order_id	path		int							true	"Order ID"
+//	@Param			packing		body		picking_entity.PackRequest	true	"Packages"
+//	@Success		200			{object}	entity.ResponseContext		"Order packed"
+//	@Failure		400			{object}	entity.ResponseContext		"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext		"Order not found"
+//	@Failure		422			{object}	entity.ResponseContext		"Invalid packing details"
+//	@Router			/orders/{order_id}/pack [post]
+func (pi *Picking) PackOrder(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Order ID", ""))
+		return
+	}
+
+	request := picking_entity.PackRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	pi.PickingRepo = application.NewPickingApplication(pi.Persistence, c)
+
+	packedOrder, packErr := pi.PickingRepo.PackOrder(orderID, request)
+	if packErr != nil {
+		sendWarehouseErrors(c, packErr, "Could not pack order")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Order packed successfully", packedOrder))
+}
+
+// staffID is the customer id of the logged in staff member, zero for API keys
+func staffID(c *gin.Context) int64 {
+	id, _ := strconv.ParseInt(c.GetString("userID"), 10, 64)
+	return id
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/privacy_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Privacy struct {
+	PrivacyRepo privacy_repository.PrivacyHandlerRepository
+	Persistence *base.Persistence
+}
+
+func NewPrivacy(p *base.Persistence) *Privacy {
+	return &Privacy{
+		Persistence: p,
+	}
+}
+
+// sendCustomerExport writes the data archive of the customer as a zip download
+func sendCustomerExport(c *gin.Context, p *base.Persistence, customerID int64) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	archive, err := application.NewPrivacyApplication(p, c).ExportCustomerData(customerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	filename := fmt.Sprintf("customer-%v-%v.zip", customerID, time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+//	@Summary		Export Customer Data
+//	@Description	Downloads a zip archive of everything stored about a customer: profile, addresses, orders with their items, tokens, audit events, wallet and points ledgers, returns, payments and shipments with their proof of delivery.
+//	@Tags			Privacy
+//	@Produce		application/zip
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Success		200			{file}		file					"Zip archive"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Customer not found"
+//	@Router			/customers/{customer_id}/export [get]
+func (pr *Privacy) ExportCustomerData(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, err := strconv.ParseInt(c.Param("customer_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid customer ID", ""))
+		return
+	}
+
+	sendCustomerExport(c, pr.Persistence, customerID)
+}
+
+//	@Summary		Erase Customer
+//	@Description	Erases a customer's personal data. The profile, the delivery details on orders, the notes on returns and the recipient, proof of delivery and locations of shipments are anonymised, addresses, tokens and proof of delivery files are deleted, and the customer is removed from the cache and the search index. Orders, wallet and points ledgers, payments and inventory logs are kept for accounting, the report says why. Customers with orders not yet delivered, cancelled or returned cannot be erased.
+//	@Tags			Privacy
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		409			{object}	entity.ResponseContext	"Customer cannot be erased yet"
+//	@Router			/customers/{customer_id}/erase [post]
+func (pr *Privacy) EraseCustomer(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, err := strconv.ParseInt(c.Param("customer_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid customer ID", ""))
+		return
+	}
+
+	pr.PrivacyRepo = application.NewPrivacyApplication(pr.Persistence, c)
+	report, err := pr.PrivacyRepo.EraseCustomer(customerID)
+	if err != nil {
+		c.JSON(http.StatusConflict, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Customer %v erased", customerID), report))
+}
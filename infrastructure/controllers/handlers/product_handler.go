@@ -64,7 +64,11 @@ func (pr *Product) SaveProduct(c *gin.Context) {
     // Call the application layer method to save the product
 	savedProduct, savedInventory, saveErr := pr.productRepo.SaveProductAndInventory(productForInventory)
 	if saveErr != nil {
-		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, "Fail to save product", ""))
+		if _, ok := saveErr["db_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, "Fail to save product", ""))
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Fail to save product", saveErr))
 		return
 	}
 
@@ -196,7 +200,7 @@ func (pr *Product) DeleteProduct(c *gin.Context) {
 
 // UpdateProduct updates a product.
 //	@Summary		Update Product
-//	@Description	Updates a product.
+//	@Description	Updates a product. The type has to be single or bundle and cannot be changed.
 //	@Tags			Product
 //	@Accept			json
 //	@Produce		json
@@ -227,11 +231,22 @@ func (pr *Product) UpdateProduct(c *gin.Context) {
 	}
 
 	// Bind the JSON request body to the existing product
+	productType := existingProduct.Type
 	if err := c.ShouldBindJSON(&existingProduct); err != nil {
 		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
 		return
 	}
 
+	if typeErr := existingProduct.ValidateType(); typeErr != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, typeErr.Error(), ""))
+		return
+	}
+	// A single product holds stock and a bundle holds components, so one cannot be turned into the other
+	if existingProduct.Type != "" && productType != "" && existingProduct.Type != productType {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "type cannot be changed", ""))
+		return
+	}
+
 	pr.productRepo = application.NewProductApplication(pr.Persistence, c)
 
 	// Update the product
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/return_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/return_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Return struct {
+	ReturnRepo  return_repository.ReturnHandlerRepository
+	Persistence *base.Persistence
+}
+
+func NewReturn(p *base.Persistence) *Return {
+	return &Return{
+		Persistence: p,
+	}
+}
+
+// RequestReturn asks to return items of an order of the logged in customer.
+//
+//	@Summary		Request Return
+//	@Description	Asks to return items of a delivered order of the logged in customer, within 30 days of delivery. Each item needs a quantity and a reason, the refund is estimated straight away.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Param			order_id	path		int									true	"Order ID"
+//	@Param			return		body		return_entity.ReturnRequestInput	true	"Items to return"
+//	@Success		201			{object}	entity.ResponseContext				"Return requested"
+//	@Failure		404			{object}	entity.ResponseContext				"Order not found"
+//	@Failure		422			{object}	entity.ResponseContext				"Invalid return"
+//	@Router			/me/orders/{order_id}/returns [post]
+func (rt *Return) RequestReturn(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid order ID", ""))
+		return
+	}
+
+	order, _ := application.NewOrderApplication(rt.Persistence, c).GetOrder(orderID)
+	if order == nil || order.ID == 0 || order.CustomerID != staffID(c) {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Order not found", ""))
+		return
+	}
+
+	input := return_entity.ReturnRequestInput{}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	rt.ReturnRepo = application.NewReturnApplication(rt.Persistence, c)
+
+	ret, requestErr := rt.ReturnRepo.RequestReturn(orderID, input)
+	if requestErr != nil {
+		sendWarehouseErrors(c, requestErr, "Invalid return")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Return requested successfully", ret))
+}
+
+// GetMyReturns lists the returns of the logged in customer.
+//
+//	@Summary		Get My Returns
+//	@Description	Lists the returns of the logged in customer, newest first.
+//	@Tags			Me
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/me/returns [get]
+func (rt *Return) GetMyReturns(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	rt.ReturnRepo = application.NewReturnApplication(rt.Persistence, c)
+
+	rets, err := rt.ReturnRepo.GetReturns(return_entity.ReturnFilter{CustomerID: staffID(c)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": rets,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Returns obtained", results))
+}
+
+// GetMyReturn retrieves a return of the logged in customer.
+//
+//	@Summary		Get My Return
+//	@Description	Retrieves a return of the logged in customer with its items and refund.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			return_id	path		int						true	"Return ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		404			{object}	entity.ResponseContext	"Return not found"
+//	@Router			/me/returns/{return_id} [get]
+func (rt *Return) GetMyReturn(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	ret, ok := rt.ownReturn(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Return %v obtained", ret.ID), ret))
+}
+
+// CancelMyReturn withdraws a return of the logged in customer.
+//
+//	@Summary		Cancel My Return
+//	@Description	Withdraws a return of the logged in customer that has not been reviewed yet.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			return_id	path		int						true	"Return ID"
+//	@Success		200			{object}	entity.ResponseContext	"Return cancelled"
+//	@Failure		404			{object}	entity.ResponseContext	"Return not found"
+//	@Failure		422			{object}	entity.ResponseContext	"Return cannot be cancelled"
+//	@Router			/me/returns/{return_id}/cancel [post]
+func (rt *Return) CancelMyReturn(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	ret, ok := rt.ownReturn(c)
+	if !ok {
+		return
+	}
+
+	cancelled, cancelErr := rt.ReturnRepo.CancelReturn(int64(ret.ID))
+	if cancelErr != nil {
+		sendWarehouseErrors(c, cancelErr, "Could not cancel return")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Return cancelled successfully", cancelled))
+}
+
+// GetReturns lists the returns.
+//
+//	@Summary		Get Returns
+//	@Description	Lists the returns, newest first, optionally only those of an order, a warehouse or in a status.
+//	@Tags			Returns
+//	@Accept			json
+//	@Produce		json
+//	@Param			order_id		query		int						false	"Order ID"
+//	@Param			warehouse_id	query		int						false	"Warehouse ID"
+//	@Param			status			query		string					false	"Return status"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/returns [get]
+func (rt *Return) GetReturns(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	filter := return_entity.ReturnFilter{Status: c.Query("status")}
+	filter.OrderID, _ = strconv.ParseInt(c.Query("order_id"), 10, 64)
+	filter.WarehouseID, _ = strconv.ParseInt(c.Query("warehouse_id"), 10, 64)
+
+	rt.ReturnRepo = application.NewReturnApplication(rt.Persistence, c)
+
+	rets, err := rt.ReturnRepo.GetReturns(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": rets,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Returns obtained", results))
+}
+
+// GetReturn retrieves a return.
+//
+//	@Summary		Get Return
+//	@Description	Retrieves a return with its items and refund.
+//	@Tags			Returns
+//	@Accept			json
+//	@Produce		json
+//	@Param			return_id	path		int						true	"Return ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Return not found"
+//	@Router			/returns/{return_id} [get]
+func (rt *Return) GetReturn(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	returnID, err := strconv.ParseInt(c.Param("return_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Return ID", ""))
+		return
+	}
+
+	rt.ReturnRepo = application.NewReturnApplication(rt.Persistence, c)
+
+	ret, err := rt.ReturnRepo.GetReturn(returnID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Return %v obtained", returnID), ret))
+}
+
+// ApproveReturn approves a requested return.
+//
+//	@Summary		Approve Return
+//	@Description	Approves a requested return so the customer can send the items back.
+//	@Tags			Returns
+//	@Accept			json
+//	@Produce		json
+//	@Param			return_id	path		int							true	"Return ID"
+//	@Param			review		body		return_entity.ReviewRequest	false	"Review"
+//	@Success		200			{object}	entity.ResponseContext		"Return approved"
+//	@Failure		400			{object}	entity.ResponseContext		"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext		"Return not found"
+//	@Failure		422			{object}	entity.ResponseContext		"Return cannot be approved"
+//	@Router			/returns/{return_id}/approve [post]
+func (rt *Return) ApproveReturn(c *gin.Context) {
+	rt.reviewReturn(c, true)
+}
+
+// RejectReturn rejects a requested return.
+//
+//	@Summary		Reject Return
+//	@Description	Rejects a requested return, the note tells the customer why.
+//	@Tags			Returns
+//	@Accept			json
+//	@Produce		json
+//	@Param			return_id	path		int							true	"Return ID"
+//	@Param			review		body		return_entity.ReviewRequest	false	"Review"
+//	@Success		200			{object}	entity.ResponseContext		"Return rejected"
+//	@Failure		400			{object}	entity.ResponseContext		"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext		"Return not found"
+//	@Failure		422			{object}	entity.ResponseContext		"Return cannot be rejected"
+//	@Router			/returns/{return_id}/reject [post]
+func (rt *Return) RejectReturn(c *gin.Context) {
+	rt.reviewReturn(c, false)
+}
+
+// InspectReturn records the inspection of a returned parcel.
+//
+//	@Summary		Inspect Return
+//	@Description	Records, for every item of an approved return, how many units were accepted and whether they are restocked, written off or returned to the supplier. Restocked units are put back on sale, the return is completed and its refund settled on the accepted units.
+//	@Tags			Returns
+//	@Accept			json
+//	@Produce		json
+//	@Param			return_id	path		int								true	"Return ID"
+//	@Param			inspection	body		return_entity.InspectionRequest	true	"Inspection"
+//	@Success		200			{object}	entity.ResponseContext			"Return inspected"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext			"Return not found"
+//	@Failure		422			{object}	entity.ResponseContext			"Invalid inspection"
+//	@Router			/returns/{return_id}/inspect [post]
+func (rt *Return) InspectReturn(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	returnID, err := strconv.ParseInt(c.Param("return_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Return ID", ""))
+		return
+	}
+
+	request := return_entity.InspectionRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	rt.ReturnRepo = application.NewReturnApplication(rt.Persistence, c)
+
+	ret, inspectErr := rt.ReturnRepo.InspectReturn(returnID, request, staffID(c))
+	if inspectErr != nil {
+		sendWarehouseErrors(c, inspectErr, "Invalid inspection")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Return inspected successfully", ret))
+}
+
+func (rt *Return) reviewReturn(c *gin.Context, approve bool) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	returnID, err := strconv.ParseInt(c.Param("return_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Return ID", ""))
+		return
+	}
+
+	request := return_entity.ReviewRequest{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+			return
+		}
+	}
+
+	rt.ReturnRepo = application.NewReturnApplication(rt.Persistence, c)
+
+	if approve {
+		ret, reviewErr := rt.ReturnRepo.ApproveReturn(returnID, request, staffID(c))
+		if reviewErr != nil {
+			sendWarehouseErrors(c, reviewErr, "Could not approve return")
+			return
+		}
+		c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Return approved successfully", ret))
+		return
+	}
+
+	ret, reviewErr := rt.ReturnRepo.RejectReturn(returnID, request, staffID(c))
+	if reviewErr != nil {
+		sendWarehouseErrors(c, reviewErr, "Could not reject return")
+		return
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Return rejected successfully", ret))
+}
+
+// ownReturn loads a return from the path and checks it belongs to the logged in customer
+func (rt *Return) ownReturn(c *gin.Context) (*return_entity.ReturnRequest, bool) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	returnID, err := strconv.ParseInt(c.Param("return_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid return ID", ""))
+		return nil, false
+	}
+
+	rt.ReturnRepo = application.NewReturnApplication(rt.Persistence, c)
+
+	ret, _ := rt.ReturnRepo.GetReturn(returnID)
+	if ret == nil || ret.CustomerID != staffID(c) {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Return not found", ""))
+		return nil, false
+	}
+
+	return ret, true
+}
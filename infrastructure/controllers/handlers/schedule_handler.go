@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/schedule_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Schedule struct {
+	ScheduleRepo schedule_repository.ScheduleHandlerRepository
+	Persistence  *base.Persistence
+}
+
+func NewSchedule(p *base.Persistence) *Schedule {
+	return &Schedule{
+		Persistence: p,
+	}
+}
+
+// GetOperatingHours retrieves the weekly opening hours of a warehouse.
+//	@Summary		Get Operating Hours
+//	@Description	Retrieves the weekly opening hours of a warehouse in its timezone. Weekday 0 is Sunday, days without hours are closed.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/hours [get]
+func (sc *Schedule) GetOperatingHours(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	sc.ScheduleRepo = application.NewScheduleApplication(sc.Persistence, c)
+
+	hours, getErr := sc.ScheduleRepo.GetOperatingHours(warehouseID)
+	if getErr != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": hours,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Operating hours of warehouse %v obtained", warehouseID), results))
+}
+
+// UpdateOperatingHours replaces the weekly opening hours of a warehouse.
+//	@Summary		Update Operating Hours
+//	@Description	Replaces the weekly opening hours of a warehouse, days left out are closed. Times are HH:MM in the warehouse's timezone.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int										true	"Warehouse ID"
+//	@Param			hours			body		warehouse_entity.OperatingHoursRequest	true	"Weekly hours"
+//	@Success		200				{object}	entity.ResponseContext					"Success"
+//	@Failure		400				{object}	entity.ResponseContext					"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext					"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext					"Invalid hours"
+//	@Router			/warehouses/{warehouse_id}/hours [put]
+func (sc *Schedule) UpdateOperatingHours(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := warehouse_entity.OperatingHoursRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	sc.ScheduleRepo = application.NewScheduleApplication(sc.Persistence, c)
+
+	hours, updateErr := sc.ScheduleRepo.UpdateOperatingHours(warehouseID, request)
+	if updateErr != nil {
+		sendWarehouseErrors(c, updateErr, "Invalid operating hours")
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": hours,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Operating hours updated successfully", results))
+}
+
+// GetHolidays retrieves the dates a warehouse is closed.
+//	@Summary		Get Holidays
+//	@Description	Retrieves the dates a warehouse is closed.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/holidays [get]
+func (sc *Schedule) GetHolidays(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	sc.ScheduleRepo = application.NewScheduleApplication(sc.Persistence, c)
+
+	holidays, getErr := sc.ScheduleRepo.GetHolidays(warehouseID)
+	if getErr != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": holidays,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Holidays of warehouse %v obtained", warehouseID), results))
+}
+
+// SaveHoliday closes a warehouse on a date.
+//	@Summary		Save Holiday
+//	@Description	Closes a warehouse on a date and removes that day's delivery slots. Refused while any slot that day has reservations.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int								true	"Warehouse ID"
+//	@Param			holiday			body		warehouse_entity.HolidayRequest	true	"Holiday"
+//	@Success		201				{object}	entity.ResponseContext			"Holiday saved"
+//	@Failure		400				{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext			"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext			"Invalid holiday"
+//	@Router			/warehouses/{warehouse_id}/holidays [post]
+func (sc *Schedule) SaveHoliday(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := warehouse_entity.HolidayRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	sc.ScheduleRepo = application.NewScheduleApplication(sc.Persistence, c)
+
+	holiday, saveErr := sc.ScheduleRepo.SaveHoliday(warehouseID, request)
+	if saveErr != nil {
+		sendWarehouseErrors(c, saveErr, "Invalid holiday")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Holiday saved successfully", holiday))
+}
+
+// DeleteHoliday reopens a warehouse on a date.
+//	@Summary		Delete Holiday
+//	@Description	Removes a holiday, the warehouse is open on that date again.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			holiday_id		path		int						true	"Holiday ID"
+//	@Success		200				{object}	entity.ResponseContext	"Holiday deleted"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Holiday not found"
+//	@Router			/warehouses/{warehouse_id}/holidays/{holiday_id} [delete]
+func (sc *Schedule) DeleteHoliday(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	holidayID, err := strconv.ParseInt(c.Param("holiday_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Holiday ID", ""))
+		return
+	}
+
+	sc.ScheduleRepo = application.NewScheduleApplication(sc.Persistence, c)
+
+	if deleteErr := sc.ScheduleRepo.DeleteHoliday(warehouseID, holidayID); deleteErr != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, deleteErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Holiday deleted successfully", ""))
+}
+
+// GetDeliverySlots lists the delivery slots of a warehouse.
+//	@Summary		Get Delivery Slots
+//	@Description	Lists the delivery slots of a warehouse that can still be booked, with the places left in each. Staff can pass all=true to include slots that are full or already started.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			from			query		string					false	"First day, YYYY-MM-DD in the warehouse's timezone, defaults to today"
+//	@Param			days			query		int						false	"Number of days, defaults to 7 and at most 31"
+//	@Param			all				query		bool					false	"Include full and past slots"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Router			/warehouses/{warehouse_id}/delivery-slots [get]
+func (sc *Schedule) GetDeliverySlots(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	days, _ := strconv.Atoi(c.Query("days"))
+	onlyAvailable := c.Query("all") != "true" || !middleware.HasPermission(c, auth_entity.PermWarehousesRead)
+
+	sc.ScheduleRepo = application.NewScheduleApplication(sc.Persistence, c)
+
+	slots, getErr := sc.ScheduleRepo.GetDeliverySlots(warehouseID, c.Query("from"), days, onlyAvailable)
+	if getErr != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": slots,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Delivery slots of warehouse %v obtained", warehouseID), results))
+}
+
+// SaveDeliverySlot adds a delivery slot to a warehouse.
+//	@Summary		Save Delivery Slot
+//	@Description	Adds a delivery slot, which must fall inside the opening hours of a day the warehouse is open.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int										true	"Warehouse ID"
+//	@Param			slot			body		warehouse_entity.DeliverySlotRequest	true	"Delivery slot"
+//	@Success		201				{object}	entity.ResponseContext					"Delivery slot saved"
+//	@Failure		400				{object}	entity.ResponseContext					"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext					"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext					"Invalid delivery slot"
+//	@Router			/warehouses/{warehouse_id}/delivery-slots [post]
+func (sc *Schedule) SaveDeliverySlot(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := warehouse_entity.DeliverySlotRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	sc.ScheduleRepo = application.NewScheduleApplication(sc.Persistence, c)
+
+	slot, saveErr := sc.ScheduleRepo.SaveDeliverySlot(warehouseID, request)
+	if saveErr != nil {
+		sendWarehouseErrors(c, saveErr, "Invalid delivery slot")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Delivery slot saved successfully", slot))
+}
+
+// GenerateDeliverySlots fills a range of days with delivery slots.
+//	@Summary		Generate Delivery Slots
+//	@Description	Fills the opening hours of each day in the range with back to back slots of the given length and capacity. Holidays, past times and existing slots are skipped.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int										true	"Warehouse ID"
+//	@Param			request			body		warehouse_entity.GenerateSlotsRequest	true	"Days and slot size"
+//	@Success		201				{object}	entity.ResponseContext					"Delivery slots created"
+//	@Failure		400				{object}	entity.ResponseContext					"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext					"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext					"Invalid request"
+//	@Router			/warehouses/{warehouse_id}/delivery-slots/generate [post]
+func (sc *Schedule) GenerateDeliverySlots(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := warehouse_entity.GenerateSlotsRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	sc.ScheduleRepo = application.NewScheduleApplication(sc.Persistence, c)
+
+	slots, generateErr := sc.ScheduleRepo.GenerateDeliverySlots(warehouseID, request)
+	if generateErr != nil {
+		sendWarehouseErrors(c, generateErr, "Invalid request")
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": slots,
+	}
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("%v delivery slots created", len(slots)), results))
+}
+
+// DeleteDeliverySlot removes a delivery slot nobody has booked.
+//	@Summary		Delete Delivery Slot
+//	@Description	Removes a delivery slot, slots with reservations cannot be removed.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			slot_id			path		int						true	"Delivery slot ID"
+//	@Success		200				{object}	entity.ResponseContext	"Delivery slot deleted"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		409				{object}	entity.ResponseContext	"Delivery slot not found or has reservations"
+//	@Router			/warehouses/{warehouse_id}/delivery-slots/{slot_id} [delete]
+func (sc *Schedule) DeleteDeliverySlot(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	slotID, err := strconv.ParseInt(c.Param("slot_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Delivery Slot ID", ""))
+		return
+	}
+
+	sc.ScheduleRepo = application.NewScheduleApplication(sc.Persistence, c)
+
+	if deleteErr := sc.ScheduleRepo.DeleteDeliverySlot(warehouseID, slotID); deleteErr != nil {
+		c.JSON(http.StatusConflict, responseContextData.ResponseData(entity.StatusFail, deleteErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Delivery slot deleted successfully", ""))
+}
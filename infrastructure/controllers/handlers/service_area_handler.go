@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/service_area_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type ServiceArea struct {
+	ServiceAreaRepo service_area_repository.ServiceAreaHandlerRepository
+	Persistence     *base.Persistence
+}
+
+func NewServiceArea(p *base.Persistence) *ServiceArea {
+	return &ServiceArea{
+		Persistence: p,
+	}
+}
+
+// GetServiceAreas retrieves the service areas of a warehouse.
+//	@Summary		Get Service Areas
+//	@Description	Retrieves the GeoJSON service areas of a warehouse.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/service-areas [get]
+func (sa *ServiceArea) GetServiceAreas(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	sa.ServiceAreaRepo = application.NewServiceAreaApplication(sa.Persistence, c)
+
+	areas, getErr := sa.ServiceAreaRepo.GetServiceAreas(warehouseID)
+	if getErr != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": areas,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Service areas of warehouse %v obtained", warehouseID), results))
+}
+
+// SaveServiceArea adds a service area to a warehouse.
+//	@Summary		Save Service Area
+//	@Description	Adds a GeoJSON polygon service area to a warehouse. Once a warehouse has an active service area it only delivers inside its service areas, not within its service radius.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int									true	"Warehouse ID"
+//	@Param			service_area	body		warehouse_entity.ServiceAreaRequest	true	"Service area"
+//	@Success		201				{object}	entity.ResponseContext				"Service area saved"
+//	@Failure		400				{object}	entity.ResponseContext				"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext				"Warehouse not found"
+//	@Failure		422				{object}	entity.ResponseContext				"Invalid service area"
+//	@Failure		500				{object}	entity.ResponseContext				"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/service-areas [post]
+func (sa *ServiceArea) SaveServiceArea(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+
+	request := warehouse_entity.ServiceAreaRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	sa.ServiceAreaRepo = application.NewServiceAreaApplication(sa.Persistence, c)
+
+	savedArea, saveErr := sa.ServiceAreaRepo.SaveServiceArea(warehouseID, request)
+	if saveErr != nil {
+		sendWarehouseErrors(c, saveErr, "Invalid service area")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Service area saved successfully", savedArea))
+}
+
+// UpdateServiceArea replaces a service area of a warehouse.
+//	@Summary		Update Service Area
+//	@Description	Replaces the name and polygon of a service area, leaving out active keeps it as it is.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int									true	"Warehouse ID"
+//	@Param			service_area_id	path		int									true	"Service area ID"
+//	@Param			service_area	body		warehouse_entity.ServiceAreaRequest	true	"Service area"
+//	@Success		200				{object}	entity.ResponseContext				"Service area updated"
+//	@Failure		400				{object}	entity.ResponseContext				"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext				"Service area not found"
+//	@Failure		422				{object}	entity.ResponseContext				"Invalid service area"
+//	@Failure		500				{object}	entity.ResponseContext				"Internal server error"
+//	@Router			/warehouses/{warehouse_id}/service-areas/{service_area_id} [put]
+func (sa *ServiceArea) UpdateServiceArea(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	areaID, err := strconv.ParseInt(c.Param("service_area_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Service Area ID", ""))
+		return
+	}
+
+	request := warehouse_entity.ServiceAreaRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	sa.ServiceAreaRepo = application.NewServiceAreaApplication(sa.Persistence, c)
+
+	updatedArea, updateErr := sa.ServiceAreaRepo.UpdateServiceArea(warehouseID, areaID, request)
+	if updateErr != nil {
+		sendWarehouseErrors(c, updateErr, "Invalid service area")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Service area updated successfully", updatedArea))
+}
+
+// DeleteServiceArea removes a service area from a warehouse.
+//	@Summary		Delete Service Area
+//	@Description	Removes a service area from a warehouse.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	path		int						true	"Warehouse ID"
+//	@Param			service_area_id	path		int						true	"Service area ID"
+//	@Success		200				{object}	entity.ResponseContext	"Service area deleted"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Service area not found"
+//	@Router			/warehouses/{warehouse_id}/service-areas/{service_area_id} [delete]
+func (sa *ServiceArea) DeleteServiceArea(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, err := strconv.ParseInt(c.Param("warehouse_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Warehouse ID", ""))
+		return
+	}
+	areaID, err := strconv.ParseInt(c.Param("service_area_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Service Area ID", ""))
+		return
+	}
+
+	sa.ServiceAreaRepo = application.NewServiceAreaApplication(sa.Persistence, c)
+
+	if deleteErr := sa.ServiceAreaRepo.DeleteServiceArea(warehouseID, areaID); deleteErr != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, deleteErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Service area deleted successfully", ""))
+}
+
+// GetServiceAreaOverlaps lists the active service areas of different warehouses that overlap.
+//	@Summary		Get Service Area Overlaps
+//	@Description	Lists every pair of active service areas belonging to different warehouses that overlap.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/service-areas/overlaps [get]
+func (sa *ServiceArea) GetServiceAreaOverlaps(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+
+	sa.ServiceAreaRepo = application.NewServiceAreaApplication(sa.Persistence, c)
+
+	overlaps, err := sa.ServiceAreaRepo.GetServiceAreaOverlaps()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": overlaps,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Service area overlaps obtained", results))
+}
+
+// CheckServiceability reports whether any warehouse delivers to a coordinate.
+//	@Summary		Check Serviceability
+//	@Description	Reports whether any warehouse delivers to a coordinate and lists the ones that do, nearest first.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			lat	query		number					true	"Latitude"
+//	@Param			lng	query		number					true	"Longitude"
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		400	{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/serviceability [get]
+func (sa *ServiceArea) CheckServiceability(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	latitude, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	longitude, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+	if latErr != nil || lngErr != nil || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid lat or lng", ""))
+		return
+	}
+
+	sa.ServiceAreaRepo = application.NewServiceAreaApplication(sa.Persistence, c)
+
+	serviceability, err := sa.ServiceAreaRepo.CheckServiceability(latitude, longitude)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	message := "Location is serviceable"
+	if !serviceability.Serviceable {
+		message = "No warehouse delivers to this location"
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, message, serviceability))
+}
+
+// sendWarehouseErrors maps the errors of a change to a warehouse's setup to a response,
+// any key ending in _not_found is a 404 and the rest are validation errors
+func sendWarehouseErrors(c *gin.Context, errs map[string]string, message string) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	if dbError, ok := errs["db_error"]; ok {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, dbError, ""))
+		return
+	}
+	for key, notFound := range errs {
+		if strings.HasSuffix(key, "_not_found") {
+			c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, notFound, ""))
+			return
+		}
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, message, errs))
+}
@@ -0,0 +1,558 @@
+package handlers
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/shipment_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Shipment struct {
+	ShipmentRepo shipment_repository.ShipmentHandlerRepository
+	Persistence  *base.Persistence
+}
+
+func NewShipment(p *base.Persistence) *Shipment {
+	return &Shipment{
+		Persistence: p,
+	}
+}
+
+// SaveDriver adds a driver.
+//
+//	@Summary		Save Driver
+//	@Description	Adds a driver. A driver linked to a customer account with the driver role can log in and work on the shipments assigned to them. A driver without a warehouse can deliver for every warehouse.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			driver	body		shipment_entity.DriverRequest	true	"Driver"
+//	@Success		201		{object}	entity.ResponseContext			"Driver saved"
+//	@Failure		422		{object}	entity.ResponseContext			"Invalid driver"
+//	@Failure		500		{object}	entity.ResponseContext			"Internal server error"
+//	@Router			/drivers [post]
+func (sh *Shipment) SaveDriver(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	request := shipment_entity.DriverRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	driver, saveErr := sh.ShipmentRepo.SaveDriver(request)
+	if saveErr != nil {
+		sendWarehouseErrors(c, saveErr, "Invalid driver")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Driver saved successfully", driver))
+}
+
+// GetDrivers lists the drivers.
+//
+//	@Summary		Get Drivers
+//	@Description	Lists the drivers, only those of a warehouse when warehouse_id is given.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	query		int						false	"Warehouse ID"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/drivers [get]
+func (sh *Shipment) GetDrivers(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	warehouseID, _ := strconv.ParseInt(c.Query("warehouse_id"), 10, 64)
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	drivers, err := sh.ShipmentRepo.GetDrivers(warehouseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": drivers,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Drivers obtained", results))
+}
+
+// GetDriver retrieves a driver.
+//
+//	@Summary		Get Driver
+//	@Description	Retrieves a driver by its ID.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			driver_id	path		int						true	"Driver ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Driver not found"
+//	@Router			/drivers/{driver_id} [get]
+func (sh *Shipment) GetDriver(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	driverID, err := strconv.ParseInt(c.Param("driver_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Driver ID", ""))
+		return
+	}
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	driver, getErr := sh.ShipmentRepo.GetDriver(driverID)
+	if getErr != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Driver %v obtained", driverID), driver))
+}
+
+// UpdateDriver replaces a driver.
+//
+//	@Summary		Update Driver
+//	@Description	Replaces the details of a driver, leaving out active keeps the driver as it is.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			driver_id	path		int								true	"Driver ID"
+//	@Param			driver		body		shipment_entity.DriverRequest	true	"Driver"
+//	@Success		200			{object}	entity.ResponseContext			"Driver updated"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext			"Driver not found"
+//	@Failure		422			{object}	entity.ResponseContext			"Invalid driver"
+//	@Router			/drivers/{driver_id} [put]
+func (sh *Shipment) UpdateDriver(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	driverID, err := strconv.ParseInt(c.Param("driver_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Driver ID", ""))
+		return
+	}
+
+	request := shipment_entity.DriverRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	driver, updateErr := sh.ShipmentRepo.UpdateDriver(driverID, request)
+	if updateErr != nil {
+		sendWarehouseErrors(c, updateErr, "Invalid driver")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Driver updated successfully", driver))
+}
+
+// DeleteDriver deletes a driver.
+//
+//	@Summary		Delete Driver
+//	@Description	Deletes a driver who has no assigned or outgoing shipments.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			driver_id	path		int						true	"Driver ID"
+//	@Success		200			{object}	entity.ResponseContext	"Driver deleted"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Driver not found"
+//	@Failure		422			{object}	entity.ResponseContext	"Driver still has shipments"
+//	@Router			/drivers/{driver_id} [delete]
+func (sh *Shipment) DeleteDriver(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	driverID, err := strconv.ParseInt(c.Param("driver_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Driver ID", ""))
+		return
+	}
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	if deleteErr := sh.ShipmentRepo.DeleteDriver(driverID); deleteErr != nil {
+		sendWarehouseErrors(c, deleteErr, "Could not delete driver")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Driver deleted successfully", ""))
+}
+
+// CreateShipment opens the shipment of a packed order.
+//
+//	@Summary		Create Shipment
+//	@Description	Opens the shipment of a packed order, assigning it to a driver straight away when driver_id is given.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			order_id	path		int								true	"Order ID"
+//	@Param			assignment	body		shipment_entity.AssignRequest	false	"Driver"
+//	@Success		201			{object}	entity.ResponseContext			"Shipment created"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext			"Order not found"
+//	@Failure		422			{object}	entity.ResponseContext			"Order cannot be shipped"
+//	@Router			/orders/{order_id}/shipment [post]
+func (sh *Shipment) CreateShipment(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Order ID", ""))
+		return
+	}
+
+	request := shipment_entity.AssignRequest{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+			return
+		}
+	}
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	shipment, createErr := sh.ShipmentRepo.CreateShipment(orderID, request, staffID(c))
+	if createErr != nil {
+		sendWarehouseErrors(c, createErr, "Could not create shipment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Shipment created successfully", shipment))
+}
+
+// GetOrderShipment tracks the shipment of an order.
+//
+//	@Summary		Get Order Shipment
+//	@Description	Retrieves the shipment of an order with its driver and delivery events. Customers can track the shipments of their own orders.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			order_id	path		int						true	"Order ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Shipment not found"
+//	@Router			/orders/{order_id}/shipment [get]
+func (sh *Shipment) GetOrderShipment(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Order ID", ""))
+		return
+	}
+
+	order, _ := application.NewOrderApplication(sh.Persistence, c).GetOrder(orderID)
+	if order == nil || order.ID == 0 || (!middleware.HasPermission(c, auth_entity.PermOrdersRead) && !middleware.IsSelf(c, order.CustomerID)) {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, "Order not found", ""))
+		return
+	}
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	shipment, getErr := sh.ShipmentRepo.GetShipmentForOrder(orderID)
+	if getErr != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, getErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Shipment of order %v obtained", orderID), shipment))
+}
+
+// GetShipments lists shipments.
+//
+//	@Summary		Get Shipments
+//	@Description	Lists shipments, newest first. Drivers only see the shipments assigned to them.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			warehouse_id	query		int						false	"Warehouse ID"
+//	@Param			driver_id		query		int						false	"Driver ID"
+//	@Param			status			query		string					false	"Shipment status"
+//	@Success		200				{object}	entity.ResponseContext	"Success"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/shipments [get]
+func (sh *Shipment) GetShipments(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	filter := shipment_entity.ShipmentFilter{Status: c.Query("status")}
+	filter.WarehouseID, _ = strconv.ParseInt(c.Query("warehouse_id"), 10, 64)
+	filter.DriverID, _ = strconv.ParseInt(c.Query("driver_id"), 10, 64)
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	if !middleware.HasPermission(c, auth_entity.PermShipmentsManage) {
+		driver, _ := sh.ShipmentRepo.GetDriverByCustomer(staffID(c))
+		if driver == nil {
+			c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Shipments obtained", map[string]interface{}{"results": []shipment_entity.Shipment{}}))
+			return
+		}
+		filter.DriverID = int64(driver.ID)
+	}
+
+	shipments, err := sh.ShipmentRepo.GetShipments(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": shipments,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Shipments obtained", results))
+}
+
+// GetShipment retrieves a shipment.
+//
+//	@Summary		Get Shipment
+//	@Description	Retrieves a shipment with its driver and delivery events.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			shipment_id	path		int						true	"Shipment ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Shipment not found"
+//	@Router			/shipments/{shipment_id} [get]
+func (sh *Shipment) GetShipment(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	shipmentID, ok := sh.deliverableShipment(c)
+	if !ok {
+		return
+	}
+
+	shipment, err := sh.ShipmentRepo.GetShipment(shipmentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Shipment %v obtained", shipmentID), shipment))
+}
+
+// AssignShipment gives a shipment to a driver.
+//
+//	@Summary		Assign Shipment
+//	@Description	Gives a pending or assigned shipment to an active driver of its warehouse.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			shipment_id	path		int								true	"Shipment ID"
+//	@Param			assignment	body		shipment_entity.AssignRequest	true	"Driver"
+//	@Success		200			{object}	entity.ResponseContext			"Shipment assigned"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext			"Shipment not found"
+//	@Failure		422			{object}	entity.ResponseContext			"Shipment cannot be assigned"
+//	@Router			/shipments/{shipment_id}/assign [post]
+func (sh *Shipment) AssignShipment(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	shipmentID, err := strconv.ParseInt(c.Param("shipment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Shipment ID", ""))
+		return
+	}
+
+	request := shipment_entity.AssignRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	shipment, assignErr := sh.ShipmentRepo.AssignShipment(shipmentID, request, staffID(c))
+	if assignErr != nil {
+		sendWarehouseErrors(c, assignErr, "Could not assign shipment")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Shipment assigned successfully", shipment))
+}
+
+// UpdateShipmentStatus moves a shipment along.
+//
+//	@Summary		Update Shipment Status
+//	@Description	Records the driver leaving with a shipment (out_for_delivery), a failed attempt (failed, with a reason) or the shipment arriving back at the warehouse (returned). A failure that may succeed another time goes back to the driver for up to 3 attempts, any other failure or return_to_warehouse sends the shipment back, and its stock is returned once it arrives.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			shipment_id	path		int								true	"Shipment ID"
+//	@Param			update		body		shipment_entity.StatusUpdate	true	"Status update"
+//	@Success		200			{object}	entity.ResponseContext			"Shipment updated"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext			"Shipment not found"
+//	@Failure		422			{object}	entity.ResponseContext			"Invalid status update"
+//	@Router			/shipments/{shipment_id}/status [post]
+func (sh *Shipment) UpdateShipmentStatus(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	shipmentID, ok := sh.deliverableShipment(c)
+	if !ok {
+		return
+	}
+
+	update := shipment_entity.StatusUpdate{}
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	shipment, updateErr := sh.ShipmentRepo.UpdateShipmentStatus(shipmentID, update, staffID(c))
+	if updateErr != nil {
+		sendWarehouseErrors(c, updateErr, "Could not update shipment")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Shipment %v is %v", shipmentID, shipment.Status), shipment))
+}
+
+// UpdateShipmentLocation records where a shipment is.
+//
+//	@Summary		Update Shipment Location
+//	@Description	Records the GPS position of a shipment on the road.
+//	@Tags			Shipments
+//	@Accept			json
+//	@Produce		json
+//	@Param			shipment_id	path		int								true	"Shipment ID"
+//	@Param			location	body		shipment_entity.LocationUpdate	true	"Position"
+//	@Success		200			{object}	entity.ResponseContext			"Location recorded"
+//	@Failure		400			{object}	entity.ResponseContext			"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext			"Shipment not found"
+//	@Failure		422			{object}	entity.ResponseContext			"Invalid location"
+//	@Router			/shipments/{shipment_id}/location [post]
+func (sh *Shipment) UpdateShipmentLocation(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	shipmentID, ok := sh.deliverableShipment(c)
+	if !ok {
+		return
+	}
+
+	update := shipment_entity.LocationUpdate{}
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	shipment, updateErr := sh.ShipmentRepo.UpdateShipmentLocation(shipmentID, update, staffID(c))
+	if updateErr != nil {
+		sendWarehouseErrors(c, updateErr, "Could not record location")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Location recorded", shipment))
+}
+
+// DeliverShipment confirms a delivery with proof.
+//
+//	@Summary		Deliver Shipment
+//	@Description	Confirms the delivery of a shipment out for delivery. A signature or a photo is required, both are uploaded to storage as proof of delivery.
+//	@Tags			Shipments
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			shipment_id		path		int						true	"Shipment ID"
+//	@Param			signature		formData	file					false	"Signature image"
+//	@Param			photo			formData	file					false	"Photo of the delivered order"
+//	@Param			recipient_name	formData	string					false	"Who received the order"
+//	@Param			latitude		formData	number					false	"Latitude"
+//	@Param			longitude		formData	number					false	"Longitude"
+//	@Param			note			formData	string					false	"Note"
+//	@Success		200				{object}	entity.ResponseContext	"Shipment delivered"
+//	@Failure		400				{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404				{object}	entity.ResponseContext	"Shipment not found"
+//	@Failure		422				{object}	entity.ResponseContext	"Invalid proof of delivery"
+//	@Failure		500				{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/shipments/{shipment_id}/deliver [post]
+func (sh *Shipment) DeliverShipment(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	shipmentID, ok := sh.deliverableShipment(c)
+	if !ok {
+		return
+	}
+
+	proof := shipment_entity.DeliveryProof{
+		RecipientName: c.PostForm("recipient_name"),
+		Note:          c.PostForm("note"),
+	}
+	if c.PostForm("latitude") != "" || c.PostForm("longitude") != "" {
+		latitude, latErr := strconv.ParseFloat(c.PostForm("latitude"), 64)
+		longitude, lngErr := strconv.ParseFloat(c.PostForm("longitude"), 64)
+		if latErr != nil || lngErr != nil {
+			c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid latitude or longitude", ""))
+			return
+		}
+		proof.Latitude = &latitude
+		proof.Longitude = &longitude
+	}
+
+	signature, err := openFormFile(c, "signature")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+	if signature != nil {
+		defer signature.Close()
+	}
+	photo, err := openFormFile(c, "photo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+	if photo != nil {
+		defer photo.Close()
+	}
+
+	shipment, deliverErr := sh.ShipmentRepo.DeliverShipment(shipmentID, proof, signature, photo, staffID(c))
+	if deliverErr != nil {
+		if storageErr, ok := deliverErr["storage_error"]; ok {
+			c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, storageErr, ""))
+			return
+		}
+		sendWarehouseErrors(c, deliverErr, "Could not deliver shipment")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Shipment delivered successfully", shipment))
+}
+
+// deliverableShipment reads the shipment id of the path and checks the caller may work on the shipment,
+// staff managing shipments can work on any of them and a driver only on those assigned to them
+func (sh *Shipment) deliverableShipment(c *gin.Context) (int64, bool) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	shipmentID, err := strconv.ParseInt(c.Param("shipment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Shipment ID", ""))
+		return 0, false
+	}
+
+	sh.ShipmentRepo = application.NewShipmentApplication(sh.Persistence, c)
+
+	if middleware.HasPermission(c, auth_entity.PermShipmentsManage) {
+		return shipmentID, true
+	}
+
+	shipment, _ := sh.ShipmentRepo.GetShipment(shipmentID)
+	if shipment == nil || shipment.Driver == nil || shipment.Driver.CustomerID == 0 || !middleware.IsSelf(c, shipment.Driver.CustomerID) {
+		c.JSON(http.StatusNotFound, responseContextData.ResponseData(entity.StatusFail, fmt.Sprintf("shipment %v not found", shipmentID), ""))
+		return 0, false
+	}
+
+	return shipmentID, true
+}
+
+// openFormFile opens an optional uploaded file, nil when the form has none
+func openFormFile(c *gin.Context, field string) (multipart.File, error) {
+	header, err := c.FormFile(field)
+	if err == http.ErrMissingFile {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return header.Open()
+}
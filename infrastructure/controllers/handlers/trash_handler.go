@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/trash_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/jobs"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Trash struct {
+	TrashRepo   trash_repository.TrashHandlerRepository
+	Persistence *base.Persistence
+}
+
+func NewTrash(p *base.Persistence) *Trash {
+	return &Trash{
+		Persistence: p,
+	}
+}
+
+// GetDeleted lists the soft-deleted rows of a resource.
+//	@Summary		List Recycle Bin
+//	@Description	Lists the soft-deleted rows of a resource (products, warehouses, categories, customers).
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource	path		string					true	"Resource name"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Router			/trash/{resource} [get]
+func (tr *Trash) GetDeleted(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	resource := c.Param("resource")
+
+	tr.TrashRepo = application.NewTrashApplication(tr.Persistence, c)
+
+	deleted, err := tr.TrashRepo.GetDeleted(resource)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results": deleted,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("Deleted %v obtained", resource), results))
+}
+
+// RestoreDeleted restores a soft-deleted row and everything deleted along with it.
+//	@Summary		Restore From Recycle Bin
+//	@Description	Restores a soft-deleted row, its related inventory and images, and its search document.
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource	path		string					true	"Resource name"
+//	@Param			id			path		int						true	"Row ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Router			/trash/{resource}/{id}/restore [post]
+func (tr *Trash) RestoreDeleted(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	resource := c.Param("resource")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	tr.TrashRepo = application.NewTrashApplication(tr.Persistence, c)
+
+	restored, restoreErr := tr.TrashRepo.RestoreDeleted(resource, id)
+	if restoreErr != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, restoreErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("%v %v restored", resource, id), restored))
+}
+
+// PurgeDeleted permanently removes a row from the recycle bin.
+//	@Summary		Purge From Recycle Bin
+//	@Description	Permanently removes a soft-deleted row. Warehouses and customers still referenced by other records cannot be purged.
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			resource	path		string					true	"Resource name"
+//	@Param			id			path		int						true	"Row ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Router			/trash/{resource}/{id} [delete]
+func (tr *Trash) PurgeDeleted(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	resource := c.Param("resource")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	tr.TrashRepo = application.NewTrashApplication(tr.Persistence, c)
+
+	purgeErr := tr.TrashRepo.PurgeDeleted(resource, id)
+	if purgeErr != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, purgeErr.Error(), ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, fmt.Sprintf("%v %v permanently deleted", resource, id), ""))
+}
+
+// PurgeExpired runs the retention policy immediately.
+//	@Summary		Purge Expired
+//	@Description	Permanently removes rows that have been in the recycle bin longer than the retention period.
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			days	query		int						false	"Retention period in days"
+//	@Success		200		{object}	entity.ResponseContext	"Success"
+//	@Failure		400		{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500		{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/trash/purge [post]
+func (tr *Trash) PurgeExpired(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	retentionDays := jobs.TrashRetentionDays()
+
+	if days := c.Query("days"); days != "" {
+		parsedDays, err := strconv.Atoi(days)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid days", ""))
+			return
+		}
+		retentionDays = parsedDays
+	}
+
+	tr.TrashRepo = application.NewTrashApplication(tr.Persistence, c)
+
+	purged, purgeErr := tr.TrashRepo.PurgeExpired(retentionDays)
+	if purgeErr != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, purgeErr.Error(), purged))
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Expired rows purged", purged))
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/wallet_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/wallet_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+type Wallet struct {
+	WalletRepo  wallet_repository.WalletHandlerRepository
+	Persistence *base.Persistence
+}
+
+func NewWallet(p *base.Persistence) *Wallet {
+	return &Wallet{
+		Persistence: p,
+	}
+}
+
+// GetMyWallet retrieves the wallet of the logged in customer.
+//
+//	@Summary		Get My Wallet
+//	@Description	Retrieves the wallet of the logged in customer with its store credit balance.
+//	@Tags			Me
+//	@Produce		json
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/me/wallet [get]
+func (w *Wallet) GetMyWallet(c *gin.Context) {
+	w.sendWallet(c, staffID(c))
+}
+
+// GetMyWalletStatement lists the wallet transactions of the logged in customer.
+//
+//	@Summary		Get My Wallet Statement
+//	@Description	Lists a page of the wallet transactions of the logged in customer with their ledger entries, newest first.
+//	@Tags			Me
+//	@Produce		json
+//	@Param			page		query		int						false	"Page, starting at 1"
+//	@Param			page_size	query		int						false	"Transactions per page, at most 100"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		500			{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/me/wallet/statement [get]
+func (w *Wallet) GetMyWalletStatement(c *gin.Context) {
+	w.sendStatement(c, staffID(c))
+}
+
+// TopUpMyWallet adds money to the wallet of the logged in customer.
+//
+//	@Summary		Top Up My Wallet
+//	@Description	Charges a payment method tokenized by the gateway and credits the wallet of the logged in customer with the amount.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Param			top_up	body		wallet_entity.TopUpRequest	true	"Top-up"
+//	@Success		201		{object}	entity.ResponseContext		"Wallet topped up"
+//	@Failure		422		{object}	entity.ResponseContext		"Invalid top-up"
+//	@Failure		502		{object}	entity.ResponseContext		"Gateway unavailable"
+//	@Router			/me/wallet/top-ups [post]
+func (w *Wallet) TopUpMyWallet(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	request := wallet_entity.TopUpRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	w.WalletRepo = application.NewWalletApplication(w.Persistence, c)
+
+	transaction, topUpErr := w.WalletRepo.TopUpWallet(staffID(c), request, staffID(c))
+	if topUpErr != nil {
+		sendPaymentErrors(c, topUpErr, "Invalid top-up")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Wallet topped up successfully", transaction))
+}
+
+// GetCustomerWallet retrieves the wallet of a customer.
+//
+//	@Summary		Get Customer Wallet
+//	@Description	Retrieves the wallet of a customer with its store credit balance.
+//	@Tags			Wallets
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Customer not found"
+//	@Router			/customers/{customer_id}/wallet [get]
+func (w *Wallet) GetCustomerWallet(c *gin.Context) {
+	customerID, ok := customerParam(c)
+	if !ok {
+		return
+	}
+
+	w.sendWallet(c, customerID)
+}
+
+// GetCustomerWalletStatement lists the wallet transactions of a customer.
+//
+//	@Summary		Get Customer Wallet Statement
+//	@Description	Lists a page of the wallet transactions of a customer with their ledger entries, newest first.
+//	@Tags			Wallets
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int						true	"Customer ID"
+//	@Param			page		query		int						false	"Page, starting at 1"
+//	@Param			page_size	query		int						false	"Transactions per page, at most 100"
+//	@Success		200			{object}	entity.ResponseContext	"Success"
+//	@Failure		400			{object}	entity.ResponseContext	"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext	"Customer not found"
+//	@Router			/customers/{customer_id}/wallet/statement [get]
+func (w *Wallet) GetCustomerWalletStatement(c *gin.Context) {
+	customerID, ok := customerParam(c)
+	if !ok {
+		return
+	}
+
+	w.sendStatement(c, customerID)
+}
+
+// CreditCustomerWallet gives store credit to a customer.
+//
+//	@Summary		Credit Customer Wallet
+//	@Description	Credits the wallet of a customer for a goodwill gesture or a refund settled outside a return, optionally about one of their orders.
+//	@Tags			Wallets
+//	@Accept			json
+//	@Produce		json
+//	@Param			customer_id	path		int							true	"Customer ID"
+//	@Param			credit		body		wallet_entity.CreditRequest	true	"Credit"
+//	@Success		201			{object}	entity.ResponseContext		"Wallet credited"
+//	@Failure		400			{object}	entity.ResponseContext		"Bad request"
+//	@Failure		404			{object}	entity.ResponseContext		"Customer not found"
+//	@Failure		422			{object}	entity.ResponseContext		"Invalid credit"
+//	@Router			/customers/{customer_id}/wallet/credits [post]
+func (w *Wallet) CreditCustomerWallet(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, ok := customerParam(c)
+	if !ok {
+		return
+	}
+
+	request := wallet_entity.CreditRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, responseContextData.ResponseData(entity.StatusFail, "Invalid JSON", ""))
+		return
+	}
+
+	w.WalletRepo = application.NewWalletApplication(w.Persistence, c)
+
+	transaction, creditErr := w.WalletRepo.CreditWallet(customerID, request, staffID(c))
+	if creditErr != nil {
+		sendWarehouseErrors(c, creditErr, "Invalid credit")
+		return
+	}
+
+	c.JSON(http.StatusCreated, responseContextData.ResponseData(entity.StatusSuccess, "Wallet credited successfully", transaction))
+}
+
+func (w *Wallet) sendWallet(c *gin.Context, customerID int64) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	w.WalletRepo = application.NewWalletApplication(w.Persistence, c)
+
+	wallet, walletErr := w.WalletRepo.GetWallet(customerID)
+	if walletErr != nil {
+		sendWarehouseErrors(c, walletErr, "Could not get wallet")
+		return
+	}
+
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Wallet obtained", wallet))
+}
+
+func (w *Wallet) sendStatement(c *gin.Context, customerID int64) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	w.WalletRepo = application.NewWalletApplication(w.Persistence, c)
+
+	pagination := entity.NewPagination(c.Query("page"), c.Query("page_size"))
+	transactions, statementErr := w.WalletRepo.GetStatement(customerID, &pagination)
+	if statementErr != nil {
+		sendWarehouseErrors(c, statementErr, "Could not get statement")
+		return
+	}
+
+	results := map[string]interface{}{
+		"results":    transactions,
+		"pagination": pagination,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Statement obtained", results))
+}
+
+// customerParam reads the customer from the path
+func customerParam(c *gin.Context) (int64, bool) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	customerID, err := strconv.ParseInt(c.Param("customer_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid Customer ID", ""))
+		return 0, false
+	}
+
+	return customerID, true
+}
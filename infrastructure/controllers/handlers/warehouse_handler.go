@@ -123,6 +123,41 @@ func (pr *Warehouse) GetAllWarehouses(c *gin.Context) {
 	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "All warehouses obtained successfully", results))
 }
 
+// GetNearestWarehouses lists warehouses by distance from a delivery point.
+//	@Summary		Get Nearest Warehouses
+//	@Description	Lists every warehouse ordered by its distance in kilometres from a delivery point, and whether it delivers there.
+//	@Tags			Warehouse
+//	@Accept			json
+//	@Produce		json
+//	@Param			lat	query		number					true	"Latitude of the delivery point"
+//	@Param			lng	query		number					true	"Longitude of the delivery point"
+//	@Success		200	{object}	entity.ResponseContext	"Success"
+//	@Failure		400	{object}	entity.ResponseContext	"Bad request"
+//	@Failure		500	{object}	entity.ResponseContext	"Internal server error"
+//	@Router			/warehouses/nearest [get]
+func (pr *Warehouse) GetNearestWarehouses(c *gin.Context) {
+	responseContextData := entity.ResponseContext{Ctx: c}
+	latitude, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	longitude, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+	if latErr != nil || lngErr != nil || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		c.JSON(http.StatusBadRequest, responseContextData.ResponseData(entity.StatusFail, "Invalid lat or lng", ""))
+		return
+	}
+
+	pr.WarehouseRepo = application.NewWarehouseApplication(pr.Persistence, c)
+
+	nearestWarehouses, err := pr.WarehouseRepo.GetNearestWarehouses(latitude, longitude)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseContextData.ResponseData(entity.StatusFail, err.Error(), ""))
+		return
+	}
+
+	results := map[string]interface{}{
+		"results" : nearestWarehouses,
+	}
+	c.JSON(http.StatusOK, responseContextData.ResponseData(entity.StatusSuccess, "Nearest warehouses obtained successfully", results))
+}
+
 // GetWarehouse retrieves a specific warehouse by ID.
 //	@Summary		Get Warehouse
 //	@Description	Retrieves a specific warehouse by ID.
@@ -3,12 +3,13 @@ package middleware
 import (
 	"fmt"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/auth"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
@@ -18,6 +19,12 @@ func AuthHandler(p *base.Persistence) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 	   token := c.Request.Header.Get("Authorization")
+
+	   // Integrations can authenticate with an API key instead of a Bearer token
+	   if apiKey := apiKeyFromRequest(c); apiKey != "" {
+		  authenticateAPIKey(p, c, apiKey)
+		  return
+	   }
 	   
 	   b := "Bearer "
 	   if !strings.Contains(token, b) {
@@ -34,8 +41,7 @@ func AuthHandler(p *base.Persistence) gin.HandlerFunc {
  
 
 
-	   keyJWT := os.Getenv("JWT_SECRET")
-	   v2, err2 := auth.NewAuthRepository(p, c).ValidateToken(t[1], keyJWT)
+	   v2, err2 := auth.NewAuthRepository(p, c).ValidateToken(t[1])
  
 	   if (err2 != nil) || (v2 != nil && !v2.Valid) {
 		  c.JSON(http.StatusForbidden, gin.H{"message": "Invalid authorization token", "status": entity.StatusError, "data": nil})
@@ -53,20 +59,65 @@ func AuthHandler(p *base.Persistence) gin.HandlerFunc {
 	   userID, ok := userIDInterface.(string)
 	   if !ok {
 		   fmt.Println("Invalid user ID format")
+		   c.JSON(http.StatusForbidden, gin.H{"message": "Invalid authorization token", "status": entity.StatusError, "data": nil})
+		   c.Abort()
 		   return
 	   }
 
-	   // Check redis blacklist tokens
-	   isBlacklisted := auth.NewAuthRepository(p, c).CheckBlacklistToken(t[1])
-	   if userID == "" || isBlacklisted == nil {
+	   // Revoked tokens are rejected by jti, logging out of all sessions rejects everything issued before it.
+	   // Emailed one-time tokens carry a purpose and are never accepted as access tokens
+	   claims := tokenCatches.Claims.(jwt.MapClaims)
+	   jti, _ := claims["jti"].(string)
+	   issuedAt, _ := claims["iat"].(float64)
+	   authRepo := auth.NewAuthRepository(p, c)
+	   customerID, _ := strconv.ParseInt(userID, 10, 64)
+	   purpose, _ := claims["purpose"].(string)
+	   if userID == "" || jti == "" || purpose != "" || authRepo.IsJTIRevoked(jti) || auth.IssuedBefore(issuedAt, authRepo.TokensValidAfter(customerID)) {
 		  c.JSON(http.StatusForbidden, gin.H{"message": "Invalid authorization token", "status": entity.StatusError, "data": nil})
 		  c.Abort()
 		  return
 	   }
+
+	   // Unknown roles fall back to the least privileged one
+	   role, _ := claims["role"].(string)
+	   if !auth_entity.IsValidRole(role) {
+		  role = auth_entity.RoleCustomer
+	   }
+
 	   c.Set("userID", userID)
+	   c.Set("role", role)
+	   c.Set("jti", jti)
  
 	   c.Next()
  
 	}
 }
 
+// apiKeyFromRequest reads the key from the X-API-Key header or an "Authorization: ApiKey <key>" header
+func apiKeyFromRequest(c *gin.Context) string {
+	if apiKey := strings.TrimSpace(c.Request.Header.Get("X-API-Key")); apiKey != "" {
+		return apiKey
+	}
+
+	token := c.Request.Header.Get("Authorization")
+	if strings.HasPrefix(token, "ApiKey ") {
+		return strings.TrimSpace(strings.TrimPrefix(token, "ApiKey "))
+	}
+	return ""
+}
+
+// authenticateAPIKey lets the request through with the scopes of the key. API keys act for no
+// customer, so ownership checks never match them
+func authenticateAPIKey(p *base.Persistence, c *gin.Context, rawKey string) {
+	apiKey, err := auth.NewAuthRepository(p, c).ValidateAPIKey(rawKey)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Invalid api key", "status": entity.StatusError, "data": nil})
+		c.Abort()
+		return
+	}
+
+	c.Set("apiKeyID", apiKey.ID)
+	c.Set("scopes", apiKey.Scopes)
+
+	c.Next()
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+)
+
+// RequirePermission only lets principals whose role grants the permission through
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"message": "You do not have permission to perform this action", "status": entity.StatusError, "data": nil})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermissionOrSelf also lets a customer through when the path parameter holds their own id
+func RequirePermissionOrSelf(permission string, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		customerID, err := strconv.ParseInt(c.Param(param), 10, 64)
+		if HasPermission(c, permission) || (err == nil && IsSelf(c, customerID)) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have permission to perform this action", "status": entity.StatusError, "data": nil})
+		c.Abort()
+	}
+}
+
+// HasPermission checks the role AuthHandler put on the request, or the scopes when an API key was used
+func HasPermission(c *gin.Context, permission string) bool {
+	if scopes, ok := c.Get("scopes"); ok {
+		apiKey := auth_entity.APIKey{Scopes: scopes.([]string)}
+		return apiKey.HasScope(permission)
+	}
+	return auth_entity.HasPermission(c.GetString("role"), permission)
+}
+
+// RequireUser rejects API keys on routes that act on the session of a logged in customer
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsAPIKey(c) {
+			c.JSON(http.StatusForbidden, gin.H{"message": "This action is not available to api keys", "status": entity.StatusError, "data": nil})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// IsAPIKey reports whether the request was authenticated with an API key
+func IsAPIKey(c *gin.Context) bool {
+	_, ok := c.Get("apiKeyID")
+	return ok
+}
+
+// IsSelf reports whether the authenticated principal is the given customer
+func IsSelf(c *gin.Context, customerID int64) bool {
+	userID, err := strconv.ParseInt(c.GetString("userID"), 10, 64)
+	return err == nil && userID == customerID
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+)
+
+// principal is what AuthHandler would have put on the request
+type principal struct {
+	role   string
+	userID string
+	scopes []string
+	apiKey bool
+}
+
+func serve(t *testing.T, who principal, path string, target string, guard gin.HandlerFunc) int {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authenticate := func(c *gin.Context) {
+		if who.apiKey {
+			c.Set("apiKeyID", uint64(1))
+			c.Set("scopes", who.scopes)
+		}
+		if who.role != "" {
+			c.Set("role", who.role)
+		}
+		if who.userID != "" {
+			c.Set("userID", who.userID)
+		}
+	}
+	router.GET(path, authenticate, guard, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder.Code
+}
+
+func TestRequirePermissionWithAPIKeyScopes(t *testing.T) {
+	tests := []struct {
+		name       string
+		who        principal
+		permission string
+		want       int
+	}{
+		{"granted scope", principal{apiKey: true, scopes: []string{auth_entity.PermInventoryRead}}, auth_entity.PermInventoryRead, http.StatusOK},
+		{"read scope does not write", principal{apiKey: true, scopes: []string{auth_entity.PermInventoryRead}}, auth_entity.PermInventoryWrite, http.StatusForbidden},
+		{"no scopes", principal{apiKey: true, scopes: []string{}}, auth_entity.PermProductsRead, http.StatusForbidden},
+		{"scopes win over a role", principal{apiKey: true, role: auth_entity.RoleAdmin, scopes: []string{auth_entity.PermProductsRead}}, auth_entity.PermProductsWrite, http.StatusForbidden},
+		{"role without a key", principal{role: auth_entity.RoleAdmin}, auth_entity.PermProductsWrite, http.StatusOK},
+		{"customer role", principal{role: auth_entity.RoleCustomer}, auth_entity.PermInventoryRead, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serve(t, tt.who, "/resource", "/resource", RequirePermission(tt.permission)); got != tt.want {
+				t.Errorf("status = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirePermissionOrSelfWithAPIKey(t *testing.T) {
+	tests := []struct {
+		name string
+		who  principal
+		want int
+	}{
+		{"key without the scope is never the customer", principal{apiKey: true, scopes: []string{auth_entity.PermOrdersRead}}, http.StatusForbidden},
+		{"key with the scope", principal{apiKey: true, scopes: []string{auth_entity.PermCustomersRead}}, http.StatusOK},
+		{"the customer themselves", principal{role: auth_entity.RoleCustomer, userID: "7"}, http.StatusOK},
+		{"another customer", principal{role: auth_entity.RoleCustomer, userID: "8"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := RequirePermissionOrSelf(auth_entity.PermCustomersRead, "customer_id")
+			if got := serve(t, tt.who, "/customers/:customer_id", "/customers/7", guard); got != tt.want {
+				t.Errorf("status = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireUserRejectsAPIKeys(t *testing.T) {
+	key := principal{apiKey: true, scopes: []string{auth_entity.PermCustomersWrite}}
+	if got := serve(t, key, "/me", "/me", RequireUser()); got != http.StatusForbidden {
+		t.Errorf("api key status = %v, want %v", got, http.StatusForbidden)
+	}
+
+	customer := principal{role: auth_entity.RoleCustomer, userID: "7"}
+	if got := serve(t, customer, "/me", "/me", RequireUser()); got != http.StatusOK {
+		t.Errorf("customer status = %v, want %v", got, http.StatusOK)
+	}
+}
+
+func TestAPIKeyFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"X-API-Key header", map[string]string{"X-API-Key": " qk_abc "}, "qk_abc"},
+		{"ApiKey authorization", map[string]string{"Authorization": "ApiKey qk_abc"}, "qk_abc"},
+		{"X-API-Key wins", map[string]string{"X-API-Key": "qk_header", "Authorization": "ApiKey qk_auth"}, "qk_header"},
+		{"bearer token is not a key", map[string]string{"Authorization": "Bearer eyJ"}, ""},
+		{"no credentials", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tt.headers {
+				c.Request.Header.Set(key, value)
+			}
+			if got := apiKeyFromRequest(c); got != tt.want {
+				t.Errorf("apiKeyFromRequest = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package addresses
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/address_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+type AddressRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewAddressRepository(p *base.Persistence, c *gin.Context) *AddressRepo {
+	return &AddressRepo{p, c}
+}
+
+var _ address_repository.AddressRepository = &AddressRepo{}
+
+// SaveAddress adds an address to the customer's address book, the first address becomes the default
+func (r *AddressRepo) SaveAddress(address *address_entity.Address) (*address_entity.Address, map[string]string) {
+	dbErr := map[string]string{}
+
+	err := r.p.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		countErr := tx.Debug().Model(&address_entity.Address{}).Where("customer_id = ?", address.CustomerID).Count(&count).Error
+		if countErr != nil {
+			return countErr
+		}
+
+		if count == 0 {
+			address.IsDefault = true
+		}
+
+		if address.IsDefault && count > 0 {
+			clearErr := tx.Debug().Model(&address_entity.Address{}).Where("customer_id = ?", address.CustomerID).Update("is_default", false).Error
+			if clearErr != nil {
+				return clearErr
+			}
+		}
+
+		return tx.Debug().Create(&address).Error
+	})
+	if err != nil {
+		fmt.Println("Failed to create address")
+		fmt.Println(err)
+		dbErr["db_error"] = "database error"
+		return nil, dbErr
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", r.p)
+	cacheRepo.SetKey(fmt.Sprintf("%v_ADDRESS", address.ID), address, time.Minute*15)
+
+	return address, nil
+}
+
+func (r *AddressRepo) GetAddress(id int64) (*address_entity.Address, error) {
+	var address *address_entity.Address
+
+	cacheRepo := cache.NewCacheRepository("Redis", r.p)
+	_ = cacheRepo.GetKey(fmt.Sprintf("%v_ADDRESS", id), &address)
+	if address == nil {
+		err := r.p.DB.Debug().Where("id = ?", id).Take(&address).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("address %v not found", id)
+			}
+			return nil, err
+		}
+		_ = cacheRepo.SetKey(fmt.Sprintf("%v_ADDRESS", id), address, time.Minute*15)
+	}
+
+	return address, nil
+}
+
+func (r *AddressRepo) GetAddressesByCustomer(customerId int64) ([]address_entity.Address, error) {
+	var addresses []address_entity.Address
+	err := r.p.DB.Debug().Where("customer_id = ?", customerId).Order("is_default desc, id asc").Find(&addresses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+// GetDefaultAddress returns nil without an error when the customer has not saved any address yet
+func (r *AddressRepo) GetDefaultAddress(customerId int64) (*address_entity.Address, error) {
+	var address address_entity.Address
+	err := r.p.DB.Debug().Where("customer_id = ? AND is_default = ?", customerId, true).Take(&address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+func (r *AddressRepo) UpdateAddress(address *address_entity.Address) (*address_entity.Address, error) {
+	err := r.p.DB.Debug().Model(&address_entity.Address{}).Where("id = ?", address.ID).
+		Select("label", "contact_name", "phone", "address", "instructions", "latitude", "longitude").
+		Updates(address).Error
+	if err != nil {
+		return nil, err
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", r.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_ADDRESS", address.ID))
+
+	return r.GetAddress(int64(address.ID))
+}
+
+// SetDefaultAddress makes the address the only default one in the customer's address book
+func (r *AddressRepo) SetDefaultAddress(customerId int64, addressId int64) (*address_entity.Address, error) {
+	var previous []uint64
+	err := r.p.DB.Transaction(func(tx *gorm.DB) error {
+		pluckErr := tx.Debug().Model(&address_entity.Address{}).Where("customer_id = ? AND is_default = ?", customerId, true).Pluck("id", &previous).Error
+		if pluckErr != nil {
+			return pluckErr
+		}
+
+		clearErr := tx.Debug().Model(&address_entity.Address{}).Where("customer_id = ?", customerId).Update("is_default", false).Error
+		if clearErr != nil {
+			return clearErr
+		}
+
+		result := tx.Debug().Model(&address_entity.Address{}).Where("id = ? AND customer_id = ?", addressId, customerId).Update("is_default", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("address %v not found", addressId)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", r.p)
+	for _, id := range previous {
+		cacheRepo.DelKey(fmt.Sprintf("%v_ADDRESS", id))
+	}
+	cacheRepo.DelKey(fmt.Sprintf("%v_ADDRESS", addressId))
+
+	return r.GetAddress(addressId)
+}
+
+// DeleteAddress removes an address, promoting the oldest remaining one if it was the default
+func (r *AddressRepo) DeleteAddress(id int64) error {
+	address, err := r.GetAddress(id)
+	if err != nil {
+		return err
+	}
+
+	var promoted uint64
+	err = r.p.DB.Transaction(func(tx *gorm.DB) error {
+		deleteErr := tx.Debug().Where("id = ?", id).Delete(&address_entity.Address{}).Error
+		if deleteErr != nil {
+			return deleteErr
+		}
+
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next address_entity.Address
+		nextErr := tx.Debug().Where("customer_id = ?", address.CustomerID).Order("id asc").Take(&next).Error
+		if errors.Is(nextErr, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if nextErr != nil {
+			return nextErr
+		}
+
+		promoted = next.ID
+		return tx.Debug().Model(&next).Update("is_default", true).Error
+	})
+
+	cacheRepo := cache.NewCacheRepository("Redis", r.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_ADDRESS", id))
+	if promoted > 0 {
+		cacheRepo.DelKey(fmt.Sprintf("%v_ADDRESS", promoted))
+	}
+	if err != nil {
+		return errors.New("database error, please try again")
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+)
+
+const (
+	defaultEmailVerificationHours = 48
+	defaultPasswordResetMinutes   = 30
+)
+
+// EmailVerificationTTL reads how long a verification link stays valid from EMAIL_VERIFICATION_TTL_HOURS
+func EmailVerificationTTL() time.Duration {
+	return time.Duration(envInt("EMAIL_VERIFICATION_TTL_HOURS", defaultEmailVerificationHours)) * time.Hour
+}
+
+// PasswordResetTTL reads how long a password reset link stays valid from PASSWORD_RESET_TTL_MINUTES
+func PasswordResetTTL() time.Duration {
+	return time.Duration(envInt("PASSWORD_RESET_TTL_MINUTES", defaultPasswordResetMinutes)) * time.Minute
+}
+
+// GenerateActionToken signs a one-time token for an emailed link. Earlier unused tokens of the
+// customer for the same purpose stop working
+func (a AuthRepo) GenerateActionToken(customerId int64, purpose string, email string, ttl time.Duration) (string, error) {
+	jti, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	signingKey, privateKey, err := a.GetSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(signingKey.Algorithm), jwt.MapClaims{
+		"user_id": fmt.Sprint(customerId),
+		"purpose": purpose,
+		"email":   email,
+		"iat":     now.Unix(),
+		"exp":     now.Add(ttl).Unix(),
+		"jti":     jti,
+	})
+	token.Header["kid"] = signingKey.KID
+
+	tokenString, err := token.SignedString(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.p.DB.Debug().Model(&auth_entity.ActionToken{}).
+		Where("customer_id = ? AND purpose = ? AND used_at IS NULL", customerId, purpose).
+		UpdateColumn("used_at", now).Error
+	if err != nil {
+		return "", err
+	}
+
+	actionToken := auth_entity.ActionToken{
+		JTI:        jti,
+		Purpose:    purpose,
+		CustomerID: customerId,
+		ExpiresAt:  now.Add(ttl),
+	}
+	err = a.p.DB.Debug().Create(&actionToken).Error
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// ConsumeActionToken verifies a one-time token for the purpose and marks it used,
+// returning the customer and email it was issued for
+func (a AuthRepo) ConsumeActionToken(tokenString string, purpose string) (int64, string, error) {
+	invalid := errors.New("invalid or expired token")
+
+	token, err := a.ValidateToken(tokenString)
+	if err != nil || !token.Valid {
+		return 0, "", invalid
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	tokenPurpose, _ := claims["purpose"].(string)
+	jti, _ := claims["jti"].(string)
+	userId, _ := claims["user_id"].(string)
+	email, _ := claims["email"].(string)
+	if tokenPurpose != purpose || jti == "" {
+		return 0, "", invalid
+	}
+
+	customerId, err := strconv.ParseInt(userId, 10, 64)
+	if err != nil {
+		return 0, "", invalid
+	}
+
+	result := a.p.DB.Debug().Model(&auth_entity.ActionToken{}).
+		Where("jti = ? AND purpose = ? AND customer_id = ? AND used_at IS NULL AND expires_at > ?", jti, purpose, customerId, time.Now()).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return 0, "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, "", invalid
+	}
+
+	return customerId, email, nil
+}
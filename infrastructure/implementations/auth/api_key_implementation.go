@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix marks our keys so they are easy to spot in logs and secret scanners
+const apiKeyPrefix = "qk_"
+
+// apiKeyLastUsedInterval limits how often last_used_at is written for busy keys
+const apiKeyLastUsedInterval = time.Minute
+
+var errInvalidAPIKey = errors.New("invalid api key")
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveAPIKey generates the key, stores its hash and returns the raw key, which is never stored.
+// Keys look like qk_<prefix>_<secret>
+func (a AuthRepo) SaveAPIKey(apiKey *auth_entity.APIKey) (string, error) {
+	id, err := randomHex(6)
+	if err != nil {
+		return "", err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", err
+	}
+
+	apiKey.Prefix = apiKeyPrefix + id
+	rawKey := apiKey.Prefix + "_" + secret
+	apiKey.KeyHash = hashAPIKey(rawKey)
+
+	err = a.p.DB.Debug().Create(apiKey).Error
+	if err != nil {
+		return "", err
+	}
+
+	return rawKey, nil
+}
+
+func (a AuthRepo) GetAPIKeys() ([]auth_entity.APIKey, error) {
+	var apiKeys []auth_entity.APIKey
+	err := a.p.DB.Debug().Order("id desc").Find(&apiKeys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (a AuthRepo) GetAPIKey(id uint64) (*auth_entity.APIKey, error) {
+	var apiKey auth_entity.APIKey
+	err := a.p.DB.Debug().Where("id = ?", id).Take(&apiKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("api key %v not found", id)
+		}
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (a AuthRepo) RevokeAPIKey(id uint64) error {
+	result := a.p.DB.Debug().Model(&auth_entity.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("api key %v not found or already revoked", id)
+	}
+
+	return nil
+}
+
+// ValidateAPIKey looks the key up by its prefix, compares the hash and rejects revoked or expired keys.
+// The last used time is refreshed at most once a minute
+func (a AuthRepo) ValidateAPIKey(rawKey string) (*auth_entity.APIKey, error) {
+	separator := strings.LastIndex(rawKey, "_")
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) || separator <= len(apiKeyPrefix) {
+		return nil, errInvalidAPIKey
+	}
+
+	var apiKey auth_entity.APIKey
+	err := a.p.DB.Debug().Where("prefix = ?", rawKey[:separator]).Take(&apiKey).Error
+	if err != nil {
+		return nil, errInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(hashAPIKey(rawKey))) != 1 {
+		return nil, errInvalidAPIKey
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt)) {
+		return nil, errInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyLastUsedInterval {
+		_ = a.p.DB.Debug().Model(&auth_entity.APIKey{}).Where("id = ?", apiKey.ID).UpdateColumn("last_used_at", now).Error
+		apiKey.LastUsedAt = &now
+	}
+
+	return &apiKey, nil
+}
@@ -1,17 +1,27 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/auth_repository"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
-
+const (
+	defaultAccessTokenMinutes = 15
+	defaultRefreshTokenHours  = 720
+)
 
 type AuthRepo struct {
 	p *base.Persistence
@@ -23,41 +33,94 @@ func NewAuthRepository(p *base.Persistence, c *gin.Context) *AuthRepo {
 	return &AuthRepo{p, c}
 }
 
+var _ auth_repository.AuthRepository = &AuthRepo{}
+
+// AccessTokenTTL reads the access token lifetime from ACCESS_TOKEN_TTL_MINUTES
+func AccessTokenTTL() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("ACCESS_TOKEN_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = defaultAccessTokenMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// RefreshTokenTTL reads the refresh token lifetime from REFRESH_TOKEN_TTL_HOURS
+func RefreshTokenTTL() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("REFRESH_TOKEN_TTL_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = defaultRefreshTokenHours
+	}
+	return time.Duration(hours) * time.Hour
+}
 
+// NewTokenID returns a random identifier used for jti claims and refresh token families
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
 
-func (a AuthRepo) GenerateToken(key []byte, userId int64, credential string) (string, error) {
+// GenerateToken signs a short-lived access token with the active signing key and returns it together with its jti
+func (a AuthRepo) GenerateToken(userId int64, credential string, role string) (string, string, error) {
+	jti, err := NewTokenID()
+	if err != nil {
+		return "", "", err
+	}
+
+	signingKey, privateKey, err := a.GetSigningKey()
+	if err != nil {
+		return "", "", err
+	}
 
 	//new token
-	token := jwt.New(jwt.SigningMethodHS256)
+	token := jwt.New(jwt.GetSigningMethod(signingKey.Algorithm))
+	token.Header["kid"] = signingKey.KID
  
 	// Claims
+	now := time.Now()
 	claims := make(jwt.MapClaims)
 	userIdString := fmt.Sprint(userId)
 	claims["user_id"] = userIdString
 	claims["credential"] = credential
-	claims["exp"] = time.Now().Add(time.Hour*720).UnixNano() / int64(time.Millisecond)
+	// Milliseconds are kept so a token issued just after a logout of every session is told apart from those before it
+	claims["iat"] = float64(now.UnixMilli()) / 1000
+	claims["exp"] = now.Add(AccessTokenTTL()).Unix()
+	claims["jti"] = jti
  
-	//Set user roles
-	//claims["roles"] = roles
+	//Set user role
+	claims["role"] = role
  
 	token.Claims = claims
  
 	// Sign and get as a string
-	tokenString, err := token.SignedString(key)
-	return tokenString, err
+	tokenString, err := token.SignedString(privateKey)
+	return tokenString, jti, err
 }
 
-func (a AuthRepo) ValidateToken(tokenString string, key string) (*jwt.Token, error) {
+// ValidateToken verifies the token with the key named in its kid header, the algorithm must be the key's own
+func (a AuthRepo) ValidateToken(tokenString string) (*jwt.Token, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-	   return []byte(key), nil
-	})
+	   kid, _ := token.Header["kid"].(string)
+	   signingKey, publicKey, keyErr := a.GetVerificationKey(kid)
+	   if keyErr != nil {
+		  return nil, keyErr
+	   }
+
+	   if token.Method.Alg() != signingKey.Algorithm {
+		  return nil, fmt.Errorf("unexpected signing algorithm %v", token.Method.Alg())
+	   }
+
+	   return publicKey, nil
+	}, jwt.WithValidMethods([]string{auth_entity.AlgorithmRS256, auth_entity.AlgorithmEdDSA}))
  
 	return token, err
 }
 
 func (a AuthRepo) GetCustomerWithUsername(username string) (*customer_entity.Customer, error) {
 	var customer *customer_entity.Customer
-	err := a.p.DB.Debug().Where("username = ?", username).Take(&customer).Error
+	err := a.p.DB.Debug().Where("LOWER(username) = ?", customer_entity.NormalizeUsername(username)).Take(&customer).Error
 
 	if err != nil {
 		return nil, err
@@ -66,25 +129,38 @@ func (a AuthRepo) GetCustomerWithUsername(username string) (*customer_entity.Cus
 	return customer, nil
 }
 
-func (a AuthRepo) BlacklistToken(token string) error {
+// RevokeJTI rejects the access token with this jti until it would have expired anyway
+func (a AuthRepo) RevokeJTI(jti string) error {
 	cacheRepo := cache.NewCacheRepository("Redis", a.p)
-
-	err := cacheRepo.SetKey(fmt.Sprintf("%v_JWTTOKEN", token), token, time.Hour * 720)
-	if err != nil {
-		return err
-	}
-	return nil
+	return cacheRepo.SetKey(fmt.Sprintf("%v_REVOKEDJTI", jti), true, AccessTokenTTL())
 }
 
-func (a AuthRepo) CheckBlacklistToken(token string) error {
+func (a AuthRepo) IsJTIRevoked(jti string) bool {
 	cacheRepo := cache.NewCacheRepository("Redis", a.p)
 
-	err := cacheRepo.GetKey(fmt.Sprintf("%v_JWTTOKEN", token), token)
+	var revoked bool
+	err := cacheRepo.GetKey(fmt.Sprintf("%v_REVOKEDJTI", jti), &revoked)
+	return err == nil && revoked
+}
 
-	if err != nil {
-		return err
-	}
+// RevokeCustomerAccessTokens rejects every access token of the customer issued up to now
+func (a AuthRepo) RevokeCustomerAccessTokens(customerId int64) error {
+	cacheRepo := cache.NewCacheRepository("Redis", a.p)
+	return cacheRepo.SetKey(fmt.Sprintf("%v_TOKENSVALIDAFTERMS", customerId), time.Now().UnixMilli(), AccessTokenTTL())
+}
+
+// TokensValidAfter returns the unix time in milliseconds up to which the customer's access tokens are rejected
+func (a AuthRepo) TokensValidAfter(customerId int64) int64 {
+	cacheRepo := cache.NewCacheRepository("Redis", a.p)
 
+	var validAfter int64
+	_ = cacheRepo.GetKey(fmt.Sprintf("%v_TOKENSVALIDAFTERMS", customerId), &validAfter)
+	return validAfter
+}
 
-	return nil
-}
\ No newline at end of file
+// IssuedBefore reports whether a token issued at issuedAt, unix seconds with a fraction as in the iat
+// claim, was issued up to validAfter, unix milliseconds. A token issued in the same millisecond as a
+// logout of every session is rejected with the others
+func IssuedBefore(issuedAt float64, validAfter int64) bool {
+	return validAfter > 0 && int64(math.Round(issuedAt*1000)) <= validAfter
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssuedBefore(t *testing.T) {
+	logout := time.Date(2024, 3, 1, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+	iat := func(at time.Time) float64 {
+		return float64(at.UnixMilli()) / 1000
+	}
+
+	tests := []struct {
+		name       string
+		issuedAt   float64
+		validAfter int64
+		want       bool
+	}{
+		{"no logout of every session", iat(logout), 0, false},
+		{"earlier in the same second", iat(logout.Add(-300 * time.Millisecond)), logout.UnixMilli(), true},
+		{"same millisecond", iat(logout), logout.UnixMilli(), true},
+		{"later in the same second", iat(logout.Add(time.Millisecond)), logout.UnixMilli(), false},
+		{"a second earlier", iat(logout.Add(-time.Second)), logout.UnixMilli(), true},
+		{"a second later", iat(logout.Add(time.Second)), logout.UnixMilli(), false},
+		{"whole second iat of an older token", float64(logout.Unix()), logout.UnixMilli(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IssuedBefore(tt.issuedAt, tt.validAfter); got != tt.want {
+				t.Errorf("IssuedBefore(%v, %v) = %v, want %v", tt.issuedAt, tt.validAfter, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"gorm.io/gorm"
+)
+
+// How long the loaded keys are trusted before the key store reads them again, so a rotation
+// done by another instance is picked up. Tokens with an unknown kid trigger an earlier reload,
+// but not more often than keyStoreMinReloadInterval
+const (
+	keyStoreRefreshInterval   = time.Minute
+	keyStoreMinReloadInterval = 5 * time.Second
+)
+
+type loadedKey struct {
+	key        auth_entity.SigningKey
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+var keyStore = struct {
+	sync.RWMutex
+	keys     map[string]loadedKey
+	active   string
+	loadedAt time.Time
+}{}
+
+// DefaultSigningAlgorithm reads the algorithm for new keys from JWT_SIGNING_ALG
+func DefaultSigningAlgorithm() string {
+	if os.Getenv("JWT_SIGNING_ALG") == auth_entity.AlgorithmEdDSA {
+		return auth_entity.AlgorithmEdDSA
+	}
+	return auth_entity.AlgorithmRS256
+}
+
+// verificationWindow is how long a retired key keeps verifying. Access tokens and the action tokens
+// in emailed links are signed with the same keys, so the window is the longest lifetime of any of
+// them and every token the key signed expires before the key stops verifying
+func verificationWindow() time.Duration {
+	window := AccessTokenTTL()
+	for _, ttl := range []time.Duration{EmailVerificationTTL(), PasswordResetTTL()} {
+		window = max(window, ttl)
+	}
+	return window + time.Minute
+}
+
+func generateKeyPair(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case auth_entity.AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case auth_entity.AlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	}
+
+	return nil, fmt.Errorf("unsupported signing algorithm %v", algorithm)
+}
+
+// newSigningKey generates an active key with a new kid, PEM encoded for storage
+func newSigningKey(algorithm string) (*auth_entity.SigningKey, error) {
+	signer, err := generateKeyPair(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth_entity.SigningKey{
+		KID:        kid,
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		Active:     true,
+	}, nil
+}
+
+func parseKey(key auth_entity.SigningKey) (loadedKey, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return loadedKey{}, fmt.Errorf("signing key %v is not PEM encoded", key.KID)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return loadedKey{}, err
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return loadedKey{}, fmt.Errorf("signing key %v cannot sign", key.KID)
+	}
+
+	return loadedKey{key: key, privateKey: signer, publicKey: signer.Public()}, nil
+}
+
+// loadKeys reads the active key and the retired keys that can still verify tokens when the
+// loaded keys are older than maxAge
+func (a AuthRepo) loadKeys(maxAge time.Duration) error {
+	keyStore.RLock()
+	fresh := keyStore.keys != nil && time.Since(keyStore.loadedAt) < maxAge
+	keyStore.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	var keys []auth_entity.SigningKey
+	err := a.p.DB.Debug().Where("active = ? OR retired_at > ?", true, time.Now().Add(-verificationWindow())).Find(&keys).Error
+	if err != nil {
+		return err
+	}
+
+	loaded := map[string]loadedKey{}
+	active := ""
+	for _, key := range keys {
+		parsedKey, parseErr := parseKey(key)
+		if parseErr != nil {
+			return parseErr
+		}
+		loaded[key.KID] = parsedKey
+		if key.Active {
+			active = key.KID
+		}
+	}
+
+	keyStore.Lock()
+	keyStore.keys = loaded
+	keyStore.active = active
+	keyStore.loadedAt = time.Now()
+	keyStore.Unlock()
+
+	return nil
+}
+
+// GetSigningKey returns the key new tokens are signed with
+func (a AuthRepo) GetSigningKey() (*auth_entity.SigningKey, crypto.Signer, error) {
+	if err := a.loadKeys(keyStoreRefreshInterval); err != nil {
+		return nil, nil, err
+	}
+
+	keyStore.RLock()
+	key, ok := keyStore.keys[keyStore.active]
+	keyStore.RUnlock()
+	if !ok {
+		return nil, nil, errors.New("no active signing key, rotate the signing keys first")
+	}
+
+	return &key.key, key.privateKey, nil
+}
+
+// GetVerificationKey returns the public key for a kid, reloading once in case the key is newer than the cache
+func (a AuthRepo) GetVerificationKey(kid string) (*auth_entity.SigningKey, crypto.PublicKey, error) {
+	for _, maxAge := range []time.Duration{keyStoreRefreshInterval, keyStoreMinReloadInterval} {
+		if err := a.loadKeys(maxAge); err != nil {
+			return nil, nil, err
+		}
+
+		keyStore.RLock()
+		key, ok := keyStore.keys[kid]
+		keyStore.RUnlock()
+		if ok {
+			return &key.key, key.publicKey, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("unknown signing key %v", kid)
+}
+
+// RotateSigningKey creates a new active key and retires the current one, which keeps
+// verifying the tokens it already signed
+func (a AuthRepo) RotateSigningKey(algorithm string) (*auth_entity.SigningKey, error) {
+	if algorithm == "" {
+		algorithm = DefaultSigningAlgorithm()
+	}
+
+	key, err := newSigningKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.p.DB.Transaction(func(tx *gorm.DB) error {
+		retireErr := tx.Debug().Model(&auth_entity.SigningKey{}).Where("active = ?", true).
+			UpdateColumns(map[string]interface{}{"active": false, "retired_at": time.Now()}).Error
+		if retireErr != nil {
+			return retireErr
+		}
+
+		return tx.Debug().Create(key).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.loadKeys(0); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// EnsureSigningKey creates the first signing key on a fresh install
+func (a AuthRepo) EnsureSigningKey() error {
+	var count int64
+	err := a.p.DB.Debug().Model(&auth_entity.SigningKey{}).Where("active = ?", true).Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = a.RotateSigningKey(DefaultSigningAlgorithm())
+	return err
+}
+
+// GetSigningKeys lists the keys that currently sign or verify tokens
+func (a AuthRepo) GetSigningKeys() ([]auth_entity.SigningKey, error) {
+	if err := a.loadKeys(keyStoreRefreshInterval); err != nil {
+		return nil, err
+	}
+
+	keyStore.RLock()
+	defer keyStore.RUnlock()
+
+	keys := make([]auth_entity.SigningKey, 0, len(keyStore.keys))
+	for _, key := range keyStore.keys {
+		keys = append(keys, key.key)
+	}
+
+	return keys, nil
+}
+
+// GetJWKS publishes the public keys so other services can verify tokens without a shared secret
+func (a AuthRepo) GetJWKS() (*auth_entity.JWKS, error) {
+	if err := a.loadKeys(keyStoreRefreshInterval); err != nil {
+		return nil, err
+	}
+
+	keyStore.RLock()
+	defer keyStore.RUnlock()
+
+	jwks := auth_entity.JWKS{Keys: []auth_entity.JWK{}}
+	for kid, key := range keyStore.keys {
+		jwk := auth_entity.JWK{Kid: kid, Use: "sig", Alg: key.key.Algorithm}
+
+		switch publicKey := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return &jwks, nil
+}
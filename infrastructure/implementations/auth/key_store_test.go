@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+)
+
+func testSigningKey(t *testing.T, algorithm string) *auth_entity.SigningKey {
+	t.Helper()
+	key, err := newSigningKey(algorithm)
+	if err != nil {
+		t.Fatalf("newSigningKey(%v): %v", algorithm, err)
+	}
+	return key
+}
+
+// useKeys loads the keys into the key store as if just read from the database
+func useKeys(t *testing.T, keys ...*auth_entity.SigningKey) {
+	t.Helper()
+	loaded := map[string]loadedKey{}
+	active := ""
+	for _, key := range keys {
+		parsed, err := parseKey(*key)
+		if err != nil {
+			t.Fatalf("parseKey(%v): %v", key.KID, err)
+		}
+		loaded[key.KID] = parsed
+		if key.Active {
+			active = key.KID
+		}
+	}
+
+	keyStore.Lock()
+	keyStore.keys = loaded
+	keyStore.active = active
+	keyStore.loadedAt = time.Now()
+	keyStore.Unlock()
+
+	t.Cleanup(func() {
+		keyStore.Lock()
+		keyStore.keys = nil
+		keyStore.active = ""
+		keyStore.Unlock()
+	})
+}
+
+func retire(key *auth_entity.SigningKey) *auth_entity.SigningKey {
+	retired := *key
+	retiredAt := time.Now()
+	retired.Active = false
+	retired.RetiredAt = &retiredAt
+	return &retired
+}
+
+func tokenKID(t *testing.T, tokenString string) string {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{"RS256 to RS256", auth_entity.AlgorithmRS256, auth_entity.AlgorithmRS256},
+		{"RS256 to EdDSA", auth_entity.AlgorithmRS256, auth_entity.AlgorithmEdDSA},
+		{"EdDSA to RS256", auth_entity.AlgorithmEdDSA, auth_entity.AlgorithmRS256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := AuthRepo{}
+			oldKey := testSigningKey(t, tt.from)
+			newKey := testSigningKey(t, tt.to)
+
+			useKeys(t, oldKey)
+			oldToken, _, err := repo.GenerateToken(1, "alice", auth_entity.RoleCustomer)
+			if err != nil {
+				t.Fatalf("GenerateToken with the old key: %v", err)
+			}
+
+			// Rotated, the old key is retired but still inside its verification window
+			useKeys(t, retire(oldKey), newKey)
+			newToken, _, err := repo.GenerateToken(1, "alice", auth_entity.RoleCustomer)
+			if err != nil {
+				t.Fatalf("GenerateToken with the new key: %v", err)
+			}
+			if kid := tokenKID(t, newToken); kid != newKey.KID {
+				t.Errorf("new token signed with %v, want the active key %v", kid, newKey.KID)
+			}
+			if _, err := repo.ValidateToken(oldToken); err != nil {
+				t.Errorf("token of the retired key rejected: %v", err)
+			}
+			if _, err := repo.ValidateToken(newToken); err != nil {
+				t.Errorf("token of the active key rejected: %v", err)
+			}
+
+			// Past the window the retired key is no longer loaded
+			useKeys(t, newKey)
+			if _, err := repo.ValidateToken(oldToken); err == nil {
+				t.Error("token of a key past its verification window accepted")
+			}
+		})
+	}
+}
+
+func TestValidateTokenRejectsForeignAlgorithm(t *testing.T) {
+	repo := AuthRepo{}
+	rsaKey := testSigningKey(t, auth_entity.AlgorithmRS256)
+	edKey := testSigningKey(t, auth_entity.AlgorithmEdDSA)
+	edKey.Active = false
+	useKeys(t, rsaKey, edKey)
+
+	// Signed with the EdDSA key but naming the RSA key
+	parsed, err := parseKey(*edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"user_id": "1", "exp": time.Now().Add(time.Minute).Unix()})
+	token.Header["kid"] = rsaKey.KID
+	tokenString, err := token.SignedString(parsed.privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.ValidateToken(tokenString); err == nil {
+		t.Error("token signed with another algorithm than its key's accepted")
+	}
+}
+
+func TestVerificationWindowOutlivesTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"defaults", nil},
+		{"long access tokens", map[string]string{"ACCESS_TOKEN_TTL_MINUTES": "4320"}},
+		{"long reset links", map[string]string{"PASSWORD_RESET_TTL_MINUTES": "6000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			window := verificationWindow()
+			for name, ttl := range map[string]time.Duration{
+				"access token":       AccessTokenTTL(),
+				"email verification": EmailVerificationTTL(),
+				"password reset":     PasswordResetTTL(),
+			} {
+				if window <= ttl {
+					t.Errorf("window %v does not outlive the %v lifetime %v", window, name, ttl)
+				}
+			}
+		})
+	}
+}
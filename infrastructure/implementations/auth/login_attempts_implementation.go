@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
+)
+
+const (
+	defaultLoginMaxAttempts      = 5
+	defaultLoginMaxAttemptsPerIP = 20
+	defaultLoginLockoutMinutes   = 15
+)
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// LoginMaxAttempts is how many failures a username may have before it is locked, from LOGIN_MAX_ATTEMPTS
+func LoginMaxAttempts() int {
+	return envInt("LOGIN_MAX_ATTEMPTS", defaultLoginMaxAttempts)
+}
+
+// LoginMaxAttemptsPerIP is the same limit for a single IP address, from LOGIN_MAX_ATTEMPTS_PER_IP
+func LoginMaxAttemptsPerIP() int {
+	return envInt("LOGIN_MAX_ATTEMPTS_PER_IP", defaultLoginMaxAttemptsPerIP)
+}
+
+// LoginLockout is how long a lockout lasts and how long failures are remembered, from LOGIN_LOCKOUT_MINUTES
+func LoginLockout() time.Duration {
+	return time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", defaultLoginLockoutMinutes)) * time.Minute
+}
+
+func UsernameAttemptsKey(username string) string {
+	return fmt.Sprintf("%v_LOGINFAILS_USER", strings.ToLower(strings.TrimSpace(username)))
+}
+
+func IPAttemptsKey(ip string) string {
+	return fmt.Sprintf("%v_LOGINFAILS_IP", ip)
+}
+
+func (a AuthRepo) GetLoginAttempts(key string) auth_entity.LoginAttempts {
+	cacheRepo := cache.NewCacheRepository("Redis", a.p)
+
+	var attempts auth_entity.LoginAttempts
+	_ = cacheRepo.GetKey(key, &attempts)
+	return attempts
+}
+
+func (a AuthRepo) SaveLoginAttempts(key string, attempts auth_entity.LoginAttempts) error {
+	cacheRepo := cache.NewCacheRepository("Redis", a.p)
+	return cacheRepo.SetKey(key, attempts, LoginLockout())
+}
+
+func (a AuthRepo) ClearLoginAttempts(key string) error {
+	cacheRepo := cache.NewCacheRepository("Redis", a.p)
+	return cacheRepo.DelKey(key)
+}
+
+func (a AuthRepo) SaveAuditEvent(event *auth_entity.AuditEvent) error {
+	return a.p.DB.Debug().Create(event).Error
+}
+
+func (a AuthRepo) GetAuditEvents(event string, username string) ([]auth_entity.AuditEvent, error) {
+	query := a.p.DB.Debug().Order("created_at desc").Limit(500)
+	if event != "" {
+		query = query.Where("event = ?", event)
+	}
+	if username != "" {
+		query = query.Where("username = ?", strings.ToLower(strings.TrimSpace(username)))
+	}
+
+	var events []auth_entity.AuditEvent
+	err := query.Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
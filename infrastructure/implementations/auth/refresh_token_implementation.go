@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"gorm.io/gorm"
+)
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveRefreshToken creates a refresh token in the family and returns the raw token, which is never stored
+func (a AuthRepo) SaveRefreshToken(tx *gorm.DB, customerId int64, familyId string, accessJti string) (string, *auth_entity.RefreshToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	rawToken := base64.RawURLEncoding.EncodeToString(b)
+
+	if tx == nil {
+		tx = a.p.DB
+	}
+
+	refreshToken := auth_entity.RefreshToken{
+		CustomerID: customerId,
+		TokenHash:  hashRefreshToken(rawToken),
+		FamilyID:   familyId,
+		AccessJTI:  accessJti,
+		ExpiresAt:  time.Now().Add(RefreshTokenTTL()),
+	}
+
+	err := tx.Debug().Create(&refreshToken).Error
+	if err != nil {
+		return "", nil, err
+	}
+
+	return rawToken, &refreshToken, nil
+}
+
+func (a AuthRepo) GetRefreshToken(rawToken string) (*auth_entity.RefreshToken, error) {
+	var refreshToken auth_entity.RefreshToken
+	err := a.p.DB.Debug().Where("token_hash = ?", hashRefreshToken(rawToken)).Take(&refreshToken).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	return &refreshToken, nil
+}
+
+// ConsumeRefreshToken revokes the token as part of a rotation. It fails when another request
+// rotated the same token first, which is treated like reuse
+func (a AuthRepo) ConsumeRefreshToken(tx *gorm.DB, id uint64, replacedById uint64) error {
+	result := tx.Debug().Model(&auth_entity.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumns(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"replaced_by_id": replacedById,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("refresh token has already been used")
+	}
+
+	return nil
+}
+
+// GetRefreshTokenByAccessJTI finds the refresh token issued together with an access token
+func (a AuthRepo) GetRefreshTokenByAccessJTI(jti string) (*auth_entity.RefreshToken, error) {
+	var refreshToken auth_entity.RefreshToken
+	err := a.p.DB.Debug().Where("access_jti = ?", jti).Take(&refreshToken).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &refreshToken, nil
+}
+
+func (a AuthRepo) GetRefreshTokenFamily(familyId string) ([]auth_entity.RefreshToken, error) {
+	var refreshTokens []auth_entity.RefreshToken
+	err := a.p.DB.Debug().Where("family_id = ?", familyId).Find(&refreshTokens).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return refreshTokens, nil
+}
+
+func (a AuthRepo) RevokeRefreshTokenFamily(familyId string) error {
+	return a.p.DB.Debug().Model(&auth_entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		UpdateColumn("revoked_at", time.Now()).Error
+}
+
+func (a AuthRepo) RevokeCustomerRefreshTokens(customerId int64) error {
+	return a.p.DB.Debug().Model(&auth_entity.RefreshToken{}).
+		Where("customer_id = ? AND revoked_at IS NULL", customerId).
+		UpdateColumn("revoked_at", time.Now()).Error
+}
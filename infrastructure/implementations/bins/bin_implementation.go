@@ -0,0 +1,305 @@
+package bins
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/bin_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/bin_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+// To manage the zones, aisles and bins of warehouses and the stock in each bin in the database
+
+// Bin Repository struct
+type BinRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewBinRepository(p *base.Persistence, c *gin.Context) *BinRepo {
+	return &BinRepo{p, c}
+}
+
+// To explicitly check that the BinRepo implements the repository.BinRepository interface
+var _ bin_repository.BinRepository = &BinRepo{}
+
+func (r *BinRepo) SaveZone(zone *bin_entity.Zone) (*bin_entity.Zone, map[string]string) {
+	dbErr := map[string]string{}
+	err := r.p.DB.Debug().Create(&zone).Error
+	if err != nil {
+		fmt.Println("Failed to create zone")
+		fmt.Println(err)
+		dbErr["db_error"] = "database error"
+		return nil, dbErr
+	}
+
+	return zone, nil
+}
+
+func (r *BinRepo) GetZone(id int64) (*bin_entity.Zone, error) {
+	var zone bin_entity.Zone
+	err := r.p.DB.Debug().Where("id = ?", id).Take(&zone).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("zone %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &zone, nil
+}
+
+// GetZones returns the zones of a warehouse in walking order
+func (r *BinRepo) GetZones(warehouseId int64) ([]bin_entity.Zone, error) {
+	var zones []bin_entity.Zone
+	err := r.p.DB.Debug().Where("warehouse_id = ?", warehouseId).Order("sequence, code").Find(&zones).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+func (r *BinRepo) UpdateZone(zone *bin_entity.Zone) (*bin_entity.Zone, error) {
+	// Select so that a sequence of 0 is saved
+	err := r.p.DB.Debug().Model(&zone).Select("code", "name", "sequence").Updates(zone).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (r *BinRepo) DeleteZone(id int64) error {
+	err := r.p.DB.Debug().Where("id = ?", id).Delete(&bin_entity.Zone{}).Error
+	if err != nil {
+		return errors.New("database error, please try again")
+	}
+
+	return nil
+}
+
+func (r *BinRepo) SaveAisle(aisle *bin_entity.Aisle) (*bin_entity.Aisle, map[string]string) {
+	dbErr := map[string]string{}
+	err := r.p.DB.Debug().Create(&aisle).Error
+	if err != nil {
+		fmt.Println("Failed to create aisle")
+		fmt.Println(err)
+		dbErr["db_error"] = "database error"
+		return nil, dbErr
+	}
+
+	return aisle, nil
+}
+
+func (r *BinRepo) GetAisle(id int64) (*bin_entity.Aisle, error) {
+	var aisle bin_entity.Aisle
+	err := r.p.DB.Debug().Where("id = ?", id).Take(&aisle).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("aisle %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &aisle, nil
+}
+
+// GetAisles returns the aisles of a zone in walking order
+func (r *BinRepo) GetAisles(zoneId int64) ([]bin_entity.Aisle, error) {
+	var aisles []bin_entity.Aisle
+	err := r.p.DB.Debug().Where("zone_id = ?", zoneId).Order("sequence, code").Find(&aisles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return aisles, nil
+}
+
+// UpdateAisle saves the aisle and moves its bins along when it changes zone
+func (r *BinRepo) UpdateAisle(aisle *bin_entity.Aisle) (*bin_entity.Aisle, error) {
+	err := r.p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Debug().Model(&aisle).Select("zone_id", "code", "sequence").Updates(aisle).Error; err != nil {
+			return err
+		}
+
+		return tx.Debug().Model(&bin_entity.Bin{}).Where("aisle_id = ?", aisle.ID).Update("zone_id", aisle.ZoneID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return aisle, nil
+}
+
+func (r *BinRepo) DeleteAisle(id int64) error {
+	err := r.p.DB.Debug().Where("id = ?", id).Delete(&bin_entity.Aisle{}).Error
+	if err != nil {
+		return errors.New("database error, please try again")
+	}
+
+	return nil
+}
+
+func (r *BinRepo) SaveBin(bin *bin_entity.Bin) (*bin_entity.Bin, map[string]string) {
+	dbErr := map[string]string{}
+	err := r.p.DB.Debug().Create(&bin).Error
+	if err != nil {
+		fmt.Println("Failed to create bin")
+		fmt.Println(err)
+		dbErr["db_error"] = "database error"
+		return nil, dbErr
+	}
+
+	return bin, nil
+}
+
+func (r *BinRepo) GetBin(id int64) (*bin_entity.Bin, error) {
+	var bin bin_entity.Bin
+	err := r.p.DB.Debug().Where("id = ?", id).Take(&bin).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("bin %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &bin, nil
+}
+
+// GetBins returns the bins of an aisle in walking order
+func (r *BinRepo) GetBins(aisleId int64) ([]bin_entity.Bin, error) {
+	var bins []bin_entity.Bin
+	err := r.p.DB.Debug().Where("aisle_id = ?", aisleId).Order("sequence, code").Find(&bins).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return bins, nil
+}
+
+func (r *BinRepo) UpdateBin(bin *bin_entity.Bin) (*bin_entity.Bin, error) {
+	// Select so that deactivating a bin is saved
+	err := r.p.DB.Debug().Model(&bin).Select("zone_id", "aisle_id", "code", "sequence", "capacity", "active").Updates(bin).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return bin, nil
+}
+
+func (r *BinRepo) DeleteBin(id int64) error {
+	err := r.p.DB.Debug().Where("id = ?", id).Delete(&bin_entity.Bin{}).Error
+	if err != nil {
+		return errors.New("database error, please try again")
+	}
+
+	return nil
+}
+
+// GetLayout returns the zones of a warehouse with their aisles and bins, everything in walking order
+func (r *BinRepo) GetLayout(warehouseId int64) ([]bin_entity.Zone, error) {
+	var zones []bin_entity.Zone
+	err := r.p.DB.Debug().
+		Preload("Aisles", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence, code")
+		}).
+		Preload("Aisles.Bins", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence, code")
+		}).
+		Where("warehouse_id = ?", warehouseId).Order("sequence, code").Find(&zones).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bin_entity.SetLabels(zones)
+
+	return zones, nil
+}
+
+// GetBinStocks returns what a bin holds
+func (r *BinRepo) GetBinStocks(binId int64) ([]bin_entity.BinStock, error) {
+	var stocks []bin_entity.BinStock
+	err := r.p.DB.Debug().Where("bin_id = ? AND quantity > 0", binId).Order("product_id").Find(&stocks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+// GetProductBinStocks returns the active bins holding the given products
+func (r *BinRepo) GetProductBinStocks(productIds []int64) ([]bin_entity.BinStock, error) {
+	var stocks []bin_entity.BinStock
+	if len(productIds) == 0 {
+		return stocks, nil
+	}
+
+	err := r.p.DB.Debug().
+		Joins("JOIN bins ON bins.id = bin_stocks.bin_id AND bins.active AND bins.deleted_at IS NULL").
+		Where("bin_stocks.product_id IN ? AND bin_stocks.quantity > 0", productIds).
+		Order("bin_stocks.product_id, bin_stocks.bin_id").Find(&stocks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+// GetBinnedQuantity returns how many units of a product sit in bins of the warehouse
+func (r *BinRepo) GetBinnedQuantity(warehouseId int64, productId int64) (int, error) {
+	var quantity int
+	err := r.p.DB.Debug().Model(&bin_entity.BinStock{}).Where("warehouse_id = ? AND product_id = ?", warehouseId, productId).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&quantity).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+// AddBinStock puts units of a product in a bin
+func (r *BinRepo) AddBinStock(tx *gorm.DB, binId uint64, warehouseId uint64, productId uint64, quantity int) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	result := tx.Debug().Model(&bin_entity.BinStock{}).Where("bin_id = ? AND product_id = ?", binId, productId).
+		Update("quantity", gorm.Expr("quantity + ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	stock := bin_entity.BinStock{
+		BinID:       binId,
+		WarehouseID: warehouseId,
+		ProductID:   productId,
+		Quantity:    quantity,
+	}
+	return tx.Debug().Create(&stock).Error
+}
+
+// TakeBinStock removes units of a product from a bin only if the bin holds that many
+func (r *BinRepo) TakeBinStock(tx *gorm.DB, binId uint64, productId uint64, quantity int) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	result := tx.Debug().Model(&bin_entity.BinStock{}).
+		Where("bin_id = ? AND product_id = ? AND quantity >= ?", binId, productId, quantity).
+		Update("quantity", gorm.Expr("quantity - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("bin %v holds fewer than %v units of product %v", binId, quantity, productId)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package bundles
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/bundle_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+// To manage bundle components in the database
+
+// Bundle Repository struct
+type BundleRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewBundleRepository(p *base.Persistence, c *gin.Context) *BundleRepo {
+	return &BundleRepo{p, c}
+}
+
+// To explicitly check that the BundleRepo implements the repository.BundleRepository interface
+var _ bundle_repository.BundleRepository = &BundleRepo{}
+
+// ValidateBundleItems checks that the given items can make up the bundle: at least one component,
+// each listed once with a positive quantity, and every component an existing product that is not a bundle
+func (r *BundleRepo) ValidateBundleItems(tx *gorm.DB, bundleID uint64, items []product_entity.BundleItem) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	if len(items) == 0 {
+		return errors.New("a bundle needs at least one component")
+	}
+
+	componentIDs := make([]uint64, 0, len(items))
+	seen := make(map[uint64]bool, len(items))
+	for _, item := range items {
+		if item.ComponentID == bundleID {
+			return errors.New("a bundle cannot contain itself")
+		}
+		if seen[item.ComponentID] {
+			return fmt.Errorf("component product %v is listed more than once, set its quantity instead", item.ComponentID)
+		}
+		seen[item.ComponentID] = true
+		if item.Quantity <= 0 {
+			return fmt.Errorf("quantity for component %v must be greater than 0", item.ComponentID)
+		}
+		componentIDs = append(componentIDs, item.ComponentID)
+	}
+
+	var components []product_entity.Product
+	err := tx.Debug().Where("id IN ?", componentIDs).Find(&components).Error
+	if err != nil {
+		return err
+	}
+
+	found := make(map[uint64]product_entity.Product, len(components))
+	for _, component := range components {
+		found[component.ID] = component
+	}
+
+	for _, id := range componentIDs {
+		component, ok := found[id]
+		if !ok {
+			return fmt.Errorf("component product %v not found", id)
+		}
+		if component.IsBundle() {
+			return fmt.Errorf("component product %v is itself a bundle", id)
+		}
+	}
+
+	return nil
+}
+
+// SaveBundleItems replaces the components of a bundle with the given items
+func (r *BundleRepo) SaveBundleItems(tx *gorm.DB, bundleID uint64, items []product_entity.BundleItem) ([]product_entity.BundleItem, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	err := r.ValidateBundleItems(tx, bundleID, items)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Debug().Where("bundle_id = ?", bundleID).Delete(&product_entity.BundleItem{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	savedItems := make([]product_entity.BundleItem, 0, len(items))
+	for _, item := range items {
+		savedItems = append(savedItems, product_entity.BundleItem{
+			BundleID:    bundleID,
+			ComponentID: item.ComponentID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	err = tx.Debug().Create(&savedItems).Error
+	if err != nil {
+		fmt.Println("Failed to create bundle items")
+		return nil, err
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", r.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_PRODUCTS", bundleID))
+
+	return savedItems, nil
+}
+
+func (r *BundleRepo) GetBundleItems(bundleID int64) ([]product_entity.BundleItem, error) {
+	var items []product_entity.BundleItem
+
+	err := r.p.DB.Debug().Where("bundle_id = ?", bundleID).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// GetBundleAvailability works out how many bundles each warehouse can fulfil from its current component stock
+func (r *BundleRepo) GetBundleAvailability(bundleID int64) (*product_entity.BundleAvailability, error) {
+	items, err := r.GetBundleItems(bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("bundle has no components")
+	}
+
+	componentIDs := make([]uint64, 0, len(items))
+	for _, item := range items {
+		componentIDs = append(componentIDs, item.ComponentID)
+	}
+
+	var inventories []inventory_entity.Inventory
+	err = r.p.DB.Debug().Where("product_id IN ?", componentIDs).Find(&inventories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	availability := product_entity.NewBundleAvailability(uint64(bundleID), items, inventories)
+	return &availability, nil
+}
@@ -0,0 +1,166 @@
+package categories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/category_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/products"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/search"
+	"gorm.io/gorm"
+)
+
+// DeleteCategoryWithPolicy deletes a category, deciding what happens to its child categories and products:
+//   - block: refuse to delete while anything still references the category
+//   - reassign: move child categories and products up to the parent category
+//   - cascade: soft delete the whole subtree together with its products
+//
+// With dryRun set nothing is changed and the report lists what would be affected
+func (c *CategoryRepo) DeleteCategoryWithPolicy(id int64, policy string, dryRun bool) (*category_entity.CategoryDeletionReport, error) {
+	if policy == "" {
+		policy = category_entity.DeletePolicyBlock
+	}
+
+	if policy != category_entity.DeletePolicyBlock && policy != category_entity.DeletePolicyReassign && policy != category_entity.DeletePolicyCascade {
+		return nil, fmt.Errorf("unknown deletion policy %v", policy)
+	}
+
+	category, err := c.getCategoryFromDB(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var childIDs []uint64
+	err = c.p.DB.Debug().Model(&category_entity.Category{}).Where("parent_id = ?", id).Order("position asc").Pluck("id", &childIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	deletedIDs := []uint64{category.ID}
+	if policy == category_entity.DeletePolicyCascade {
+		deletedIDs = nil
+		err = c.p.DB.Debug().Model(&category_entity.Category{}).Where("path LIKE ?", category.Path+"%").Pluck("id", &deletedIDs).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var productIDs []uint64
+	err = c.p.DB.Debug().Model(&product_entity.Product{}).Where("category_id IN ?", deletedIDs).Pluck("id", &productIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	report := &category_entity.CategoryDeletionReport{
+		CategoryID:         category.ID,
+		Policy:             policy,
+		DryRun:             dryRun,
+		ChildCategoryIDs:   childIDs,
+		DeletedCategoryIDs: deletedIDs,
+		AffectedProductIDs: productIDs,
+	}
+
+	switch policy {
+	case category_entity.DeletePolicyBlock:
+		if len(childIDs) > 0 || len(productIDs) > 0 {
+			return report, fmt.Errorf("category %v still has %v child categories and %v products", id, len(childIDs), len(productIDs))
+		}
+	case category_entity.DeletePolicyReassign:
+		if category.ParentID == 0 && len(productIDs) > 0 {
+			return report, fmt.Errorf("category %v is a root category, its %v products have no parent to move to", id, len(productIDs))
+		}
+		report.ReassignedToID = category.ParentID
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	deletedAt := time.Now()
+	err = c.p.DB.Transaction(func(tx *gorm.DB) error {
+		switch policy {
+		case category_entity.DeletePolicyReassign:
+			if reassignErr := c.reassignToParent(tx, category, productIDs); reassignErr != nil {
+				return reassignErr
+			}
+		case category_entity.DeletePolicyCascade:
+			if deleteErr := products.SoftDeleteProducts(tx, toInt64s(productIDs), deletedAt); deleteErr != nil {
+				return deleteErr
+			}
+		}
+
+		return tx.Debug().Model(&category_entity.Category{}).Where("id IN ?", deletedIDs).Update("deleted_at", deletedAt).Error
+	})
+	if err != nil {
+		return report, err
+	}
+
+	c.clearCategoryCache(toInt64s(append(deletedIDs, childIDs...)))
+
+	cacheRepo := cache.NewCacheRepository("Redis", c.p)
+	searchRepo := search.NewSearchRepository("Mongo", c.p, c.c)
+	for _, productID := range productIDs {
+		cacheRepo.DelKey(fmt.Sprintf("%v_PRODUCTS", productID))
+		if policy == category_entity.DeletePolicyCascade {
+			_ = searchRepo.DeleteSingleDoc("id", "products", int64(productID))
+		}
+	}
+
+	return report, nil
+}
+
+// reassignToParent lifts the subtrees of the direct children one level up and moves the products to the parent
+func (c *CategoryRepo) reassignToParent(tx *gorm.DB, category *category_entity.Category, productIDs []uint64) error {
+	parentPath := "/"
+	if category.ParentID != 0 {
+		parent, err := c.getCategoryFromDB(category.ParentID)
+		if err != nil {
+			return err
+		}
+		parentPath = parent.Path
+	}
+
+	nextPosition := c.nextPosition(category.ParentID)
+	var children []category_entity.Category
+	err := tx.Debug().Where("parent_id = ?", category.ID).Order("position asc").Find(&children).Error
+	if err != nil {
+		return err
+	}
+
+	for i, child := range children {
+		updateErr := tx.Debug().Model(&category_entity.Category{}).Where("id = ?", child.ID).
+			UpdateColumns(map[string]interface{}{
+				"parent_id": category.ParentID,
+				"position":  nextPosition + i,
+			}).Error
+		if updateErr != nil {
+			return updateErr
+		}
+	}
+
+	err = tx.Debug().Unscoped().Model(&category_entity.Category{}).
+		Where("path LIKE ? AND id <> ?", category.Path+"%", category.ID).
+		UpdateColumns(map[string]interface{}{
+			"path":  gorm.Expr("CONCAT(?::text, SUBSTR(path, ?))", parentPath, len(category.Path)+1),
+			"depth": gorm.Expr("depth - 1"),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	return tx.Debug().Model(&product_entity.Product{}).Where("id IN ?", productIDs).Update("category_id", category.ParentID).Error
+}
+
+func toInt64s(ids []uint64) []int64 {
+	converted := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		converted = append(converted, int64(id))
+	}
+	return converted
+}
@@ -32,6 +32,19 @@ func (c *CategoryRepo) SaveCategory(category *category_entity.Category) (*catego
 	cacheRepo := cache.NewCacheRepository("Redis", c.p)
 
 	dbErr := map[string]string{}
+	parentPath := "/"
+	category.Depth = 0
+	if category.ParentID != 0 {
+		parent, parentErr := c.getCategoryFromDB(category.ParentID)
+		if parentErr != nil {
+			dbErr["db_error"] = parentErr.Error()
+			return nil, dbErr
+		}
+		parentPath = parent.Path
+		category.Depth = parent.Depth + 1
+	}
+
+	category.Position = c.nextPosition(category.ParentID)
 	err := c.p.DB.Debug().Create(&category).Error
 	if err != nil {
 		fmt.Println("Failed to create category")
@@ -39,6 +52,14 @@ func (c *CategoryRepo) SaveCategory(category *category_entity.Category) (*catego
 		dbErr["db_error"] = "database error"
 		return nil, dbErr
 	}
+
+	// The path needs the generated ID, so it is set once the row exists
+	category.Path = categoryPath(parentPath, category.ID)
+	err = c.p.DB.Debug().Model(category).UpdateColumn("path", category.Path).Error
+	if err != nil {
+		dbErr["db_error"] = "database error"
+		return nil, dbErr
+	}
 	fmt.Printf("Type of product: %T\n", category) // Log the type of the product
 
 	cacheRepo.SetKey(fmt.Sprintf("%v_CATEGORIES", category.ID), category, time.Minute * 15)
@@ -113,31 +134,17 @@ func (c *CategoryRepo) GetAllCategories() ([]category_entity.Category, error) {
 	return categories, nil
 }
 
+// GetParentCategories returns the category followed by each of its ancestors up to the root
 func (c *CategoryRepo) GetParentCategories(id int64) ([]category_entity.Category, error) {
-	var relatedCategories []category_entity.Category
-	var rootReached bool
-	childCategory, err := c.GetCategory(id)
-	relatedCategories = append(relatedCategories, *childCategory)
+	breadcrumbs, err := c.GetBreadcrumbs(id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Search for direct parent category
-	for !rootReached {
-		parentCategory, parentCategoryErr := c.GetCategory(childCategory.ParentID)
-		if parentCategoryErr != nil {
-			return nil, parentCategoryErr
-		}
-
-		relatedCategories = append(relatedCategories, *parentCategory)
-
-		if parentCategory.ParentID == 0 {
-			rootReached = true
-			return relatedCategories, nil
-		}
-
-		parentCategory = childCategory
-	} 
+	relatedCategories := make([]category_entity.Category, 0, len(breadcrumbs))
+	for i := len(breadcrumbs) - 1; i >= 0; i-- {
+		relatedCategories = append(relatedCategories, breadcrumbs[i])
+	}
 
 	return relatedCategories, nil
 }
@@ -146,7 +153,14 @@ func (c *CategoryRepo) GetParentCategories(id int64) ([]category_entity.Category
 func (c *CategoryRepo) UpdateCategory(category *category_entity.Category) (*category_entity.Category, error) {
 	cacheRepo := cache.NewCacheRepository("Redis", c.p)
 
-	err := c.p.DB.Debug().Where("id = ?", category.ID).Updates(&category).Error
+	stored, err := c.getCategoryFromDB(int64(category.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	// The position in the tree is only changed through MoveCategory so descendants stay consistent
+	// The points multiplier is only changed through SetPointsMultiplier, where it can be set to 0
+	err = c.p.DB.Debug().Omit("ParentID", "Path", "Depth", "PointsMultiplier", "ParentCategories").Where("id = ?", category.ID).Updates(&category).Error
 	if err != nil {
 		return nil, err
 	}
@@ -154,23 +168,38 @@ func (c *CategoryRepo) UpdateCategory(category *category_entity.Category) (*cate
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
+
+	if category.ParentID != stored.ParentID {
+		return c.MoveCategory(int64(category.ID), category.ParentID)
+	}
+
+	category.Path = stored.Path
+	category.Depth = stored.Depth
+	category.PointsMultiplier = stored.PointsMultiplier
 	_ = cacheRepo.SetKey(fmt.Sprintf("%v_CATEGORIES", category.ID), category, time.Minute * 15)
 
 
 	return category, nil
 }
 
-func (c *CategoryRepo) DeleteCategory(id int64) error {
-	var category category_entity.Category
+// SetPointsMultiplier changes how fast the category's products earn loyalty points
+func (c *CategoryRepo) SetPointsMultiplier(id int64, multiplier float64) (*category_entity.Category, error) {
+	result := c.p.DB.Debug().Model(&category_entity.Category{}).Where("id = ?", id).Update("points_multiplier", multiplier)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("category %v not found", id)
+	}
 
-	err := c.p.DB.Debug().Where("id = ?", id).Delete(&category).Error
-	
 	cacheRepo := cache.NewCacheRepository("Redis", c.p)
-
 	cacheRepo.DelKey(fmt.Sprintf("%v_CATEGORIES", id))
-	if err != nil {
-		return errors.New("database error, please try again")
-	}
 
-	return nil
+	return c.getCategoryFromDB(id)
+}
+
+// DeleteCategory only deletes categories that have no child categories or products
+func (c *CategoryRepo) DeleteCategory(id int64) error {
+	_, err := c.DeleteCategoryWithPolicy(id, category_entity.DeletePolicyBlock, false)
+	return err
 }
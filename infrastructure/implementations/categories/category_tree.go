@@ -0,0 +1,324 @@
+package categories
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/category_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
+	"gorm.io/gorm"
+)
+
+// Categories keep a materialized path of their ancestor IDs, e.g. "/1/4/9/", so a subtree is a
+// single prefix match and the ancestors of a node can be read straight from its path
+
+func categoryPath(parentPath string, id uint64) string {
+	if parentPath == "" {
+		parentPath = "/"
+	}
+	return fmt.Sprintf("%s%d/", parentPath, id)
+}
+
+func pathIDs(path string) []int64 {
+	var ids []int64
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (c *CategoryRepo) getCategoryFromDB(id int64) (*category_entity.Category, error) {
+	var category *category_entity.Category
+	err := c.p.DB.Debug().Where("id = ?", id).Take(&category).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("category %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if category.Path == "" {
+		return nil, errors.New("category paths are missing, rebuild the category tree")
+	}
+
+	return category, nil
+}
+
+func (c *CategoryRepo) nextPosition(parentID int64) int {
+	var maxPosition *int
+	c.p.DB.Debug().Model(&category_entity.Category{}).Where("parent_id = ?", parentID).Select("MAX(position)").Scan(&maxPosition)
+	if maxPosition == nil {
+		return 0
+	}
+	return *maxPosition + 1
+}
+
+func (c *CategoryRepo) clearCategoryCache(ids []int64) {
+	cacheRepo := cache.NewCacheRepository("Redis", c.p)
+	for _, id := range ids {
+		cacheRepo.DelKey(fmt.Sprintf("%v_CATEGORIES", id))
+	}
+}
+
+// GetCategoryTree returns the whole tree when rootID is 0, otherwise the subtree under rootID
+func (c *CategoryRepo) GetCategoryTree(rootID int64) ([]*category_entity.CategoryNode, error) {
+	var categories []category_entity.Category
+	query := c.p.DB.Debug().Order("depth asc, position asc, id asc")
+
+	if rootID != 0 {
+		root, err := c.getCategoryFromDB(rootID)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("path LIKE ?", root.Path+"%")
+	}
+
+	err := query.Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[uint64]*category_entity.CategoryNode, len(categories))
+	var roots []*category_entity.CategoryNode
+	for _, category := range categories {
+		node := &category_entity.CategoryNode{Category: category, Children: []*category_entity.CategoryNode{}}
+		nodes[category.ID] = node
+
+		parent, ok := nodes[uint64(category.ParentID)]
+		if ok && category.ID != uint64(rootID) {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	return roots, nil
+}
+
+// GetBreadcrumbs returns the ancestors of a category from the root down to the category itself
+func (c *CategoryRepo) GetBreadcrumbs(id int64) ([]category_entity.Category, error) {
+	category, err := c.getCategoryFromDB(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var breadcrumbs []category_entity.Category
+	err = c.p.DB.Debug().Where("id IN ?", pathIDs(category.Path)).Order("depth asc").Find(&breadcrumbs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return breadcrumbs, nil
+}
+
+// MoveCategory re-parents a category and rewrites the path of its whole subtree in one statement
+func (c *CategoryRepo) MoveCategory(id int64, newParentID int64) (*category_entity.Category, error) {
+	category, err := c.getCategoryFromDB(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if newParentID == id {
+		return nil, errors.New("a category cannot be its own parent")
+	}
+
+	newParentPath := "/"
+	newDepth := 0
+	if newParentID != 0 {
+		parent, parentErr := c.getCategoryFromDB(newParentID)
+		if parentErr != nil {
+			return nil, parentErr
+		}
+
+		if strings.HasPrefix(parent.Path, category.Path) {
+			return nil, errors.New("a category cannot be moved under one of its descendants")
+		}
+
+		newParentPath = parent.Path
+		newDepth = parent.Depth + 1
+	}
+
+	oldPath := category.Path
+	newPath := categoryPath(newParentPath, category.ID)
+
+	var subtreeIDs []int64
+	err = c.p.DB.Debug().Unscoped().Model(&category_entity.Category{}).Where("path LIKE ?", oldPath+"%").Pluck("id", &subtreeIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.p.DB.Transaction(func(tx *gorm.DB) error {
+		// Soft-deleted descendants are moved too so a later restore lands in the right place
+		moveErr := tx.Debug().Unscoped().Model(&category_entity.Category{}).
+			Where("path LIKE ?", oldPath+"%").
+			UpdateColumns(map[string]interface{}{
+				"path":  gorm.Expr("CONCAT(?::text, SUBSTR(path, ?))", newPath, len(oldPath)+1),
+				"depth": gorm.Expr("depth + ?", newDepth-category.Depth),
+			}).Error
+		if moveErr != nil {
+			return moveErr
+		}
+
+		return tx.Debug().Model(&category_entity.Category{}).Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{
+				"parent_id": newParentID,
+				"position":  c.nextPosition(newParentID),
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.clearCategoryCache(subtreeIDs)
+
+	return c.getCategoryFromDB(id)
+}
+
+// ReorderCategories sets the order of the children of a parent, every child must be listed exactly once
+func (c *CategoryRepo) ReorderCategories(parentID int64, orderedIDs []int64) ([]category_entity.Category, error) {
+	var children []category_entity.Category
+	err := c.p.DB.Debug().Where("parent_id = ?", parentID).Find(&children).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orderedIDs) != len(children) {
+		return nil, fmt.Errorf("expected %v category ids, got %v", len(children), len(orderedIDs))
+	}
+
+	isChild := make(map[int64]bool, len(children))
+	for _, child := range children {
+		isChild[int64(child.ID)] = true
+	}
+
+	seen := make(map[int64]bool, len(orderedIDs))
+	for _, childID := range orderedIDs {
+		if !isChild[childID] {
+			return nil, fmt.Errorf("category %v is not a child of %v", childID, parentID)
+		}
+		if seen[childID] {
+			return nil, fmt.Errorf("category %v is listed more than once", childID)
+		}
+		seen[childID] = true
+	}
+
+	err = c.p.DB.Transaction(func(tx *gorm.DB) error {
+		for position, childID := range orderedIDs {
+			updateErr := tx.Debug().Model(&category_entity.Category{}).Where("id = ?", childID).UpdateColumn("position", position).Error
+			if updateErr != nil {
+				return updateErr
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.clearCategoryCache(orderedIDs)
+
+	var reordered []category_entity.Category
+	err = c.p.DB.Debug().Where("parent_id = ?", parentID).Order("position asc").Find(&reordered).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return reordered, nil
+}
+
+// GetProductsInCategoryTree lists the products of a category and of all of its descendants
+func (c *CategoryRepo) GetProductsInCategoryTree(id int64) ([]product_entity.Product, error) {
+	category, err := c.getCategoryFromDB(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []product_entity.Product
+	err = c.p.DB.Debug().
+		Preload("Category").
+		Preload("Images").
+		Preload("Inventories").
+		Joins("JOIN categories ON categories.id = products.category_id AND categories.deleted_at IS NULL").
+		Where("categories.path LIKE ?", category.Path+"%").
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// RebuildCategoryPaths recomputes path and depth for every category from the parent IDs
+func (c *CategoryRepo) RebuildCategoryPaths() error {
+	var categories []category_entity.Category
+	err := c.p.DB.Debug().Unscoped().Find(&categories).Error
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[uint64]*category_entity.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	paths := make(map[uint64]string, len(categories))
+	var resolve func(category *category_entity.Category, visiting map[uint64]bool) (string, error)
+	resolve = func(category *category_entity.Category, visiting map[uint64]bool) (string, error) {
+		if path, ok := paths[category.ID]; ok {
+			return path, nil
+		}
+		if visiting[category.ID] {
+			return "", fmt.Errorf("category %v is part of a cycle", category.ID)
+		}
+		visiting[category.ID] = true
+
+		parentPath := "/"
+		if parent, ok := byID[uint64(category.ParentID)]; ok && category.ParentID != 0 {
+			var err error
+			parentPath, err = resolve(parent, visiting)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		paths[category.ID] = categoryPath(parentPath, category.ID)
+		return paths[category.ID], nil
+	}
+
+	var changedIDs []int64
+	err = c.p.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range categories {
+			category := &categories[i]
+			path, resolveErr := resolve(category, map[uint64]bool{})
+			if resolveErr != nil {
+				return resolveErr
+			}
+
+			depth := len(pathIDs(path)) - 1
+			if category.Path == path && category.Depth == depth {
+				continue
+			}
+
+			updateErr := tx.Debug().Unscoped().Model(&category_entity.Category{}).Where("id = ?", category.ID).
+				UpdateColumns(map[string]interface{}{"path": path, "depth": depth}).Error
+			if updateErr != nil {
+				return updateErr
+			}
+			changedIDs = append(changedIDs, int64(category.ID))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.clearCategoryCache(changedIDs)
+
+	return nil
+}
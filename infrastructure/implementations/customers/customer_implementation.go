@@ -29,6 +29,9 @@ func (c *CustomerRepo) SaveCustomer(customer *customer_entity.Customer) (*custom
 	cacheRepo := cache.NewCacheRepository("Redis", c.p)
 
 	dbErr := map[string]string{}
+	customer.Username = customer_entity.NormalizeUsername(customer.Username)
+	customer.Email = customer_entity.NormalizeEmail(customer.Email)
+	customer.EmailVerifiedAt = nil
 	err := c.p.DB.Debug().Create(&customer).Error
 	if err != nil {
 		fmt.Println("Failed to create customer")
@@ -80,7 +83,22 @@ func (c *CustomerRepo) UpdateCustomer(customer *customer_entity.Customer) (*cust
 	cacheRepo := cache.NewCacheRepository("Redis", c.p)
 
 
-	err := c.p.DB.Debug().Where("id = ?", customer.ID).Updates(&customer).Error
+	// A changed email address has to be verified again
+	customer.Username = customer_entity.NormalizeUsername(customer.Username)
+	customer.Email = customer_entity.NormalizeEmail(customer.Email)
+	var current customer_entity.Customer
+	if err := c.p.DB.Debug().Select("email").Where("id = ?", customer.ID).Take(&current).Error; err == nil && current.Email != customer.Email {
+		if err := c.p.DB.Debug().Model(&customer_entity.Customer{}).Where("id = ?", customer.ID).UpdateColumn("email_verified_at", nil).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	err := c.p.DB.Debug().Where("id = ?", customer.ID).Omit("role", "email_verified_at", "password").Updates(&customer).Error
+	if err != nil {
+		return nil, err
+	}
+	cacheRepo.DelKey(fmt.Sprintf("%v_CUSTOMER", customer.ID))
+	customer, err = c.GetCustomer(int64(customer.ID))
 	if err != nil {
 		return nil, err
 	}
@@ -107,4 +125,83 @@ func (c *CustomerRepo) DeleteCustomer(id int64) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (c *CustomerRepo) UpdateCustomerRole(id int64, role string) (*customer_entity.Customer, error) {
+	result := c.p.DB.Debug().Model(&customer_entity.Customer{}).Where("id = ?", id).UpdateColumn("role", role)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("customer %v not found", id)
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", c.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_CUSTOMER", id))
+
+	return c.GetCustomer(id)
+}
+
+func (c *CustomerRepo) GetCustomerByEmail(email string) (*customer_entity.Customer, error) {
+	var customer customer_entity.Customer
+	err := c.p.DB.Debug().Where("email = ?", customer_entity.NormalizeEmail(email)).Take(&customer).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+// GetCustomerByUsername matches case insensitively, older accounts may still have mixed case usernames
+func (c *CustomerRepo) GetCustomerByUsername(username string) (*customer_entity.Customer, error) {
+	var customer customer_entity.Customer
+	err := c.p.DB.Debug().Where("LOWER(username) = ?", customer_entity.NormalizeUsername(username)).Take(&customer).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+// MarkEmailVerified sets the verification time, as long as the customer still has the email the token was sent to
+func (c *CustomerRepo) MarkEmailVerified(id int64, email string) (*customer_entity.Customer, error) {
+	result := c.p.DB.Debug().Model(&customer_entity.Customer{}).Where("id = ? AND email = ?", id, email).UpdateColumn("email_verified_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("the email address has changed since the link was sent")
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", c.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_CUSTOMER", id))
+
+	return c.GetCustomer(id)
+}
+
+// UpdatePassword stores an already hashed password, UpdateColumn skips the BeforeSave hook so it is not hashed twice
+func (c *CustomerRepo) UpdatePassword(id int64, hashedPassword string) error {
+	result := c.p.DB.Debug().Model(&customer_entity.Customer{}).Where("id = ?", id).UpdateColumn("password", hashedPassword)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("customer %v not found", id)
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", c.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_CUSTOMER", id))
+
+	return nil
+}
+
+// GetPasswordHash reads the stored password hash from the database, the cached customer does not carry it
+func (c *CustomerRepo) GetPasswordHash(id int64) (string, error) {
+	var customer customer_entity.Customer
+	err := c.p.DB.Debug().Select("password").Where("id = ?", id).Take(&customer).Error
+	if err != nil {
+		return "", err
+	}
+
+	return customer.Password, nil
+}
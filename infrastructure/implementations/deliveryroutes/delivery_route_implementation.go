@@ -0,0 +1,118 @@
+package deliveryroutes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/delivery_route_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+// To manage planned delivery routes in the database
+
+// Delivery Route Repository struct
+type DeliveryRouteRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewDeliveryRouteRepository(p *base.Persistence, c *gin.Context) *DeliveryRouteRepo {
+	return &DeliveryRouteRepo{p, c}
+}
+
+// To explicitly check that the DeliveryRouteRepo implements the repository.DeliveryRouteRepository interface
+var _ delivery_route_repository.DeliveryRouteRepository = &DeliveryRouteRepo{}
+
+// SaveDeliveryRoute creates the route together with its stops
+func (r *DeliveryRouteRepo) SaveDeliveryRoute(tx *gorm.DB, route *shipment_entity.DeliveryRoute) (*shipment_entity.DeliveryRoute, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	err := tx.Debug().Omit("Stops.Shipment").Create(&route).Error
+	if err != nil {
+		fmt.Println("Failed to create delivery route")
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return route, nil
+}
+
+// GetDeliveryRoute returns the route with its stops in visiting order and their shipments
+func (r *DeliveryRouteRepo) GetDeliveryRoute(id int64) (*shipment_entity.DeliveryRoute, error) {
+	var route shipment_entity.DeliveryRoute
+	err := r.p.DB.Debug().Preload("Stops", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence")
+	}).Preload("Stops.Shipment").Where("id = ?", id).Take(&route).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("route %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &route, nil
+}
+
+// GetDeliveryRoutes returns the routes matching the filter with their stops, newest first
+func (r *DeliveryRouteRepo) GetDeliveryRoutes(filter shipment_entity.DeliveryRouteFilter) ([]shipment_entity.DeliveryRoute, error) {
+	var routes []shipment_entity.DeliveryRoute
+	query := r.p.DB.Debug().Preload("Stops", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence")
+	}).Preload("Stops.Shipment")
+	if filter.WarehouseID > 0 {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.DriverID > 0 {
+		query = query.Where("driver_id = ?", filter.DriverID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	err := query.Order("created_at desc, run").Find(&routes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return routes, nil
+}
+
+// GetRoutedShipmentIDs returns the shipments of a warehouse that are already on a planned route
+func (r *DeliveryRouteRepo) GetRoutedShipmentIDs(warehouseId int64) (map[uint64]bool, error) {
+	var ids []uint64
+	err := r.p.DB.Debug().Model(&shipment_entity.RouteStop{}).
+		Joins("JOIN delivery_routes ON delivery_routes.id = route_stops.route_id AND delivery_routes.deleted_at IS NULL").
+		Where("delivery_routes.warehouse_id = ? AND delivery_routes.status = ?", warehouseId, shipment_entity.RouteStatusPlanned).
+		Pluck("route_stops.shipment_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	routed := map[uint64]bool{}
+	for _, id := range ids {
+		routed[id] = true
+	}
+	return routed, nil
+}
+
+// UpdateDeliveryRouteStatus moves the route to a new status only if it is still in the expected one
+func (r *DeliveryRouteRepo) UpdateDeliveryRouteStatus(tx *gorm.DB, id int64, fromStatus string, toStatus string) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	result := tx.Debug().Model(&shipment_entity.DeliveryRoute{}).Where("id = ? AND status = ?", id, fromStatus).Update("status", toStatus)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("route %v is not %v", id, fromStatus)
+	}
+
+	return nil
+}
@@ -3,12 +3,10 @@ package inventories
 import (
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/inventory_repository"
-	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 	"gorm.io/gorm"
 )
@@ -29,9 +27,6 @@ func NewInventoryRepository(p *base.Persistence, c *gin.Context) *InventoryRepo
 var _ inventory_repository.InventoryRepository = &InventoryRepo{}
 
 func (r *InventoryRepo) SaveInventory(inventory *inventory_entity.Inventory) (*inventory_entity.Inventory, map[string]string) {
-
-	cacheRepo := cache.NewCacheRepository("Redis", r.p)
-
 	dbErr := map[string]string{}
 	err := r.p.DB.Debug().Create(&inventory).Error
 
@@ -55,28 +50,22 @@ func (r *InventoryRepo) SaveInventory(inventory *inventory_entity.Inventory) (*i
 		return nil, dbErr
 	}
 
-
-	cacheRepo.SetKey(fmt.Sprintf("%v_INVENTORY", inventory.ProductID), inventory, time.Minute * 15)
-	
 	return inventory, nil
 }
 
-
-func (r *InventoryRepo) GetInventory(productID int64) (*inventory_entity.Inventory, error) {
+// GetInventory returns the stock a warehouse holds of a product, nil when the warehouse does not stock it.
+// Stock is read from the database as orders change it all the time
+func (r *InventoryRepo) GetInventory(productID int64, warehouseID uint64) (*inventory_entity.Inventory, error) {
 	var inventory *inventory_entity.Inventory
 
-	cacheRepo := cache.NewCacheRepository("Redis", r.p)
-	_ = cacheRepo.GetKey(fmt.Sprintf("%v_INVENTORY", productID), &inventory)
-	if inventory == nil {
-		err := r.p.DB.Debug().Where("product_id = ?", productID).Take(&inventory).Error
-		if err != nil {
-			fmt.Println("Failed to get Inventory")
-		}
-		// if inventory != nil && inventory.ProductID > 0 {
-		// 	_ = cacheRepo.SetKey(fmt.Sprintf("%v_INVENTORY", productID), inventory, time.Minute * 15)
-		// }
+	err := r.p.DB.Debug().Where("product_id = ? AND warehouse_id = ?", productID, warehouseID).Take(&inventory).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		fmt.Println("Failed to get Inventory")
+		return nil, err
 	}
-
 
 	return inventory, nil
 }
@@ -95,20 +84,27 @@ func (r *InventoryRepo) GetAllInventoryInWarehouse(warehouseID int64) ([]invento
 }
 
 
-func (r *InventoryRepo) UpdateInventory(inventory *inventory_entity.Inventory) (*inventory_entity.Inventory, error) {
-	cacheRepo := cache.NewCacheRepository("Redis", r.p)
+// GetInventoriesForProducts returns the stock rows of the given products in every warehouse
+func (r *InventoryRepo) GetInventoriesForProducts(productIds []int64) ([]inventory_entity.Inventory, error) {
+	var inventory []inventory_entity.Inventory
+	if len(productIds) == 0 {
+		return inventory, nil
+	}
 
-	err := r.p.DB.Debug().Where("product_id = ?", inventory.ProductID).Updates(&inventory).Error
+	err := r.p.DB.Debug().Where("product_id IN ?", productIds).Find(&inventory).Error
 	if err != nil {
 		return nil, err
 	}
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
+	return inventory, nil
+}
+
+func (r *InventoryRepo) UpdateInventory(inventory *inventory_entity.Inventory) (*inventory_entity.Inventory, error) {
+	err := r.p.DB.Debug().Where("product_id = ? AND warehouse_id = ?", inventory.ProductID, inventory.WarehouseID).Updates(&inventory).Error
+	if err != nil {
 		return nil, err
 	}
 
-	_ = cacheRepo.SetKey(fmt.Sprintf("%v_INVENTORY", inventory.ProductID), inventory, time.Minute * 15)
-
 	return inventory, nil
 }
 
@@ -116,15 +112,6 @@ func (r *InventoryRepo) DeleteInventory(id int64) error {
 	var inventory inventory_entity.Inventory	
 
 	err := r.p.DB.Debug().Where("product_id = ?", id).Delete(&inventory).Error
-	if err != nil {
-		return err
-	}
-
-	cacheRepo := cache.NewCacheRepository("Redis", r.p)
-	
-
-
-	cacheRepo.DelKey(fmt.Sprintf("%v_INVENTORY", id))
 	if err != nil {
 		return errors.New("database error, please try again")
 	}
@@ -132,14 +119,28 @@ func (r *InventoryRepo) DeleteInventory(id int64) error {
 	return nil
 }
 
-func (r *InventoryRepo) ReduceInventory(tx *gorm.DB, id int64, quantityOrdered int64) error {
+// ReduceInventory takes the ordered quantity off the stock the warehouse holds of the product
+func (r *InventoryRepo) ReduceInventory(tx *gorm.DB, id int64, quantityOrdered int64, warehouseId uint64) error {
+	return r.reduceInventory(tx, id, quantityOrdered, 0, warehouseId, "Product ordered - Reduce inventory")
+}
+
+// ReduceInventoryForBundle reduces the stock of a bundle component and records the bundle in the log
+func (r *InventoryRepo) ReduceInventoryForBundle(tx *gorm.DB, componentId int64, quantityOrdered int64, bundleId uint64, warehouseId uint64) error {
+	return r.reduceInventory(tx, componentId, quantityOrdered, bundleId, warehouseId, fmt.Sprintf("Bundle %v ordered - Reduce component inventory", bundleId))
+}
+
+func (r *InventoryRepo) reduceInventory(tx *gorm.DB, id int64, quantityOrdered int64, bundleId uint64, warehouseId uint64, reason string) error {
 	span := r.p.Logger.Start(r.c, "implementations/ReduceInventory")
 	defer span.End()
-	inventory, invErr := r.GetInventory(id)
+	inventory, invErr := r.GetInventory(id, warehouseId)
 	if invErr != nil {
 		return invErr
 	}
 
+	if inventory == nil {
+		return fmt.Errorf("product %v is not stocked at warehouse %v", id, warehouseId)
+	}
+
 
 	if tx == nil {
 		var errTx error
@@ -167,14 +168,15 @@ func (r *InventoryRepo) ReduceInventory(tx *gorm.DB, id int64, quantityOrdered i
 		r.p.Logger.Error("implementations/ReduceInventory", map[string]interface{}{"error": fmt.Sprintf("not enough stock. Maximum quantity is %v", inventory.Stock)})
 		return fmt.Errorf("not enough stock. Maximum quantity is %v", inventory.Stock)
 	}
-	result := tx.Model(&inventory).
+	result := tx.Model(&inventory_entity.Inventory{}).Where("product_id = ? AND warehouse_id = ? AND stock >= ?", id, warehouseId, quantityOrdered).
 		Update("stock", gorm.Expr("stock - ?", quantityOrdered))
 	
 	logInventory := &inventory_entity.InventoryLog{
 		ProductID:     inventory.ProductID,
 		WarehouseID:   inventory.WarehouseID,
 		StockChange:   -int(quantityOrdered),
-		Reason:        "Product ordered - Reduce inventory",
+		Reason:        reason,
+		BundleID:      bundleId,
 	}
 
 	logResultErr := tx.Create(&logInventory).Error
@@ -186,9 +188,9 @@ func (r *InventoryRepo) ReduceInventory(tx *gorm.DB, id int64, quantityOrdered i
 		return result.Error
 	}
 
-	// Check if any rows were affected
+	// Check if any rows were affected, the stock may have been taken since it was read
 	if result.RowsAffected == 0 {
-		return errors.New("inventory not found")
+		return fmt.Errorf("not enough stock of product %v at warehouse %v", id, warehouseId)
 	}
 
 	// Check if the stock is negative after reduction
@@ -201,9 +203,9 @@ func (r *InventoryRepo) ReduceInventory(tx *gorm.DB, id int64, quantityOrdered i
 	return nil
 }
 
-func (r *InventoryRepo) IncreaseInventory(productId int64, quantityToAdd int64) error {
+func (r *InventoryRepo) IncreaseInventory(productId int64, warehouseId uint64, quantityToAdd int64) error {
     // Update inventory stock directly in the database
-    result := r.p.DB.Model(&inventory_entity.Inventory{}).Where("product_id = ?", productId).
+    result := r.p.DB.Model(&inventory_entity.Inventory{}).Where("product_id = ? AND warehouse_id = ?", productId, warehouseId).
         Update("stock", gorm.Expr("stock + ?", quantityToAdd))
     if result.Error != nil {
         return result.Error
@@ -217,3 +219,96 @@ func (r *InventoryRepo) IncreaseInventory(productId int64, quantityToAdd int64)
     return nil
 }
 
+
+// IncreaseInventoryForBundle restocks a bundle component and records the bundle in the log
+func (r *InventoryRepo) IncreaseInventoryForBundle(componentId int64, warehouseId uint64, quantityToAdd int64, bundleId uint64) error {
+	inventory, invErr := r.GetInventory(componentId, warehouseId)
+	if invErr != nil {
+		return invErr
+	}
+
+	if inventory == nil {
+		return errors.New("inventory not found")
+	}
+
+	increaseErr := r.IncreaseInventory(componentId, warehouseId, quantityToAdd)
+	if increaseErr != nil {
+		return increaseErr
+	}
+
+	logInventory := &inventory_entity.InventoryLog{
+		ProductID:     inventory.ProductID,
+		WarehouseID:   inventory.WarehouseID,
+		StockChange:   int(quantityToAdd),
+		Reason:        fmt.Sprintf("Bundle %v reversed - Increase component inventory", bundleId),
+		BundleID:      bundleId,
+	}
+
+	return r.p.DB.Create(&logInventory).Error
+}
+
+// ReturnInventory puts stock taken by an order back on the shelf of the warehouse it shipped from and logs why
+func (r *InventoryRepo) ReturnInventory(tx *gorm.DB, productId int64, quantity int64, bundleId uint64, warehouseId uint64, reason string) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	inventory, _ := r.GetInventory(productId, warehouseId)
+	if inventory == nil || inventory.ProductID == 0 {
+		return fmt.Errorf("inventory for product %v at warehouse %v not found", productId, warehouseId)
+	}
+
+	result := tx.Model(&inventory_entity.Inventory{}).Where("product_id = ? AND warehouse_id = ?", productId, warehouseId).
+		Update("stock", gorm.Expr("stock + ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	logInventory := &inventory_entity.InventoryLog{
+		ProductID:   inventory.ProductID,
+		WarehouseID: inventory.WarehouseID,
+		StockChange: int(quantity),
+		Reason:      reason,
+		BundleID:    bundleId,
+	}
+
+	return tx.Create(&logInventory).Error
+}
+
+// ReceiveInventory adds newly arrived stock that is put away straight into a bin of the warehouse
+func (r *InventoryRepo) ReceiveInventory(tx *gorm.DB, productId int64, quantity int64, warehouseId uint64, binId uint64) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	inventory, _ := r.GetInventory(productId, warehouseId)
+	if inventory == nil || inventory.ProductID == 0 {
+		return fmt.Errorf("inventory for product %v at warehouse %v not found", productId, warehouseId)
+	}
+
+	result := tx.Model(&inventory_entity.Inventory{}).Where("product_id = ? AND warehouse_id = ?", productId, warehouseId).
+		Update("stock", gorm.Expr("stock + ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	logInventory := &inventory_entity.InventoryLog{
+		ProductID:   inventory.ProductID,
+		WarehouseID: inventory.WarehouseID,
+		StockChange: int(quantity),
+		Reason:      "Stock received - Put away",
+		Quantity:    int(quantity),
+		ToBinID:     binId,
+	}
+
+	return tx.Create(&logInventory).Error
+}
+
+// LogInventory records a change that is not made through the other inventory methods, such as a bin move
+func (r *InventoryRepo) LogInventory(tx *gorm.DB, logInventory *inventory_entity.InventoryLog) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	return tx.Create(&logInventory).Error
+}
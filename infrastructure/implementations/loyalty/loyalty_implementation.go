@@ -0,0 +1,206 @@
+package loyalty
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/loyalty_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/loyalty_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// To manage loyalty accounts and their points ledger in the database
+
+// Loyalty Repository struct
+type LoyaltyRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewLoyaltyRepository(p *base.Persistence, c *gin.Context) *LoyaltyRepo {
+	return &LoyaltyRepo{p, c}
+}
+
+// To explicitly check that the LoyaltyRepo implements the repository.LoyaltyRepository interface
+var _ loyalty_repository.LoyaltyRepository = &LoyaltyRepo{}
+
+// lotColumns are the columns a lot update writes
+var lotColumns = []string{"remaining", "vested", "expires_at"}
+
+// GetOrCreateAccount returns the loyalty account of the customer, opening an empty one the first time
+func (r *LoyaltyRepo) GetOrCreateAccount(tx *gorm.DB, customerId int64) (*loyalty_entity.LoyaltyAccount, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	// Two requests opening the same account end up with the one that was created first
+	err := tx.Debug().Clauses(clause.OnConflict{DoNothing: true}).Create(&loyalty_entity.LoyaltyAccount{CustomerID: customerId}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var account loyalty_entity.LoyaltyAccount
+	err = tx.Debug().Where("customer_id = ?", customerId).Take(&account).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// PostEntry moves the account's balances and appends the entry. A change that would take either
+// balance below zero does not go through, so concurrent redemptions cannot spend the same points
+func (r *LoyaltyRepo) PostEntry(tx *gorm.DB, pointsEntry *loyalty_entity.PointsEntry, availableChange int64, pendingChange int64) error {
+	if tx == nil {
+		return r.p.DB.Transaction(func(tx *gorm.DB) error {
+			return r.PostEntry(tx, pointsEntry, availableChange, pendingChange)
+		})
+	}
+
+	query := tx.Debug().Model(&loyalty_entity.LoyaltyAccount{}).Where("id = ?", pointsEntry.AccountID)
+	if availableChange < 0 {
+		query = query.Where("available >= ?", -availableChange)
+	}
+	if pendingChange < 0 {
+		query = query.Where("pending >= ?", -pendingChange)
+	}
+	result := query.Updates(map[string]interface{}{
+		"available": gorm.Expr("available + ?", availableChange),
+		"pending":   gorm.Expr("pending + ?", pendingChange),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("loyalty account %v does not have enough points", pointsEntry.AccountID)
+	}
+
+	// The update holds the account's row until the end of the transaction, so the balances read are ours
+	var account loyalty_entity.LoyaltyAccount
+	if err := tx.Debug().Where("id = ?", pointsEntry.AccountID).Take(&account).Error; err != nil {
+		return err
+	}
+	pointsEntry.AvailableAfter = account.Available
+	pointsEntry.PendingAfter = account.Pending
+
+	return tx.Debug().Create(&pointsEntry).Error
+}
+
+// GetEntries returns a page of the account's statement, newest first
+func (r *LoyaltyRepo) GetEntries(accountId uint64, pagination *entity.Pagination) ([]loyalty_entity.PointsEntry, error) {
+	var total int64
+	err := r.p.DB.Debug().Model(&loyalty_entity.PointsEntry{}).Where("account_id = ?", accountId).Count(&total).Error
+	if err != nil {
+		return nil, err
+	}
+	pagination.SetTotal(total)
+
+	var entries []loyalty_entity.PointsEntry
+	err = r.p.DB.Debug().Where("account_id = ?", accountId).
+		Order("id desc").Offset(pagination.Offset()).Limit(pagination.PageSize).Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetOrderEntries returns the entries of a type recorded for an order
+func (r *LoyaltyRepo) GetOrderEntries(tx *gorm.DB, orderId int64, entryType string) ([]loyalty_entity.PointsEntry, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	var entries []loyalty_entity.PointsEntry
+	err := tx.Debug().Where("order_id = ? AND type = ?", orderId, entryType).Order("id").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetSpendableLots locks the account's vested lots with points left, the ones expiring first first
+func (r *LoyaltyRepo) GetSpendableLots(tx *gorm.DB, accountId uint64) ([]loyalty_entity.PointsEntry, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	var lots []loyalty_entity.PointsEntry
+	err := tx.Debug().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account_id = ? AND type IN ? AND vested = ? AND remaining > 0", accountId, []string{loyalty_entity.EntryEarn, loyalty_entity.EntryRestore}, true).
+		Order("expires_at, id").Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// GetLotsToVest returns earned lots whose return window closed before the time
+func (r *LoyaltyRepo) GetLotsToVest(before time.Time, limit int) ([]loyalty_entity.PointsEntry, error) {
+	var lots []loyalty_entity.PointsEntry
+	err := r.p.DB.Debug().Where("type = ? AND vested = ? AND vests_at <= ?", loyalty_entity.EntryEarn, false, before).
+		Order("vests_at, id").Limit(limit).Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// GetLotsToExpire returns vested lots with points left that expired before the time
+func (r *LoyaltyRepo) GetLotsToExpire(before time.Time, limit int) ([]loyalty_entity.PointsEntry, error) {
+	var lots []loyalty_entity.PointsEntry
+	err := r.p.DB.Debug().Where("type IN ? AND vested = ? AND remaining > 0 AND expires_at <= ?", []string{loyalty_entity.EntryEarn, loyalty_entity.EntryRestore}, true, before).
+		Order("expires_at, id").Limit(limit).Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// UpdateLot saves the lot only if it still has the expected points left and vesting,
+// so two steps cannot take the same points
+func (r *LoyaltyRepo) UpdateLot(tx *gorm.DB, lot *loyalty_entity.PointsEntry, fromRemaining int64, fromVested bool) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	result := tx.Debug().Model(&loyalty_entity.PointsEntry{}).Where("id = ? AND remaining = ? AND vested = ?", lot.ID, fromRemaining, fromVested).
+		Select(lotColumns).Updates(lot)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("points lot %v changed, try again", lot.ID)
+	}
+
+	return nil
+}
+
+// GetPointsMultipliers returns the points multiplier of the category of each product
+func (r *LoyaltyRepo) GetPointsMultipliers(productIds []int64) (map[int64]float64, error) {
+	var rows []struct {
+		ID               int64
+		PointsMultiplier float64
+	}
+	err := r.p.DB.Debug().Model(&product_entity.Product{}).Select("products.id, categories.points_multiplier").
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Where("products.id IN ?", productIds).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	multipliers := map[int64]float64{}
+	for _, row := range rows {
+		multipliers[row.ID] = row.PointsMultiplier
+	}
+	return multipliers, nil
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"os"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/mailer_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/mailer/file"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/mailer/smtp"
+)
+
+const (
+	SMTP = "SMTP"
+	File = "File"
+)
+
+// ConfiguredMailer reads the mailer type from MAILER, local setups write mails to files
+func ConfiguredMailer() string {
+	if os.Getenv("MAILER") == SMTP {
+		return SMTP
+	}
+	return File
+}
+
+// NewMailerRepository creates a new mailer repository based on the specified type
+func NewMailerRepository(repositoryType string) mailer_repository.MailerRepository {
+	switch repositoryType {
+	case SMTP:
+		return smtp.NewMailerRepository()
+	case File:
+		return file.NewMailerRepository()
+	default:
+		return file.NewMailerRepository()
+	}
+}
@@ -0,0 +1,41 @@
+package file
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/mailer_repository"
+)
+
+// fileRepo writes every mail to a file in MAIL_DIR instead of sending it, for local development
+type fileRepo struct {
+	dir string
+}
+
+func (f fileRepo) SendMail(to string, subject string, body string) error {
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := filepath.Join(f.dir, fmt.Sprintf("%v_%v.eml", time.Now().UnixNano(), filepath.Base(to)))
+	content := fmt.Sprintf("To: %v\r\nSubject: %v\r\nDate: %v\r\n\r\n%v\r\n", to, subject, time.Now().Format(time.RFC1123Z), body)
+
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		return err
+	}
+
+	log.Printf("mail to %v written to %v", to, name)
+	return nil
+}
+
+func NewMailerRepository() mailer_repository.MailerRepository {
+	dir := os.Getenv("MAIL_DIR")
+	if dir == "" {
+		dir = "mail"
+	}
+
+	return &fileRepo{dir: dir}
+}
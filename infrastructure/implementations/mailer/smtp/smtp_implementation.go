@@ -0,0 +1,58 @@
+package smtp
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/mailer_repository"
+)
+
+type smtpRepo struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func (s smtpRepo) SendMail(to string, subject string, body string) error {
+	if s.host == "" || s.from == "" {
+		return errors.New("SMTP NOT CONFIGURED")
+	}
+
+	message := strings.Join([]string{
+		fmt.Sprintf("From: %v", s.from),
+		fmt.Sprintf("To: %v", to),
+		fmt.Sprintf("Subject: %v", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		body,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	return smtp.SendMail(s.host+":"+s.port, auth, s.from, []string{to}, []byte(message))
+}
+
+// NewMailerRepository reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM
+func NewMailerRepository() mailer_repository.MailerRepository {
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	return &smtpRepo{
+		host:     os.Getenv("SMTP_HOST"),
+		port:     port,
+		username: os.Getenv("SMTP_USERNAME"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     os.Getenv("SMTP_FROM"),
+	}
+}
@@ -87,3 +87,27 @@ func (o *OrderedItemsRepo) GetAllOrderedItemsForOrder(orderId int64) ([]orderedi
 
 	return orderedItems, nil
 }
+
+// GetOrderedComponents returns the bundle components recorded on an ordered item when it was ordered
+func (o *OrderedItemsRepo) GetOrderedComponents(orderedItemId uint64) ([]ordereditem_entity.OrderedComponent, error) {
+	var components []ordereditem_entity.OrderedComponent
+
+	err := o.p.DB.Debug().Where("ordered_item_id = ?", orderedItemId).Find(&components).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
+
+// UpdateOrderedItemQuantity changes how many of the product the order takes and reprices the line
+func (o *OrderedItemsRepo) UpdateOrderedItemQuantity(tx *gorm.DB, id uint64, quantity int64) error {
+	if tx == nil {
+		tx = o.p.DB
+	}
+
+	return tx.Debug().Model(&ordereditem_entity.OrderedItem{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"quantity":    quantity,
+		"total_price": gorm.Expr("unit_price * ?", quantity),
+	}).Error
+}
@@ -6,11 +6,14 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/ordereditem_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/order_repository"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepo struct {
@@ -81,6 +84,21 @@ func (o *OrderRepo) GetOrder(id int64) (*order_entity.Order, error) {
 	return order, nil
 }
 
+// LockOrder reads the order inside tx, bypassing the cache, and locks it until tx ends
+func (o *OrderRepo) LockOrder(tx *gorm.DB, id int64) (*order_entity.Order, error) {
+	if tx == nil {
+		tx = o.p.DB
+	}
+
+	var order *order_entity.Order
+	err := tx.Debug().Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&order).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
 func (o *OrderRepo) GetAllOrders() ([]order_entity.Order, error) {
 	var orders []order_entity.Order
 	err := o.p.DB.Debug().Preload("OrderedItems").Find(&orders).Error
@@ -95,13 +113,42 @@ func (o *OrderRepo) GetAllOrders() ([]order_entity.Order, error) {
 	return orders, nil
 }
 
+func (o *OrderRepo) GetOrdersByCustomer(customerId int64) ([]order_entity.Order, error) {
+	var orders []order_entity.Order
+	err := o.p.DB.Debug().Preload("OrderedItems").Where("customer_id = ?", customerId).Order("created_at desc").Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// GetOrdersByCustomerPage returns one page of the customer's orders, newest first, and sets the total on the pagination
+func (o *OrderRepo) GetOrdersByCustomerPage(customerId int64, pagination *entity.Pagination) ([]order_entity.Order, error) {
+	var total int64
+	err := o.p.DB.Debug().Model(&order_entity.Order{}).Where("customer_id = ?", customerId).Count(&total).Error
+	if err != nil {
+		return nil, err
+	}
+	pagination.SetTotal(total)
+
+	var orders []order_entity.Order
+	err = o.p.DB.Debug().Preload("OrderedItems").Where("customer_id = ?", customerId).
+		Order("created_at desc").Offset(pagination.Offset()).Limit(pagination.PageSize).Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
 func (o *OrderRepo) UpdateOrder(order *order_entity.Order) (*order_entity.Order, error) {
 	span := o.p.Logger.Start(o.c, "implementations/UpdateOrder")
 	defer span.End()
 	cacheRepo := cache.NewCacheRepository("Redis", o.p)
 
 
-	err := o.p.DB.Debug().Where("id = ?", order.ID).Updates(&order).Error
+	err := o.p.DB.Debug().Where("id = ?", order.ID).Omit(append(order_entity.FixedColumns(), clause.Associations)...).Updates(&order).Error
 	if err != nil {
 		return nil, err
 	}
@@ -110,11 +157,175 @@ func (o *OrderRepo) UpdateOrder(order *order_entity.Order) (*order_entity.Order,
 		return nil, err
 	}
 
+	err = o.p.DB.Debug().Preload("OrderedItems").Where("id = ?", order.ID).Take(&order).Error
+	if err != nil {
+		return nil, err
+	}
+
 	_ = cacheRepo.SetKey(fmt.Sprintf("%v_ORDER", order.ID), order, time.Minute * 15)
 
 	return order, nil
 }
 
+// UpdateOrderStatus moves the order to a new status only if it is still in the expected one,
+// so two requests cannot both act on the same transition
+func (o *OrderRepo) UpdateOrderStatus(tx *gorm.DB, id int64, fromStatus string, toStatus string) error {
+	if tx == nil {
+		tx = o.p.DB
+	}
+
+	result := tx.Debug().Model(&order_entity.Order{}).Where("id = ? AND status = ?", id, fromStatus).Update("status", toStatus)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("order %v is not %v", id, fromStatus)
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", o.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_ORDER", id))
+
+	return nil
+}
+
+// GetOrdersToPick returns the oldest pending orders of a warehouse, earliest delivery slot first
+func (o *OrderRepo) GetOrdersToPick(warehouseId int64, limit int) ([]order_entity.Order, error) {
+	var orders []order_entity.Order
+	err := o.p.DB.Debug().Preload("OrderedItems").
+		Where("warehouse_id = ? AND status = ?", warehouseId, order_entity.OrderStatusPending).
+		Order("delivery_starts_at ASC NULLS LAST, created_at ASC").Limit(limit).Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// GetOrdersByIDs returns the orders with the given ids without their items
+func (o *OrderRepo) GetOrdersByIDs(ids []int64) ([]order_entity.Order, error) {
+	var orders []order_entity.Order
+	if len(ids) == 0 {
+		return orders, nil
+	}
+
+	err := o.p.DB.Debug().Where("id IN ?", ids).Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// RecalculateOrderTotals sums the order's lines again after their quantities changed
+func (o *OrderRepo) RecalculateOrderTotals(tx *gorm.DB, id int64) error {
+	if tx == nil {
+		tx = o.p.DB
+	}
+
+	totalCost := tx.Model(&ordereditem_entity.OrderedItem{}).Select("COALESCE(SUM(total_price), 0)").Where("order_id = ?", id)
+	err := tx.Debug().Model(&order_entity.Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"total_cost":     totalCost,
+		"total_checkout": gorm.Expr("(?) + total_fees - points_discount", totalCost),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", o.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_ORDER", id))
+
+	return nil
+}
+
+// SavePackingDetails records the packages of a picked order and moves it to packed
+func (o *OrderRepo) SavePackingDetails(id int64, packageCount int, weightKg float64) error {
+	result := o.p.DB.Debug().Model(&order_entity.Order{}).Where("id = ? AND status = ?", id, order_entity.OrderStatusPicked).Updates(map[string]interface{}{
+		"status":            order_entity.OrderStatusPacked,
+		"package_count":     packageCount,
+		"package_weight_kg": weightKg,
+		"packed_at":         time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("order %v is not %v", id, order_entity.OrderStatusPicked)
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", o.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_ORDER", id))
+
+	return nil
+}
+
+// SaveWalletAmount records what the customer's wallet paid for the order, which can only happen once
+func (o *OrderRepo) SaveWalletAmount(tx *gorm.DB, id int64, amount float64, status string) error {
+	if tx == nil {
+		tx = o.p.DB
+	}
+
+	result := tx.Debug().Model(&order_entity.Order{}).Where("id = ? AND wallet_amount = 0", id).Updates(map[string]interface{}{
+		"wallet_amount": amount,
+		"status":        status,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("order %v is already paid from the wallet", id)
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", o.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_ORDER", id))
+
+	return nil
+}
+
+// RefundWalletAmount lowers what the wallet paid for the order by the amount given back to it
+func (o *OrderRepo) RefundWalletAmount(tx *gorm.DB, id int64, amount float64) error {
+	if tx == nil {
+		tx = o.p.DB
+	}
+
+	result := tx.Debug().Model(&order_entity.Order{}).Where("id = ? AND wallet_amount >= ?", id, amount).
+		Update("wallet_amount", gorm.Expr("wallet_amount - ?", amount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("order %v was paid less than %v from the wallet", id, amount)
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", o.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_ORDER", id))
+
+	return nil
+}
+
+// SavePointsRedemption takes the discount of the redeemed points off the order's checkout, which can only happen once
+func (o *OrderRepo) SavePointsRedemption(tx *gorm.DB, id int64, points int64, discount float64) error {
+	if tx == nil {
+		tx = o.p.DB
+	}
+
+	result := tx.Debug().Model(&order_entity.Order{}).Where("id = ? AND points_redeemed = 0", id).Updates(map[string]interface{}{
+		"points_redeemed": points,
+		"points_discount": discount,
+		"total_checkout":  gorm.Expr("total_checkout - ?", discount),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("order %v already has points redeemed", id)
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", o.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_ORDER", id))
+
+	return nil
+}
+
 func (o *OrderRepo) DeleteOrder(id int64) error {
 	var order order_entity.Order
 
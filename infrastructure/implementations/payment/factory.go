@@ -0,0 +1,63 @@
+package payment
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/payment_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/payment/fake"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+const (
+	Fake = "Fake"
+)
+
+// Gateways are the values PAYMENT_GATEWAY accepts
+var Gateways = []string{Fake}
+
+// ConfiguredGateway reads the gateway type from PAYMENT_GATEWAY, there is no default so the fake
+// gateway only runs when it is asked for
+func ConfiguredGateway() string {
+	return os.Getenv("PAYMENT_GATEWAY")
+}
+
+// IsRealGateway reports whether the gateway moves real money, the fake gateway approves any token
+func IsRealGateway(gateway string) bool {
+	return isGateway(gateway) && gateway != Fake
+}
+
+// CheckConfiguration rejects a missing or unknown PAYMENT_GATEWAY and a missing PAYMENT_WEBHOOK_SECRET,
+// the server refuses to start rather than taking payments through the wrong gateway
+func CheckConfiguration() error {
+	gateway := ConfiguredGateway()
+	if !isGateway(gateway) {
+		return fmt.Errorf("PAYMENT_GATEWAY must be one of %v, got %q", Gateways, gateway)
+	}
+	if os.Getenv("PAYMENT_WEBHOOK_SECRET") == "" {
+		return fmt.Errorf("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+	return nil
+}
+
+// NewPaymentRepository creates a new payment repository based on the specified type
+func NewPaymentRepository(repositoryType string, p *base.Persistence, c *gin.Context) payment_repository.PaymentRepository {
+	switch repositoryType {
+	case Fake:
+		return fake.NewFakeRepository(p, c)
+	// Add cases for real gateways here
+	default:
+		// Unknown gateways never get this far, CheckConfiguration stops the server at startup
+		panic(fmt.Sprintf("unknown payment gateway %q", repositoryType))
+	}
+}
+
+func isGateway(gateway string) bool {
+	for _, known := range Gateways {
+		if gateway == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package fake
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/payment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/payment_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// Tokens with a known outcome, any other token is approved
+const (
+	TokenDeclined          = "tok_declined"
+	TokenInsufficientFunds = "tok_insufficient_funds"
+	TokenError             = "tok_gateway_error"
+)
+
+// fakeRepo is an in-process gateway for development. It keeps its payments in memory, so
+// payments authorized before a restart are unknown to it afterwards
+type fakeRepo struct {
+	p      *base.Persistence
+	c      *gin.Context
+	secret string
+}
+
+type fakePayment struct {
+	authorized float64
+	captured   float64
+	refunded   float64
+	voided     bool
+}
+
+var ledger = struct {
+	sync.Mutex
+	payments map[string]*fakePayment
+}{payments: map[string]*fakePayment{}}
+
+func NewFakeRepository(p *base.Persistence, c *gin.Context) payment_repository.PaymentRepository {
+	return &fakeRepo{p, c, os.Getenv("PAYMENT_WEBHOOK_SECRET")}
+}
+
+func (f *fakeRepo) Name() string {
+	return "Fake"
+}
+
+func (f *fakeRepo) Authorize(token string, amount float64, description string) (*payment_entity.GatewayResult, error) {
+	switch token {
+	case TokenError:
+		return nil, errors.New("fake gateway unavailable")
+	case TokenDeclined:
+		return &payment_entity.GatewayResult{Message: "card_declined"}, nil
+	case TokenInsufficientFunds:
+		return &payment_entity.GatewayResult{Message: "insufficient_funds"}, nil
+	}
+
+	reference, err := newReference()
+	if err != nil {
+		return nil, err
+	}
+
+	ledger.Lock()
+	defer ledger.Unlock()
+	ledger.payments[reference] = &fakePayment{authorized: amount}
+
+	return &payment_entity.GatewayResult{Reference: reference, Success: true, Message: description, Amount: amount}, nil
+}
+
+func (f *fakeRepo) Capture(reference string, amount float64) (*payment_entity.GatewayResult, error) {
+	ledger.Lock()
+	defer ledger.Unlock()
+	payment, err := lookup(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.voided || payment.captured > 0 {
+		return &payment_entity.GatewayResult{Reference: reference, Message: "already_settled"}, nil
+	}
+	if amount > payment.authorized {
+		return &payment_entity.GatewayResult{Reference: reference, Message: "amount_too_large"}, nil
+	}
+
+	payment.captured = amount
+	return &payment_entity.GatewayResult{Reference: reference, Success: true, Amount: amount}, nil
+}
+
+func (f *fakeRepo) Void(reference string) (*payment_entity.GatewayResult, error) {
+	ledger.Lock()
+	defer ledger.Unlock()
+	payment, err := lookup(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.voided || payment.captured > 0 {
+		return &payment_entity.GatewayResult{Reference: reference, Message: "already_settled"}, nil
+	}
+
+	payment.voided = true
+	return &payment_entity.GatewayResult{Reference: reference, Success: true, Amount: payment.authorized}, nil
+}
+
+func (f *fakeRepo) Refund(reference string, amount float64) (*payment_entity.GatewayResult, error) {
+	ledger.Lock()
+	defer ledger.Unlock()
+	payment, err := lookup(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount > payment.captured-payment.refunded {
+		return &payment_entity.GatewayResult{Reference: reference, Message: "amount_too_large"}, nil
+	}
+
+	payment.refunded += amount
+	return &payment_entity.GatewayResult{Reference: reference, Success: true, Amount: amount}, nil
+}
+
+// VerifyWebhook checks the payload is signed with the webhook secret, the signature being the
+// hex HMAC-SHA256 of the raw payload
+func (f *fakeRepo) VerifyWebhook(payload []byte, signature string) (*payment_entity.WebhookEvent, error) {
+	expected := Sign(payload, f.secret)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, errors.New("invalid webhook signature")
+	}
+
+	var event payment_entity.WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %v", err)
+	}
+	if event.ID == "" || event.Type == "" || event.Reference == "" {
+		return nil, errors.New("webhook payload needs an id, a type and a reference")
+	}
+
+	return &event, nil
+}
+
+// Sign returns the signature of a webhook payload, for sending webhooks to a local setup
+func Sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func lookup(reference string) (*fakePayment, error) {
+	payment, ok := ledger.payments[reference]
+	if !ok {
+		return nil, fmt.Errorf("payment %v is unknown to the fake gateway", reference)
+	}
+	return payment, nil
+}
+
+func newReference() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "fake_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,149 @@
+package fake
+
+import (
+	"testing"
+
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/payment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/payment_repository"
+)
+
+// step is one call to the gateway and what it should answer
+type step struct {
+	call        string
+	amount      float64
+	wantSuccess bool
+	wantMessage string
+}
+
+func authorize(t *testing.T, gateway payment_repository.PaymentRepository, amount float64) string {
+	t.Helper()
+	result, err := gateway.Authorize("tok_visa", amount, "order 1")
+	if err != nil || !result.Success {
+		t.Fatalf("Authorize = %+v, %v", result, err)
+	}
+	return result.Reference
+}
+
+func run(gateway payment_repository.PaymentRepository, reference string, s step) (*payment_entity.GatewayResult, error) {
+	switch s.call {
+	case "capture":
+		return gateway.Capture(reference, s.amount)
+	case "void":
+		return gateway.Void(reference)
+	}
+	return gateway.Refund(reference, s.amount)
+}
+
+func TestFakeGatewayStateMachine(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"void an authorization", []step{
+			{"void", 0, true, ""},
+			{"void", 0, false, "already_settled"},
+			{"capture", 50, false, "already_settled"},
+			{"refund", 10, false, "amount_too_large"},
+		}},
+		{"capture then refund in parts", []step{
+			{"capture", 80, true, ""},
+			{"capture", 80, false, "already_settled"},
+			{"void", 0, false, "already_settled"},
+			{"refund", 30, true, ""},
+			{"refund", 50, true, ""},
+			{"refund", 0.01, false, "amount_too_large"},
+		}},
+		{"refund more than was captured", []step{
+			{"capture", 40, true, ""},
+			{"refund", 40.01, false, "amount_too_large"},
+			{"refund", 40, true, ""},
+		}},
+		{"capture more than was authorized", []step{
+			{"capture", 100.01, false, "amount_too_large"},
+			{"capture", 100, true, ""},
+		}},
+		{"refund before capture", []step{
+			{"refund", 10, false, "amount_too_large"},
+			{"void", 0, true, ""},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := NewFakeRepository(nil, nil)
+			reference := authorize(t, gateway, 100)
+
+			for i, s := range tt.steps {
+				result, err := run(gateway, reference, s)
+				if err != nil {
+					t.Fatalf("step %v %v: %v", i, s.call, err)
+				}
+				if result.Success != s.wantSuccess || result.Message != s.wantMessage {
+					t.Errorf("step %v %v %v = %v %q, want %v %q", i, s.call, s.amount, result.Success, result.Message, s.wantSuccess, s.wantMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestFakeGatewayTokens(t *testing.T) {
+	gateway := NewFakeRepository(nil, nil)
+
+	tests := []struct {
+		token       string
+		wantErr     bool
+		wantSuccess bool
+		wantMessage string
+	}{
+		{TokenDeclined, false, false, "card_declined"},
+		{TokenInsufficientFunds, false, false, "insufficient_funds"},
+		{TokenError, true, false, ""},
+		{"tok_visa", false, true, "order 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			result, err := gateway.Authorize(tt.token, 10, "order 1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if result.Success != tt.wantSuccess || result.Message != tt.wantMessage {
+				t.Errorf("Authorize(%v) = %v %q, want %v %q", tt.token, result.Success, result.Message, tt.wantSuccess, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestFakeGatewayUnknownReference(t *testing.T) {
+	gateway := NewFakeRepository(nil, nil)
+	if _, err := gateway.Capture("fake_unknown", 10); err == nil {
+		t.Error("Capture of an unknown payment succeeded")
+	}
+	if _, err := gateway.Void("fake_unknown"); err == nil {
+		t.Error("Void of an unknown payment succeeded")
+	}
+	if _, err := gateway.Refund("fake_unknown", 10); err == nil {
+		t.Error("Refund of an unknown payment succeeded")
+	}
+}
+
+func TestFakeGatewayVerifyWebhook(t *testing.T) {
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "whsec_test")
+	gateway := NewFakeRepository(nil, nil)
+	payload := []byte(`{"id":"evt_1","type":"payment.captured","reference":"fake_1","amount":10}`)
+
+	event, err := gateway.VerifyWebhook(payload, Sign(payload, "whsec_test"))
+	if err != nil {
+		t.Fatalf("VerifyWebhook with a good signature: %v", err)
+	}
+	if event.ID != "evt_1" || event.Reference != "fake_1" {
+		t.Errorf("event = %+v", event)
+	}
+
+	if _, err := gateway.VerifyWebhook(payload, Sign(payload, "another_secret")); err == nil {
+		t.Error("VerifyWebhook accepted a payload signed with another secret")
+	}
+}
@@ -0,0 +1,128 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/payment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/payment_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// To manage payment records in the database
+
+// Payment Repository struct
+type PaymentRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewPaymentRepository(p *base.Persistence, c *gin.Context) *PaymentRepo {
+	return &PaymentRepo{p, c}
+}
+
+// To explicitly check that the PaymentRepo implements the repository.PaymentRecordRepository interface
+var _ payment_repository.PaymentRecordRepository = &PaymentRepo{}
+
+// paymentColumns are the columns a payment update writes
+var paymentColumns = []string{"status", "amount", "captured_amount", "refunded_amount", "captured_at", "voided_at"}
+
+// SavePayment creates the payment together with its first events
+func (r *PaymentRepo) SavePayment(tx *gorm.DB, payment *payment_entity.Payment) (*payment_entity.Payment, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	err := tx.Debug().Create(&payment).Error
+	if err != nil {
+		fmt.Println("Failed to create payment")
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// GetPayment returns the payment with its events, oldest first
+func (r *PaymentRepo) GetPayment(id int64) (*payment_entity.Payment, error) {
+	var payment payment_entity.Payment
+	err := r.p.DB.Debug().Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("recorded_at, id")
+	}).Where("id = ?", id).Take(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("payment %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// GetPaymentByReference returns the payment a gateway knows by the reference
+func (r *PaymentRepo) GetPaymentByReference(reference string) (*payment_entity.Payment, error) {
+	var payment payment_entity.Payment
+	err := r.p.DB.Debug().Where("reference = ?", reference).Take(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("payment %v not found", reference)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// GetPaymentsForOrder returns the payments of an order with their events, newest first
+func (r *PaymentRepo) GetPaymentsForOrder(orderId int64) ([]payment_entity.Payment, error) {
+	var payments []payment_entity.Payment
+	err := r.p.DB.Debug().Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("recorded_at, id")
+	}).Where("order_id = ?", orderId).Order("created_at desc").Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// UpdatePayment saves the payment only if it is still in the expected status,
+// so the same step cannot be taken twice
+func (r *PaymentRepo) UpdatePayment(tx *gorm.DB, payment *payment_entity.Payment, fromStatus string) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	result := tx.Debug().Model(&payment_entity.Payment{}).Where("id = ? AND status = ?", payment.ID, fromStatus).
+		Select(paymentColumns).Omit(clause.Associations).Updates(payment)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("payment %v is not %v", payment.ID, fromStatus)
+	}
+
+	return nil
+}
+
+func (r *PaymentRepo) SavePaymentEvent(tx *gorm.DB, event *payment_entity.PaymentEvent) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	return tx.Debug().Create(&event).Error
+}
+
+// HasGatewayEvent reports whether a webhook with the gateway's event id was already recorded
+func (r *PaymentRepo) HasGatewayEvent(eventId string) (bool, error) {
+	var count int64
+	err := r.p.DB.Debug().Model(&payment_entity.PaymentEvent{}).Where("gateway_event_id = ?", eventId).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,144 @@
+package picking
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/picking_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/picking_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// To manage pick lists in the database
+
+// Picking Repository struct
+type PickingRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewPickingRepository(p *base.Persistence, c *gin.Context) *PickingRepo {
+	return &PickingRepo{p, c}
+}
+
+// To explicitly check that the PickingRepo implements the repository.PickingRepository interface
+var _ picking_repository.PickingRepository = &PickingRepo{}
+
+// SavePickList creates the pick list together with its items
+func (r *PickingRepo) SavePickList(tx *gorm.DB, pickList *picking_entity.PickList) (*picking_entity.PickList, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	err := tx.Debug().Create(&pickList).Error
+	if err != nil {
+		fmt.Println("Failed to create pick list")
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return pickList, nil
+}
+
+// GetPickList returns the pick list with its items in the order they are walked
+func (r *PickingRepo) GetPickList(id int64) (*picking_entity.PickList, error) {
+	var pickList picking_entity.PickList
+	err := r.p.DB.Debug().Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC, location = '' ASC, location ASC, product_id ASC, order_id ASC")
+	}).Where("id = ?", id).Take(&pickList).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("pick list %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pickList, nil
+}
+
+// LockPickItems reads the items of a pick list in walk order and locks them until the transaction ends,
+// so confirmations on the same list run one after the other and each sees what the others recorded
+func (r *PickingRepo) LockPickItems(tx *gorm.DB, pickListId int64) ([]picking_entity.PickItem, error) {
+	var items []picking_entity.PickItem
+	err := tx.Debug().Clauses(clause.Locking{Strength: "UPDATE"}).Where("pick_list_id = ?", pickListId).
+		Order("sequence ASC, location = '' ASC, location ASC, product_id ASC, order_id ASC").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// GetPickLists returns the pick lists of a warehouse, newest first, optionally only those in a status
+func (r *PickingRepo) GetPickLists(warehouseId int64, status string) ([]picking_entity.PickList, error) {
+	var pickLists []picking_entity.PickList
+	query := r.p.DB.Debug().Where("warehouse_id = ?", warehouseId)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Order("created_at desc").Find(&pickLists).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return pickLists, nil
+}
+
+func (r *PickingRepo) GetPickItemsForOrder(tx *gorm.DB, orderId int64) ([]picking_entity.PickItem, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	var items []picking_entity.PickItem
+	err := tx.Debug().Where("order_id = ?", orderId).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// UpdatePickItem saves what was picked of the item only if it is still in fromStatus, so an item is confirmed once
+func (r *PickingRepo) UpdatePickItem(tx *gorm.DB, item *picking_entity.PickItem, fromStatus string) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	result := tx.Debug().Model(&picking_entity.PickItem{}).Where("id = ? AND status = ?", item.ID, fromStatus).
+		Select("picked_quantity", "removed_quantity", "status", "picked_by", "picked_at").Updates(item)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("pick item %v is not %v", item.ID, fromStatus)
+	}
+
+	return nil
+}
+
+// UpdatePickListStatus moves the pick list to a new status only if it is still in the expected one
+func (r *PickingRepo) UpdatePickListStatus(tx *gorm.DB, id int64, fromStatus string, toStatus string) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	updates := map[string]interface{}{"status": toStatus}
+	if toStatus == picking_entity.PickListStatusCompleted {
+		updates["completed_at"] = time.Now()
+	}
+
+	result := tx.Debug().Model(&picking_entity.PickList{}).Where("id = ? AND status = ?", id, fromStatus).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("pick list %v is not %v", id, fromStatus)
+	}
+
+	return nil
+}
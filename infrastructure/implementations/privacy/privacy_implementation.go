@@ -0,0 +1,243 @@
+package privacy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/loyalty_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/privacy_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/return_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/wallet_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/privacy_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+type PrivacyRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewPrivacyRepository(p *base.Persistence, c *gin.Context) *PrivacyRepo {
+	return &PrivacyRepo{p, c}
+}
+
+var _ privacy_repository.PrivacyRepository = &PrivacyRepo{}
+
+// getCustomer also finds customers that deleted their account, their data is still on record
+func getCustomer(db *gorm.DB, customerId int64) (*customer_entity.Customer, error) {
+	var customer customer_entity.Customer
+	err := db.Debug().Unscoped().Where("id = ?", customerId).Take(&customer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("customer %v not found", customerId)
+		}
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+// GetCustomerData collects every record tied to the customer, including soft-deleted ones
+func (r *PrivacyRepo) GetCustomerData(customerId int64) (*privacy_entity.CustomerDataExport, error) {
+	customer, err := getCustomer(r.p.DB, customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	export := privacy_entity.CustomerDataExport{
+		ExportedAt: time.Now(),
+		Customer:   customer.ToResponse(),
+	}
+
+	db := r.p.DB.Debug().Unscoped()
+	if err := db.Where("customer_id = ?", customerId).Order("id").Find(&export.Addresses).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Preload("OrderedItems").Where("customer_id = ?", customerId).Order("id").Find(&export.Orders).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("customer_id = ?", customerId).Order("id").Find(&export.RefreshTokens).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("customer_id = ?", customerId).Order("id").Find(&export.ActionTokens).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("customer_id = ? OR username = ?", customerId, customer_entity.NormalizeUsername(customer.Username)).
+		Order("id").Find(&export.AuditEvents).Error; err != nil {
+		return nil, err
+	}
+
+	var wallets []wallet_entity.Wallet
+	if err := db.Where("customer_id = ?", customerId).Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+	if len(wallets) > 0 {
+		export.Wallet = &wallets[0]
+		if err := db.Where("wallet_id = ?", wallets[0].ID).Order("id").Find(&export.WalletTransactions).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var accounts []loyalty_entity.LoyaltyAccount
+	if err := db.Where("customer_id = ?", customerId).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	if len(accounts) > 0 {
+		export.LoyaltyAccount = &accounts[0]
+		if err := db.Where("account_id = ?", accounts[0].ID).Order("id").Find(&export.PointsEntries).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Preload("Items").Where("customer_id = ?", customerId).Order("id").Find(&export.Returns).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Events").Where("customer_id = ?", customerId).Order("id").Find(&export.Payments).Error; err != nil {
+		return nil, err
+	}
+
+	// The driver is left out, their details are not the customer's
+	if err := db.Preload("Events").Where("order_id IN (?)", customerOrders(db, customerId)).Order("id").Find(&export.Shipments).Error; err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// customerOrders selects the ids of the customer's orders, for use as a subquery
+func customerOrders(db *gorm.DB, customerId int64) *gorm.DB {
+	return db.Model(&order_entity.Order{}).Select("id").Where("customer_id = ?", customerId)
+}
+
+// EraseCustomer anonymises the personal fields of the customer, their orders, returns and shipments and
+// deletes their addresses and tokens. The records in privacy_entity.RetainedRecords stay for accounting
+func (r *PrivacyRepo) EraseCustomer(customerId int64) (*privacy_entity.ErasureReport, error) {
+	report := privacy_entity.ErasureReport{CustomerID: customerId, ErasedAt: time.Now(), Retained: privacy_entity.RetainedRecords}
+
+	err := r.p.DB.Transaction(func(tx *gorm.DB) error {
+		customer, err := getCustomer(tx, customerId)
+		if err != nil {
+			return err
+		}
+		report.Username = customer_entity.NormalizeUsername(customer.Username)
+
+		// Orders still on their way need the delivery address and the customer's money to settle
+		var open int64
+		err = tx.Debug().Model(&order_entity.Order{}).Where("customer_id = ? AND status NOT IN ?", customerId, order_entity.ClosedStatuses).Count(&open).Error
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return fmt.Errorf("customer %v has %v orders not yet delivered, cancelled or returned, wait for them to close first", customerId, open)
+		}
+
+		db := tx.Debug().Unscoped()
+		if err := db.Model(&address_entity.Address{}).Where("customer_id = ?", customerId).Pluck("id", &report.AddressIDs).Error; err != nil {
+			return err
+		}
+		if err := db.Where("customer_id = ?", customerId).Delete(&address_entity.Address{}).Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(&order_entity.Order{}).Where("customer_id = ?", customerId).Pluck("id", &report.OrderIDs).Error; err != nil {
+			return err
+		}
+		err = db.Model(&order_entity.Order{}).Where("customer_id = ?", customerId).UpdateColumns(map[string]interface{}{
+			"address_id":            0,
+			"delivery_label":        "",
+			"delivery_contact_name": privacy_entity.ErasedName,
+			"delivery_phone":        "",
+			"delivery_address":      "",
+			"delivery_instructions": "",
+			"delivery_latitude":     0,
+			"delivery_longitude":    0,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := db.Model(&return_entity.ReturnRequest{}).Where("customer_id = ?", customerId).Pluck("id", &report.ReturnIDs).Error; err != nil {
+			return err
+		}
+		if err := db.Model(&return_entity.ReturnRequest{}).Where("customer_id = ?", customerId).UpdateColumn("note", "").Error; err != nil {
+			return err
+		}
+		if err := db.Model(&return_entity.ReturnItem{}).Where("return_id IN ?", report.ReturnIDs).UpdateColumn("comment", "").Error; err != nil {
+			return err
+		}
+
+		// Proof of delivery files are deleted once the transaction commits, the report has the shipments
+		if err := db.Model(&shipment_entity.Shipment{}).Where("order_id IN (?)", customerOrders(db, customerId)).Pluck("id", &report.ShipmentIDs).Error; err != nil {
+			return err
+		}
+		err = db.Model(&shipment_entity.Shipment{}).Where("id IN ?", report.ShipmentIDs).UpdateColumns(map[string]interface{}{
+			"recipient_name": "",
+			"signature_url":  "",
+			"photo_url":      "",
+			"last_latitude":  nil,
+			"last_longitude": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		err = db.Model(&shipment_entity.ShipmentEvent{}).Where("shipment_id IN ?", report.ShipmentIDs).UpdateColumns(map[string]interface{}{
+			"latitude":  nil,
+			"longitude": nil,
+			"note":      "",
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		result := db.Where("customer_id = ?", customerId).Delete(&auth_entity.RefreshToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		report.RefreshTokensDeleted = result.RowsAffected
+
+		result = db.Where("customer_id = ?", customerId).Delete(&auth_entity.ActionToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		report.ActionTokensDeleted = result.RowsAffected
+
+		// Audit events are kept but no longer point at a person
+		result = db.Model(&auth_entity.AuditEvent{}).Where("customer_id = ? OR username = ?", customerId, report.Username).
+			UpdateColumns(map[string]interface{}{"username": "", "ip": ""})
+		if result.Error != nil {
+			return result.Error
+		}
+		report.AuditEventsAnonymised = result.RowsAffected
+
+		// The password is not a bcrypt hash, so nobody can log in as the erased customer
+		err = db.Model(&customer_entity.Customer{}).Where("id = ?", customerId).UpdateColumns(map[string]interface{}{
+			"name":              privacy_entity.ErasedName,
+			"address":           "",
+			"latitude":          0,
+			"longitude":         0,
+			"username":          fmt.Sprintf("erased-%v", customerId),
+			"email":             "",
+			"email_verified_at": nil,
+			"password":          "erased",
+			"role":              auth_entity.RoleCustomer,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return db.Model(&customer_entity.Customer{}).Where("id = ? AND deleted_at IS NULL", customerId).UpdateColumn("deleted_at", report.ErasedAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/image_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/repository/product_repository"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
@@ -103,7 +105,8 @@ func (r *ProductRepo) GetProduct(id int64) (*product_entity.Product, error) {
         err := r.p.DB.Debug().
 		Preload("Category").
 		Preload("Images").
-		Preload("Inventory").
+		Preload("Inventories").
+		Preload("BundleItems").
 		Where("id = ?", id).Take(&product).Error
         if err != nil {
             fmt.Println("Failed to get product")
@@ -127,7 +130,8 @@ func (r *ProductRepo) GetAllProducts() ([]product_entity.Product, error) {
 	err := r.p.DB.Debug().
 	Preload("Category").
 	Preload("Images").
-	Preload("Inventory").
+	Preload("Inventories").
+	Preload("BundleItems").
 	Find(&products).Error
 
 	if err != nil {
@@ -172,11 +176,15 @@ func (r *ProductRepo) UpdateProduct(product *product_entity.Product) (*product_e
 }
 
 func (r *ProductRepo) DeleteProduct(id int64) error {
-	var product product_entity.Product
 	searchRepo := search.NewSearchRepository("Mongo", r.p, r.c)
 	collectionName := "products"
 	fieldName := "id"
-	err := r.p.DB.Debug().Where("id = ?", id).Delete(&product).Error
+	// Soft delete the product together with its inventory, images and bundle components using
+	// the same timestamp, so a restore from the recycle bin brings back exactly this set of rows
+	deletedAt := time.Now()
+	err := r.p.DB.Transaction(func(tx *gorm.DB) error {
+		return SoftDeleteProducts(tx, []int64{id}, deletedAt)
+	})
 	
 	searchErr := searchRepo.DeleteSingleDoc(fieldName, collectionName, id)
 	cacheRepo := cache.NewCacheRepository("Redis", r.p)
@@ -192,3 +200,28 @@ func (r *ProductRepo) DeleteProduct(id int64) error {
 
 	return nil
 }
+
+
+// SoftDeleteProducts marks products and their inventory, images and bundle components as deleted at the given time
+func SoftDeleteProducts(tx *gorm.DB, ids []int64, deletedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	deleteErr := tx.Debug().Model(&product_entity.Product{}).Where("id IN ?", ids).Update("deleted_at", deletedAt).Error
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	deleteErr = tx.Debug().Model(&inventory_entity.Inventory{}).Where("product_id IN ?", ids).Update("deleted_at", deletedAt).Error
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	deleteErr = tx.Debug().Model(&image_entity.Image{}).Where("product_id IN ?", ids).Update("deleted_at", deletedAt).Error
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	return tx.Debug().Model(&product_entity.BundleItem{}).Where("bundle_id IN ?", ids).Update("deleted_at", deletedAt).Error
+}
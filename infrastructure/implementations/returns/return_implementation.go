@@ -0,0 +1,118 @@
+package returns
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/return_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/return_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// To manage return requests in the database
+
+// Return Repository struct
+type ReturnRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewReturnRepository(p *base.Persistence, c *gin.Context) *ReturnRepo {
+	return &ReturnRepo{p, c}
+}
+
+// To explicitly check that the ReturnRepo implements the repository.ReturnRepository interface
+var _ return_repository.ReturnRepository = &ReturnRepo{}
+
+// returnColumns are the columns a return update writes, listed so that clearing a note is saved
+var returnColumns = []string{"status", "review_note", "items_amount", "discount_amount", "fees_amount", "refund_amount",
+	"reviewed_by", "reviewed_at", "inspected_by", "inspected_at"}
+
+// SaveReturn creates the return together with its items
+func (r *ReturnRepo) SaveReturn(ret *return_entity.ReturnRequest) (*return_entity.ReturnRequest, map[string]string) {
+	dbErr := map[string]string{}
+	err := r.p.DB.Debug().Create(&ret).Error
+	if err != nil {
+		fmt.Println("Failed to create return")
+		fmt.Println(err)
+		dbErr["db_error"] = "database error"
+		return nil, dbErr
+	}
+
+	return ret, nil
+}
+
+// GetReturn returns the return with its items
+func (r *ReturnRepo) GetReturn(id int64) (*return_entity.ReturnRequest, error) {
+	var ret return_entity.ReturnRequest
+	err := r.p.DB.Debug().Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id = ?", id).Take(&ret).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("return %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// GetReturns returns the returns matching the filter with their items, newest first
+func (r *ReturnRepo) GetReturns(filter return_entity.ReturnFilter) ([]return_entity.ReturnRequest, error) {
+	var rets []return_entity.ReturnRequest
+	query := r.p.DB.Debug().Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+	if filter.CustomerID > 0 {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+	if filter.OrderID > 0 {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if filter.WarehouseID > 0 {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	err := query.Order("created_at desc").Find(&rets).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rets, nil
+}
+
+// UpdateReturn saves the return only if it is still in the expected status,
+// so a return cannot be reviewed or inspected twice
+func (r *ReturnRepo) UpdateReturn(tx *gorm.DB, ret *return_entity.ReturnRequest, fromStatus string) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	result := tx.Debug().Model(&return_entity.ReturnRequest{}).Where("id = ? AND status = ?", ret.ID, fromStatus).
+		Select(returnColumns).Omit(clause.Associations).Updates(ret)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("return %v is not %v", ret.ID, fromStatus)
+	}
+
+	return nil
+}
+
+// UpdateReturnItem saves the outcome of the item's inspection
+func (r *ReturnRepo) UpdateReturnItem(tx *gorm.DB, item *return_entity.ReturnItem) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	return tx.Debug().Model(&return_entity.ReturnItem{}).Where("id = ?", item.ID).
+		Select("accepted_quantity", "disposition").Updates(item).Error
+}
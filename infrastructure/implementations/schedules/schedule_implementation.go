@@ -0,0 +1,202 @@
+package schedules
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/schedule_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// To manage warehouse opening hours, holidays and delivery slots in the database
+
+// Schedule Repository struct
+type ScheduleRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewScheduleRepository(p *base.Persistence, c *gin.Context) *ScheduleRepo {
+	return &ScheduleRepo{p, c}
+}
+
+// To explicitly check that the ScheduleRepo implements the repository.ScheduleRepository interface
+var _ schedule_repository.ScheduleRepository = &ScheduleRepo{}
+
+// SaveOperatingHours replaces the weekly hours of a warehouse
+func (r *ScheduleRepo) SaveOperatingHours(warehouseId uint64, hours []warehouse_entity.OperatingHours) ([]warehouse_entity.OperatingHours, error) {
+	savedHours := make([]warehouse_entity.OperatingHours, 0, len(hours))
+	err := r.p.DB.Transaction(func(tx *gorm.DB) error {
+		// Hard delete, a soft deleted day would still hold the unique weekday
+		err := tx.Debug().Unscoped().Where("warehouse_id = ?", warehouseId).Delete(&warehouse_entity.OperatingHours{}).Error
+		if err != nil {
+			return err
+		}
+
+		for _, day := range hours {
+			day.ID = 0
+			day.WarehouseID = warehouseId
+			if err := tx.Debug().Create(&day).Error; err != nil {
+				return err
+			}
+			savedHours = append(savedHours, day)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return savedHours, nil
+}
+
+func (r *ScheduleRepo) GetOperatingHours(warehouseId int64) ([]warehouse_entity.OperatingHours, error) {
+	var hours []warehouse_entity.OperatingHours
+	err := r.p.DB.Debug().Where("warehouse_id = ?", warehouseId).Order("weekday").Find(&hours).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return hours, nil
+}
+
+func (r *ScheduleRepo) SaveHoliday(holiday *warehouse_entity.Holiday) (*warehouse_entity.Holiday, map[string]string) {
+	dbErr := map[string]string{}
+	err := r.p.DB.Debug().Create(&holiday).Error
+	if err != nil {
+		fmt.Println("Failed to create holiday")
+		fmt.Println(err)
+		dbErr["db_error"] = "database error"
+		return nil, dbErr
+	}
+
+	return holiday, nil
+}
+
+func (r *ScheduleRepo) GetHoliday(id int64) (*warehouse_entity.Holiday, error) {
+	var holiday warehouse_entity.Holiday
+	err := r.p.DB.Debug().Where("id = ?", id).Take(&holiday).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("holiday %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &holiday, nil
+}
+
+func (r *ScheduleRepo) GetHolidays(warehouseId int64) ([]warehouse_entity.Holiday, error) {
+	var holidays []warehouse_entity.Holiday
+	err := r.p.DB.Debug().Where("warehouse_id = ?", warehouseId).Order("date").Find(&holidays).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return holidays, nil
+}
+
+func (r *ScheduleRepo) DeleteHoliday(id int64) error {
+	err := r.p.DB.Debug().Unscoped().Where("id = ?", id).Delete(&warehouse_entity.Holiday{}).Error
+	if err != nil {
+		return errors.New("database error, please try again")
+	}
+
+	return nil
+}
+
+// SaveDeliverySlots creates the slots, skipping any that start at the same time as an existing slot
+func (r *ScheduleRepo) SaveDeliverySlots(slots []warehouse_entity.DeliverySlot) ([]warehouse_entity.DeliverySlot, error) {
+	savedSlots := make([]warehouse_entity.DeliverySlot, 0, len(slots))
+	for _, slot := range slots {
+		result := r.p.DB.Debug().Clauses(clause.OnConflict{DoNothing: true}).Create(&slot)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			slot.SetAvailable()
+			savedSlots = append(savedSlots, slot)
+		}
+	}
+
+	return savedSlots, nil
+}
+
+func (r *ScheduleRepo) GetDeliverySlot(id int64) (*warehouse_entity.DeliverySlot, error) {
+	var slot warehouse_entity.DeliverySlot
+	err := r.p.DB.Debug().Where("id = ?", id).Take(&slot).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("delivery slot %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slot.SetAvailable()
+	return &slot, nil
+}
+
+// GetDeliverySlots returns the slots of a warehouse starting in [from, to)
+func (r *ScheduleRepo) GetDeliverySlots(warehouseId int64, from time.Time, to time.Time) ([]warehouse_entity.DeliverySlot, error) {
+	var slots []warehouse_entity.DeliverySlot
+	err := r.p.DB.Debug().Where("warehouse_id = ? AND starts_at >= ? AND starts_at < ?", warehouseId, from, to).
+		Order("starts_at").Find(&slots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range slots {
+		slots[i].SetAvailable()
+	}
+
+	return slots, nil
+}
+
+// DeleteDeliverySlot removes a slot nobody has booked
+func (r *ScheduleRepo) DeleteDeliverySlot(id int64) error {
+	result := r.p.DB.Debug().Unscoped().Where("id = ? AND reserved = 0", id).Delete(&warehouse_entity.DeliverySlot{})
+	if result.Error != nil {
+		return errors.New("database error, please try again")
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("delivery slot %v has reservations", id)
+	}
+
+	return nil
+}
+
+// ReserveDeliverySlot takes one place in a slot that has not started and is not full, the check
+// and the increment are one statement so two checkouts cannot take the last place
+func (r *ScheduleRepo) ReserveDeliverySlot(tx *gorm.DB, id int64) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	result := tx.Debug().Model(&warehouse_entity.DeliverySlot{}).
+		Where("id = ? AND reserved < capacity AND starts_at > ?", id, time.Now()).
+		Update("reserved", gorm.Expr("reserved + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("delivery slot %v is full or no longer available", id)
+	}
+
+	return nil
+}
+
+// ReleaseDeliverySlot gives back a place taken by an order
+func (r *ScheduleRepo) ReleaseDeliverySlot(tx *gorm.DB, id int64) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	return tx.Debug().Model(&warehouse_entity.DeliverySlot{}).
+		Where("id = ? AND reserved > 0", id).
+		Update("reserved", gorm.Expr("reserved - 1")).Error
+}
@@ -0,0 +1,112 @@
+package serviceareas
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/service_area_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+// To manage warehouse service areas in the database
+
+// Service Area Repository struct
+type ServiceAreaRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewServiceAreaRepository(p *base.Persistence, c *gin.Context) *ServiceAreaRepo {
+	return &ServiceAreaRepo{p, c}
+}
+
+// To explicitly check that the ServiceAreaRepo implements the repository.ServiceAreaRepository interface
+var _ service_area_repository.ServiceAreaRepository = &ServiceAreaRepo{}
+
+func (r *ServiceAreaRepo) SaveServiceArea(area *warehouse_entity.ServiceArea) (*warehouse_entity.ServiceArea, map[string]string) {
+	dbErr := map[string]string{}
+	err := r.p.DB.Debug().Create(&area).Error
+	if err != nil {
+		fmt.Println("Failed to create service area")
+		fmt.Println(err)
+		dbErr["db_error"] = "database error"
+		return nil, dbErr
+	}
+
+	r.clearWarehouseCache(area.WarehouseID)
+
+	return area, nil
+}
+
+func (r *ServiceAreaRepo) GetServiceArea(id int64) (*warehouse_entity.ServiceArea, error) {
+	var area warehouse_entity.ServiceArea
+	err := r.p.DB.Debug().Where("id = ?", id).Take(&area).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("service area %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &area, nil
+}
+
+// GetServiceAreas returns the service areas of a warehouse
+func (r *ServiceAreaRepo) GetServiceAreas(warehouseId int64) ([]warehouse_entity.ServiceArea, error) {
+	var areas []warehouse_entity.ServiceArea
+	err := r.p.DB.Debug().Where("warehouse_id = ?", warehouseId).Order("id").Find(&areas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return areas, nil
+}
+
+// GetActiveServiceAreas returns the active service areas of every warehouse
+func (r *ServiceAreaRepo) GetActiveServiceAreas() ([]warehouse_entity.ServiceArea, error) {
+	var areas []warehouse_entity.ServiceArea
+	err := r.p.DB.Debug().Where("active = ?", true).Order("warehouse_id, id").Find(&areas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return areas, nil
+}
+
+func (r *ServiceAreaRepo) UpdateServiceArea(area *warehouse_entity.ServiceArea) (*warehouse_entity.ServiceArea, error) {
+	// Select so that deactivating an area is saved
+	err := r.p.DB.Debug().Model(&area).Select("name", "area", "active").Updates(area).Error
+	if err != nil {
+		return nil, err
+	}
+
+	r.clearWarehouseCache(area.WarehouseID)
+
+	return area, nil
+}
+
+func (r *ServiceAreaRepo) DeleteServiceArea(id int64) error {
+	area, err := r.GetServiceArea(id)
+	if err != nil {
+		return err
+	}
+
+	err = r.p.DB.Debug().Where("id = ?", id).Delete(&warehouse_entity.ServiceArea{}).Error
+	if err != nil {
+		return errors.New("database error, please try again")
+	}
+
+	r.clearWarehouseCache(area.WarehouseID)
+
+	return nil
+}
+
+// clearWarehouseCache drops the cached warehouse, which carries its service areas
+func (r *ServiceAreaRepo) clearWarehouseCache(warehouseId uint64) {
+	cacheRepo := cache.NewCacheRepository("Redis", r.p)
+	cacheRepo.DelKey(fmt.Sprintf("%v_WAREHOUSE", warehouseId))
+}
@@ -0,0 +1,204 @@
+package shipments
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/shipment_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// To manage drivers and shipments in the database
+
+// Shipment Repository struct
+type ShipmentRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewShipmentRepository(p *base.Persistence, c *gin.Context) *ShipmentRepo {
+	return &ShipmentRepo{p, c}
+}
+
+// To explicitly check that the ShipmentRepo implements the repository.ShipmentRepository interface
+var _ shipment_repository.ShipmentRepository = &ShipmentRepo{}
+
+// shipmentColumns are the columns a shipment update writes, listed so that clearing a failure reason is saved
+var shipmentColumns = []string{"driver_id", "status", "attempts", "failure_reason", "last_latitude", "last_longitude",
+	"recipient_name", "signature_url", "photo_url", "assigned_at", "dispatched_at", "delivered_at", "returned_at"}
+
+func (r *ShipmentRepo) SaveDriver(driver *shipment_entity.Driver) (*shipment_entity.Driver, map[string]string) {
+	dbErr := map[string]string{}
+	err := r.p.DB.Debug().Create(&driver).Error
+	if err != nil {
+		fmt.Println("Failed to create driver")
+		fmt.Println(err)
+		dbErr["db_error"] = "database error"
+		return nil, dbErr
+	}
+
+	return driver, nil
+}
+
+func (r *ShipmentRepo) GetDriver(id int64) (*shipment_entity.Driver, error) {
+	var driver shipment_entity.Driver
+	err := r.p.DB.Debug().Where("id = ?", id).Take(&driver).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("driver %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &driver, nil
+}
+
+// GetDriverByCustomer returns the driver record of a customer account
+func (r *ShipmentRepo) GetDriverByCustomer(customerId int64) (*shipment_entity.Driver, error) {
+	var driver shipment_entity.Driver
+	err := r.p.DB.Debug().Where("customer_id = ?", customerId).Take(&driver).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("customer %v is not a driver", customerId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &driver, nil
+}
+
+// GetDrivers returns the drivers, only those of a warehouse when one is given
+func (r *ShipmentRepo) GetDrivers(warehouseId int64) ([]shipment_entity.Driver, error) {
+	var drivers []shipment_entity.Driver
+	query := r.p.DB.Debug()
+	if warehouseId > 0 {
+		query = query.Where("warehouse_id = ?", warehouseId)
+	}
+
+	err := query.Order("name").Find(&drivers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return drivers, nil
+}
+
+func (r *ShipmentRepo) UpdateDriver(driver *shipment_entity.Driver) (*shipment_entity.Driver, error) {
+	// Select so that deactivating a driver is saved
+	err := r.p.DB.Debug().Model(&driver).Select("name", "phone", "warehouse_id", "customer_id", "vehicle_capacity_kg", "vehicle_capacity_packages", "active").Updates(driver).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+func (r *ShipmentRepo) DeleteDriver(id int64) error {
+	err := r.p.DB.Debug().Where("id = ?", id).Delete(&shipment_entity.Driver{}).Error
+	if err != nil {
+		return errors.New("database error, please try again")
+	}
+
+	return nil
+}
+
+// SaveShipment creates the shipment together with its first events
+func (r *ShipmentRepo) SaveShipment(tx *gorm.DB, shipment *shipment_entity.Shipment) (*shipment_entity.Shipment, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	err := tx.Debug().Omit("Driver").Create(&shipment).Error
+	if err != nil {
+		fmt.Println("Failed to create shipment")
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+// GetShipment returns the shipment with its driver and events, oldest event first
+func (r *ShipmentRepo) GetShipment(id int64) (*shipment_entity.Shipment, error) {
+	var shipment shipment_entity.Shipment
+	err := r.p.DB.Debug().Preload("Driver").Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("recorded_at, id")
+	}).Where("id = ?", id).Take(&shipment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("shipment %v not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &shipment, nil
+}
+
+// GetShipmentForOrder returns the shipment of an order with its driver and events
+func (r *ShipmentRepo) GetShipmentForOrder(orderId int64) (*shipment_entity.Shipment, error) {
+	var shipment shipment_entity.Shipment
+	err := r.p.DB.Debug().Preload("Driver").Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("recorded_at, id")
+	}).Where("order_id = ?", orderId).Order("id desc").Take(&shipment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("order %v has no shipment", orderId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &shipment, nil
+}
+
+// GetShipments returns the shipments matching the filter with their drivers, newest first
+func (r *ShipmentRepo) GetShipments(filter shipment_entity.ShipmentFilter) ([]shipment_entity.Shipment, error) {
+	var shipments []shipment_entity.Shipment
+	query := r.p.DB.Debug().Preload("Driver")
+	if filter.WarehouseID > 0 {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.DriverID > 0 {
+		query = query.Where("driver_id = ?", filter.DriverID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	err := query.Order("created_at desc").Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return shipments, nil
+}
+
+// UpdateShipment saves the shipment only if it is still in the expected status,
+// so two updates cannot both act on the same step
+func (r *ShipmentRepo) UpdateShipment(tx *gorm.DB, shipment *shipment_entity.Shipment, fromStatus string) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	result := tx.Debug().Model(&shipment_entity.Shipment{}).Where("id = ? AND status = ?", shipment.ID, fromStatus).
+		Select(shipmentColumns).Omit(clause.Associations).Updates(shipment)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("shipment %v is not %v", shipment.ID, fromStatus)
+	}
+
+	return nil
+}
+
+func (r *ShipmentRepo) SaveShipmentEvent(tx *gorm.DB, event *shipment_entity.ShipmentEvent) error {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	return tx.Debug().Create(&event).Error
+}
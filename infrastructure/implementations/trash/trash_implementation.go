@@ -0,0 +1,423 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/bin_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/category_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/image_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/loyalty_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/payment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/return_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/wallet_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/trash_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/search"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/storage"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+)
+
+const (
+	Products   = "products"
+	Warehouses = "warehouses"
+	Categories = "categories"
+	Customers  = "customers"
+)
+
+// trashResource describes how a soft-deleted resource is listed, restored and purged
+type trashResource struct {
+	newModel   func() interface{}
+	newList    func() interface{}
+	cacheKey   string
+	collection string
+	references []trashReference
+}
+
+// trashReference is a table pointing at a resource, a row it points at is never purged
+type trashReference struct {
+	name   string
+	model  interface{}
+	column string
+}
+
+var resources = map[string]trashResource{
+	Products: {
+		newModel:   func() interface{} { return &product_entity.Product{} },
+		newList:    func() interface{} { return &[]product_entity.Product{} },
+		cacheKey:   "%v_PRODUCTS",
+		collection: "products",
+	},
+	Warehouses: {
+		newModel:   func() interface{} { return &warehouse_entity.Warehouse{} },
+		newList:    func() interface{} { return &[]warehouse_entity.Warehouse{} },
+		cacheKey:   "%v_WAREHOUSE",
+		collection: "warehouses",
+		references: []trashReference{
+			{"products", &product_entity.Product{}, "warehouse_id"},
+			{"inventory", &inventory_entity.Inventory{}, "warehouse_id"},
+			{"bins", &bin_entity.Bin{}, "warehouse_id"},
+			{"delivery slots", &warehouse_entity.DeliverySlot{}, "warehouse_id"},
+			{"drivers", &shipment_entity.Driver{}, "warehouse_id"},
+			{"orders", &order_entity.Order{}, "warehouse_id"},
+		},
+	},
+	Categories: {
+		newModel: func() interface{} { return &category_entity.Category{} },
+		newList:  func() interface{} { return &[]category_entity.Category{} },
+		cacheKey: "%v_CATEGORIES",
+	},
+	Customers: {
+		newModel: func() interface{} { return &customer_entity.Customer{} },
+		newList:  func() interface{} { return &[]customer_entity.Customer{} },
+		cacheKey: "%v_CUSTOMER",
+		references: []trashReference{
+			{"orders", &order_entity.Order{}, "customer_id"},
+			{"wallet", &wallet_entity.Wallet{}, "customer_id"},
+			{"loyalty account", &loyalty_entity.LoyaltyAccount{}, "customer_id"},
+			{"payments", &payment_entity.Payment{}, "customer_id"},
+			{"returns", &return_entity.ReturnRequest{}, "customer_id"},
+			{"drivers", &shipment_entity.Driver{}, "customer_id"},
+		},
+	},
+}
+
+// Resources lists every resource that supports the recycle bin
+func Resources() []string {
+	return []string{Products, Warehouses, Categories, Customers}
+}
+
+// Trash Repository struct
+type TrashRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewTrashRepository(p *base.Persistence, c *gin.Context) *TrashRepo {
+	return &TrashRepo{p, c}
+}
+
+// To explicitly check that the TrashRepo implements the repository.TrashRepository interface
+var _ trash_repository.TrashRepository = &TrashRepo{}
+
+func getResource(resource string) (trashResource, error) {
+	res, ok := resources[resource]
+	if !ok {
+		return trashResource{}, fmt.Errorf("resource %v does not support the recycle bin", resource)
+	}
+	return res, nil
+}
+
+func (r *TrashRepo) GetDeleted(resource string) (interface{}, error) {
+	res, err := getResource(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	list := res.newList()
+	err = r.p.DB.Debug().Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// RestoreDeleted clears deleted_at on the row and on everything that was deleted together with it
+func (r *TrashRepo) RestoreDeleted(resource string, id int64) (interface{}, error) {
+	res, err := getResource(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedAt, err := r.getDeletedAt(res, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var restoredProductIDs []int64
+	err = r.p.DB.Transaction(func(tx *gorm.DB) error {
+		restoreErr := tx.Debug().Unscoped().Model(res.newModel()).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error
+		if restoreErr != nil {
+			return restoreErr
+		}
+
+		switch resource {
+		case Products:
+			return restoreProductRelations(tx, id, deletedAt)
+		case Categories:
+			var relationsErr error
+			restoredProductIDs, relationsErr = restoreCategoryRelations(tx, id, deletedAt)
+			return relationsErr
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	restored := res.newModel()
+	err = r.p.DB.Debug().Where("id = ?", id).Take(restored).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if res.collection != "" {
+		searchRepo := search.NewSearchRepository("Mongo", r.p, r.c)
+		searchErr := searchRepo.InsertDoc(res.collection, restored)
+		if searchErr != nil {
+			log.Println(searchErr)
+		}
+	}
+
+	cacheRepo := cache.NewCacheRepository("Redis", r.p)
+	cacheRepo.DelKey(fmt.Sprintf(res.cacheKey, id))
+
+	for _, productId := range restoredProductIDs {
+		var product product_entity.Product
+		if takeErr := r.p.DB.Debug().Where("id = ?", productId).Take(&product).Error; takeErr != nil {
+			log.Println(takeErr)
+			continue
+		}
+
+		searchRepo := search.NewSearchRepository("Mongo", r.p, r.c)
+		if searchErr := searchRepo.InsertDoc(Products, product); searchErr != nil {
+			log.Println(searchErr)
+		}
+		cacheRepo.DelKey(fmt.Sprintf("%v_PRODUCTS", productId))
+	}
+
+	return restored, nil
+}
+
+// PurgeDeleted permanently removes a row that is already in the recycle bin, as long as nothing still points at it
+func (r *TrashRepo) PurgeDeleted(resource string, id int64) error {
+	res, err := getResource(resource)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.getDeletedAt(res, id); err != nil {
+		return err
+	}
+
+	var purgedImages []image_entity.Image
+	err = r.p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkReferences(tx, resource, res, id); err != nil {
+			return err
+		}
+
+		if resource == Customers {
+			if err := purgeCustomerRelations(tx, id); err != nil {
+				return err
+			}
+		}
+
+		if resource == Products {
+			var purgeErr error
+			purgedImages, purgeErr = purgeProductRelations(tx, id)
+			if purgeErr != nil {
+				return purgeErr
+			}
+		}
+
+		return tx.Debug().Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(res.newModel()).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	storageRepo := storage.NewStorageRepository("Supabase", r.p)
+	for _, image := range purgedImages {
+		deleteErr := storageRepo.DeleteFile("images", fmt.Sprint(image.ID))
+		if deleteErr != nil {
+			log.Println(deleteErr)
+		}
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore permanently removes every row of a resource deleted before the given time. A row that
+// cannot be purged is logged and skipped, it is tried again on the next run
+func (r *TrashRepo) PurgeDeletedBefore(resource string, before time.Time) (int64, error) {
+	res, err := getResource(resource)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []int64
+	err = r.p.DB.Debug().Unscoped().Model(res.newModel()).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for _, id := range ids {
+		if purgeErr := r.PurgeDeleted(resource, id); purgeErr != nil {
+			r.p.Logger.Error("implementations/PurgeDeletedBefore", map[string]interface{}{"resource": resource, "id": id, "error": purgeErr.Error()})
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// checkReferences refuses to purge a row other tables still point at, soft-deleted rows included
+func checkReferences(tx *gorm.DB, resource string, res trashResource, id int64) error {
+	for _, reference := range res.references {
+		var count int64
+		err := tx.Debug().Unscoped().Model(reference.model).Where(reference.column+" = ?", id).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%v %v is still referenced by %v %v and cannot be purged", resource, id, count, reference.name)
+		}
+	}
+
+	return nil
+}
+
+func (r *TrashRepo) getDeletedAt(res trashResource, id int64) (time.Time, error) {
+	var row struct {
+		DeletedAt gorm.DeletedAt
+	}
+
+	err := r.p.DB.Debug().Unscoped().Model(res.newModel()).Select("deleted_at").Where("id = ?", id).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, errors.New("record not found")
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !row.DeletedAt.Valid {
+		return time.Time{}, errors.New("record is not in the recycle bin")
+	}
+
+	return row.DeletedAt.Time, nil
+}
+
+// Only relations deleted in the same statement as the product are restored, anything removed
+// on its own beforehand stays in the recycle bin
+func restoreProductRelations(tx *gorm.DB, productId int64, deletedAt time.Time) error {
+	relations := []struct {
+		model  interface{}
+		column string
+	}{
+		{&inventory_entity.Inventory{}, "product_id"},
+		{&image_entity.Image{}, "product_id"},
+		{&product_entity.BundleItem{}, "bundle_id"},
+	}
+
+	for _, relation := range relations {
+		err := tx.Debug().Unscoped().Model(relation.model).
+			Where(relation.column+" = ? AND deleted_at = ?", productId, deletedAt).
+			UpdateColumn("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreCategoryRelations brings back the subcategories and products removed by a cascade delete
+// of the category, returning the ids of the restored products
+func restoreCategoryRelations(tx *gorm.DB, categoryId int64, deletedAt time.Time) ([]int64, error) {
+	var category category_entity.Category
+	err := tx.Debug().Unscoped().Where("id = ?", categoryId).Take(&category).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var categoryIDs []int64
+	err = tx.Debug().Unscoped().Model(&category_entity.Category{}).
+		Where("path LIKE ? AND deleted_at = ?", category.Path+"%", deletedAt).
+		Pluck("id", &categoryIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	categoryIDs = append(categoryIDs, categoryId)
+
+	err = tx.Debug().Unscoped().Model(&category_entity.Category{}).
+		Where("id IN ?", categoryIDs).UpdateColumn("deleted_at", nil).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var productIDs []int64
+	err = tx.Debug().Unscoped().Model(&product_entity.Product{}).
+		Where("category_id IN ? AND deleted_at = ?", categoryIDs, deletedAt).
+		Pluck("id", &productIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, productId := range productIDs {
+		err = tx.Debug().Unscoped().Model(&product_entity.Product{}).Where("id = ?", productId).UpdateColumn("deleted_at", nil).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if err = restoreProductRelations(tx, productId, deletedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return productIDs, nil
+}
+
+func purgeProductRelations(tx *gorm.DB, productId int64) ([]image_entity.Image, error) {
+	var images []image_entity.Image
+	err := tx.Debug().Unscoped().Where("product_id = ? AND deleted_at IS NOT NULL", productId).Find(&images).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Debug().Unscoped().Where("product_id = ? AND deleted_at IS NOT NULL", productId).Delete(&image_entity.Image{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Debug().Unscoped().Where("product_id = ? AND deleted_at IS NOT NULL", productId).Delete(&inventory_entity.Inventory{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Debug().Unscoped().Where("bundle_id = ? AND deleted_at IS NOT NULL", productId).Delete(&product_entity.BundleItem{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// purgeCustomerRelations removes the addresses and tokens of a customer that is purged, nothing else may point at them
+func purgeCustomerRelations(tx *gorm.DB, customerId int64) error {
+	relations := []interface{}{&address_entity.Address{}, &auth_entity.RefreshToken{}, &auth_entity.ActionToken{}}
+	for _, relation := range relations {
+		if err := tx.Debug().Unscoped().Where("customer_id = ?", customerId).Delete(relation).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
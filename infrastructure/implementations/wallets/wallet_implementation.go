@@ -0,0 +1,117 @@
+package wallets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/wallet_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/repository/wallet_repository"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// To manage wallets and their ledger in the database
+
+// Wallet Repository struct
+type WalletRepo struct {
+	p *base.Persistence
+	c *gin.Context
+}
+
+func NewWalletRepository(p *base.Persistence, c *gin.Context) *WalletRepo {
+	return &WalletRepo{p, c}
+}
+
+// To explicitly check that the WalletRepo implements the repository.WalletRepository interface
+var _ wallet_repository.WalletRepository = &WalletRepo{}
+
+func (r *WalletRepo) GetWallet(customerId int64) (*wallet_entity.Wallet, error) {
+	var wallet wallet_entity.Wallet
+	err := r.p.DB.Debug().Where("customer_id = ?", customerId).Take(&wallet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("customer %v has no wallet", customerId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// GetOrCreateWallet returns the wallet of the customer, opening an empty one the first time
+func (r *WalletRepo) GetOrCreateWallet(tx *gorm.DB, customerId int64) (*wallet_entity.Wallet, error) {
+	if tx == nil {
+		tx = r.p.DB
+	}
+
+	// Two requests opening the same wallet end up with the one that was created first
+	err := tx.Debug().Clauses(clause.OnConflict{DoNothing: true}).Create(&wallet_entity.Wallet{CustomerID: customerId}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var wallet wallet_entity.Wallet
+	err = tx.Debug().Where("customer_id = ?", customerId).Take(&wallet).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// PostTransaction moves the wallet's balance and appends the transaction with its entries. A debit
+// only goes through if the balance covers it at that moment, so concurrent debits cannot overdraw
+func (r *WalletRepo) PostTransaction(tx *gorm.DB, transaction *wallet_entity.WalletTransaction) error {
+	if tx == nil {
+		return r.p.DB.Transaction(func(tx *gorm.DB) error {
+			return r.PostTransaction(tx, transaction)
+		})
+	}
+
+	if !transaction.Balanced() {
+		return fmt.Errorf("transaction of wallet %v is not balanced", transaction.WalletID)
+	}
+
+	query := tx.Debug().Model(&wallet_entity.Wallet{}).Where("id = ?", transaction.WalletID)
+	if !wallet_entity.Credits(transaction.Type) {
+		query = query.Where("balance >= ?", transaction.Amount)
+	}
+	result := query.Update("balance", gorm.Expr("balance + ?", transaction.Change()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("wallet %v balance is less than %.2f", transaction.WalletID, transaction.Amount)
+	}
+
+	// The update holds the wallet's row until the end of the transaction, so the balance read is ours
+	var wallet wallet_entity.Wallet
+	if err := tx.Debug().Where("id = ?", transaction.WalletID).Take(&wallet).Error; err != nil {
+		return err
+	}
+	transaction.BalanceAfter = wallet.Balance
+
+	return tx.Debug().Create(&transaction).Error
+}
+
+// GetTransactions returns a page of the wallet's statement with the ledger entries, newest first
+func (r *WalletRepo) GetTransactions(walletId uint64, pagination *entity.Pagination) ([]wallet_entity.WalletTransaction, error) {
+	var total int64
+	err := r.p.DB.Debug().Model(&wallet_entity.WalletTransaction{}).Where("wallet_id = ?", walletId).Count(&total).Error
+	if err != nil {
+		return nil, err
+	}
+	pagination.SetTotal(total)
+
+	var transactions []wallet_entity.WalletTransaction
+	err = r.p.DB.Debug().Preload("Entries").Where("wallet_id = ?", walletId).
+		Order("id desc").Offset(pagination.Offset()).Limit(pagination.PageSize).Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
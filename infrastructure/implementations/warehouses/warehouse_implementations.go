@@ -3,6 +3,7 @@ package warehouses
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,7 +12,9 @@ import (
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/cache"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/search"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/utils/geo"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // To manage new warehouse repositories in the database
@@ -35,7 +38,7 @@ func (r *WarehouseRepo) SaveWarehouse(warehouse *warehouse_entity.Warehouse) (*w
 	searchRepo := search.NewSearchRepository("Mongo", r.p, r.c)
 
 	dbErr := map[string]string{}
-	err := r.p.DB.Debug().Create(&warehouse).Error
+	err := r.p.DB.Debug().Omit(clause.Associations).Create(&warehouse).Error
 	collectionName := "warehouses"
 	if err != nil {
 		fmt.Println("Failed to create warehouse")
@@ -44,6 +47,7 @@ func (r *WarehouseRepo) SaveWarehouse(warehouse *warehouse_entity.Warehouse) (*w
 		return nil, dbErr
 	}
 
+	warehouse.ServiceAreas = nil
 	searchErr := searchRepo.InsertDoc(collectionName, &warehouse)
 
 	if searchErr != nil {
@@ -64,7 +68,7 @@ func (r *WarehouseRepo) GetWarehouse(id int64) (*warehouse_entity.Warehouse, err
 	cacheRepo := cache.NewCacheRepository("Redis", r.p)
 	_ = cacheRepo.GetKey(fmt.Sprintf("%v_WAREHOUSE", id), &warehouse)
 	if warehouse == nil {
-		err := r.p.DB.Debug().Where("id = ?", id).Take(&warehouse).Error
+		err := r.p.DB.Debug().Preload("ServiceAreas").Where("id = ?", id).Take(&warehouse).Error
 		if err != nil {
 			fmt.Println("Failed to get warehouse")
 		}
@@ -79,7 +83,7 @@ func (r *WarehouseRepo) GetWarehouse(id int64) (*warehouse_entity.Warehouse, err
 
 func (r *WarehouseRepo) GetAllWarehouses() ([]warehouse_entity.Warehouse, error) {
 	var warehouses []warehouse_entity.Warehouse
-	err := r.p.DB.Debug().Find(&warehouses).Error
+	err := r.p.DB.Debug().Preload("ServiceAreas").Find(&warehouses).Error
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +100,7 @@ func (r *WarehouseRepo) UpdateWarehouse(warehouse *warehouse_entity.Warehouse) (
 	searchRepo := search.NewSearchRepository("Mongo", r.p, r.c)
 	collectionName := "warehouses"
 
-	err := r.p.DB.Debug().Where("id = ?", warehouse.ID).Updates(&warehouse).Error
+	err := r.p.DB.Debug().Omit(clause.Associations).Where("id = ?", warehouse.ID).Updates(&warehouse).Error
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +109,10 @@ func (r *WarehouseRepo) UpdateWarehouse(warehouse *warehouse_entity.Warehouse) (
 		return nil, err
 	}
 
+	// Service areas are managed on their own, return the ones saved rather than any sent with the warehouse
+	warehouse.ServiceAreas = nil
+	_ = r.p.DB.Debug().Where("warehouse_id = ?", warehouse.ID).Find(&warehouse.ServiceAreas).Error
+
 	searchErr := searchRepo.UpdateDoc(uint(warehouse.ID), collectionName, &warehouse)
 
 	if searchErr != nil {
@@ -141,4 +149,45 @@ func (r *WarehouseRepo) DeleteWarehouse(id int64) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// GetWarehousesServing returns the warehouses whose service area covers the coordinate
+func (r *WarehouseRepo) GetWarehousesServing(latitude float64, longitude float64) ([]warehouse_entity.Warehouse, error) {
+	allWarehouses, err := r.GetAllWarehouses()
+	if err != nil {
+		return nil, err
+	}
+
+	var serving []warehouse_entity.Warehouse
+	for _, warehouse := range allWarehouses {
+		if warehouse.Serves(latitude, longitude) {
+			serving = append(serving, warehouse)
+		}
+	}
+
+	return serving, nil
+}
+
+// GetNearestWarehouses returns every warehouse ordered by its haversine distance from the coordinate
+func (r *WarehouseRepo) GetNearestWarehouses(latitude float64, longitude float64) ([]warehouse_entity.WarehouseDistance, error) {
+	allWarehouses, err := r.GetAllWarehouses()
+	if err != nil {
+		return nil, err
+	}
+
+	nearest := make([]warehouse_entity.WarehouseDistance, 0, len(allWarehouses))
+	for _, warehouse := range allWarehouses {
+		distance := geo.Haversine(warehouse.Latitude, warehouse.Longitude, latitude, longitude)
+		nearest = append(nearest, warehouse_entity.WarehouseDistance{
+			Warehouse:  warehouse,
+			DistanceKm: distance,
+			Serves:     distance <= warehouse.ServiceRadius,
+		})
+	}
+
+	sort.SliceStable(nearest, func(i, j int) bool {
+		return nearest[i].DistanceKm < nearest[j].DistanceKm
+	})
+
+	return nearest, nil
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// StartLoyaltyPointsJob vests the points of orders past their return window and expires old points once an hour
+func StartLoyaltyPointsJob(p *base.Persistence) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for {
+			loyaltyApp := application.NewLoyaltyApplication(p, nil)
+			vested, err := loyaltyApp.VestPoints(time.Now())
+			if err != nil {
+				log.Println("loyalty points job failed to vest points:", err)
+			} else if vested > 0 {
+				log.Println("loyalty points job vested points:", vested)
+			}
+
+			expired, err := loyaltyApp.ExpirePoints(time.Now())
+			if err != nil {
+				log.Println("loyalty points job failed to expire points:", err)
+			} else if expired > 0 {
+				log.Println("loyalty points job expired points:", expired)
+			}
+
+			<-ticker.C
+		}
+	}()
+}
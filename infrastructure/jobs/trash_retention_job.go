@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+const defaultTrashRetentionDays = 30
+
+// TrashRetentionDays reads the recycle bin retention period from TRASH_RETENTION_DAYS
+func TrashRetentionDays() int {
+	retentionDays, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || retentionDays < 0 {
+		return defaultTrashRetentionDays
+	}
+	return retentionDays
+}
+
+// StartTrashRetentionJob hard-purges soft-deleted rows older than the retention period once a day
+func StartTrashRetentionJob(p *base.Persistence) {
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			purged, err := application.NewTrashApplication(p, nil).PurgeExpired(TrashRetentionDays())
+			if err != nil {
+				log.Println("trash retention job failed:", err)
+			} else {
+				log.Println("trash retention job purged:", purged)
+			}
+
+			<-ticker.C
+		}
+	}()
+}
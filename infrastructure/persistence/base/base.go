@@ -3,13 +3,22 @@ package base
 import (
 	"log"
 
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/address_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/bin_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/category_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/image_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/inventory_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/loyalty_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/order_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/ordereditem_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/payment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/picking_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/product_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/return_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/shipment_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/wallet_entity"
 	"github.com/harisquqo/quqo-challenge-1/domain/entity/warehouse_entity"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/logger"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base/db"
@@ -78,13 +87,64 @@ func NewPersistence() (*Persistence, error) {
 
 //This migrate all tables
 func (s *Persistence) Automigrate() error {
-	return s.DB.AutoMigrate(&product_entity.Product{}, 
+	err := s.DB.AutoMigrate(&product_entity.Product{}, 
+		&product_entity.BundleItem{},
 		&inventory_entity.Inventory{},
 		&inventory_entity.InventoryLog{}, 
 		&warehouse_entity.Warehouse{}, 
+		&warehouse_entity.ServiceArea{},
+		&warehouse_entity.OperatingHours{},
+		&warehouse_entity.Holiday{},
+		&warehouse_entity.DeliverySlot{},
+		&bin_entity.Zone{},
+		&bin_entity.Aisle{},
+		&bin_entity.Bin{},
+		&bin_entity.BinStock{},
+		&picking_entity.PickList{},
+		&picking_entity.PickItem{},
+		&shipment_entity.Driver{},
+		&shipment_entity.Shipment{},
+		&shipment_entity.ShipmentEvent{},
+		&shipment_entity.DeliveryRoute{},
+		&shipment_entity.RouteStop{},
+		&return_entity.ReturnRequest{},
+		&return_entity.ReturnItem{},
+		&payment_entity.Payment{},
+		&payment_entity.PaymentEvent{},
+		&wallet_entity.Wallet{},
+		&wallet_entity.WalletTransaction{},
+		&wallet_entity.LedgerEntry{},
+		&loyalty_entity.LoyaltyAccount{},
+		&loyalty_entity.PointsEntry{},
 		&image_entity.Image{},
 		&category_entity.Category{},
 		&customer_entity.Customer{},
+		&address_entity.Address{},
+		&auth_entity.RefreshToken{},
+		&auth_entity.SigningKey{},
+		&auth_entity.AuditEvent{},
+		&auth_entity.ActionToken{},
+		&auth_entity.APIKey{},
 		&order_entity.Order{},
-		&ordereditem_entity.OrderedItem{})
+		&ordereditem_entity.OrderedItem{},
+		&ordereditem_entity.OrderedComponent{})
+	if err != nil {
+		return err
+	}
+
+	return s.migrateInventoryKey()
+}
+
+// migrateInventoryKey moves inventories created when a product could only be stocked in one warehouse
+// over to a key on both the product and the warehouse, AutoMigrate does not change an existing primary key
+func (s *Persistence) migrateInventoryKey() error {
+	var keyColumns int64
+	err := s.DB.Raw(`SELECT COUNT(*) FROM information_schema.key_column_usage k
+		JOIN information_schema.table_constraints t ON t.constraint_name = k.constraint_name AND t.table_schema = k.table_schema
+		WHERE t.table_schema = current_schema() AND t.table_name = 'inventories' AND t.constraint_type = 'PRIMARY KEY'`).Scan(&keyColumns).Error
+	if err != nil || keyColumns != 1 {
+		return err
+	}
+
+	return s.DB.Exec("ALTER TABLE inventories DROP CONSTRAINT inventories_pkey, ADD PRIMARY KEY (product_id, warehouse_id)").Error
 }
\ No newline at end of file
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func AddressRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    addresses := handlers.NewAddress(p)
+
+    router.GET("admin/customers/:customer_id/addresses", middleware.RequirePermissionOrSelf(auth_entity.PermCustomersRead, "customer_id"), addresses.GetAddresses)
+    router.POST("admin/customers/:customer_id/addresses", middleware.RequirePermissionOrSelf(auth_entity.PermCustomersWrite, "customer_id"), addresses.SaveAddress)
+    router.GET("admin/customers/:customer_id/addresses/:address_id", middleware.RequirePermissionOrSelf(auth_entity.PermCustomersRead, "customer_id"), addresses.GetAddress)
+    router.PUT("admin/customers/:customer_id/addresses/:address_id", middleware.RequirePermissionOrSelf(auth_entity.PermCustomersWrite, "customer_id"), addresses.UpdateAddress)
+    router.PUT("admin/customers/:customer_id/addresses/:address_id/default", middleware.RequirePermissionOrSelf(auth_entity.PermCustomersWrite, "customer_id"), addresses.SetDefaultAddress)
+    router.DELETE("admin/customers/:customer_id/addresses/:address_id", middleware.RequirePermissionOrSelf(auth_entity.PermCustomersWrite, "customer_id"), addresses.DeleteAddress)
+}
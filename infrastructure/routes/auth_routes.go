@@ -2,7 +2,9 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
@@ -10,10 +12,25 @@ func AuthRoutesPublic(router *gin.RouterGroup, p *base.Persistence) {
     auth := handlers.NewAuth(p)
 	
 	router.POST("admin/login", auth.Login)
+	router.POST("admin/token/refresh", auth.RefreshToken)
+	router.GET(".well-known/jwks.json", auth.GetJWKS)
+	router.POST("admin/email/verification/confirm", auth.VerifyEmail)
+	router.POST("admin/password/reset/request", auth.RequestPasswordReset)
+	router.POST("admin/password/reset/confirm", auth.ResetPassword)
 }
 
 func AuthRoutesPrivate(router *gin.RouterGroup, p *base.Persistence) {
     auth := handlers.NewAuth(p)
 	
-	router.POST("admin/logout", auth.Logout)
+	router.POST("admin/logout", middleware.RequireUser(), auth.Logout)
+	router.POST("admin/logout/all", middleware.RequireUser(), auth.LogoutAllSessions)
+	router.POST("admin/email/verification/request", middleware.RequireUser(), auth.RequestEmailVerification)
+	router.POST("admin/token/revoke", middleware.RequirePermission(auth_entity.PermSessionsManage), auth.RevokeToken)
+	router.GET("admin/keys", middleware.RequirePermission(auth_entity.PermKeysManage), auth.GetSigningKeys)
+	router.POST("admin/keys/rotate", middleware.RequirePermission(auth_entity.PermKeysManage), auth.RotateSigningKey)
+	router.POST("admin/login/unlock", middleware.RequirePermission(auth_entity.PermSessionsManage), auth.UnlockLogin)
+	router.GET("admin/audit-events", middleware.RequirePermission(auth_entity.PermSessionsManage), auth.GetAuditEvents)
+	router.GET("admin/api-keys", middleware.RequirePermission(auth_entity.PermAPIKeysManage), auth.GetAPIKeys)
+	router.POST("admin/api-keys", middleware.RequirePermission(auth_entity.PermAPIKeysManage), auth.CreateAPIKey)
+	router.DELETE("admin/api-keys/:api_key_id", middleware.RequirePermission(auth_entity.PermAPIKeysManage), auth.RevokeAPIKey)
 }
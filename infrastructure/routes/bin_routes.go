@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func BinRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    bins := handlers.NewBin(p)
+
+    router.GET("admin/warehouses/:warehouse_id/layout", middleware.RequirePermission(auth_entity.PermWarehousesRead), bins.GetLayout)
+    router.POST("admin/warehouses/:warehouse_id/zones", middleware.RequirePermission(auth_entity.PermWarehousesWrite), bins.SaveZone)
+    router.PUT("admin/warehouses/:warehouse_id/zones/:zone_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), bins.UpdateZone)
+    router.DELETE("admin/warehouses/:warehouse_id/zones/:zone_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), bins.DeleteZone)
+    router.POST("admin/warehouses/:warehouse_id/aisles", middleware.RequirePermission(auth_entity.PermWarehousesWrite), bins.SaveAisle)
+    router.PUT("admin/warehouses/:warehouse_id/aisles/:aisle_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), bins.UpdateAisle)
+    router.DELETE("admin/warehouses/:warehouse_id/aisles/:aisle_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), bins.DeleteAisle)
+    router.POST("admin/warehouses/:warehouse_id/bins", middleware.RequirePermission(auth_entity.PermWarehousesWrite), bins.SaveBin)
+    router.GET("admin/warehouses/:warehouse_id/bins/:bin_id", middleware.RequirePermission(auth_entity.PermInventoryRead), bins.GetBin)
+    router.PUT("admin/warehouses/:warehouse_id/bins/:bin_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), bins.UpdateBin)
+    router.DELETE("admin/warehouses/:warehouse_id/bins/:bin_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), bins.DeleteBin)
+    router.POST("admin/warehouses/:warehouse_id/put-away", middleware.RequirePermission(auth_entity.PermInventoryWrite), bins.PutAway)
+    router.POST("admin/warehouses/:warehouse_id/bin-moves", middleware.RequirePermission(auth_entity.PermInventoryWrite), bins.MoveStock)
+    router.GET("admin/products/:product_id/bins", middleware.RequirePermission(auth_entity.PermInventoryRead), bins.GetProductBins)
+}
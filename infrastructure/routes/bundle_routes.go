@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func BundleRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    bundles := handlers.NewBundle(p)
+
+    router.GET("admin/products/:product_id/bundle", middleware.RequirePermission(auth_entity.PermProductsRead), bundles.GetBundle)
+    router.PUT("admin/products/:product_id/bundle", middleware.RequirePermission(auth_entity.PermProductsWrite), bundles.UpdateBundle)
+    router.GET("admin/products/:product_id/bundle/availability", middleware.RequirePermission(auth_entity.PermProductsRead), bundles.GetBundleAvailability)
+}
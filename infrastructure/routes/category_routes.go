@@ -2,17 +2,27 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
 func CategoryRoutes(router *gin.RouterGroup, p *base.Persistence) {
     categories := handlers.NewCategory(p)
        
-    router.POST("admin/categories", categories.SaveCategory)
-    router.GET("admin/categories/:category_id", categories.GetCategory)
-    router.GET("admin/categories/parents/:category_id", categories.GetParentCategories)
-    router.GET("admin/categories", categories.GetAllCategories)
-	router.PUT("admin/categories/:category_id", categories.UpdateCategory)
-    router.DELETE("admin/categories/:category_id", categories.DeleteCategory)
+    router.POST("admin/categories", middleware.RequirePermission(auth_entity.PermCategoriesWrite), categories.SaveCategory)
+    router.GET("admin/categories/:category_id", middleware.RequirePermission(auth_entity.PermCategoriesRead), categories.GetCategory)
+    router.GET("admin/categories/parents/:category_id", middleware.RequirePermission(auth_entity.PermCategoriesRead), categories.GetParentCategories)
+    router.GET("admin/categories", middleware.RequirePermission(auth_entity.PermCategoriesRead), categories.GetAllCategories)
+	router.PUT("admin/categories/:category_id", middleware.RequirePermission(auth_entity.PermCategoriesWrite), categories.UpdateCategory)
+    router.DELETE("admin/categories/:category_id", middleware.RequirePermission(auth_entity.PermCategoriesWrite), categories.DeleteCategory)
+    router.GET("admin/categories/tree", middleware.RequirePermission(auth_entity.PermCategoriesRead), categories.GetCategoryTree)
+    router.POST("admin/categories/tree/rebuild", middleware.RequirePermission(auth_entity.PermCategoriesWrite), categories.RebuildCategoryPaths)
+    router.GET("admin/categories/:category_id/tree", middleware.RequirePermission(auth_entity.PermCategoriesRead), categories.GetCategoryTree)
+    router.GET("admin/categories/:category_id/breadcrumbs", middleware.RequirePermission(auth_entity.PermCategoriesRead), categories.GetBreadcrumbs)
+    router.GET("admin/categories/:category_id/products", middleware.RequirePermission(auth_entity.PermCategoriesRead), categories.GetProductsInCategoryTree)
+    router.PUT("admin/categories/:category_id/move", middleware.RequirePermission(auth_entity.PermCategoriesWrite), categories.MoveCategory)
+    router.PUT("admin/categories/:category_id/children/order", middleware.RequirePermission(auth_entity.PermCategoriesWrite), categories.ReorderCategories)
+    router.PUT("admin/categories/:category_id/points-multiplier", middleware.RequirePermission(auth_entity.PermCategoriesWrite), categories.SetPointsMultiplier)
 }
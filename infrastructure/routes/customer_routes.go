@@ -2,17 +2,21 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
 func CustomerPrivateRoutes(router *gin.RouterGroup, p *base.Persistence) {
     customers := handlers.NewCustomer(p)
     
-    router.GET("admin/customers", customers.GetAllCustomers)
-    router.GET("admin/customers/:customer_id", customers.GetCustomer)
-    router.PUT("admin/customers/:customer_id", customers.UpdateCustomer)
-    router.DELETE("admin/customers/:customer_id", customers.DeleteCustomer)
+    router.GET("admin/customers", middleware.RequirePermission(auth_entity.PermCustomersRead), customers.GetAllCustomers)
+    router.GET("admin/customers/:customer_id", middleware.RequirePermissionOrSelf(auth_entity.PermCustomersRead, "customer_id"), customers.GetCustomer)
+    router.PUT("admin/customers/:customer_id", middleware.RequirePermissionOrSelf(auth_entity.PermCustomersWrite, "customer_id"), customers.UpdateCustomer)
+    router.DELETE("admin/customers/:customer_id", middleware.RequirePermission(auth_entity.PermCustomersWrite), customers.DeleteCustomer)
+    router.PUT("admin/customers/:customer_id/role", middleware.RequirePermission(auth_entity.PermRolesManage), customers.UpdateCustomerRole)
+    router.PUT("admin/customers/:customer_id/password", middleware.RequireUser(), customers.ChangePassword)
 }
 
 
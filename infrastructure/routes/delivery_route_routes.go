@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func DeliveryRouteRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    deliveryRoutes := handlers.NewDeliveryRoute(p)
+
+    router.POST("admin/warehouses/:warehouse_id/routes/plan", middleware.RequirePermission(auth_entity.PermShipmentsManage), deliveryRoutes.PlanRoutes)
+    router.GET("admin/warehouses/:warehouse_id/routes", middleware.RequirePermission(auth_entity.PermShipmentsManage), deliveryRoutes.GetWarehouseRoutes)
+    router.GET("admin/drivers/:driver_id/routes", middleware.RequirePermission(auth_entity.PermDeliveriesWrite), deliveryRoutes.GetDriverRoutes)
+    router.GET("admin/routes/:route_id", middleware.RequirePermission(auth_entity.PermDeliveriesWrite), deliveryRoutes.GetRoute)
+    router.DELETE("admin/routes/:route_id", middleware.RequirePermission(auth_entity.PermShipmentsManage), deliveryRoutes.CancelRoute)
+}
@@ -19,6 +19,7 @@ func InitRouter(p *base.Persistence) *gin.Engine {
     InitMiddleware(r)
     AuthRoutesPublic(r.Group("/"), p)
     CustomerPublicRoutes(r.Group("/"), p)
+    PaymentWebhookRoutes(r.Group("/"), p)
     private := r.Group("/")
     authMiddleware := middleware.AuthHandler(p)
 
@@ -32,14 +33,29 @@ func InitRouter(p *base.Persistence) *gin.Engine {
     // Define routes within the private group
     {
         ProductRoutes(private, p)
+        BundleRoutes(private, p)
         InventoryRoutes(private, p)
         WarehouseRoutes(private, p)
+        ServiceAreaRoutes(private, p)
+        ScheduleRoutes(private, p)
+        BinRoutes(private, p)
         ImageRoutes(private, p)
         CategoryRoutes(private, p)
         CustomerPrivateRoutes(private, p)
+        AddressRoutes(private, p)
+        MeRoutes(private, p)
+        PrivacyRoutes(private, p)
         OrderRoutes(private, p)
         OrderedItemRoutes(private, p)
+        PickingRoutes(private, p)
+        ShipmentRoutes(private, p)
+        DeliveryRouteRoutes(private, p)
+        ReturnRoutes(private, p)
+        PaymentRoutes(private, p)
+        WalletRoutes(private, p)
+        LoyaltyRoutes(private, p)
         AuthRoutesPrivate(private, p)
+        TrashRoutes(private, p)
     }
 
     // Swagger documentation setup
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// LoyaltyRoutes serve customers their own loyalty points under me, and staff looking into customers' points
+func LoyaltyRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    loyalty := handlers.NewLoyalty(p)
+    me := router.Group("me", middleware.RequireUser())
+
+    me.GET("loyalty", loyalty.GetMyLoyalty)
+    me.GET("loyalty/statement", loyalty.GetMyLoyaltyStatement)
+    router.GET("admin/customers/:customer_id/loyalty", middleware.RequirePermission(auth_entity.PermLoyaltyManage), loyalty.GetCustomerLoyalty)
+    router.GET("admin/customers/:customer_id/loyalty/statement", middleware.RequirePermission(auth_entity.PermLoyaltyManage), loyalty.GetCustomerLoyaltyStatement)
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// MeRoutes act on the logged in customer only, so they need no permission beyond being logged in
+func MeRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    me := handlers.NewMe(p)
+    group := router.Group("me", middleware.RequireUser())
+
+    group.GET("", me.GetProfile)
+    group.PUT("", me.UpdateProfile)
+    group.DELETE("", me.DeleteAccount)
+    group.PUT("password", me.ChangePassword)
+    group.GET("export", me.ExportData)
+    group.GET("addresses", me.GetAddresses)
+    group.POST("addresses", me.SaveAddress)
+    group.GET("addresses/:address_id", me.GetAddress)
+    group.PUT("addresses/:address_id", me.UpdateAddress)
+    group.PUT("addresses/:address_id/default", me.SetDefaultAddress)
+    group.DELETE("addresses/:address_id", me.DeleteAddress)
+    group.GET("orders", me.GetOrders)
+    group.GET("orders/:order_id", me.GetOrder)
+    group.POST("orders/:order_id/cancel", me.CancelOrder)
+}
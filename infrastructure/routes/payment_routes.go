@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// PaymentRoutes serve customers paying their own orders under me, and staff capturing, voiding and refunding payments
+func PaymentRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    payments := handlers.NewPayment(p)
+    me := router.Group("me", middleware.RequireUser())
+
+    me.POST("orders/:order_id/pay", payments.PayMyOrder)
+    me.GET("orders/:order_id/payments", payments.GetMyOrderPayments)
+    router.GET("admin/orders/:order_id/payments", middleware.RequirePermission(auth_entity.PermPaymentsManage), payments.GetOrderPayments)
+    router.GET("admin/payments/:payment_id", middleware.RequirePermission(auth_entity.PermPaymentsManage), payments.GetPayment)
+    router.POST("admin/payments/:payment_id/capture", middleware.RequirePermission(auth_entity.PermPaymentsManage), payments.CapturePayment)
+    router.POST("admin/payments/:payment_id/void", middleware.RequirePermission(auth_entity.PermPaymentsManage), payments.VoidPayment)
+    router.POST("admin/payments/:payment_id/refund", middleware.RequirePermission(auth_entity.PermPaymentsManage), payments.RefundPayment)
+}
+
+// PaymentWebhookRoutes are reached by the payment gateway, which signs its webhooks instead of logging in
+func PaymentWebhookRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    payments := handlers.NewPayment(p)
+
+    router.POST("payments/webhook", payments.PaymentWebhook)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func PickingRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    picking := handlers.NewPicking(p)
+
+    router.POST("admin/warehouses/:warehouse_id/pick-lists", middleware.RequirePermission(auth_entity.PermPickingManage), picking.CreatePickList)
+    router.GET("admin/warehouses/:warehouse_id/pick-lists", middleware.RequirePermission(auth_entity.PermPickingManage), picking.GetPickLists)
+    router.GET("admin/pick-lists/:pick_list_id", middleware.RequirePermission(auth_entity.PermPickingManage), picking.GetPickList)
+    router.POST("admin/pick-lists/:pick_list_id/items/:pick_item_id/confirm", middleware.RequirePermission(auth_entity.PermPickingManage), picking.ConfirmPick)
+    router.POST("admin/orders/:order_id/pack", middleware.RequirePermission(auth_entity.PermPickingManage), picking.PackOrder)
+}
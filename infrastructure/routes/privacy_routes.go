@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func PrivacyRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    privacy := handlers.NewPrivacy(p)
+
+    router.GET("admin/customers/:customer_id/export", middleware.RequirePermission(auth_entity.PermPrivacyManage), privacy.ExportCustomerData)
+    router.POST("admin/customers/:customer_id/erase", middleware.RequirePermission(auth_entity.PermPrivacyManage), privacy.EraseCustomer)
+}
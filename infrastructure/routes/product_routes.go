@@ -2,7 +2,9 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
@@ -10,12 +12,12 @@ func ProductRoutes(router *gin.RouterGroup, p *base.Persistence) {
     products := handlers.NewProduct(p)
 
     
-    router.POST("admin/products", products.SaveProduct)
+    router.POST("admin/products", middleware.RequirePermission(auth_entity.PermProductsWrite), products.SaveProduct)
     // router.POST("admin/products/multiple", products.SaveMultipleProducts)
-    router.GET("admin/products", products.GetAllProducts)
-    router.GET("admin/products/:product_id", products.GetProduct)
-    router.PUT("admin/products/:product_id", products.UpdateProduct)
-    router.DELETE("admin/products/:product_id", products.DeleteProduct)
-    router.GET("admin/products/search", products.SearchProduct)
-    router.POST("admin/products/search", products.UpdateProductSearchDB)
+    router.GET("admin/products", middleware.RequirePermission(auth_entity.PermProductsRead), products.GetAllProducts)
+    router.GET("admin/products/:product_id", middleware.RequirePermission(auth_entity.PermProductsRead), products.GetProduct)
+    router.PUT("admin/products/:product_id", middleware.RequirePermission(auth_entity.PermProductsWrite), products.UpdateProduct)
+    router.DELETE("admin/products/:product_id", middleware.RequirePermission(auth_entity.PermProductsWrite), products.DeleteProduct)
+    router.GET("admin/products/search", middleware.RequirePermission(auth_entity.PermProductsRead), products.SearchProduct)
+    router.POST("admin/products/search", middleware.RequirePermission(auth_entity.PermProductsWrite), products.UpdateProductSearchDB)
 }
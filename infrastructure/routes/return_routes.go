@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// ReturnRoutes serve customers returning their own orders under me, and staff reviewing and inspecting returns
+func ReturnRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    returns := handlers.NewReturn(p)
+    me := router.Group("me", middleware.RequireUser())
+
+    me.POST("orders/:order_id/returns", returns.RequestReturn)
+    me.GET("returns", returns.GetMyReturns)
+    me.GET("returns/:return_id", returns.GetMyReturn)
+    me.POST("returns/:return_id/cancel", returns.CancelMyReturn)
+    router.GET("admin/returns", middleware.RequirePermission(auth_entity.PermReturnsManage), returns.GetReturns)
+    router.GET("admin/returns/:return_id", middleware.RequirePermission(auth_entity.PermReturnsManage), returns.GetReturn)
+    router.POST("admin/returns/:return_id/approve", middleware.RequirePermission(auth_entity.PermReturnsManage), returns.ApproveReturn)
+    router.POST("admin/returns/:return_id/reject", middleware.RequirePermission(auth_entity.PermReturnsManage), returns.RejectReturn)
+    router.POST("admin/returns/:return_id/inspect", middleware.RequirePermission(auth_entity.PermReturnsManage), returns.InspectReturn)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func ScheduleRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    schedules := handlers.NewSchedule(p)
+
+    router.GET("admin/warehouses/:warehouse_id/hours", middleware.RequirePermission(auth_entity.PermWarehousesRead), schedules.GetOperatingHours)
+    router.PUT("admin/warehouses/:warehouse_id/hours", middleware.RequirePermission(auth_entity.PermWarehousesWrite), schedules.UpdateOperatingHours)
+    router.GET("admin/warehouses/:warehouse_id/holidays", middleware.RequirePermission(auth_entity.PermWarehousesRead), schedules.GetHolidays)
+    router.POST("admin/warehouses/:warehouse_id/holidays", middleware.RequirePermission(auth_entity.PermWarehousesWrite), schedules.SaveHoliday)
+    router.DELETE("admin/warehouses/:warehouse_id/holidays/:holiday_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), schedules.DeleteHoliday)
+    router.GET("admin/warehouses/:warehouse_id/delivery-slots", schedules.GetDeliverySlots)
+    router.POST("admin/warehouses/:warehouse_id/delivery-slots", middleware.RequirePermission(auth_entity.PermWarehousesWrite), schedules.SaveDeliverySlot)
+    router.POST("admin/warehouses/:warehouse_id/delivery-slots/generate", middleware.RequirePermission(auth_entity.PermWarehousesWrite), schedules.GenerateDeliverySlots)
+    router.DELETE("admin/warehouses/:warehouse_id/delivery-slots/:slot_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), schedules.DeleteDeliverySlot)
+}
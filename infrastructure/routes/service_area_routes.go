@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func ServiceAreaRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    serviceAreas := handlers.NewServiceArea(p)
+
+    router.GET("admin/serviceability", serviceAreas.CheckServiceability)
+    router.GET("admin/warehouses/service-areas/overlaps", middleware.RequirePermission(auth_entity.PermWarehousesRead), serviceAreas.GetServiceAreaOverlaps)
+    router.GET("admin/warehouses/:warehouse_id/service-areas", middleware.RequirePermission(auth_entity.PermWarehousesRead), serviceAreas.GetServiceAreas)
+    router.POST("admin/warehouses/:warehouse_id/service-areas", middleware.RequirePermission(auth_entity.PermWarehousesWrite), serviceAreas.SaveServiceArea)
+    router.PUT("admin/warehouses/:warehouse_id/service-areas/:service_area_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), serviceAreas.UpdateServiceArea)
+    router.DELETE("admin/warehouses/:warehouse_id/service-areas/:service_area_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), serviceAreas.DeleteServiceArea)
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func ShipmentRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    shipments := handlers.NewShipment(p)
+
+    router.POST("admin/drivers", middleware.RequirePermission(auth_entity.PermShipmentsManage), shipments.SaveDriver)
+    router.GET("admin/drivers", middleware.RequirePermission(auth_entity.PermShipmentsManage), shipments.GetDrivers)
+    router.GET("admin/drivers/:driver_id", middleware.RequirePermission(auth_entity.PermShipmentsManage), shipments.GetDriver)
+    router.PUT("admin/drivers/:driver_id", middleware.RequirePermission(auth_entity.PermShipmentsManage), shipments.UpdateDriver)
+    router.DELETE("admin/drivers/:driver_id", middleware.RequirePermission(auth_entity.PermShipmentsManage), shipments.DeleteDriver)
+    router.POST("admin/orders/:order_id/shipment", middleware.RequirePermission(auth_entity.PermShipmentsManage), shipments.CreateShipment)
+    router.GET("admin/orders/:order_id/shipment", shipments.GetOrderShipment)
+    router.GET("admin/shipments", middleware.RequirePermission(auth_entity.PermDeliveriesWrite), shipments.GetShipments)
+    router.GET("admin/shipments/:shipment_id", middleware.RequirePermission(auth_entity.PermDeliveriesWrite), shipments.GetShipment)
+    router.POST("admin/shipments/:shipment_id/assign", middleware.RequirePermission(auth_entity.PermShipmentsManage), shipments.AssignShipment)
+    router.POST("admin/shipments/:shipment_id/status", middleware.RequirePermission(auth_entity.PermDeliveriesWrite), shipments.UpdateShipmentStatus)
+    router.POST("admin/shipments/:shipment_id/location", middleware.RequirePermission(auth_entity.PermDeliveriesWrite), shipments.UpdateShipmentLocation)
+    router.POST("admin/shipments/:shipment_id/deliver", middleware.RequirePermission(auth_entity.PermDeliveriesWrite), shipments.DeliverShipment)
+}
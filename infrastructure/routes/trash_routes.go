@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+func TrashRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    trash := handlers.NewTrash(p)
+
+    router.POST("admin/trash/purge", middleware.RequirePermission(auth_entity.PermTrashManage), trash.PurgeExpired)
+    router.GET("admin/trash/:resource", middleware.RequirePermission(auth_entity.PermTrashManage), trash.GetDeleted)
+    router.POST("admin/trash/:resource/:id/restore", middleware.RequirePermission(auth_entity.PermTrashManage), trash.RestoreDeleted)
+    router.DELETE("admin/trash/:resource/:id", middleware.RequirePermission(auth_entity.PermTrashManage), trash.PurgeDeleted)
+}
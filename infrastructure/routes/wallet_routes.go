@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
+)
+
+// WalletRoutes serve customers their own wallet under me, and staff looking into and crediting customers' wallets
+func WalletRoutes(router *gin.RouterGroup, p *base.Persistence) {
+    wallets := handlers.NewWallet(p)
+    me := router.Group("me", middleware.RequireUser())
+
+    me.GET("wallet", wallets.GetMyWallet)
+    me.GET("wallet/statement", wallets.GetMyWalletStatement)
+    me.POST("wallet/top-ups", wallets.TopUpMyWallet)
+    router.GET("admin/customers/:customer_id/wallet", middleware.RequirePermission(auth_entity.PermWalletsManage), wallets.GetCustomerWallet)
+    router.GET("admin/customers/:customer_id/wallet/statement", middleware.RequirePermission(auth_entity.PermWalletsManage), wallets.GetCustomerWalletStatement)
+    router.POST("admin/customers/:customer_id/wallet/credits", middleware.RequirePermission(auth_entity.PermWalletsManage), wallets.CreditCustomerWallet)
+}
@@ -2,7 +2,9 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/handlers"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/controllers/middleware"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 )
 
@@ -10,13 +12,14 @@ func WarehouseRoutes(router *gin.RouterGroup, p *base.Persistence) {
     warehouses := handlers.NewWarehouse(p)
 
     
-    router.POST("admin/warehouses", warehouses.SaveWarehouse)
+    router.POST("admin/warehouses", middleware.RequirePermission(auth_entity.PermWarehousesWrite), warehouses.SaveWarehouse)
     // router.POST("admin/warehouses/multiple", warehouses.SaveMultiplewarehouses)
-    router.GET("admin/warehouses", warehouses.GetAllWarehouses)
-    router.GET("admin/warehouses/:warehouse_id", warehouses.GetWarehouse)
-    router.GET("admin/warehouses/:warehouse_id/inventories", warehouses.GetInventoriesInWarehouse)
-    router.PUT("admin/warehouses/:warehouse_id", warehouses.UpdateWarehouse)
-    router.DELETE("admin/warehouses/:warehouse_id", warehouses.DeleteWarehouse)
-    router.GET("admin/warehouses/search", warehouses.SearchWarehouse)
-    router.POST("admin/warehouses/search", warehouses.UpdateWarehouseSearchDB)
+    router.GET("admin/warehouses", middleware.RequirePermission(auth_entity.PermWarehousesRead), warehouses.GetAllWarehouses)
+    router.GET("admin/warehouses/nearest", middleware.RequirePermission(auth_entity.PermWarehousesRead), warehouses.GetNearestWarehouses)
+    router.GET("admin/warehouses/:warehouse_id", middleware.RequirePermission(auth_entity.PermWarehousesRead), warehouses.GetWarehouse)
+    router.GET("admin/warehouses/:warehouse_id/inventories", middleware.RequirePermission(auth_entity.PermInventoryRead), warehouses.GetInventoriesInWarehouse)
+    router.PUT("admin/warehouses/:warehouse_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), warehouses.UpdateWarehouse)
+    router.DELETE("admin/warehouses/:warehouse_id", middleware.RequirePermission(auth_entity.PermWarehousesWrite), warehouses.DeleteWarehouse)
+    router.GET("admin/warehouses/search", middleware.RequirePermission(auth_entity.PermWarehousesRead), warehouses.SearchWarehouse)
+    router.POST("admin/warehouses/search", middleware.RequirePermission(auth_entity.PermWarehousesWrite), warehouses.UpdateWarehouseSearchDB)
 }
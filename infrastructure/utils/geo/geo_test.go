@@ -0,0 +1,32 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{"same point", 51.5, -0.12, 51.5, -0.12, 0},
+		{"one degree of latitude", 0, 0, 1, 0, 111.19},
+		{"one degree of longitude at the equator", 0, 0, 0, 1, 111.19},
+		{"london to paris", 51.5074, -0.1278, 48.8566, 2.3522, 343.56},
+		{"antipodes", 0, 0, 0, 180, math.Pi * earthRadiusKm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Haversine(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Haversine() = %.4f, want %.2f", got, tt.want)
+			}
+			if reversed := Haversine(tt.lat2, tt.lng2, tt.lat1, tt.lng1); math.Abs(reversed-got) > 1e-9 {
+				t.Errorf("Haversine() is not symmetric: %v and %v", got, reversed)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+)
+
+const PolygonType = "Polygon"
+
+// Polygon is a GeoJSON polygon, the first ring is the boundary and any further rings are holes.
+// Positions are [longitude, latitude] as in the GeoJSON spec
+type Polygon struct {
+	Type        string        `json:"type"`
+	Coordinates [][][]float64 `json:"coordinates"`
+}
+
+// Validate checks the polygon is a GeoJSON polygon with closed rings of valid positions
+func (p Polygon) Validate() error {
+	if p.Type != PolygonType {
+		return fmt.Errorf("type must be %v", PolygonType)
+	}
+
+	if len(p.Coordinates) == 0 {
+		return errors.New("a polygon needs at least one ring")
+	}
+
+	for i, ring := range p.Coordinates {
+		if len(ring) < 4 {
+			return fmt.Errorf("ring %v needs at least 4 positions", i)
+		}
+
+		for _, position := range ring {
+			if len(position) < 2 {
+				return fmt.Errorf("ring %v has a position without a longitude and latitude", i)
+			}
+			if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+				return fmt.Errorf("ring %v has an out of range position %v", i, position)
+			}
+		}
+
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Errorf("ring %v is not closed", i)
+		}
+	}
+
+	return nil
+}
+
+// Contains reports whether the coordinate lies inside the boundary and outside every hole
+func (p Polygon) Contains(latitude float64, longitude float64) bool {
+	if len(p.Coordinates) == 0 || !ringContains(p.Coordinates[0], latitude, longitude) {
+		return false
+	}
+
+	for _, hole := range p.Coordinates[1:] {
+		if ringContains(hole, latitude, longitude) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Overlaps reports whether two polygons share any area, holes are ignored and polygons
+// that only touch along a border are reported as overlapping
+func (p Polygon) Overlaps(other Polygon) bool {
+	if len(p.Coordinates) == 0 || len(other.Coordinates) == 0 {
+		return false
+	}
+
+	ring, otherRing := p.Coordinates[0], other.Coordinates[0]
+	for i := 0; i+1 < len(ring); i++ {
+		for j := 0; j+1 < len(otherRing); j++ {
+			if segmentsIntersect(ring[i], ring[i+1], otherRing[j], otherRing[j+1]) {
+				return true
+			}
+		}
+	}
+
+	// Without crossing edges the polygons only overlap when one lies inside the other
+	return ringContains(otherRing, ring[0][1], ring[0][0]) || ringContains(ring, otherRing[0][1], otherRing[0][0])
+}
+
+// ringContains casts a ray from the coordinate and counts the edges it crosses
+func ringContains(ring [][]float64, latitude float64, longitude float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+
+		if (yi > latitude) != (yj > latitude) && longitude < (xj-xi)*(latitude-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+func segmentsIntersect(a, b, c, d []float64) bool {
+	d1 := orientation(c, d, a)
+	d2 := orientation(c, d, b)
+	d3 := orientation(a, b, c)
+	d4 := orientation(a, b, d)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	return (d1 == 0 && onSegment(c, d, a)) || (d2 == 0 && onSegment(c, d, b)) ||
+		(d3 == 0 && onSegment(a, b, c)) || (d4 == 0 && onSegment(a, b, d))
+}
+
+func orientation(a, b, c []float64) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// onSegment reports whether c, known to be collinear with a and b, lies between them
+func onSegment(a, b, c []float64) bool {
+	return c[0] >= min(a[0], b[0]) && c[0] <= max(a[0], b[0]) && c[1] >= min(a[1], b[1]) && c[1] <= max(a[1], b[1])
+}
@@ -0,0 +1,93 @@
+package geo
+
+import "testing"
+
+// square returns a closed ring for the square with its south west corner at lng, lat
+func square(lng, lat, size float64) [][]float64 {
+	return [][]float64{{lng, lat}, {lng + size, lat}, {lng + size, lat + size}, {lng, lat + size}, {lng, lat}}
+}
+
+func TestPolygonValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		polygon Polygon
+		wantErr bool
+	}{
+		{"valid square", Polygon{PolygonType, [][][]float64{square(0, 0, 1)}}, false},
+		{"valid with hole", Polygon{PolygonType, [][][]float64{square(0, 0, 4), square(1, 1, 1)}}, false},
+		{"wrong type", Polygon{"Point", [][][]float64{square(0, 0, 1)}}, true},
+		{"no rings", Polygon{PolygonType, nil}, true},
+		{"too few positions", Polygon{PolygonType, [][][]float64{{{0, 0}, {1, 0}, {0, 0}}}}, true},
+		{"missing latitude", Polygon{PolygonType, [][][]float64{{{0, 0}, {1}, {1, 1}, {0, 0}}}}, true},
+		{"longitude out of range", Polygon{PolygonType, [][][]float64{square(180, 0, 1)}}, true},
+		{"latitude out of range", Polygon{PolygonType, [][][]float64{square(0, 90, 1)}}, true},
+		{"open ring", Polygon{PolygonType, [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}}, true},
+		{"open hole", Polygon{PolygonType, [][][]float64{square(0, 0, 4), {{1, 1}, {2, 1}, {2, 2}, {1, 2}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.polygon.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	withHole := Polygon{PolygonType, [][][]float64{square(0, 0, 4), square(1, 1, 2)}}
+
+	tests := []struct {
+		name      string
+		polygon   Polygon
+		latitude  float64
+		longitude float64
+		want      bool
+	}{
+		{"inside", Polygon{PolygonType, [][][]float64{square(0, 0, 4)}}, 2, 2, true},
+		{"outside", Polygon{PolygonType, [][][]float64{square(0, 0, 4)}}, 5, 2, false},
+		{"inside boundary outside hole", withHole, 0.5, 0.5, true},
+		{"inside hole", withHole, 2, 2, false},
+		{"beyond boundary", withHole, -1, 2, false},
+		{"latitude and longitude not swapped", Polygon{PolygonType, [][][]float64{{{10, 0}, {11, 0}, {11, 1}, {10, 1}, {10, 0}}}}, 0.5, 10.5, true},
+		{"no rings", Polygon{PolygonType, nil}, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.polygon.Contains(tt.latitude, tt.longitude); got != tt.want {
+				t.Errorf("Contains(%v, %v) = %v, want %v", tt.latitude, tt.longitude, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygonOverlaps(t *testing.T) {
+	base := Polygon{PolygonType, [][][]float64{square(0, 0, 4)}}
+
+	tests := []struct {
+		name  string
+		other Polygon
+		want  bool
+	}{
+		{"crossing edges", Polygon{PolygonType, [][][]float64{square(2, 2, 4)}}, true},
+		{"other inside", Polygon{PolygonType, [][][]float64{square(1, 1, 1)}}, true},
+		{"other around", Polygon{PolygonType, [][][]float64{square(-1, -1, 6)}}, true},
+		{"touching edge", Polygon{PolygonType, [][][]float64{square(4, 0, 4)}}, true},
+		{"touching corner", Polygon{PolygonType, [][][]float64{square(4, 4, 1)}}, true},
+		{"apart", Polygon{PolygonType, [][][]float64{square(5, 5, 1)}}, false},
+		{"no rings", Polygon{PolygonType, nil}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Overlaps(tt.other); got != tt.want {
+				t.Errorf("Overlaps() = %v, want %v", got, tt.want)
+			}
+			if got := tt.other.Overlaps(base); got != tt.want {
+				t.Errorf("reversed Overlaps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
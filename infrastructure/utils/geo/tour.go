@@ -0,0 +1,94 @@
+package geo
+
+// Point is a coordinate in degrees
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// maxTwoOptPasses bounds the improvement passes, each pass is quadratic in the number of stops
+const maxTwoOptPasses = 50
+
+// PlanTour orders the stops of a round trip that starts and ends at the depot. A nearest-neighbour
+// tour is built first and then improved with 2-opt, reversing stretches of the tour while that makes
+// it shorter. The result holds the indexes of the stops in visiting order
+func PlanTour(depot Point, stops []Point) []int {
+	if len(stops) == 0 {
+		return []int{}
+	}
+
+	// Node 0 is the depot, node i+1 is stop i
+	nodes := append([]Point{depot}, stops...)
+	distances := make([][]float64, len(nodes))
+	for i := range nodes {
+		distances[i] = make([]float64, len(nodes))
+		for j := range nodes {
+			distances[i][j] = Haversine(nodes[i].Latitude, nodes[i].Longitude, nodes[j].Latitude, nodes[j].Longitude)
+		}
+	}
+
+	tour := nearestNeighbour(distances)
+	twoOpt(distances, tour)
+
+	order := make([]int, len(stops))
+	for i := range order {
+		order[i] = tour[i+1] - 1
+	}
+	return order
+}
+
+// TourDistance returns the length in kilometres of the round trip visiting the stops in order
+func TourDistance(depot Point, stops []Point, order []int) float64 {
+	distance := 0.0
+	previous := depot
+	for _, index := range order {
+		distance += Haversine(previous.Latitude, previous.Longitude, stops[index].Latitude, stops[index].Longitude)
+		previous = stops[index]
+	}
+	return distance + Haversine(previous.Latitude, previous.Longitude, depot.Latitude, depot.Longitude)
+}
+
+// nearestNeighbour returns a closed tour over the nodes starting at node 0 and going to the
+// closest unvisited node each time, node 0 appears at both ends
+func nearestNeighbour(distances [][]float64) []int {
+	visited := make([]bool, len(distances))
+	visited[0] = true
+	tour := []int{0}
+
+	current := 0
+	for len(tour) < len(distances) {
+		next := -1
+		for candidate := range distances {
+			if !visited[candidate] && (next == -1 || distances[current][candidate] < distances[current][next]) {
+				next = candidate
+			}
+		}
+		visited[next] = true
+		tour = append(tour, next)
+		current = next
+	}
+
+	return append(tour, 0)
+}
+
+// twoOpt shortens the closed tour in place, keeping the depot at both ends
+func twoOpt(distances [][]float64, tour []int) {
+	const epsilon = 1e-9
+	for pass := 0; pass < maxTwoOptPasses; pass++ {
+		improved := false
+		for i := 1; i < len(tour)-2; i++ {
+			for k := i + 1; k < len(tour)-1; k++ {
+				a, b, c, d := tour[i-1], tour[i], tour[k], tour[k+1]
+				if distances[a][c]+distances[b][d] < distances[a][b]+distances[c][d]-epsilon {
+					for left, right := i, k; left < right; left, right = left+1, right-1 {
+						tour[left], tour[right] = tour[right], tour[left]
+					}
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			return
+		}
+	}
+}
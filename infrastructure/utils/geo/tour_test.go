@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestPlanTourVisitsEveryStopOnce(t *testing.T) {
+	depot := Point{Latitude: 51.5, Longitude: -0.12}
+	stops := []Point{{51.51, -0.1}, {51.49, -0.15}, {51.52, -0.13}, {51.5, -0.09}}
+
+	order := PlanTour(depot, stops)
+	sorted := append([]int{}, order...)
+	sort.Ints(sorted)
+	for i, index := range sorted {
+		if index != i {
+			t.Fatalf("PlanTour() = %v, want every stop index once", order)
+		}
+	}
+
+	if got := PlanTour(depot, nil); len(got) != 0 {
+		t.Errorf("PlanTour() without stops = %v, want empty", got)
+	}
+}
+
+func TestPlanTourNeverLongerThanNearestNeighbour(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for run := 0; run < 200; run++ {
+		depot := Point{Latitude: 51.5, Longitude: -0.12}
+		stops := make([]Point, 1+random.Intn(20))
+		for i := range stops {
+			stops[i] = Point{Latitude: 51.4 + random.Float64()*0.2, Longitude: -0.22 + random.Float64()*0.2}
+		}
+
+		nodes := append([]Point{depot}, stops...)
+		distances := make([][]float64, len(nodes))
+		for i := range nodes {
+			distances[i] = make([]float64, len(nodes))
+			for j := range nodes {
+				distances[i][j] = Haversine(nodes[i].Latitude, nodes[i].Longitude, nodes[j].Latitude, nodes[j].Longitude)
+			}
+		}
+		tour := nearestNeighbour(distances)
+		nearest := make([]int, len(stops))
+		for i := range nearest {
+			nearest[i] = tour[i+1] - 1
+		}
+
+		planned := TourDistance(depot, stops, PlanTour(depot, stops))
+		if unimproved := TourDistance(depot, stops, nearest); planned > unimproved+1e-9 {
+			t.Fatalf("run %v: 2-opt tour is %.6f km, nearest-neighbour tour is %.6f km", run, planned, unimproved)
+		}
+	}
+}
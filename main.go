@@ -1,12 +1,23 @@
 package main
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
 	"log"
+	"os"
 
+	"github.com/harisquqo/quqo-challenge-1/application"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/auth_entity"
+	"github.com/harisquqo/quqo-challenge-1/domain/entity/customer_entity"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/auth"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/implementations/payment"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/config"
+	"github.com/harisquqo/quqo-challenge-1/infrastructure/jobs"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/persistence/base"
 	"github.com/harisquqo/quqo-challenge-1/infrastructure/routes"
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 )
 
 func init() {
@@ -24,7 +35,130 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// go run . rotate-keys [RS256|EdDSA] rotates the token signing key and exits
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		algorithm := ""
+		if len(os.Args) > 2 {
+			algorithm = os.Args[2]
+		}
+
+		if err := p.Automigrate(); err != nil {
+			log.Fatal(err)
+		}
+
+		key, err := auth.NewAuthRepository(p, nil).RotateSigningKey(algorithm)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("rotated signing key, new %v key %v is active", key.Algorithm, key.KID)
+		return
+	}
+
+	// go run . create-admin <username> <email> [--promote-existing] creates an administrator and exits,
+	// the password is read from ADMIN_PASSWORD
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		if err := p.Automigrate(); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := createAdmin(p, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := payment.CheckConfiguration(); err != nil {
+		log.Fatal(err)
+	}
+
 	router := routes.InitRouter(p)
 
+	if err := auth.NewAuthRepository(p, nil).EnsureSigningKey(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Backfill the materialized category paths for categories created before they existed
+	if err := application.NewCategoryApplication(p, nil).RebuildCategoryPaths(); err != nil {
+		log.Println(err)
+	}
+
+	jobs.StartTrashRetentionJob(p)
+	jobs.StartLoyaltyPointsJob(p)
+
     router.Run(":8080")
-}
\ No newline at end of file
+}
+// createAdmin creates a new administrator account. An account that already holds the username is
+// only promoted with --promote-existing and after the operator types the username again, so a
+// squatted signup cannot be turned into an admin by accident
+func createAdmin(p *base.Persistence, args []string) error {
+	promote := false
+	positional := []string{}
+	for _, arg := range args {
+		if arg == "--promote-existing" {
+			promote = true
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("usage: create-admin <username> <email> [--promote-existing]")
+	}
+
+	username := positional[0]
+	email := ""
+	if len(positional) > 1 {
+		email = positional[1]
+	}
+
+	existing, err := auth.NewAuthRepository(p, nil).GetCustomerWithUsername(username)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	customerApp := application.NewCustomerApplication(p, nil)
+	if existing != nil && existing.ID != 0 {
+		if existing.Role == auth_entity.RoleAdmin {
+			log.Printf("customer %v is already an admin", existing.Username)
+			return nil
+		}
+		if !promote {
+			return fmt.Errorf("username %v is already taken by customer %v, rerun with --promote-existing to make that account an admin", existing.Username, existing.ID)
+		}
+
+		fmt.Printf("promote customer %v (%v, %v) to admin? type the username again to confirm: ", existing.ID, existing.Username, existing.Email)
+		confirmation, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if customer_entity.NormalizeUsername(confirmation) != existing.Username {
+			return fmt.Errorf("promotion of %v not confirmed", existing.Username)
+		}
+
+		if _, err := customerApp.UpdateCustomerRole(existing.ID, auth_entity.RoleAdmin); err != nil {
+			return err
+		}
+
+		log.Printf("promoted customer %v to admin", existing.Username)
+		return nil
+	}
+
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		return fmt.Errorf("ADMIN_PASSWORD must be set to create an admin")
+	}
+
+	customer, errs := customerApp.SaveCustomer(customer_entity.CustomerRequest{
+		Name:     username,
+		Username: username,
+		Email:    email,
+		Password: password,
+	})
+	if errs != nil {
+		return fmt.Errorf("could not create admin: %v", errs)
+	}
+
+	if _, err := customerApp.UpdateCustomerRole(customer.ID, auth_entity.RoleAdmin); err != nil {
+		return err
+	}
+
+	log.Printf("created admin %v", customer.Username)
+	return nil
+}